@@ -1244,6 +1244,17 @@ type ChannelCloseSummary struct {
 	// funds have been swept.
 	IsPending bool
 
+	// RecoveredBalance is the total value of the time-locked outputs that
+	// were swept back into the wallet once the channel's pending funds
+	// were fully resolved. This is only set for channels that have been
+	// forcibly closed.
+	RecoveredBalance btcutil.Amount
+
+	// SweepTXIDs are the txids of the transactions that swept the
+	// channel's time-locked outputs back into the wallet, linking the
+	// force close of the channel to the recovery of its funds.
+	SweepTXIDs []chainhash.Hash
+
 	// TODO(roasbeef): also store short_chan_id?
 }
 
@@ -1401,11 +1412,22 @@ func putChannelCloseSummary(tx *bolt.Tx, chanID []byte,
 }
 
 func serializeChannelCloseSummary(w io.Writer, cs *ChannelCloseSummary) error {
-	return writeElements(w,
+	err := writeElements(w,
 		cs.ChanPoint, cs.ChainHash, cs.ClosingTXID, cs.RemotePub, cs.Capacity,
 		cs.SettledBalance, cs.TimeLockedBalance, cs.CloseType,
-		cs.IsPending,
+		cs.IsPending, cs.RecoveredBalance, uint16(len(cs.SweepTXIDs)),
 	)
+	if err != nil {
+		return err
+	}
+
+	for _, sweepTXID := range cs.SweepTXIDs {
+		if err := writeElement(w, sweepTXID); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func fetchChannelCloseSummary(tx *bolt.Tx,
@@ -1437,6 +1459,26 @@ func deserializeCloseChannelSummary(r io.Reader) (*ChannelCloseSummary, error) {
 		return nil, err
 	}
 
+	// Summaries written before the sweep details were recorded end here,
+	// so we treat an EOF as the absence of these fields.
+	var numSweeps uint16
+	err = readElements(r, &c.RecoveredBalance, &numSweeps)
+	switch {
+	case err == io.EOF:
+		return c, nil
+	case err != nil:
+		return nil, err
+	}
+
+	if numSweeps > 0 {
+		c.SweepTXIDs = make([]chainhash.Hash, numSweeps)
+	}
+	for i := range c.SweepTXIDs {
+		if err := readElement(r, &c.SweepTXIDs[i]); err != nil {
+			return nil, err
+		}
+	}
+
 	return c, nil
 }
 
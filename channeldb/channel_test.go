@@ -783,4 +783,31 @@ func TestFetchClosedChannels(t *testing.T) {
 		t.Fatalf("incorrect number of closed channels: expecting %v, "+
 			"got %v", 0, len(closed))
 	}
+
+	// Finally, record the sweeps of the channel's time-locked outputs, and
+	// ensure that they're persisted within the close summary.
+	sweepTXIDs := []chainhash.Hash{rev, key}
+	const recoveredBalance = 9000
+	err = cdb.MarkChanSwept(
+		&state.FundingOutpoint, sweepTXIDs, recoveredBalance,
+	)
+	if err != nil {
+		t.Fatalf("failed recording channel sweeps: %v", err)
+	}
+	closed, err = cdb.FetchClosedChannels(false)
+	if err != nil {
+		t.Fatalf("failed fetcing closed channels: %v", err)
+	}
+	if len(closed) != 1 {
+		t.Fatalf("incorrect number of closed channels: expecting %v, "+
+			"got %v", 1, len(closed))
+	}
+	if !reflect.DeepEqual(closed[0].SweepTXIDs, sweepTXIDs) {
+		t.Fatalf("sweep txids don't match: expected %v got %v",
+			sweepTXIDs, closed[0].SweepTXIDs)
+	}
+	if closed[0].RecoveredBalance != recoveredBalance {
+		t.Fatalf("recovered balance doesn't match: expected %v got %v",
+			recoveredBalance, closed[0].RecoveredBalance)
+	}
 }
@@ -10,7 +10,9 @@ import (
 
 	"github.com/boltdb/bolt"
 	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/chaincfg/chainhash"
 	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
 )
 
 const (
@@ -434,6 +436,28 @@ func (d *DB) FetchClosedChannels(pendingOnly bool) ([]*ChannelCloseSummary, erro
 // cooperatively closed and it's reach a single confirmation, or after all the
 // pending funds in a channel that has been forcibly closed have been swept.
 func (d *DB) MarkChanFullyClosed(chanPoint *wire.OutPoint) error {
+	return d.markChanFullyClosed(chanPoint, nil)
+}
+
+// MarkChanSwept marks a forcibly closed channel as fully closed within the
+// database, once all of its pending funds have been swept. The txids of the
+// sweep transactions and the total value recovered are recorded in the
+// channel's close summary, linking the force close to the recovery of the
+// channel's funds.
+func (d *DB) MarkChanSwept(chanPoint *wire.OutPoint,
+	sweepTXIDs []chainhash.Hash, recoveredBalance btcutil.Amount) error {
+
+	return d.markChanFullyClosed(chanPoint, func(cs *ChannelCloseSummary) {
+		cs.SweepTXIDs = sweepTXIDs
+		cs.RecoveredBalance = recoveredBalance
+	})
+}
+
+// markChanFullyClosed clears the pending flag of the channel's close summary,
+// applying the optional update to the summary before it is written back.
+func (d *DB) markChanFullyClosed(chanPoint *wire.OutPoint,
+	update func(*ChannelCloseSummary)) error {
+
 	return d.Update(func(tx *bolt.Tx) error {
 		var b bytes.Buffer
 		if err := writeOutpoint(&b, chanPoint); err != nil {
@@ -464,6 +488,9 @@ func (d *DB) MarkChanFullyClosed(chanPoint *wire.OutPoint) error {
 		}
 
 		chanSummary.IsPending = false
+		if update != nil {
+			update(chanSummary)
+		}
 
 		var newSummary bytes.Buffer
 		err = serializeChannelCloseSummary(&newSummary, chanSummary)
@@ -204,6 +204,8 @@ func (b *BtcWallet) NewAddress(t lnwallet.AddressType, change bool) (btcutil.Add
 		addrType = waddrmgr.NestedWitnessPubKey
 	case lnwallet.PubKeyHash:
 		addrType = waddrmgr.PubKeyHash
+	case lnwallet.TaprootPubKey:
+		return nil, fmt.Errorf("taproot addresses are not supported")
 	default:
 		return nil, fmt.Errorf("unknown address type")
 	}
@@ -4448,6 +4448,30 @@ type ForceCloseSummary struct {
 	// local node to sweep any outgoing HTLC"s after the timeout period has
 	// passed.
 	HtlcResolutions []OutgoingHtlcResolution
+
+	// AnchorResolution describes our anchor output on the close tx, which
+	// can be swept as soon as the close tx confirms. This is nil if the
+	// close tx has no anchor output for us.
+	AnchorResolution *AnchorResolution
+
+	// SweepFeeRate is an optional fee rate, in satoshis per unit of
+	// weight, at which the outputs above should be swept. If zero, the
+	// fee rate is determined by a fee estimate at the time of the sweep.
+	SweepFeeRate btcutil.Amount
+}
+
+// AnchorResolution houses the information required to sweep our anchor output
+// from a force closed commitment transaction. Unlike the other outputs of the
+// commitment, the anchor output carries no relative delay, and can be swept by
+// us as soon as the commitment confirms.
+type AnchorResolution struct {
+	// AnchorOutpoint is the outpoint of our anchor output on the
+	// commitment transaction.
+	AnchorOutpoint wire.OutPoint
+
+	// AnchorSignDesc is a sign descriptor that has been populated with the
+	// necessary items required to spend the anchor output.
+	AnchorSignDesc SignDescriptor
 }
 
 // ForceClose executes a unilateral closure of the transaction at the current
@@ -33,6 +33,10 @@ const (
 
 	// PubKeyHash represents a regular p2pkh output.
 	PubKeyHash
+
+	// TaprootPubKey represents a p2tr output, a version 1 witness output
+	// paying to a taproot output key.
+	TaprootPubKey
 )
 
 // Utxo is an unspent output denoted by its outpoint, and output value of the
@@ -1004,6 +1004,51 @@ func CommitSpendRevoke(signer Signer, signDesc *SignDescriptor,
 	return witnessStack, nil
 }
 
+// CommitScriptAnchor constructs the witness script of an anchor output on an
+// anchor-channel commitment transaction. The output is spendable immediately
+// by the owner of the funding key, or by anyone once 16 blocks have passed
+// since the commitment transaction confirmed.
+//
+// Possible Input Scripts:
+//     OWNER:     <sig>
+//     ANYONE:    <emptyvector> (after 16 blocks)
+//
+// Anchor Script:
+//     <funding_pubkey> OP_CHECKSIG OP_IFDUP
+//     OP_NOTIF
+//         OP_16 OP_CHECKSEQUENCEVERIFY
+//     OP_ENDIF
+func CommitScriptAnchor(key *btcec.PublicKey) ([]byte, error) {
+	builder := txscript.NewScriptBuilder()
+
+	builder.AddData(key.SerializeCompressed())
+	builder.AddOp(txscript.OP_CHECKSIG)
+	builder.AddOp(txscript.OP_IFDUP)
+	builder.AddOp(txscript.OP_NOTIF)
+	builder.AddOp(txscript.OP_16)
+	builder.AddOp(txscript.OP_CHECKSEQUENCEVERIFY)
+	builder.AddOp(txscript.OP_ENDIF)
+
+	return builder.Script()
+}
+
+// CommitSpendAnchor constructs a valid witness allowing the owner of an anchor
+// output to spend it immediately using their funding key.
+func CommitSpendAnchor(signer Signer, signDesc *SignDescriptor,
+	sweepTx *wire.MsgTx) (wire.TxWitness, error) {
+
+	sweepSig, err := signer.SignOutputRaw(sweepTx, signDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	witnessStack := wire.TxWitness(make([][]byte, 2))
+	witnessStack[0] = append(sweepSig, byte(signDesc.HashType))
+	witnessStack[1] = signDesc.WitnessScript
+
+	return witnessStack, nil
+}
+
 // CommitSpendNoDelay constructs a valid witness allowing a node to spend their
 // settled no-delay output on the counterparty's commitment transaction.
 //
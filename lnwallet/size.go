@@ -40,6 +40,12 @@ const (
 	//	- WitnessScriptSHA256: 32 bytes
 	P2WSHSize = 1 + 1 + 32
 
+	// P2TRSize 34 bytes
+	//	- OP_1: 1 byte
+	//	- OP_DATA: 1 byte (x-only public key length)
+	//	- x-only public key: 32 bytes
+	P2TRSize = 1 + 1 + 32
+
 	// P2PKHOutputSize 34 bytes
 	//      - value: 8 bytes
 	//      - var_int: 1 byte (pkscript_length)
@@ -58,6 +64,12 @@ const (
 	//      - pkscript (p2wsh): 34 bytes
 	P2WSHOutputSize = 8 + 1 + P2WSHSize
 
+	// P2TROutputSize 43 bytes
+	//      - value: 8 bytes
+	//      - var_int: 1 byte (pkscript_length)
+	//      - pkscript (p2tr): 34 bytes
+	P2TROutputSize = 8 + 1 + P2TRSize
+
 	// P2SHOutputSize 32 bytes
 	//      - value: 8 bytes
 	//      - var_int: 1 byte (pkscript_length)
@@ -210,6 +222,25 @@ const (
 	//     - witness_script (to_local_script)
 	ToLocalTimeoutWitnessSize = 1 + 1 + 73 + 1 + 1 + ToLocalScriptSize
 
+	// AnchorScriptSize 40 bytes
+	//      - OP_DATA: 1 byte (pub key len)
+	//      - funding_pubkey: 33 bytes
+	//      - OP_CHECKSIG: 1 byte
+	//      - OP_IFDUP: 1 byte
+	//      - OP_NOTIF: 1 byte
+	//      - OP_16: 1 byte
+	//      - OP_CHECKSEQUENCEVERIFY: 1 byte
+	//      - OP_ENDIF: 1 byte
+	AnchorScriptSize = 1 + 33 + 1 + 1 + 1 + 1 + 1 + 1
+
+	// AnchorWitnessSize 116 bytes
+	//      - number_of_witness_elements: 1 byte
+	//      - sig_length: 1 byte
+	//      - sig: 73 bytes
+	//      - witness_script_length: 1 byte
+	//      - witness_script (anchor_script)
+	AnchorWitnessSize = 1 + 1 + 73 + 1 + AnchorScriptSize
+
 	// ToLocalPenaltyWitnessSize 160 bytes
 	//      - number_of_witness_elements: 1 byte
 	//      - revocation_sig_length: 1 byte
@@ -446,6 +477,21 @@ func (twe *TxWeightEstimator) AddP2SHOutput() {
 	twe.outputCount++
 }
 
+// AddP2TROutput updates the weight estimate to account for an additional P2TR
+// output.
+func (twe *TxWeightEstimator) AddP2TROutput() {
+	twe.outputSize += P2TROutputSize
+	twe.outputCount++
+}
+
+// AddOutput updates the weight estimate to account for an additional output
+// paying to the given public key script.
+func (twe *TxWeightEstimator) AddOutput(pkScript []byte) {
+	twe.outputSize += 8 + wire.VarIntSerializeSize(uint64(len(pkScript))) +
+		len(pkScript)
+	twe.outputCount++
+}
+
 // Weight gets the estimated weight of the transaction.
 func (twe *TxWeightEstimator) Weight() int {
 	txSizeStripped := BaseTxSize +
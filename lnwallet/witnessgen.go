@@ -43,8 +43,37 @@ const (
 	// output that was offered to us, and for which we have a payment
 	// preimage.
 	HtlcAcceptedSuccess WitnessType = 6
+
+	// CommitmentAnchor is a witness that allows us to spend our anchor
+	// output on an anchor-channel commitment transaction.
+	CommitmentAnchor WitnessType = 7
 )
 
+// String returns a human readable name for the witness type, suitable for
+// display to users.
+func (wt WitnessType) String() string {
+	switch wt {
+	case CommitmentTimeLock:
+		return "commitment timelock"
+	case CommitmentNoDelay:
+		return "commitment no delay"
+	case CommitmentRevoke:
+		return "commitment revoke"
+	case HtlcOfferedRevoke:
+		return "offered HTLC revoke"
+	case HtlcAcceptedRevoke:
+		return "accepted HTLC revoke"
+	case HtlcOfferedTimeout:
+		return "offered HTLC timeout"
+	case HtlcAcceptedSuccess:
+		return "accepted HTLC success"
+	case CommitmentAnchor:
+		return "commitment anchor"
+	default:
+		return fmt.Sprintf("unknown witness type %d", uint16(wt))
+	}
+}
+
 // WitnessGenerator represents a function which is able to generate the final
 // witness for a particular public key script. This function acts as an
 // abstraction layer, hiding the details of the underlying script.
@@ -76,6 +105,8 @@ func (wt WitnessType) GenWitnessFunc(signer Signer,
 			return SenderHtlcSpendRevoke(signer, desc, tx)
 		case HtlcOfferedTimeout:
 			return HtlcSpendSuccess(signer, desc, tx)
+		case CommitmentAnchor:
+			return CommitSpendAnchor(signer, desc, tx)
 		default:
 			return nil, fmt.Errorf("unknown witness type: %v", wt)
 		}
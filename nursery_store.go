@@ -9,6 +9,7 @@ import (
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/roasbeef/btcd/chaincfg/chainhash"
 	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
 )
 
 //	              Overview of Nursery Store Storage Hierarchy
@@ -38,6 +39,45 @@ import (
 //   ├── last-finalized-height-key: <last-finalized-height>
 //   ├── last-graduated-height-key: <last-graduated-height>
 //   |
+//   |   LIMBO BALANCE INDEX
+//   |
+//   |   The limbo balance index maintains a running total of the value that
+//   |   has yet to graduate for each channel, allowing the balance to be
+//   |   queried without decoding every output in the channel bucket.
+//   |
+//   ├── limbo-balance-index-key/
+//   │   └── <chan-point-1>: <limbo-balance>
+//   |
+//   |   SWEEP INDEX
+//   |
+//   |   The sweep index records the txids of the sweep txns that graduated
+//   |   each channel's kindergarten outputs, such that they can be recorded
+//   |   in the channel's close summary once the channel is fully closed.
+//   |
+//   ├── sweep-index-key/
+//   │   └── <chan-point-1>/
+//   |       └── <sweep-txid-1>: ""
+//   |
+//   |   GRADUATION ARCHIVE
+//   |
+//   |   If the nursery is configured to retain graduated outputs, the
+//   |   graduated outputs of each removed channel are moved to the graduation
+//   |   archive, keyed by their outpoint.
+//   |
+//   ├── graduation-archive-key/
+//   │   └── <chan-point-1>/
+//   |       └── <outpoint-1>: <graduated-output-1>
+//   |
+//   |   DROPPED OUTPUTS
+//   |
+//   |   Outputs that were abandoned rather than incubated, e.g. because they
+//   |   were uneconomical to sweep, are recorded along with the reason why,
+//   |   such that their abandonment can be audited.
+//   |
+//   ├── dropped-outputs-key/
+//   │   └── <chan-point-1>/
+//   |       └── <outpoint-1>: <dropped-output-1>
+//   |
 //   |   CHANNEL INDEX
 //   |
 //   |   The channel index contains a directory for each channel that has a
@@ -46,9 +86,10 @@ import (
 //   |   outputs that are awaiting some state transition. The name of each file
 //   |   contains the outpoint of the spendable output in the file, and is
 //   |   prefixed with 4-byte state prefix, indicating whether the spendable
-//   |   output is a crib, preschool, or kindergarten, or graduated output. The
-//   |   nursery store supports the ability to enumerate all outputs for a
-//   |   particular channel, which is useful in constructing nursery reports.
+//   |   output is a crib, preschool, kindergarten, graduated, or resolved
+//   |   output. The nursery store supports the ability to enumerate all
+//   |   outputs for a particular channel, which is useful in constructing
+//   |   nursery reports.
 //   |
 //   ├── channel-index-key/
 //   │   ├── <chan-point-1>/                      <- CHANNEL BUCKET
@@ -75,7 +116,12 @@ import (
 //   |   output. If a height bucket is less than or equal to the current last
 //   |   finalized height and has a non-zero number of kindergarten outputs, a
 //   |   height bucket will also contain the finalized kindergarten sweep txn
-//   |   under the "finalized-kndr-txn" key.
+//   |   under the "finalized-kndr-txn" key. If the kindergarten class was
+//   |   split across multiple sweep txns, each additional txn is stored under
+//   |   the same key suffixed with its 4-byte index. The fee rate of each txn
+//   |   is stored under the "finalized-kndr-fee" key suffixed with the txn's
+//   |   4-byte index, and the number of times the txns have been broadcast
+//   |   is stored under the "sweep-broadcasts" key.
 //   |
 //   └── height-index-key/
 //       ├── <height-1>/                             <- HEIGHT BUCKET
@@ -84,7 +130,10 @@ import (
 //       |   |    └── <state-prefix><outpoint-5>: ""
 //       |   ├── <chan-point-2>/
 //       |   |    └── <state-prefix><outpoint-3>: ""
-//       |   └── finalized-kndr-txn:              "" | <kndr-sweep-tnx>
+//       |   ├── finalized-kndr-fee<index>:       <fee-rate>
+//       |   ├── finalized-kndr-txn:              "" | <kndr-sweep-tnx>
+//       |   ├── finalized-kndr-txn<index>:       <kndr-sweep-tnx>
+//       |   └── sweep-broadcasts:                <count>
 //       └── <height-2>/
 //           └── <chan-point-1>/
 //                └── <state-prefix><outpoint-1>: ""
@@ -102,24 +151,91 @@ type NurseryStore interface {
 	// potential crash.
 	Incubate(*kidOutput, []babyOutput) error
 
+	// IncubateBatch behaves like Incubate, but accepts the commitment
+	// outputs of multiple channels, persisting all of the provided outputs
+	// within a single transaction.
+	IncubateBatch([]*kidOutput, []babyOutput) error
+
 	// CribToKinder atomically moves a babyOutput in the crib bucket to the
 	// kindergarten bucket. The now mature kidOutput contained in the
 	// babyOutput will be stored as it waits out the kidOutput's CSV delay.
 	CribToKinder(*babyOutput) error
 
+	// ResolveCrib atomically moves a babyOutput from the crib bucket to
+	// the resolved bucket, removing it from the height index. This should
+	// be executed if the htlc output spent by the babyOutput's timeout txn
+	// was instead spent by another txn, such that the timeout txn can
+	// never confirm.
+	ResolveCrib(*babyOutput) error
+
 	// PreschoolToKinder atomically moves a kidOutput from the preschool
 	// bucket to the kindergarten bucket. This transition should be executed
 	// after receiving confirmation of the preschool output's commitment
 	// transaction.
 	PreschoolToKinder(*kidOutput) error
 
+	// KinderToPreschool atomically moves a kidOutput from the kindergarten
+	// bucket back to the preschool bucket. This transition should be
+	// executed if the confirmation of the commitment transaction is
+	// reorged out of the chain. The provided kidOutput must still carry
+	// the confirmation height at which it was promoted.
+	KinderToPreschool(*kidOutput) error
+
+	// RekeyPreschool replaces the preschool output stored under the given
+	// outpoint with the provided kid output, whose outpoint may differ.
+	// This is used when the channel was closed by a txn other than the
+	// commitment txn with which the output was incubated.
+	RekeyPreschool(oldOutPoint *wire.OutPoint, kid *kidOutput) error
+
 	// GraduateKinder atomically moves the kindergarten class at the
 	// provided height into the graduated status. This involves removing the
 	// kindergarten entries from both the height and channel indexes, and
-	// cleaning up the finalized kindergarten sweep txn. The height bucket
+	// cleaning up the finalized kindergarten sweep txns. The height bucket
 	// will be opportunistically pruned from the height index as outputs are
-	// removed.
-	GraduateKinder(height uint32) error
+	// removed. Each graduated output records sweepHeight, the height at
+	// which its sweep txn confirmed.
+	GraduateKinder(height, sweepHeight uint32) error
+
+	// GraduateSweep atomically moves the kindergarten outputs at the
+	// provided height that are spent by the given sweep txn into the
+	// graduated status, recording sweepHeight as the height at which the
+	// sweep confirmed. Other kindergarten outputs at the height are left
+	// untouched, and the finalized sweep txns are only cleaned up once
+	// every kindergarten output at the height has graduated. The outputs
+	// graduated by this call are returned, which are none if they have
+	// already graduated.
+	GraduateSweep(height uint32, sweepTx *wire.MsgTx,
+		sweepHeight uint32) ([]kidOutput, error)
+
+	// UngraduateKinder atomically reverts the graduation of the provided
+	// kindergarten outputs, which were swept by the given finalized txns
+	// at the provided height. This should be executed if the confirmation
+	// of the sweep txns is reorged out of the chain. The outputs are
+	// restored to the kindergarten bucket and height index, and the
+	// finalized txns are restored to the height bucket, such that they
+	// can be rebroadcast.
+	UngraduateKinder(height uint32, kids []kidOutput,
+		finalTxns []*wire.MsgTx) error
+
+	// DeferKinder moves a kindergarten output's entry in the height index
+	// from one height to another, such that the nursery will revisit the
+	// output at the later height. This is used for outputs that cannot yet
+	// be swept at their maturity height, such as outputs with time based
+	// relative locks that have not yet matured, or outputs that are too
+	// small to be swept on their own.
+	DeferKinder(kid *kidOutput, fromHeight, toHeight uint32) error
+
+	// RequeueCrib moves a crib output's entry in the height index from the
+	// given height to its CLTV expiry height. This is used to correct crib
+	// outputs that were indexed at a height preceding their expiry, whose
+	// timeout txns cannot yet be broadcast.
+	RequeueCrib(baby *babyOutput, fromHeight uint32) error
+
+	// RekeyCrib replaces the crib output stored under the given outpoint
+	// with the provided baby output, whose outpoint may differ. This is
+	// used when the output's timeout txn is combined with those of other
+	// crib outputs into a single batched txn.
+	RekeyCrib(oldOutPoint *wire.OutPoint, baby *babyOutput) error
 
 	// FetchPreschools returns a list of all outputs currently stored in the
 	// preschool bucket.
@@ -128,14 +244,36 @@ type NurseryStore interface {
 	// FetchClass returns a list of kindergarten and crib outputs whose
 	// timelocks expire at the given height. If the kindergarten class at
 	// this height hash been finalized previously, via FinalizeKinder, it
-	// will also returns the finalized kindergarten sweep txn.
-	FetchClass(height uint32) (*wire.MsgTx, []kidOutput, []babyOutput, error)
-
-	// FinalizeKinder accepts a block height and the kindergarten sweep txn
-	// computed for this height. Upon startup, we will rebroadcast any
-	// finalized kindergarten txns instead of signing a new txn, as this
-	// result in a different txid from a preceding broadcast.
-	FinalizeKinder(height uint32, tx *wire.MsgTx) error
+	// will also returns the finalized kindergarten sweep txns.
+	FetchClass(height uint32) ([]*wire.MsgTx, []kidOutput, []babyOutput,
+		error)
+
+	// FinalizeKinder accepts a block height and the kindergarten sweep txns
+	// computed for this height, along with the fee rate paid by each txn.
+	// Upon startup, we will rebroadcast any finalized kindergarten txns
+	// instead of signing new txns, as this result in different txids from
+	// a preceding broadcast.
+	FinalizeKinder(height uint32, txns []*wire.MsgTx,
+		feeRates []btcutil.Amount) error
+
+	// FinalizedSweepInfo returns a summary of each kindergarten sweep txn
+	// finalized at the given height, including the fee rate in effect when
+	// the txn was finalized.
+	FinalizedSweepInfo(height uint32) ([]SweepInfo, error)
+
+	// FetchFinalizedTxns returns the kindergarten sweep txns finalized at
+	// the given height, exactly as they were persisted by FinalizeKinder.
+	FetchFinalizedTxns(height uint32) ([]*wire.MsgTx, error)
+
+	// RecordSweepBroadcast increments the number of times the finalized
+	// kindergarten sweep txns at the given height have been broadcast,
+	// returning the updated count. The count is removed once the height's
+	// kindergarten outputs have graduated.
+	RecordSweepBroadcast(height uint32) (uint32, error)
+
+	// SweepBroadcasts returns the number of times the finalized
+	// kindergarten sweep txns at the given height have been broadcast.
+	SweepBroadcasts(height uint32) (uint32, error)
 
 	// LastFinalizedHeight returns the last block height for which the
 	// nursery store finalized a kindergarten class.
@@ -149,6 +287,17 @@ type NurseryStore interface {
 	// nursery store successfully graduated all outputs.
 	LastGraduatedHeight() (uint32, error)
 
+	// GraduateBlock records the provided height as the last graduated
+	// height, along with the hash of the block at that height, allowing
+	// the nursery to detect whether the block was reorged out while it
+	// was offline.
+	GraduateBlock(height uint32, hash *chainhash.Hash) error
+
+	// LastGraduatedBlock returns the last graduated height, along with the
+	// hash of its block as recorded by GraduateBlock. The hash is nil if
+	// the height was graduated without recording its hash.
+	LastGraduatedBlock() (uint32, *chainhash.Hash, error)
+
 	// HeightsBelowOrEqual returns the lowest non-empty heights in the
 	// height index, that exist at or below the provided upper bound.
 	HeightsBelowOrEqual(height uint32) ([]uint32, error)
@@ -163,6 +312,10 @@ type NurseryStore interface {
 	// ListChannels returns all channels the nursery is currently tracking.
 	ListChannels() ([]wire.OutPoint, error)
 
+	// ChannelExists returns true if the nursery store is tracking any
+	// outputs of the provided channel point.
+	ChannelExists(*wire.OutPoint) (bool, error)
+
 	// IsMatureChannel determines the whether or not all of the outputs in a
 	// particular channel bucket have been marked as graduated.
 	IsMatureChannel(*wire.OutPoint) (bool, error)
@@ -171,6 +324,69 @@ type NurseryStore interface {
 	// the provided channel point, this method should only be called if
 	// IsMatureChannel indicates the channel is ready for removal.
 	RemoveChannel(*wire.OutPoint) error
+
+	// ArchiveChannel behaves like RemoveChannel, except that the channel's
+	// graduated outputs are moved to the graduation archive rather than
+	// being deleted, such that they can later be retrieved via
+	// FetchArchivedGraduations.
+	ArchiveChannel(*wire.OutPoint) error
+
+	// CancelChannel erases all of the provided channel's outputs from the
+	// nursery store, regardless of their state, along with its entries in
+	// the height index. This is used when the channel's force close was
+	// superseded, such that its outputs will never mature.
+	CancelChannel(*wire.OutPoint) error
+
+	// ForceGraduateChannel atomically moves all of the provided channel's
+	// crib, preschool and kindergarten outputs to the graduated state,
+	// removing its entries in the height index. This is used when the
+	// channel's outputs were swept out-of-band.
+	ForceGraduateChannel(*wire.OutPoint) error
+
+	// FetchArchivedGraduations returns the graduated outputs of the
+	// provided channel point that were retained by ArchiveChannel.
+	FetchArchivedGraduations(*wire.OutPoint) ([]kidOutput, error)
+
+	// RecordDroppedOutputs persists the outputs of the provided channel
+	// point that were abandoned rather than incubated.
+	RecordDroppedOutputs(*wire.OutPoint, []droppedOutput) error
+
+	// FetchDroppedOutputs returns the outputs of the provided channel
+	// point that were recorded by RecordDroppedOutputs.
+	FetchDroppedOutputs(*wire.OutPoint) ([]droppedOutput, error)
+
+	// ChanLimboBalance returns the total value of all outputs for the
+	// provided channel point that have not yet graduated. The balance is
+	// maintained as outputs are incubated and graduated, such that it can
+	// be retrieved without iterating over the channel's outputs.
+	ChanLimboBalance(*wire.OutPoint) (btcutil.Amount, error)
+
+	// ChanSweepTxids returns the txids of the sweep txns that graduated
+	// the kindergarten outputs of the provided channel point.
+	ChanSweepTxids(*wire.OutPoint) ([]chainhash.Hash, error)
+
+	// RecordChannelCloseTx persists the txid of the force close txn of the
+	// provided channel point, from which its incubated outputs originate.
+	RecordChannelCloseTx(*wire.OutPoint, *chainhash.Hash) error
+
+	// ChannelCloseTx returns the txid of the force close txn of the
+	// provided channel point, as recorded by RecordChannelCloseTx. A nil
+	// txid is returned if none was recorded.
+	ChannelCloseTx(*wire.OutPoint) (*chainhash.Hash, error)
+
+	// Prune removes the empty buckets and orphaned entries left behind
+	// as outputs move through the nursery store. The pruning is performed
+	// in a single transaction, such that it is either applied in its
+	// entirety or not at all. Pruning doesn't shrink the database file.
+	Prune() error
+
+	// Update executes the provided closure within a single database
+	// transaction, passing it a NurseryStore whose operations are all
+	// performed within that transaction. The writes made by the closure
+	// are only persisted if it returns nil, allowing several operations to
+	// be applied atomically. The store passed to the closure must not be
+	// used after it returns.
+	Update(func(NurseryStore) error) error
 }
 
 var (
@@ -186,6 +402,11 @@ var (
 	// the last bucket that successfully graduated all outputs.
 	lastGraduatedHeightKey = []byte("last-graduated-height")
 
+	// lastGraduatedHashKey is a static key used to retrieve the hash of
+	// the block at the last graduated height, stored alongside the height
+	// it belongs to.
+	lastGraduatedHashKey = []byte("last-graduated-hash")
+
 	// channelIndexKey is a static key used to lookup the bucket containing
 	// all of the nursery's active channels.
 	channelIndexKey = []byte("channel-index")
@@ -195,11 +416,55 @@ var (
 	// action.
 	heightIndexKey = []byte("height-index")
 
+	// limboBalanceIndexKey is a static key used to lookup the bucket
+	// containing the running limbo balance of each active channel.
+	limboBalanceIndexKey = []byte("limbo-balance-index")
+
+	// sweepIndexKey is a static key used to lookup the bucket containing
+	// the txids of the sweep txns that graduated each channel's outputs.
+	sweepIndexKey = []byte("sweep-index")
+
+	// closeTxIndexKey is a static key used to lookup the bucket containing
+	// the txid of the force close txn of each active channel.
+	closeTxIndexKey = []byte("close-tx-index")
+
+	// graduationArchiveKey is a static key used to lookup the bucket
+	// containing the graduated outputs of channels that have been archived.
+	graduationArchiveKey = []byte("graduation-archive")
+
+	// droppedOutputsKey is a static key used to lookup the bucket
+	// containing the outputs of each channel that were abandoned rather
+	// than incubated.
+	droppedOutputsKey = []byte("dropped-outputs")
+
 	// finalizedKndrTxnKey is a static key that can be used to locate a
 	// finalized kindergarten sweep txn.
 	finalizedKndrTxnKey = []byte("finalized-kndr-txn")
+
+	// finalizedKndrFeeRateKey is a static key that can be used to locate
+	// the fee rate of a finalized kindergarten sweep txn.
+	finalizedKndrFeeRateKey = []byte("finalized-kndr-fee")
+
+	// sweepBroadcastsKey is a static key that can be used to locate the
+	// number of times the finalized kindergarten sweep txns at a height
+	// have been broadcast.
+	sweepBroadcastsKey = []byte("sweep-broadcasts")
 )
 
+// SweepInfo summarizes a finalized kindergarten sweep txn.
+type SweepInfo struct {
+	// Txid is the txid of the sweep txn.
+	Txid chainhash.Hash
+
+	// FeeRate is the fee rate, in satoshis per unit of weight, that was in
+	// effect when the sweep txn was finalized. A zero value indicates that
+	// the txn was finalized before fee rates were recorded.
+	FeeRate btcutil.Amount
+
+	// SweptAmount is the total value paid to the outputs of the sweep txn.
+	SweptAmount btcutil.Amount
+}
+
 // Defines the state prefixes that will be used to persistently track an
 // output's progress through the nursery.
 // NOTE: Each state prefix MUST be exactly 4 bytes in length, the nursery logic
@@ -228,6 +493,13 @@ var (
 	// this serves as a persistent marker that the nursery should mark the
 	// channel fully closed in the channeldb.
 	gradPrefix = []byte("grad")
+
+	// rslvPrefix is the state prefix given to htlc outputs that were
+	// resolved externally, as the htlc output spent by their timeout txn
+	// was instead claimed by another txn, e.g. the counterparty's success
+	// txn. Like graduated outputs, resolved outputs are in a terminal
+	// state, and don't prevent the channel from being marked fully closed.
+	rslvPrefix = []byte("rslv")
 )
 
 // prefixChainKey creates the root level keys for the nursery store. The keys
@@ -277,6 +549,11 @@ type nurseryStore struct {
 	db        *channeldb.DB
 
 	pfxChainKey []byte
+
+	// tx, if non-nil, is the database transaction within which all
+	// operations of the store are performed. This is only set for the
+	// stores passed to the closures of Update.
+	tx *bolt.Tx
 }
 
 // newNurseryStore accepts a chain hash and a channeldb.DB instance, returning
@@ -300,15 +577,74 @@ func newNurseryStore(chainHash *chainhash.Hash,
 	}, nil
 }
 
+// Update executes the provided closure within a single database transaction,
+// passing it a nurseryStore bound to that transaction. If the store is already
+// bound to a transaction, the closure joins it.
+func (ns *nurseryStore) Update(f func(NurseryStore) error) error {
+	if ns.tx != nil {
+		return f(ns)
+	}
+
+	return ns.db.Update(func(tx *bolt.Tx) error {
+		return f(&nurseryStore{
+			chainHash:   ns.chainHash,
+			db:          ns.db,
+			pfxChainKey: ns.pfxChainKey,
+			tx:          tx,
+		})
+	})
+}
+
+// update executes the provided closure within a read-write database
+// transaction, using the store's bound transaction if it has one.
+func (ns *nurseryStore) update(f func(tx *bolt.Tx) error) error {
+	if ns.tx != nil {
+		return f(ns.tx)
+	}
+
+	return ns.db.Update(f)
+}
+
+// view executes the provided closure within a read-only database transaction,
+// using the store's bound transaction if it has one.
+func (ns *nurseryStore) view(f func(tx *bolt.Tx) error) error {
+	if ns.tx != nil {
+		return f(ns.tx)
+	}
+
+	return ns.db.View(f)
+}
+
 // Incubate persists the beginning of the incubation process for the CSV-delayed
 // commitment output and a list of two-stage htlc outputs.
 func (ns *nurseryStore) Incubate(kid *kidOutput, babies []babyOutput) error {
-	return ns.db.Update(func(tx *bolt.Tx) error {
-		// Store commitment output in preschool bucket if not nil.
-		if kid != nil {
+	var kids []*kidOutput
+	if kid != nil {
+		kids = append(kids, kid)
+	}
+
+	return ns.IncubateBatch(kids, babies)
+}
+
+// IncubateBatch persists the beginning of the incubation process for the
+// CSV-delayed commitment outputs and two-stage htlc outputs of any number of
+// channels in a single transaction. This allows many channels that were force
+// closed at once to be incubated without a separate write for each of them.
+func (ns *nurseryStore) IncubateBatch(kids []*kidOutput,
+	babies []babyOutput) error {
+
+	return ns.update(func(tx *bolt.Tx) error {
+		// Store each commitment output in the preschool bucket.
+		for _, kid := range kids {
 			if err := ns.enterPreschool(tx, kid); err != nil {
 				return err
 			}
+
+			err := ns.adjustLimboBalance(tx, kid.OriginChanPoint(),
+				int64(kid.Amount()))
+			if err != nil {
+				return err
+			}
 		}
 
 		// Add all htlc outputs to the crib bucket.
@@ -316,6 +652,12 @@ func (ns *nurseryStore) Incubate(kid *kidOutput, babies []babyOutput) error {
 			if err := ns.enterCrib(tx, &baby); err != nil {
 				return err
 			}
+
+			err := ns.adjustLimboBalance(tx, baby.OriginChanPoint(),
+				int64(baby.Amount()))
+			if err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -326,7 +668,7 @@ func (ns *nurseryStore) Incubate(kid *kidOutput, babies []babyOutput) error {
 // kindergarten bucket. The now mature kidOutput contained in the babyOutput
 // will be stored as it waits out the kidOutput's CSV delay.
 func (ns *nurseryStore) CribToKinder(bby *babyOutput) error {
-	return ns.db.Update(func(tx *bolt.Tx) error {
+	return ns.update(func(tx *bolt.Tx) error {
 
 		// First, retrieve or create the channel bucket corresponding to
 		// the baby output's origin channel point.
@@ -381,8 +723,9 @@ func (ns *nurseryStore) CribToKinder(bby *babyOutput) error {
 
 		// Now, compute the height at which this kidOutput's CSV delay
 		// will expire.  This is done by adding the required delay to
-		// the block height at which the output was confirmed.
-		maturityHeight := bby.ConfHeight() + bby.BlocksToMaturity()
+		// the block height at which the output was confirmed, or an
+		// estimate thereof for time based delays.
+		maturityHeight := bby.MaturityHeight()
 
 		// Retrive or create a height-channel bucket corresponding to
 		// the kidOutput's maturity height.
@@ -400,11 +743,64 @@ func (ns *nurseryStore) CribToKinder(bby *babyOutput) error {
 	})
 }
 
+// ResolveCrib atomically moves a babyOutput from the crib bucket to the
+// resolved bucket, removes its entry in the height index, and deducts its value
+// from the channel's limbo balance. This transition should be executed if the
+// htlc output spent by the babyOutput's timeout txn was spent by a different
+// txn.
+func (ns *nurseryStore) ResolveCrib(bby *babyOutput) error {
+	return ns.update(func(tx *bolt.Tx) error {
+		chanPoint := bby.OriginChanPoint()
+		chanBucket := ns.getChannelBucket(tx, chanPoint)
+		if chanBucket == nil {
+			return ErrContractNotFound
+		}
+
+		pfxOutputKey, err := prefixOutputKey(cribPrefix, bby.OutPoint())
+		if err != nil {
+			return err
+		}
+
+		// If the output is no longer in the crib, it has already been
+		// resolved or promoted, so there is nothing left to do.
+		babyBytes := chanBucket.Get(pfxOutputKey)
+		if babyBytes == nil {
+			return nil
+		}
+
+		// Copy the serialized output, as it must outlive its deletion
+		// from the channel bucket.
+		babyBytes = append([]byte(nil), babyBytes...)
+
+		if err := chanBucket.Delete(pfxOutputKey); err != nil {
+			return err
+		}
+
+		err = ns.removeOutputFromHeight(tx, bby.expiry, chanPoint,
+			pfxOutputKey)
+		if err != nil {
+			return err
+		}
+
+		// Retain the output in the channel bucket under the resolved
+		// prefix, recording that it was claimed by another txn.
+		copy(pfxOutputKey, rslvPrefix)
+		if err := chanBucket.Put(pfxOutputKey, babyBytes); err != nil {
+			return err
+		}
+
+		// The output will never be swept by the nursery, so its value
+		// is no longer in limbo.
+		return ns.adjustLimboBalance(tx, chanPoint,
+			-int64(bby.Amount()))
+	})
+}
+
 // PreschoolToKinder atomically moves a kidOutput from the preschool bucket to
 // the kindergarten bucket. This transition should be executed after receiving
 // confirmation of the preschool output's commitment transaction.
 func (ns *nurseryStore) PreschoolToKinder(kid *kidOutput) error {
-	return ns.db.Update(func(tx *bolt.Tx) error {
+	return ns.update(func(tx *bolt.Tx) error {
 
 		// Create or retrieve the channel bucket corresponding to the
 		// kid output's origin channel point.
@@ -456,8 +852,9 @@ func (ns *nurseryStore) PreschoolToKinder(kid *kidOutput) error {
 		// to revisit this output once it has fully matured.
 
 		// Compute the maturity height, by adding the output's CSV delay
-		// to its confirmation height.
-		maturityHeight := kid.ConfHeight() + kid.BlocksToMaturity()
+		// to its confirmation height, or an estimate thereof for time
+		// based delays.
+		maturityHeight := kid.MaturityHeight()
 
 		// Create or retrieve the height-channel bucket for this
 		// channel. This method will first create a height bucket for
@@ -476,27 +873,88 @@ func (ns *nurseryStore) PreschoolToKinder(kid *kidOutput) error {
 	})
 }
 
+// KinderToPreschool atomically moves a kidOutput from the kindergarten bucket
+// back to the preschool bucket, removing its entry from the height index. This
+// transition should be executed if the confirmation of the commitment
+// transaction is reorged out of the chain.
+func (ns *nurseryStore) KinderToPreschool(kid *kidOutput) error {
+	return ns.update(func(tx *bolt.Tx) error {
+		chanPoint := kid.OriginChanPoint()
+		chanBucket := ns.getChannelBucket(tx, chanPoint)
+		if chanBucket == nil {
+			return ErrContractNotFound
+		}
+
+		// Ensure the output is still in the kindergarten bucket, as it
+		// may have already been swept.
+		pfxOutputKey, err := prefixOutputKey(kndrPrefix, kid.OutPoint())
+		if err != nil {
+			return err
+		}
+		if chanBucket.Get(pfxOutputKey) == nil {
+			return ErrKinderNotFound
+		}
+
+		// Remove the output from the height index at the maturity
+		// height computed from its previous confirmation height.
+		err = ns.removeOutputFromHeight(tx, kid.MaturityHeight(),
+			chanPoint, pfxOutputKey)
+		if err != nil {
+			return err
+		}
+
+		// And remove the kindergarten output from the channel bucket.
+		if err := chanBucket.Delete(pfxOutputKey); err != nil {
+			return err
+		}
+
+		// Finally, store the output under its preschool key, having
+		// cleared the confirmation height that is no longer valid.
+		psclKid := *kid
+		psclKid.SetConfHeight(0)
+
+		return ns.enterPreschool(tx, &psclKid)
+	})
+}
+
 // GraduateKinder atomically moves the kindergarten class at the provided height
 // into the graduated status. This involves removing the kindergarten entries
 // from both the height and channel indexes, and cleaning up the finalized
-// kindergarten sweep txn. The height bucket will be opportunistically pruned
-// from the height index as outputs are removed.
-func (ns *nurseryStore) GraduateKinder(height uint32) error {
-	return ns.db.Update(func(tx *bolt.Tx) error {
+// kindergarten sweep txns. The height bucket will be opportunistically pruned
+// from the height index as outputs are removed. The confirmation height of the
+// sweep txn is recorded in each graduated output.
+func (ns *nurseryStore) GraduateKinder(height, sweepHeight uint32) error {
+	return ns.update(func(tx *bolt.Tx) error {
 
 		// Since all kindergarten outputs at a particular height are
-		// swept in a single txn, we can now safely delete the finalized
-		// txn, since it has already been broadcast and confirmed.
+		// graduated together once each of the height's sweep txns has
+		// confirmed, we can now safely delete the finalized txns.
 		hghtBucket := ns.getHeightBucket(tx, height)
 		if hghtBucket == nil {
 			// Nothing to delete, bucket has already been removed.
 			return nil
 		}
 
-		// Remove the finalized kindergarten txn, we do this before
-		// removing the outputs so that the extra entry doesn't prevent
+		// Before removing the finalized txns, index the txid of the
+		// txn sweeping each output, so that the sweeps can later be
+		// attributed to the outputs' channels.
+		finalTxns, err := ns.getFinalizedTxns(tx, height)
+		if err != nil {
+			return err
+		}
+
+		sweepTxids := make(map[wire.OutPoint]chainhash.Hash)
+		for _, finalTx := range finalTxns {
+			txid := finalTx.TxHash()
+			for _, txIn := range finalTx.TxIn {
+				sweepTxids[txIn.PreviousOutPoint] = txid
+			}
+		}
+
+		// Remove the finalized kindergarten txns, we do this before
+		// removing the outputs so that the extra entries don't prevent
 		// the height bucket from being opportunistically pruned below.
-		if err := hghtBucket.Delete(finalizedKndrTxnKey); err != nil {
+		if err := removeFinalizedTxns(hghtBucket); err != nil {
 			return err
 		}
 
@@ -511,160 +969,578 @@ func (ns *nurseryStore) GraduateKinder(height uint32) error {
 					return err
 				}
 
-				outpoint := kid.OutPoint()
-				chanPoint := kid.OriginChanPoint()
-
-				// Construct the key under which the output is
-				// currently stored height and channel indexes.
-				pfxOutputKey, err := prefixOutputKey(kndrPrefix,
-					outpoint)
-				if err != nil {
-					return err
+				// Record the txid of the txn that swept the
+				// output, if it was finalized at this height.
+				var sweepTxid *chainhash.Hash
+				if txid, ok := sweepTxids[*kid.OutPoint()]; ok {
+					sweepTxid = &txid
 				}
 
-				// Remove the grad output's entry in the height
-				// index.
-				err = ns.removeOutputFromHeight(tx, height,
-					chanPoint, pfxOutputKey)
-				if err != nil {
-					return err
-				}
+				return ns.graduateOutput(tx, height,
+					sweepHeight, &kid, sweepTxid)
+			},
+		)
+	})
+}
 
-				chanBucket := ns.getChannelBucket(tx,
-					chanPoint)
-				if chanBucket == nil {
-					return ErrContractNotFound
-				}
+// GraduateSweep atomically moves the kindergarten outputs at the provided
+// height that are spent by the given sweep txn into the graduated status,
+// leaving any other kindergarten outputs at the height untouched. This allows
+// the outputs of each sweep txn finalized at a height to graduate as soon as
+// their own sweep confirms. The finalized sweep txns are only removed once no
+// kindergarten outputs remain at the height, such that the sweeps still
+// awaiting confirmation can be rebroadcast after a restart. The graduated
+// outputs are returned.
+func (ns *nurseryStore) GraduateSweep(height uint32, sweepTx *wire.MsgTx,
+	sweepHeight uint32) ([]kidOutput, error) {
+
+	var graduating []kidOutput
+	err := ns.update(func(tx *bolt.Tx) error {
+		hghtBucket := ns.getHeightBucket(tx, height)
+		if hghtBucket == nil {
+			// Nothing to graduate, bucket has already been
+			// removed.
+			return nil
+		}
+
+		spent := make(map[wire.OutPoint]struct{}, len(sweepTx.TxIn))
+		for _, txIn := range sweepTx.TxIn {
+			spent[txIn.PreviousOutPoint] = struct{}{}
+		}
 
-				// Remove previous output with kindergarten
-				// prefix.
-				err = chanBucket.Delete(pfxOutputKey)
+		// Partition the kindergarten outputs at this height into those
+		// spent by the sweep txn, and those still awaiting their own.
+		var numPending int
+		err := ns.forEachHeightPrefix(tx, kndrPrefix, height,
+			func(v []byte) error {
+				var kid kidOutput
+				err := kid.Decode(bytes.NewReader(v))
 				if err != nil {
 					return err
 				}
 
-				// Convert kindergarten key to graduate key.
-				copy(pfxOutputKey, gradPrefix)
-
-				var gradBuffer bytes.Buffer
-				if err := kid.Encode(&gradBuffer); err != nil {
-					return err
+				if _, ok := spent[*kid.OutPoint()]; !ok {
+					numPending++
+					return nil
 				}
 
-				// Insert serialized output into channel bucket
-				// using graduate-prefixed key.
-				return chanBucket.Put(pfxOutputKey,
-					gradBuffer.Bytes())
+				graduating = append(graduating, kid)
+				return nil
 			},
 		)
-	})
-}
-
-// FinalizeKinder accepts a block height and a finalized kindergarten sweep
-// transaction, persisting the transaction at the appropriate height bucket. The
-// nursery store's last finalized height is also updated with the provided
-// height.
-func (ns *nurseryStore) FinalizeKinder(height uint32,
-	finalTx *wire.MsgTx) error {
+		if err != nil {
+			return err
+		}
 
-	return ns.db.Update(func(tx *bolt.Tx) error {
-		return ns.finalizeKinder(tx, height, finalTx)
-	})
-}
+		// If this sweep graduates the last of the height's outputs,
+		// the finalized txns are removed before the outputs, so that
+		// the height bucket can be opportunistically pruned below.
+		if numPending == 0 {
+			if err := removeFinalizedTxns(hghtBucket); err != nil {
+				return err
+			}
+		}
 
-// GraduateHeight persists the provided height as the nursery store's last
-// graduated height.
-func (ns *nurseryStore) GraduateHeight(height uint32) error {
+		sweepTxid := sweepTx.TxHash()
+		for i := range graduating {
+			err := ns.graduateOutput(tx, height, sweepHeight,
+				&graduating[i], &sweepTxid)
+			if err != nil {
+				return err
+			}
+		}
 
-	return ns.db.Update(func(tx *bolt.Tx) error {
-		return ns.putLastGraduatedHeight(tx, height)
+		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return graduating, nil
 }
 
-// FetchClass returns a list of babyOutputs in the crib bucket whose CLTV
-// delay expires at the provided block height.
-// FetchClass returns a list of the kindergarten and crib outputs whose timeouts
-// are expiring
-func (ns *nurseryStore) FetchClass(
-	height uint32) (*wire.MsgTx, []kidOutput, []babyOutput, error) {
+// graduateOutput moves a kindergarten output at the given height into the
+// graduated status, removing its entry from the height index and recording
+// the height at which its sweep confirmed. If known, the txid of the sweep txn
+// is added to the channel's sweep index.
+func (ns *nurseryStore) graduateOutput(tx *bolt.Tx, height,
+	sweepHeight uint32, kid *kidOutput, sweepTxid *chainhash.Hash) error {
 
-	// Construct list of all crib and kindergarten outputs that need to be
-	// processed at the provided block height.
-	var finalTx *wire.MsgTx
-	var kids []kidOutput
-	var babies []babyOutput
-	if err := ns.db.View(func(tx *bolt.Tx) error {
+	outpoint := kid.OutPoint()
+	chanPoint := kid.OriginChanPoint()
 
-		var err error
-		finalTx, err = ns.getFinalizedTxn(tx, height)
-		if err != nil {
-			return err
-		}
+	// Construct the key under which the output is currently stored height
+	// and channel indexes.
+	pfxOutputKey, err := prefixOutputKey(kndrPrefix, outpoint)
+	if err != nil {
+		return err
+	}
 
-		// Append each crib output to our list of babyOutputs.
-		if err = ns.forEachHeightPrefix(tx, cribPrefix, height,
-			func(buf []byte) error {
+	// Remove the grad output's entry in the height index.
+	err = ns.removeOutputFromHeight(tx, height, chanPoint, pfxOutputKey)
+	if err != nil {
+		return err
+	}
 
-				// We will attempt to deserialize all outputs
-				// stored with the crib prefix into babyOutputs,
-				// since this is the expected type that would
-				// have been serialized previously.
-				var baby babyOutput
-				babyReader := bytes.NewReader(buf)
-				if err := baby.Decode(babyReader); err != nil {
-					return err
-				}
+	chanBucket := ns.getChannelBucket(tx, chanPoint)
+	if chanBucket == nil {
+		return ErrContractNotFound
+	}
 
-				babies = append(babies, baby)
+	// Remove previous output with kindergarten prefix.
+	if err := chanBucket.Delete(pfxOutputKey); err != nil {
+		return err
+	}
 
-				return nil
+	// The output is no longer in limbo, so deduct its value from the
+	// channel's limbo balance.
+	err = ns.adjustLimboBalance(tx, chanPoint, -int64(kid.Amount()))
+	if err != nil {
+		return err
+	}
 
-			},
-		); err != nil {
+	if sweepTxid != nil {
+		err := ns.addChanSweepTxid(tx, chanPoint, sweepTxid)
+		if err != nil {
 			return err
 		}
+	}
 
-		// Append each kindergarten output to our list of kidOutputs.
-		return ns.forEachHeightPrefix(tx, kndrPrefix, height,
-			func(buf []byte) error {
-				// We will attempt to deserialize all outputs
-				// stored with the kindergarten prefix into
-				// kidOutputs, since this is the expected type
-				// that would have been serialized previously.
-				var kid kidOutput
-				kidReader := bytes.NewReader(buf)
-				if err := kid.Decode(kidReader); err != nil {
-					return err
-				}
-
-				kids = append(kids, kid)
-
-				return nil
+	// Convert kindergarten key to graduate key.
+	copy(pfxOutputKey, gradPrefix)
 
-			})
+	kid.sweepConfHeight = sweepHeight
 
-	}); err != nil {
-		return nil, nil, nil, err
+	var gradBuffer bytes.Buffer
+	if err := kid.Encode(&gradBuffer); err != nil {
+		return err
 	}
 
-	return finalTx, kids, babies, nil
+	// Insert serialized output into channel bucket using graduate-prefixed
+	// key.
+	return chanBucket.Put(pfxOutputKey, gradBuffer.Bytes())
 }
 
-// FetchPreschools returns a list of all outputs currently stored in the
-// preschool bucket.
-func (ns *nurseryStore) FetchPreschools() ([]kidOutput, error) {
-	var kids []kidOutput
-	if err := ns.db.View(func(tx *bolt.Tx) error {
+// DeferKinder moves a kindergarten output's entry in the height index from
+// fromHeight to toHeight. The output's entry in the channel index is left
+// unmodified.
+func (ns *nurseryStore) DeferKinder(kid *kidOutput, fromHeight,
+	toHeight uint32) error {
 
-		// Retrieve the existing chain bucket for this nursery store.
-		chainBucket := tx.Bucket(ns.pfxChainKey)
-		if chainBucket == nil {
-			return nil
+	return ns.update(func(tx *bolt.Tx) error {
+		chanPoint := kid.OriginChanPoint()
+
+		pfxOutputKey, err := prefixOutputKey(kndrPrefix, kid.OutPoint())
+		if err != nil {
+			return err
 		}
 
-		// Load the existing channel index from the chain bucket.
-		chanIndex := chainBucket.Bucket(channelIndexKey)
-		if chanIndex == nil {
+		// Remove the output from its current height, pruning the
+		// height bucket if it was the last output at this height.
+		err = ns.removeOutputFromHeight(tx, fromHeight, chanPoint,
+			pfxOutputKey)
+		if err != nil {
+			return err
+		}
+
+		// Then, register the output at the new height.
+		hghtChanBucket, err := ns.createHeightChanBucket(tx, toHeight,
+			chanPoint)
+		if err != nil {
+			return err
+		}
+
+		return hghtChanBucket.Put(pfxOutputKey, []byte{})
+	})
+}
+
+// RequeueCrib moves a crib output's entry in the height index from the
+// provided height to the output's CLTV expiry height, such that its timeout txn
+// is broadcast once it can be included in the chain.
+func (ns *nurseryStore) RequeueCrib(baby *babyOutput, fromHeight uint32) error {
+	return ns.update(func(tx *bolt.Tx) error {
+		chanPoint := baby.OriginChanPoint()
+
+		pfxOutputKey, err := prefixOutputKey(cribPrefix, baby.OutPoint())
+		if err != nil {
+			return err
+		}
+
+		// Remove the output from its current height, pruning the
+		// height bucket if it was the last output at this height.
+		err = ns.removeOutputFromHeight(tx, fromHeight, chanPoint,
+			pfxOutputKey)
+		if err != nil {
+			return err
+		}
+
+		// Then, register the output at its expiry height.
+		hghtChanBucket, err := ns.createHeightChanBucket(tx,
+			baby.expiry, chanPoint)
+		if err != nil {
+			return err
+		}
+
+		return hghtChanBucket.Put(pfxOutputKey, []byte{})
+	})
+}
+
+// RekeyCrib replaces the crib output stored under the provided outpoint with
+// the given baby output, which is stored under its own outpoint at its expiry
+// height. An error is returned if no crib output exists under the old
+// outpoint.
+func (ns *nurseryStore) RekeyCrib(oldOutPoint *wire.OutPoint,
+	baby *babyOutput) error {
+
+	return ns.update(func(tx *bolt.Tx) error {
+		chanPoint := baby.OriginChanPoint()
+		chanBucket := ns.getChannelBucket(tx, chanPoint)
+		if chanBucket == nil {
+			return fmt.Errorf("channel %v not found", chanPoint)
+		}
+
+		pfxOutputKey, err := prefixOutputKey(cribPrefix, oldOutPoint)
+		if err != nil {
+			return err
+		}
+
+		if chanBucket.Get(pfxOutputKey) == nil {
+			return fmt.Errorf("crib output %v not found",
+				oldOutPoint)
+		}
+
+		// The new output is entered before the old one is removed, such
+		// that the height bucket isn't pruned in between.
+		if err := ns.enterCrib(tx, baby); err != nil {
+			return err
+		}
+
+		if err := chanBucket.Delete(pfxOutputKey); err != nil {
+			return err
+		}
+
+		return ns.removeOutputFromHeight(tx, baby.expiry, chanPoint,
+			pfxOutputKey)
+	})
+}
+
+// UngraduateKinder reverts the graduation of the provided kindergarten outputs,
+// whose sweep txns' confirmation was reorged out of the chain. Each output is
+// moved from the graduated state back into the kindergarten bucket and the
+// height index at the given height, its value is restored to the channel's
+// limbo balance, and the txids of the sweeps spending it are removed from the
+// sweep index. The finalized sweep txns are restored to the height bucket,
+// without modifying the last finalized height, so that they can be
+// rebroadcast.
+func (ns *nurseryStore) UngraduateKinder(height uint32, kids []kidOutput,
+	finalTxns []*wire.MsgTx) error {
+
+	return ns.update(func(tx *bolt.Tx) error {
+		for i := range kids {
+			kid := kids[i]
+			outpoint := kid.OutPoint()
+			chanPoint := kid.OriginChanPoint()
+
+			// If the channel has already been removed, its
+			// graduation can no longer be reverted.
+			chanBucket := ns.getChannelBucket(tx, chanPoint)
+			if chanBucket == nil {
+				return ErrContractNotFound
+			}
+
+			pfxOutputKey, err := prefixOutputKey(gradPrefix,
+				outpoint)
+			if err != nil {
+				return err
+			}
+
+			// Skip any outputs that are not currently graduated.
+			if chanBucket.Get(pfxOutputKey) == nil {
+				continue
+			}
+
+			if err := chanBucket.Delete(pfxOutputKey); err != nil {
+				return err
+			}
+
+			// Store the output under its kindergarten key, without
+			// the height at which its sweep had confirmed.
+			copy(pfxOutputKey, kndrPrefix)
+			kid.sweepConfHeight = 0
+
+			var kidBuffer bytes.Buffer
+			if err := kid.Encode(&kidBuffer); err != nil {
+				return err
+			}
+
+			err = chanBucket.Put(pfxOutputKey, kidBuffer.Bytes())
+			if err != nil {
+				return err
+			}
+
+			// Restore the output's entry in the height index, such
+			// that it is swept again at the provided height.
+			hghtChanBucket, err := ns.createHeightChanBucket(tx,
+				height, chanPoint)
+			if err != nil {
+				return err
+			}
+
+			err = hghtChanBucket.Put(pfxOutputKey, []byte{})
+			if err != nil {
+				return err
+			}
+
+			// The output is once again in limbo.
+			err = ns.adjustLimboBalance(tx, chanPoint,
+				int64(kid.Amount()))
+			if err != nil {
+				return err
+			}
+
+			// Only the txids of the sweeps spending the output are
+			// removed, as the channel's other outputs may have
+			// been graduated by the height's other sweeps.
+			for _, finalTx := range finalTxns {
+				if !spendsOutpoint(finalTx, outpoint) {
+					continue
+				}
+
+				txid := finalTx.TxHash()
+				err := ns.removeChanSweepTxid(tx, chanPoint,
+					&txid)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		hghtBucket := ns.getHeightBucket(tx, height)
+		if hghtBucket == nil {
+			return nil
+		}
+
+		return putFinalizedTxns(hghtBucket, finalTxns, nil)
+	})
+}
+
+// spendsOutpoint returns true if any of the txn's inputs spend the outpoint.
+func spendsOutpoint(txn *wire.MsgTx, outpoint *wire.OutPoint) bool {
+	for _, txIn := range txn.TxIn {
+		if txIn.PreviousOutPoint == *outpoint {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FinalizeKinder accepts a block height and the finalized kindergarten sweep
+// transactions, persisting the transactions at the appropriate height bucket.
+// The nursery store's last finalized height is also updated with the provided
+// height.
+func (ns *nurseryStore) FinalizeKinder(height uint32,
+	finalTxns []*wire.MsgTx, feeRates []btcutil.Amount) error {
+
+	if feeRates != nil && len(feeRates) != len(finalTxns) {
+		return fmt.Errorf("expected %d fee rates for finalized txns, "+
+			"got %d", len(finalTxns), len(feeRates))
+	}
+
+	return ns.update(func(tx *bolt.Tx) error {
+		return ns.finalizeKinder(tx, height, finalTxns, feeRates)
+	})
+}
+
+// FinalizedSweepInfo returns a summary of each kindergarten sweep txn
+// finalized at the given height, in the order in which they were finalized.
+func (ns *nurseryStore) FinalizedSweepInfo(height uint32) ([]SweepInfo, error) {
+	var sweepInfos []SweepInfo
+	if err := ns.view(func(tx *bolt.Tx) error {
+		finalTxns, err := ns.getFinalizedTxns(tx, height)
+		if err != nil {
+			return err
+		}
+
+		hghtBucket := ns.getHeightBucket(tx, height)
+		for i, finalTx := range finalTxns {
+			sweepInfo := SweepInfo{
+				Txid: finalTx.TxHash(),
+			}
+			for _, txOut := range finalTx.TxOut {
+				sweepInfo.SweptAmount += btcutil.Amount(
+					txOut.Value,
+				)
+			}
+
+			// Txns finalized before fee rates were recorded will
+			// have no accompanying fee rate.
+			feeRateBytes := hghtBucket.Get(finalizedFeeRateKey(i))
+			if len(feeRateBytes) == 8 {
+				sweepInfo.FeeRate = btcutil.Amount(
+					byteOrder.Uint64(feeRateBytes),
+				)
+			}
+
+			sweepInfos = append(sweepInfos, sweepInfo)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return sweepInfos, nil
+}
+
+// FetchFinalizedTxns returns the kindergarten sweep txns finalized at the
+// given height, in the order in which they were finalized. Nil is returned if
+// the height has not been finalized.
+func (ns *nurseryStore) FetchFinalizedTxns(height uint32) ([]*wire.MsgTx,
+	error) {
+
+	var finalTxns []*wire.MsgTx
+	if err := ns.view(func(tx *bolt.Tx) error {
+		var err error
+		finalTxns, err = ns.getFinalizedTxns(tx, height)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return finalTxns, nil
+}
+
+// GraduateHeight persists the provided height as the nursery store's last
+// graduated height.
+func (ns *nurseryStore) GraduateHeight(height uint32) error {
+
+	return ns.update(func(tx *bolt.Tx) error {
+		return ns.putLastGraduatedHeight(tx, height)
+	})
+}
+
+// GraduateBlock atomically persists the provided height as the nursery store's
+// last graduated height, along with the hash of the block at that height.
+func (ns *nurseryStore) GraduateBlock(height uint32,
+	hash *chainhash.Hash) error {
+
+	return ns.update(func(tx *bolt.Tx) error {
+		if err := ns.putLastGraduatedHeight(tx, height); err != nil {
+			return err
+		}
+
+		chainBucket := tx.Bucket(ns.pfxChainKey)
+
+		var hashBytes [4 + chainhash.HashSize]byte
+		byteOrder.PutUint32(hashBytes[:4], height)
+		copy(hashBytes[4:], hash[:])
+
+		return chainBucket.Put(lastGraduatedHashKey, hashBytes[:])
+	})
+}
+
+// FetchClass returns a list of babyOutputs in the crib bucket whose CLTV
+// delay expires at the provided block height.
+// FetchClass returns a list of the kindergarten and crib outputs whose timeouts
+// are expiring
+func (ns *nurseryStore) FetchClass(
+	height uint32) ([]*wire.MsgTx, []kidOutput, []babyOutput, error) {
+
+	// Construct list of all crib and kindergarten outputs that need to be
+	// processed at the provided block height.
+	var finalTxns []*wire.MsgTx
+	var kids []kidOutput
+	var babies []babyOutput
+	if err := ns.view(func(tx *bolt.Tx) error {
+
+		var err error
+		finalTxns, err = ns.getFinalizedTxns(tx, height)
+		if err != nil {
+			return err
+		}
+
+		// Append each crib output to our list of babyOutputs.
+		if err = ns.forEachHeightPrefix(tx, cribPrefix, height,
+			func(buf []byte) error {
+
+				// We will attempt to deserialize all outputs
+				// stored with the crib prefix into babyOutputs,
+				// since this is the expected type that would
+				// have been serialized previously.
+				// A single corrupt output is skipped, rather
+				// than preventing the rest of the class from
+				// being processed.
+				var baby babyOutput
+				babyReader := bytes.NewReader(buf)
+				err := baby.Decode(babyReader)
+				if err == nil {
+					err = baby.validate()
+				}
+				if err != nil {
+					utxnLog.Errorf("Skipping corrupt crib "+
+						"output at height=%d: %v",
+						height, err)
+					return nil
+				}
+
+				babies = append(babies, baby)
+
+				return nil
+
+			},
+		); err != nil {
+			return err
+		}
+
+		// Append each kindergarten output to our list of kidOutputs.
+		return ns.forEachHeightPrefix(tx, kndrPrefix, height,
+			func(buf []byte) error {
+				// We will attempt to deserialize all outputs
+				// stored with the kindergarten prefix into
+				// kidOutputs, since this is the expected type
+				// that would have been serialized previously.
+				var kid kidOutput
+				kidReader := bytes.NewReader(buf)
+				err := kid.Decode(kidReader)
+				if err == nil {
+					err = kid.validate()
+				}
+				if err != nil {
+					utxnLog.Errorf("Skipping corrupt kndr "+
+						"output at height=%d: %v",
+						height, err)
+					return nil
+				}
+
+				kids = append(kids, kid)
+
+				return nil
+
+			})
+
+	}); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return finalTxns, kids, babies, nil
+}
+
+// FetchPreschools returns a list of all outputs currently stored in the
+// preschool bucket.
+func (ns *nurseryStore) FetchPreschools() ([]kidOutput, error) {
+	var kids []kidOutput
+	if err := ns.view(func(tx *bolt.Tx) error {
+
+		// Retrieve the existing chain bucket for this nursery store.
+		chainBucket := tx.Bucket(ns.pfxChainKey)
+		if chainBucket == nil {
+			return nil
+		}
+
+		// Load the existing channel index from the chain bucket.
+		chanIndex := chainBucket.Bucket(channelIndexKey)
+		if chanIndex == nil {
 			return nil
 		}
 
@@ -702,11 +1578,19 @@ func (ns *nurseryStore) FetchPreschools() ([]kidOutput, error) {
 				// Deserialize each output as a kidOutput, since
 				// this should have been the type that was
 				// serialized when it was written to disk.
+				// A single corrupt output is skipped, rather
+				// than preventing the remaining outputs from
+				// being loaded.
 				var psclOutput kidOutput
 				psclReader := bytes.NewReader(v)
 				err := psclOutput.Decode(psclReader)
+				if err == nil {
+					err = psclOutput.validate()
+				}
 				if err != nil {
-					return err
+					utxnLog.Errorf("Skipping corrupt pscl "+
+						"output %x: %v", k[4:], err)
+					continue
 				}
 
 				// Add the deserialized output to our list of
@@ -727,7 +1611,7 @@ func (ns *nurseryStore) FetchPreschools() ([]kidOutput, error) {
 // index at or below the provided upper bound.
 func (ns *nurseryStore) HeightsBelowOrEqual(height uint32) ([]uint32, error) {
 	var activeHeights []uint32
-	err := ns.db.View(func(tx *bolt.Tx) error {
+	err := ns.view(func(tx *bolt.Tx) error {
 		// Ensure that the chain bucket for this nursery store exists.
 		chainBucket := tx.Bucket(ns.pfxChainKey)
 		if chainBucket == nil {
@@ -772,7 +1656,7 @@ func (ns *nurseryStore) HeightsBelowOrEqual(height uint32) ([]uint32, error) {
 func (ns *nurseryStore) ForChanOutputs(chanPoint *wire.OutPoint,
 	callback func([]byte, []byte) error) error {
 
-	return ns.db.View(func(tx *bolt.Tx) error {
+	return ns.view(func(tx *bolt.Tx) error {
 		return ns.forChanOutputs(tx, chanPoint, callback)
 	})
 }
@@ -780,7 +1664,7 @@ func (ns *nurseryStore) ForChanOutputs(chanPoint *wire.OutPoint,
 // ListChannels returns all channels the nursery is currently tracking.
 func (ns *nurseryStore) ListChannels() ([]wire.OutPoint, error) {
 	var activeChannels []wire.OutPoint
-	if err := ns.db.View(func(tx *bolt.Tx) error {
+	if err := ns.view(func(tx *bolt.Tx) error {
 		// Retrieve the existing chain bucket for this nursery store.
 		chainBucket := tx.Bucket(ns.pfxChainKey)
 		if chainBucket == nil {
@@ -811,198 +1695,1112 @@ func (ns *nurseryStore) ListChannels() ([]wire.OutPoint, error) {
 	return activeChannels, nil
 }
 
+// ChannelExists returns true if the channel index contains a channel bucket for
+// the provided channel point.
+func (ns *nurseryStore) ChannelExists(chanPoint *wire.OutPoint) (bool, error) {
+	var exists bool
+	err := ns.view(func(tx *bolt.Tx) error {
+		exists = ns.getChannelBucket(tx, chanPoint) != nil
+		return nil
+	})
+
+	return exists, err
+}
+
 // IsMatureChannel determines the whether or not all of the outputs in a
 // particular channel bucket have been marked as graduated.
 func (ns *nurseryStore) IsMatureChannel(chanPoint *wire.OutPoint) (bool, error) {
-	err := ns.db.View(func(tx *bolt.Tx) error {
-		// Iterate over the contents of the channel bucket, computing
-		// both total number of outputs, and those that have the grad
-		// prefix.
+	err := ns.view(func(tx *bolt.Tx) error {
+		// Iterate over the contents of the channel bucket, ensuring
+		// that each output has either graduated or been resolved.
 		return ns.forChanOutputs(tx, chanPoint,
 			func(pfxKey, _ []byte) error {
-				if !bytes.HasPrefix(pfxKey, gradPrefix) {
-					return ErrImmatureChannel
+				switch {
+				case bytes.HasPrefix(pfxKey, gradPrefix),
+					bytes.HasPrefix(pfxKey, rslvPrefix):
+
+					return nil
 				}
-				return nil
+
+				return ErrImmatureChannel
 			})
 
 	})
-	if err != nil && err != ErrImmatureChannel {
-		return false, err
-	}
-
-	return err == nil, nil
+	if err != nil && err != ErrImmatureChannel {
+		return false, err
+	}
+
+	return err == nil, nil
+}
+
+// ErrImmatureChannel signals a channel cannot be removed because not all of its
+// outputs have graduated.
+var ErrImmatureChannel = errors.New("cannot remove immature channel, " +
+	"still has ungraduated outputs")
+
+// RemoveChannel channel erases all entries from the channel bucket for the
+// provided channel point.
+// NOTE: The channel's entries in the height index are assumed to be removed.
+func (ns *nurseryStore) RemoveChannel(chanPoint *wire.OutPoint) error {
+	return ns.update(func(tx *bolt.Tx) error {
+		return ns.removeChannel(tx, chanPoint, false)
+	})
+}
+
+// ArchiveChannel atomically removes the channel bucket of the provided channel
+// point, moving each of its graduated outputs to the graduation archive. This
+// method should only be called if IsMatureChannel indicates the channel is
+// ready for removal.
+func (ns *nurseryStore) ArchiveChannel(chanPoint *wire.OutPoint) error {
+	return ns.update(func(tx *bolt.Tx) error {
+		return ns.removeChannel(tx, chanPoint, true)
+	})
+}
+
+// ErrSweepFinalized is returned when attempting to cancel a channel whose
+// kindergarten outputs have been included in a finalized sweep txn, which may
+// also spend the outputs of other channels.
+var ErrSweepFinalized = errors.New("cannot cancel channel, outputs " +
+	"included in finalized sweep")
+
+// CancelChannel erases all of the provided channel's outputs from the nursery
+// store, along with the channel's entries in the height index, even if the
+// channel still has ungraduated outputs. ErrContractNotFound is returned if
+// the channel is unknown, and ErrSweepFinalized if any of its kindergarten
+// outputs are awaiting a finalized sweep txn.
+func (ns *nurseryStore) CancelChannel(chanPoint *wire.OutPoint) error {
+	return ns.update(func(tx *bolt.Tx) error {
+		chainBucket := tx.Bucket(ns.pfxChainKey)
+		if chainBucket == nil {
+			return ErrContractNotFound
+		}
+
+		chanIndex := chainBucket.Bucket(channelIndexKey)
+		if chanIndex == nil {
+			return ErrContractNotFound
+		}
+
+		var chanBuffer bytes.Buffer
+		if err := writeOutpoint(&chanBuffer, chanPoint); err != nil {
+			return err
+		}
+		chanBytes := chanBuffer.Bytes()
+
+		if chanIndex.Bucket(chanBytes) == nil {
+			return ErrContractNotFound
+		}
+
+		err := ns.cancelChannelHeights(tx, chainBucket, chanPoint,
+			chanBytes)
+		if err != nil {
+			return err
+		}
+
+		return ns.removeChannelEntries(chainBucket, chanIndex, chanBytes)
+	})
+}
+
+// ForceGraduateChannel atomically moves all of the provided channel's crib,
+// preschool and kindergarten outputs to the graduated state, and removes the
+// channel's entries in the height index, such that the nursery neither
+// broadcasts nor sweeps any of them. This is used when the channel's outputs
+// were swept out-of-band. ErrContractNotFound is returned if the channel is
+// unknown, and ErrSweepFinalized if any of its kindergarten outputs are
+// awaiting a finalized sweep txn.
+func (ns *nurseryStore) ForceGraduateChannel(chanPoint *wire.OutPoint) error {
+	return ns.update(func(tx *bolt.Tx) error {
+		chainBucket := tx.Bucket(ns.pfxChainKey)
+		if chainBucket == nil {
+			return ErrContractNotFound
+		}
+
+		chanBucket := ns.getChannelBucket(tx, chanPoint)
+		if chanBucket == nil {
+			return ErrContractNotFound
+		}
+
+		var chanBuffer bytes.Buffer
+		if err := writeOutpoint(&chanBuffer, chanPoint); err != nil {
+			return err
+		}
+
+		err := ns.cancelChannelHeights(tx, chainBucket, chanPoint,
+			chanBuffer.Bytes())
+		if err != nil {
+			return err
+		}
+
+		// Collect the channel's ungraduated outputs before modifying
+		// the channel bucket, as bolt does not permit the bucket being
+		// iterated to be modified.
+		var (
+			pfxKeys [][]byte
+			kids    []kidOutput
+		)
+		err = chanBucket.ForEach(func(k, v []byte) error {
+			var kid kidOutput
+			switch {
+			case bytes.HasPrefix(k, cribPrefix):
+				var baby babyOutput
+				err := baby.Decode(bytes.NewReader(v))
+				if err != nil {
+					return err
+				}
+				kid = baby.kidOutput
+
+			case bytes.HasPrefix(k, psclPrefix),
+				bytes.HasPrefix(k, kndrPrefix):
+
+				err := kid.Decode(bytes.NewReader(v))
+				if err != nil {
+					return err
+				}
+
+			default:
+				return nil
+			}
+
+			pfxKeys = append(pfxKeys, append([]byte(nil), k...))
+			kids = append(kids, kid)
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for i, pfxKey := range pfxKeys {
+			if err := chanBucket.Delete(pfxKey); err != nil {
+				return err
+			}
+
+			err := ns.adjustLimboBalance(
+				tx, chanPoint, -int64(kids[i].Amount()),
+			)
+			if err != nil {
+				return err
+			}
+
+			var gradBuffer bytes.Buffer
+			if err := kids[i].Encode(&gradBuffer); err != nil {
+				return err
+			}
+
+			copy(pfxKey, gradPrefix)
+			err = chanBucket.Put(pfxKey, gradBuffer.Bytes())
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// cancelChannelHeights removes the provided channel from each height in the
+// height index at which it has outputs, using cancelChannelAtHeight.
+func (ns *nurseryStore) cancelChannelHeights(tx *bolt.Tx,
+	chainBucket *bolt.Bucket, chanPoint *wire.OutPoint,
+	chanBytes []byte) error {
+
+	hghtIndex := chainBucket.Bucket(heightIndexKey)
+	if hghtIndex == nil {
+		return nil
+	}
+
+	// Collect the heights at which the channel has outputs before
+	// modifying the height index, as bolt does not permit the bucket being
+	// iterated to be modified.
+	var heights []uint32
+	collectHeight := func(heightBytes, v []byte) error {
+		if v != nil || len(heightBytes) != 4 {
+			return nil
+		}
+
+		hghtBucket := hghtIndex.Bucket(heightBytes)
+		if hghtBucket.Bucket(chanBytes) != nil {
+			height := byteOrder.Uint32(heightBytes)
+			heights = append(heights, height)
+		}
+
+		return nil
+	}
+	if err := hghtIndex.ForEach(collectHeight); err != nil {
+		return err
+	}
+
+	for _, height := range heights {
+		err := ns.cancelChannelAtHeight(tx, height, chanPoint,
+			chanBytes)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cancelChannelAtHeight removes the height-channel bucket of the provided
+// channel at the given height, pruning the height bucket if it is left empty.
+// ErrSweepFinalized is returned if the height's finalized sweep txns spend
+// any of the channel's kindergarten outputs.
+func (ns *nurseryStore) cancelChannelAtHeight(tx *bolt.Tx, height uint32,
+	chanPoint *wire.OutPoint, chanBytes []byte) error {
+
+	hghtBucket := ns.getHeightBucket(tx, height)
+	hghtChanBucket := ns.getHeightChanBucket(tx, height, chanPoint)
+
+	finalTxns, err := ns.getFinalizedTxns(tx, height)
+	if err != nil {
+		return err
+	}
+	if len(finalTxns) > 0 {
+		k, _ := hghtChanBucket.Cursor().Seek(kndrPrefix)
+		if bytes.HasPrefix(k, kndrPrefix) {
+			return ErrSweepFinalized
+		}
+	}
+
+	if err := removeBucketIfExists(hghtBucket, chanBytes); err != nil {
+		return err
+	}
+
+	_, err = ns.pruneHeight(tx, height)
+	if err != nil && err != errBucketNotEmpty {
+		return err
+	}
+
+	return nil
+}
+
+// removeChannel erases all entries from the channel bucket for the provided
+// channel point. If archive is true, the channel's graduated outputs are first
+// copied into the graduation archive.
+func (ns *nurseryStore) removeChannel(tx *bolt.Tx, chanPoint *wire.OutPoint,
+	archive bool) error {
+
+	// Retrieve the existing chain bucket for this nursery store.
+	chainBucket := tx.Bucket(ns.pfxChainKey)
+	if chainBucket == nil {
+		return nil
+	}
+
+	// Retrieve the channel index stored in the chain bucket.
+	chanIndex := chainBucket.Bucket(channelIndexKey)
+	if chanIndex == nil {
+		return nil
+	}
+
+	// Serialize the provided channel point, such that we can delete the
+	// mature channel bucket.
+	var chanBuffer bytes.Buffer
+	if err := writeOutpoint(&chanBuffer, chanPoint); err != nil {
+		return err
+	}
+	chanBytes := chanBuffer.Bytes()
+
+	// If the graduated outputs are to be retained, create the channel's
+	// bucket within the graduation archive.
+	var archiveBucket *bolt.Bucket
+	if archive {
+		archiveIndex, err := chainBucket.CreateBucketIfNotExists(
+			graduationArchiveKey,
+		)
+		if err != nil {
+			return err
+		}
+
+		archiveBucket, err = archiveIndex.CreateBucketIfNotExists(
+			chanBytes,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := ns.forChanOutputs(tx, chanPoint, func(k, v []byte) error {
+		// Resolved outputs were already removed from the height index,
+		// and are not archived as they never graduated.
+		if bytes.HasPrefix(k, rslvPrefix) {
+			return nil
+		}
+
+		if !bytes.HasPrefix(k, gradPrefix) {
+			return ErrImmatureChannel
+		}
+
+		// Copy the graduated output into the archive, keyed by its
+		// outpoint without the state prefix.
+		if archiveBucket != nil {
+			if err := archiveBucket.Put(k[4:], v); err != nil {
+				return err
+			}
+		}
+
+		// Construct a kindergarten prefixed key, since this would
+		// have been the preceding state for a grad output.
+		kndrKey := make([]byte, len(k))
+		copy(kndrKey, k)
+		copy(kndrKey[:4], kndrPrefix)
+
+		// Decode each to retrieve the output's maturity height.
+		var kid kidOutput
+		if err := kid.Decode(bytes.NewReader(v)); err != nil {
+			return err
+		}
+
+		maturityHeight := kid.MaturityHeight()
+
+		hghtBucket := ns.getHeightBucket(tx, maturityHeight)
+		if hghtBucket == nil {
+			return nil
+		}
+
+		return removeBucketIfExists(hghtBucket, chanBytes)
+	})
+	if err != nil {
+		return err
+	}
+
+	return ns.removeChannelEntries(chainBucket, chanIndex, chanBytes)
+}
+
+// removeChannelEntries erases the channel bucket of the provided serialized
+// channel point, along with its limbo balance, sweep txids and close txid.
+func (ns *nurseryStore) removeChannelEntries(chainBucket,
+	chanIndex *bolt.Bucket, chanBytes []byte) error {
+
+	// Remove the channel's limbo balance, which should be zero unless the
+	// channel is being canceled.
+	limboIndex := chainBucket.Bucket(limboBalanceIndexKey)
+	if limboIndex != nil {
+		if err := limboIndex.Delete(chanBytes); err != nil {
+			return err
+		}
+	}
+
+	// Likewise, remove the channel's sweep txids, which should have been
+	// recorded in the channel's close summary.
+	sweepIndex := chainBucket.Bucket(sweepIndexKey)
+	if sweepIndex != nil {
+		err := removeBucketIfExists(sweepIndex, chanBytes)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The channel's close summary also records its closing txid.
+	closeTxIndex := chainBucket.Bucket(closeTxIndexKey)
+	if closeTxIndex != nil {
+		if err := closeTxIndex.Delete(chanBytes); err != nil {
+			return err
+		}
+	}
+
+	return removeBucketIfExists(chanIndex, chanBytes)
+}
+
+// FetchArchivedGraduations returns the graduated outputs of the provided
+// channel point that were moved to the graduation archive when the channel was
+// removed. If the channel was never archived, an empty slice is returned.
+func (ns *nurseryStore) FetchArchivedGraduations(
+	chanPoint *wire.OutPoint) ([]kidOutput, error) {
+
+	var kids []kidOutput
+	err := ns.view(func(tx *bolt.Tx) error {
+		chainBucket := tx.Bucket(ns.pfxChainKey)
+		if chainBucket == nil {
+			return nil
+		}
+
+		archiveIndex := chainBucket.Bucket(graduationArchiveKey)
+		if archiveIndex == nil {
+			return nil
+		}
+
+		var chanBuffer bytes.Buffer
+		if err := writeOutpoint(&chanBuffer, chanPoint); err != nil {
+			return err
+		}
+
+		archiveBucket := archiveIndex.Bucket(chanBuffer.Bytes())
+		if archiveBucket == nil {
+			return nil
+		}
+
+		return archiveBucket.ForEach(func(_, v []byte) error {
+			var kid kidOutput
+			if err := kid.Decode(bytes.NewReader(v)); err != nil {
+				return err
+			}
+			kids = append(kids, kid)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return kids, nil
+}
+
+// RecordDroppedOutputs persists the provided outputs of the given channel
+// point, which were abandoned rather than incubated. Unlike the channel's
+// incubated outputs, these are retained after the channel is removed.
+func (ns *nurseryStore) RecordDroppedOutputs(chanPoint *wire.OutPoint,
+	outputs []droppedOutput) error {
+
+	return ns.update(func(tx *bolt.Tx) error {
+		chainBucket, err := tx.CreateBucketIfNotExists(ns.pfxChainKey)
+		if err != nil {
+			return err
+		}
+
+		droppedIndex, err := chainBucket.CreateBucketIfNotExists(
+			droppedOutputsKey,
+		)
+		if err != nil {
+			return err
+		}
+
+		var chanBuffer bytes.Buffer
+		if err := writeOutpoint(&chanBuffer, chanPoint); err != nil {
+			return err
+		}
+
+		droppedBucket, err := droppedIndex.CreateBucketIfNotExists(
+			chanBuffer.Bytes(),
+		)
+		if err != nil {
+			return err
+		}
+
+		for i := range outputs {
+			var outputKey bytes.Buffer
+			err := writeOutpoint(&outputKey, &outputs[i].outpoint)
+			if err != nil {
+				return err
+			}
+
+			var outputBuffer bytes.Buffer
+			if err := outputs[i].Encode(&outputBuffer); err != nil {
+				return err
+			}
+
+			err = droppedBucket.Put(
+				outputKey.Bytes(), outputBuffer.Bytes(),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// FetchDroppedOutputs returns the outputs of the provided channel point that
+// were abandoned rather than incubated. If no outputs of the channel were
+// dropped, an empty slice is returned.
+func (ns *nurseryStore) FetchDroppedOutputs(
+	chanPoint *wire.OutPoint) ([]droppedOutput, error) {
+
+	var outputs []droppedOutput
+	err := ns.view(func(tx *bolt.Tx) error {
+		chainBucket := tx.Bucket(ns.pfxChainKey)
+		if chainBucket == nil {
+			return nil
+		}
+
+		droppedIndex := chainBucket.Bucket(droppedOutputsKey)
+		if droppedIndex == nil {
+			return nil
+		}
+
+		var chanBuffer bytes.Buffer
+		if err := writeOutpoint(&chanBuffer, chanPoint); err != nil {
+			return err
+		}
+
+		droppedBucket := droppedIndex.Bucket(chanBuffer.Bytes())
+		if droppedBucket == nil {
+			return nil
+		}
+
+		return droppedBucket.ForEach(func(_, v []byte) error {
+			var output droppedOutput
+			err := output.Decode(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+			outputs = append(outputs, output)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return outputs, nil
+}
+
+// ChanLimboBalance returns the total value of all outputs for the provided
+// channel point that have not yet graduated. Channels incubated before the
+// limbo balance index was introduced fall back to summing the channel's
+// ungraduated outputs.
+func (ns *nurseryStore) ChanLimboBalance(
+	chanPoint *wire.OutPoint) (btcutil.Amount, error) {
+
+	var limboBalance btcutil.Amount
+	err := ns.view(func(tx *bolt.Tx) error {
+		balance, ok, err := ns.getLimboBalance(tx, chanPoint)
+		if err != nil {
+			return err
+		}
+		if ok {
+			limboBalance = balance
+			return nil
+		}
+
+		return ns.forChanOutputs(tx, chanPoint, func(k, v []byte) error {
+			switch {
+			case bytes.HasPrefix(k, cribPrefix):
+				var baby babyOutput
+				if err := baby.Decode(bytes.NewReader(v)); err != nil {
+					return err
+				}
+				limboBalance += baby.Amount()
+
+			case bytes.HasPrefix(k, psclPrefix),
+				bytes.HasPrefix(k, kndrPrefix):
+
+				var kid kidOutput
+				if err := kid.Decode(bytes.NewReader(v)); err != nil {
+					return err
+				}
+				limboBalance += kid.Amount()
+			}
+
+			return nil
+		})
+	})
+	if err != nil && err != ErrContractNotFound {
+		return 0, err
+	}
+
+	return limboBalance, nil
+}
+
+// LastFinalizedHeight returns the last block height for which the nursery
+// store has finalized a kindergarten class.
+func (ns *nurseryStore) LastFinalizedHeight() (uint32, error) {
+	var lastFinalizedHeight uint32
+	err := ns.view(func(tx *bolt.Tx) error {
+		var err error
+		lastFinalizedHeight, err = ns.getLastFinalizedHeight(tx)
+		return err
+	})
+
+	return lastFinalizedHeight, err
+}
+
+// RecordSweepBroadcast increments the number of times the finalized
+// kindergarten sweep txns at the given height have been broadcast, returning
+// the updated count.
+func (ns *nurseryStore) RecordSweepBroadcast(height uint32) (uint32, error) {
+	var numBroadcasts uint32
+	err := ns.update(func(tx *bolt.Tx) error {
+		hghtBucket := ns.getHeightBucket(tx, height)
+		if hghtBucket == nil {
+			return fmt.Errorf("no height bucket at height=%d", height)
+		}
+
+		if v := hghtBucket.Get(sweepBroadcastsKey); len(v) == 4 {
+			numBroadcasts = byteOrder.Uint32(v)
+		}
+		numBroadcasts++
+
+		var countBytes [4]byte
+		byteOrder.PutUint32(countBytes[:], numBroadcasts)
+
+		return hghtBucket.Put(sweepBroadcastsKey, countBytes[:])
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return numBroadcasts, nil
+}
+
+// SweepBroadcasts returns the number of times the finalized kindergarten sweep
+// txns at the given height have been broadcast.
+func (ns *nurseryStore) SweepBroadcasts(height uint32) (uint32, error) {
+	var numBroadcasts uint32
+	err := ns.view(func(tx *bolt.Tx) error {
+		hghtBucket := ns.getHeightBucket(tx, height)
+		if hghtBucket == nil {
+			return nil
+		}
+
+		if v := hghtBucket.Get(sweepBroadcastsKey); len(v) == 4 {
+			numBroadcasts = byteOrder.Uint32(v)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return numBroadcasts, nil
+}
+
+// LastGraduatedHeight returns the last block height for which the nursery
+// store has successfully graduated all outputs.
+func (ns *nurseryStore) LastGraduatedHeight() (uint32, error) {
+	var lastGraduatedHeight uint32
+	err := ns.view(func(tx *bolt.Tx) error {
+		var err error
+		lastGraduatedHeight, err = ns.getLastGraduatedHeight(tx)
+		return err
+	})
+
+	return lastGraduatedHeight, err
+}
+
+// LastGraduatedBlock returns the nursery store's last graduated height, along
+// with the hash of its block. Since the hash is stored alongside the height it
+// belongs to, a nil hash is returned if the last graduated height was
+// persisted via GraduateHeight, or before block hashes were recorded.
+func (ns *nurseryStore) LastGraduatedBlock() (uint32, *chainhash.Hash,
+	error) {
+
+	var (
+		height uint32
+		hash   *chainhash.Hash
+	)
+	err := ns.view(func(tx *bolt.Tx) error {
+		var err error
+		height, err = ns.getLastGraduatedHeight(tx)
+		if err != nil {
+			return err
+		}
+
+		chainBucket := tx.Bucket(ns.pfxChainKey)
+		if chainBucket == nil {
+			return nil
+		}
+
+		hashBytes := chainBucket.Get(lastGraduatedHashKey)
+		if len(hashBytes) != 4+chainhash.HashSize ||
+			byteOrder.Uint32(hashBytes[:4]) != height {
+
+			return nil
+		}
+
+		hash = &chainhash.Hash{}
+		copy(hash[:], hashBytes[4:])
+
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return height, hash, nil
+}
+
+// Prune atomically removes any empty height-channel and height buckets from
+// the height index, any empty channel buckets from the sweep index, graduation
+// archive, and dropped outputs index, and any limbo balances belonging to
+// channels that are no longer tracked by the channel index. Channel buckets in
+// the channel index are left untouched, as an empty channel bucket still
+// signals that the channel must be marked fully closed.
+//
+// NOTE: This doesn't compact the database file. Since the nursery store shares
+// its bolt file with the channeldb, the freed pages are reused by later writes
+// rather than being returned to the filesystem.
+func (ns *nurseryStore) Prune() error {
+	return ns.update(func(tx *bolt.Tx) error {
+		chainBucket := tx.Bucket(ns.pfxChainKey)
+		if chainBucket == nil {
+			return nil
+		}
+
+		if err := ns.pruneHeightIndex(chainBucket); err != nil {
+			return err
+		}
+
+		// Prune the empty channel buckets from each of the indexes
+		// keyed by channel point.
+		chanIndexKeys := [][]byte{
+			sweepIndexKey,
+			graduationArchiveKey,
+			droppedOutputsKey,
+		}
+		for _, indexKey := range chanIndexKeys {
+			index := chainBucket.Bucket(indexKey)
+			if index == nil {
+				continue
+			}
+
+			if err := removeEmptyBuckets(index); err != nil {
+				return err
+			}
+		}
+
+		return ns.pruneLimboBalances(chainBucket)
+	})
+}
+
+// Helper Methods
+
+// enterCrib accepts a new htlc output that the nursery will incubate through
+// its two-stage process of sweeping funds back to the user's wallet. These
+// outputs are persisted in the nursery store in the crib state, and will be
+// revisited after the first-stage output's CLTV has expired.
+func (ns *nurseryStore) enterCrib(tx *bolt.Tx, baby *babyOutput) error {
+	// First, retrieve or create the channel bucket corresponding to the
+	// baby output's origin channel point.
+	chanPoint := baby.OriginChanPoint()
+	chanBucket, err := ns.createChannelBucket(tx, chanPoint)
+	if err != nil {
+		return err
+	}
+
+	// Next, retrieve or create the height-channel bucket located in the
+	// height bucket corresponding to the baby output's CLTV expiry height.
+	hghtChanBucket, err := ns.createHeightChanBucket(tx,
+		baby.expiry, chanPoint)
+	if err != nil {
+		return err
+	}
+
+	// Since we are inserting this output into the crib bucket, we create a
+	// key that prefixes the baby output's outpoint with the crib prefix.
+	pfxOutputKey, err := prefixOutputKey(cribPrefix, baby.OutPoint())
+	if err != nil {
+		return err
+	}
+
+	// Serialize the baby output so that it can be written to the underlying
+	// key-value store.
+	var babyBuffer bytes.Buffer
+	if err := baby.Encode(&babyBuffer); err != nil {
+		return err
+	}
+	babyBytes := babyBuffer.Bytes()
+
+	// Now, insert the serialized output into its channel bucket under the
+	// prefixed key created above.
+	if err := chanBucket.Put(pfxOutputKey, babyBytes); err != nil {
+		return err
+	}
+
+	// Finally, create a corresponding bucket in the height-channel bucket
+	// for this crib output. The existence of this bucket indicates that the
+	// serialized output can be retrieved from the channel bucket using the
+	// same prefix key.
+	return hghtChanBucket.Put(pfxOutputKey, []byte{})
+}
+
+// RekeyPreschool replaces the preschool output stored under the provided
+// outpoint with the given kid output, which is stored under its own outpoint.
+// An error is returned if no preschool output exists under the old outpoint.
+func (ns *nurseryStore) RekeyPreschool(oldOutPoint *wire.OutPoint,
+	kid *kidOutput) error {
+
+	return ns.update(func(tx *bolt.Tx) error {
+		chanPoint := kid.OriginChanPoint()
+		chanBucket := ns.getChannelBucket(tx, chanPoint)
+		if chanBucket == nil {
+			return fmt.Errorf("channel %v not found", chanPoint)
+		}
+
+		pfxOutputKey, err := prefixOutputKey(psclPrefix, oldOutPoint)
+		if err != nil {
+			return err
+		}
+
+		if chanBucket.Get(pfxOutputKey) == nil {
+			return fmt.Errorf("preschool output %v not found",
+				oldOutPoint)
+		}
+
+		if err := chanBucket.Delete(pfxOutputKey); err != nil {
+			return err
+		}
+
+		return ns.enterPreschool(tx, kid)
+	})
+}
+
+// enterPreschool accepts a new commitment output that the nursery will incubate
+// through a single stage before sweeping. Outputs are stored in the preschool
+// bucket until the commitment transaction has been confirmed, at which point
+// they will be moved to the kindergarten bucket.
+func (ns *nurseryStore) enterPreschool(tx *bolt.Tx, kid *kidOutput) error {
+	// First, retrieve or create the channel bucket corresponding to the
+	// baby output's origin channel point.
+	chanPoint := kid.OriginChanPoint()
+	chanBucket, err := ns.createChannelBucket(tx, chanPoint)
+	if err != nil {
+		return err
+	}
+
+	// Since the kidOutput is being inserted into the preschool bucket, we
+	// create a key that prefixes its outpoint with the preschool prefix.
+	pfxOutputKey, err := prefixOutputKey(psclPrefix, kid.OutPoint())
+	if err != nil {
+		return err
+	}
+
+	// Serialize the kidOutput and insert it into the channel bucket.
+	var kidBuffer bytes.Buffer
+	if err := kid.Encode(&kidBuffer); err != nil {
+		return err
+	}
+
+	return chanBucket.Put(pfxOutputKey, kidBuffer.Bytes())
+}
+
+// ChanSweepTxids returns the txids of the sweep txns that graduated the
+// kindergarten outputs of the provided channel point.
+func (ns *nurseryStore) ChanSweepTxids(
+	chanPoint *wire.OutPoint) ([]chainhash.Hash, error) {
+
+	var sweepTxids []chainhash.Hash
+	err := ns.view(func(tx *bolt.Tx) error {
+		chainBucket := tx.Bucket(ns.pfxChainKey)
+		if chainBucket == nil {
+			return nil
+		}
+
+		sweepIndex := chainBucket.Bucket(sweepIndexKey)
+		if sweepIndex == nil {
+			return nil
+		}
+
+		var chanBuffer bytes.Buffer
+		if err := writeOutpoint(&chanBuffer, chanPoint); err != nil {
+			return err
+		}
+
+		chanSweeps := sweepIndex.Bucket(chanBuffer.Bytes())
+		if chanSweeps == nil {
+			return nil
+		}
+
+		return chanSweeps.ForEach(func(k, _ []byte) error {
+			var txid chainhash.Hash
+			copy(txid[:], k)
+			sweepTxids = append(sweepTxids, txid)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sweepTxids, nil
+}
+
+// RecordChannelCloseTx persists the txid of the force close txn of the provided
+// channel point. The txid is removed along with the rest of the channel's
+// entries once the channel is removed from the nursery store.
+func (ns *nurseryStore) RecordChannelCloseTx(chanPoint *wire.OutPoint,
+	txid *chainhash.Hash) error {
+
+	return ns.update(func(tx *bolt.Tx) error {
+		chainBucket, err := tx.CreateBucketIfNotExists(ns.pfxChainKey)
+		if err != nil {
+			return err
+		}
+
+		closeTxIndex, err := chainBucket.CreateBucketIfNotExists(
+			closeTxIndexKey,
+		)
+		if err != nil {
+			return err
+		}
+
+		var chanBuffer bytes.Buffer
+		if err := writeOutpoint(&chanBuffer, chanPoint); err != nil {
+			return err
+		}
+
+		return closeTxIndex.Put(chanBuffer.Bytes(), txid[:])
+	})
 }
 
-// ErrImmatureChannel signals a channel cannot be removed because not all of its
-// outputs have graduated.
-var ErrImmatureChannel = errors.New("cannot remove immature channel, " +
-	"still has ungraduated outputs")
+// ChannelCloseTx returns the txid of the force close txn of the provided
+// channel point. If no txid was recorded for the channel, a nil txid is
+// returned.
+func (ns *nurseryStore) ChannelCloseTx(
+	chanPoint *wire.OutPoint) (*chainhash.Hash, error) {
 
-// RemoveChannel channel erases all entries from the channel bucket for the
-// provided channel point.
-// NOTE: The channel's entries in the height index are assumed to be removed.
-func (ns *nurseryStore) RemoveChannel(chanPoint *wire.OutPoint) error {
-	return ns.db.Update(func(tx *bolt.Tx) error {
-		// Retrieve the existing chain bucket for this nursery store.
+	var closeTxid *chainhash.Hash
+	err := ns.view(func(tx *bolt.Tx) error {
 		chainBucket := tx.Bucket(ns.pfxChainKey)
 		if chainBucket == nil {
 			return nil
 		}
 
-		// Retrieve the channel index stored in the chain bucket.
-		chanIndex := chainBucket.Bucket(channelIndexKey)
-		if chanIndex == nil {
+		closeTxIndex := chainBucket.Bucket(closeTxIndexKey)
+		if closeTxIndex == nil {
 			return nil
 		}
 
-		// Serialize the provided channel point, such that we can delete
-		// the mature channel bucket.
 		var chanBuffer bytes.Buffer
 		if err := writeOutpoint(&chanBuffer, chanPoint); err != nil {
 			return err
 		}
-		chanBytes := chanBuffer.Bytes()
-
-		err := ns.forChanOutputs(tx, chanPoint, func(k, v []byte) error {
-			if !bytes.HasPrefix(k, gradPrefix) {
-				return ErrImmatureChannel
-			}
-
-			// Construct a kindergarten prefixed key, since this
-			// would have been the preceding state for a grad
-			// output.
-			kndrKey := make([]byte, len(k))
-			copy(kndrKey, k)
-			copy(kndrKey[:4], kndrPrefix)
-
-			// Decode each to retrieve the output's maturity height.
-			var kid kidOutput
-			if err := kid.Decode(bytes.NewReader(v)); err != nil {
-				return err
-			}
-
-			maturityHeight := kid.ConfHeight() + kid.BlocksToMaturity()
 
-			hghtBucket := ns.getHeightBucket(tx, maturityHeight)
-			if hghtBucket == nil {
-				return nil
-			}
-
-			return removeBucketIfExists(hghtBucket, chanBytes)
-		})
-		if err != nil {
-			return err
+		txidBytes := closeTxIndex.Get(chanBuffer.Bytes())
+		if txidBytes == nil {
+			return nil
 		}
 
-		return removeBucketIfExists(chanIndex, chanBytes)
-	})
-}
-
-// LastFinalizedHeight returns the last block height for which the nursery
-// store has finalized a kindergarten class.
-func (ns *nurseryStore) LastFinalizedHeight() (uint32, error) {
-	var lastFinalizedHeight uint32
-	err := ns.db.View(func(tx *bolt.Tx) error {
-		var err error
-		lastFinalizedHeight, err = ns.getLastFinalizedHeight(tx)
-		return err
-	})
-
-	return lastFinalizedHeight, err
-}
-
-// LastGraduatedHeight returns the last block height for which the nursery
-// store has successfully graduated all outputs.
-func (ns *nurseryStore) LastGraduatedHeight() (uint32, error) {
-	var lastGraduatedHeight uint32
-	err := ns.db.View(func(tx *bolt.Tx) error {
 		var err error
-		lastGraduatedHeight, err = ns.getLastGraduatedHeight(tx)
+		closeTxid, err = chainhash.NewHash(txidBytes)
 		return err
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return lastGraduatedHeight, err
+	return closeTxid, nil
 }
 
-// Helper Methods
+// addChanSweepTxid records the txid of a txn that swept one of the outputs of
+// the provided channel point in the sweep index.
+func (ns *nurseryStore) addChanSweepTxid(tx *bolt.Tx, chanPoint *wire.OutPoint,
+	txid *chainhash.Hash) error {
 
-// enterCrib accepts a new htlc output that the nursery will incubate through
-// its two-stage process of sweeping funds back to the user's wallet. These
-// outputs are persisted in the nursery store in the crib state, and will be
-// revisited after the first-stage output's CLTV has expired.
-func (ns *nurseryStore) enterCrib(tx *bolt.Tx, baby *babyOutput) error {
-	// First, retrieve or create the channel bucket corresponding to the
-	// baby output's origin channel point.
-	chanPoint := baby.OriginChanPoint()
-	chanBucket, err := ns.createChannelBucket(tx, chanPoint)
+	chainBucket, err := tx.CreateBucketIfNotExists(ns.pfxChainKey)
 	if err != nil {
 		return err
 	}
 
-	// Next, retrieve or create the height-channel bucket located in the
-	// height bucket corresponding to the baby output's CLTV expiry height.
-	hghtChanBucket, err := ns.createHeightChanBucket(tx,
-		baby.expiry, chanPoint)
+	sweepIndex, err := chainBucket.CreateBucketIfNotExists(sweepIndexKey)
 	if err != nil {
 		return err
 	}
 
-	// Since we are inserting this output into the crib bucket, we create a
-	// key that prefixes the baby output's outpoint with the crib prefix.
-	pfxOutputKey, err := prefixOutputKey(cribPrefix, baby.OutPoint())
-	if err != nil {
+	var chanBuffer bytes.Buffer
+	if err := writeOutpoint(&chanBuffer, chanPoint); err != nil {
 		return err
 	}
 
-	// Serialize the baby output so that it can be written to the underlying
-	// key-value store.
-	var babyBuffer bytes.Buffer
-	if err := baby.Encode(&babyBuffer); err != nil {
+	chanSweeps, err := sweepIndex.CreateBucketIfNotExists(
+		chanBuffer.Bytes(),
+	)
+	if err != nil {
 		return err
 	}
-	babyBytes := babyBuffer.Bytes()
 
-	// Now, insert the serialized output into its channel bucket under the
-	// prefixed key created above.
-	if err := chanBucket.Put(pfxOutputKey, babyBytes); err != nil {
+	return chanSweeps.Put(txid[:], []byte{})
+}
+
+// removeChanSweepTxid removes the txid of a sweep txn from the sweep index of
+// the provided channel point, if present.
+func (ns *nurseryStore) removeChanSweepTxid(tx *bolt.Tx,
+	chanPoint *wire.OutPoint, txid *chainhash.Hash) error {
+
+	chainBucket := tx.Bucket(ns.pfxChainKey)
+	if chainBucket == nil {
+		return nil
+	}
+
+	sweepIndex := chainBucket.Bucket(sweepIndexKey)
+	if sweepIndex == nil {
+		return nil
+	}
+
+	var chanBuffer bytes.Buffer
+	if err := writeOutpoint(&chanBuffer, chanPoint); err != nil {
 		return err
 	}
 
-	// Finally, create a corresponding bucket in the height-channel bucket
-	// for this crib output. The existence of this bucket indicates that the
-	// serialized output can be retrieved from the channel bucket using the
-	// same prefix key.
-	return hghtChanBucket.Put(pfxOutputKey, []byte{})
+	chanSweeps := sweepIndex.Bucket(chanBuffer.Bytes())
+	if chanSweeps == nil {
+		return nil
+	}
+
+	return chanSweeps.Delete(txid[:])
 }
 
-// enterPreschool accepts a new commitment output that the nursery will incubate
-// through a single stage before sweeping. Outputs are stored in the preschool
-// bucket until the commitment transaction has been confirmed, at which point
-// they will be moved to the kindergarten bucket.
-func (ns *nurseryStore) enterPreschool(tx *bolt.Tx, kid *kidOutput) error {
-	// First, retrieve or create the channel bucket corresponding to the
-	// baby output's origin channel point.
-	chanPoint := kid.OriginChanPoint()
-	chanBucket, err := ns.createChannelBucket(tx, chanPoint)
+// getLimboBalance retrieves the running limbo balance for the provided channel
+// point. The returned boolean is false if no balance has been recorded for the
+// channel.
+func (ns *nurseryStore) getLimboBalance(tx *bolt.Tx,
+	chanPoint *wire.OutPoint) (btcutil.Amount, bool, error) {
+
+	chainBucket := tx.Bucket(ns.pfxChainKey)
+	if chainBucket == nil {
+		return 0, false, nil
+	}
+
+	limboIndex := chainBucket.Bucket(limboBalanceIndexKey)
+	if limboIndex == nil {
+		return 0, false, nil
+	}
+
+	var chanBuffer bytes.Buffer
+	if err := writeOutpoint(&chanBuffer, chanPoint); err != nil {
+		return 0, false, err
+	}
+
+	balanceBytes := limboIndex.Get(chanBuffer.Bytes())
+	if len(balanceBytes) != 8 {
+		return 0, false, nil
+	}
+
+	return btcutil.Amount(byteOrder.Uint64(balanceBytes)), true, nil
+}
+
+// adjustLimboBalance applies the provided delta to the running limbo balance
+// of the given channel point. A channel without a recorded balance is treated
+// as having a balance of zero, and the balance is never allowed to drop below
+// zero.
+func (ns *nurseryStore) adjustLimboBalance(tx *bolt.Tx,
+	chanPoint *wire.OutPoint, delta int64) error {
+
+	chainBucket, err := tx.CreateBucketIfNotExists(ns.pfxChainKey)
 	if err != nil {
 		return err
 	}
 
-	// Since the kidOutput is being inserted into the preschool bucket, we
-	// create a key that prefixes its outpoint with the preschool prefix.
-	pfxOutputKey, err := prefixOutputKey(psclPrefix, kid.OutPoint())
+	limboIndex, err := chainBucket.CreateBucketIfNotExists(
+		limboBalanceIndexKey,
+	)
 	if err != nil {
 		return err
 	}
 
-	// Serialize the kidOutput and insert it into the channel bucket.
-	var kidBuffer bytes.Buffer
-	if err := kid.Encode(&kidBuffer); err != nil {
+	var chanBuffer bytes.Buffer
+	if err := writeOutpoint(&chanBuffer, chanPoint); err != nil {
 		return err
 	}
+	chanBytes := chanBuffer.Bytes()
 
-	return chanBucket.Put(pfxOutputKey, kidBuffer.Bytes())
+	var balance int64
+	if balanceBytes := limboIndex.Get(chanBytes); len(balanceBytes) == 8 {
+		balance = int64(byteOrder.Uint64(balanceBytes))
+	}
+
+	balance += delta
+	if balance < 0 {
+		balance = 0
+	}
+
+	var balanceBytes [8]byte
+	byteOrder.PutUint64(balanceBytes[:], uint64(balance))
+
+	return limboIndex.Put(chanBytes, balanceBytes[:])
 }
 
 // createChannelBucket creates or retrieves a channel bucket for the provided
@@ -1304,15 +3102,16 @@ func (ns *nurseryStore) getLastFinalizedHeight(tx *bolt.Tx) (uint32, error) {
 	return byteOrder.Uint32(heightBytes), nil
 }
 
-// finalizeKinder records a finalized kingergarten sweep txn to the given height
-// bucket. It also updates the nursery store's last finalized height, so that we
-// do not finalize the same height twice. If the finalized txn is nil, i.e. if
-// the height has no kindergarten outputs, the height will be marked as
-// finalized, and we skip the process of writing the txn. When the class is
-// loaded, a nil value will be returned if no txn has been written to a
-// finalized height bucket.
+// finalizeKinder records the finalized kingergarten sweep txns to the given
+// height bucket. It also updates the nursery store's last finalized height, so
+// that we do not finalize the same height twice. If there are no finalized
+// txns, i.e. if the height has no kindergarten outputs, the height will be
+// marked as finalized, and we skip the process of writing the txns. When the
+// class is loaded, a nil slice will be returned if no txns have been written
+// to a finalized height bucket. If provided, the fee rate of each txn is
+// written alongside it.
 func (ns *nurseryStore) finalizeKinder(tx *bolt.Tx, height uint32,
-	finalTx *wire.MsgTx) error {
+	finalTxns []*wire.MsgTx, feeRates []btcutil.Amount) error {
 
 	// TODO(conner) ensure height is greater that current finalized height.
 
@@ -1334,32 +3133,84 @@ func (ns *nurseryStore) finalizeKinder(tx *bolt.Tx, height uint32,
 		return err
 	}
 
-	// 2. Write the finalized txn in the appropriate height bucket.
+	// 2. Write the finalized txns in the appropriate height bucket.
 
-	// If there is no finalized txn, we have nothing to do.
-	if finalTx == nil {
+	// If there are no finalized txns, we have nothing to do.
+	if len(finalTxns) == 0 {
 		return nil
 	}
 
-	// Otherwise serialize the finalized txn and write it to the height
+	// Otherwise serialize each finalized txn and write it to the height
 	// bucket.
 	hghtBucket := ns.getHeightBucket(tx, height)
 	if hghtBucket == nil {
 		return nil
 	}
 
-	var finalTxnBuf bytes.Buffer
-	if err := finalTx.Serialize(&finalTxnBuf); err != nil {
-		return err
+	return putFinalizedTxns(hghtBucket, finalTxns, feeRates)
+}
+
+// putFinalizedTxns serializes each of the finalized kindergarten sweep txns
+// into the provided height bucket, along with their fee rates if known.
+func putFinalizedTxns(hghtBucket *bolt.Bucket, finalTxns []*wire.MsgTx,
+	feeRates []btcutil.Amount) error {
+
+	for i, finalTx := range finalTxns {
+		var finalTxnBuf bytes.Buffer
+		if err := finalTx.Serialize(&finalTxnBuf); err != nil {
+			return err
+		}
+
+		err := hghtBucket.Put(finalizedTxnKey(i), finalTxnBuf.Bytes())
+		if err != nil {
+			return err
+		}
+
+		if feeRates == nil {
+			continue
+		}
+
+		var feeRateBytes [8]byte
+		byteOrder.PutUint64(feeRateBytes[:], uint64(feeRates[i]))
+		err = hghtBucket.Put(finalizedFeeRateKey(i), feeRateBytes[:])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// finalizedTxnKey returns the key under which the i-th finalized kindergarten
+// sweep txn is stored in a height bucket. The first txn is stored under the
+// bare finalized-kndr-txn key, while any additional txns have their 4-byte
+// index appended.
+func finalizedTxnKey(i int) []byte {
+	if i == 0 {
+		return finalizedKndrTxnKey
 	}
 
-	return hghtBucket.Put(finalizedKndrTxnKey, finalTxnBuf.Bytes())
+	key := make([]byte, len(finalizedKndrTxnKey)+4)
+	copy(key, finalizedKndrTxnKey)
+	byteOrder.PutUint32(key[len(finalizedKndrTxnKey):], uint32(i))
+
+	return key
+}
+
+// finalizedFeeRateKey returns the key under which the fee rate of the i-th
+// finalized kindergarten sweep txn is stored in a height bucket.
+func finalizedFeeRateKey(i int) []byte {
+	key := make([]byte, len(finalizedKndrFeeRateKey)+4)
+	copy(key, finalizedKndrFeeRateKey)
+	byteOrder.PutUint32(key[len(finalizedKndrFeeRateKey):], uint32(i))
+
+	return key
 }
 
-// getFinalizedTxn retrieves the finalized kindergarten sweep txn at the given
-// height, returning nil if one was not found.
-func (ns *nurseryStore) getFinalizedTxn(tx *bolt.Tx,
-	height uint32) (*wire.MsgTx, error) {
+// getFinalizedTxns retrieves the finalized kindergarten sweep txns at the
+// given height, returning nil if none were found.
+func (ns *nurseryStore) getFinalizedTxns(tx *bolt.Tx,
+	height uint32) ([]*wire.MsgTx, error) {
 
 	hghtBucket := ns.getHeightBucket(tx, height)
 	if hghtBucket == nil {
@@ -1367,19 +3218,51 @@ func (ns *nurseryStore) getFinalizedTxn(tx *bolt.Tx,
 		return nil, nil
 	}
 
-	finalTxBytes := hghtBucket.Get(finalizedKndrTxnKey)
-	if finalTxBytes == nil {
-		// No finalized txn for this height.
-		return nil, nil
+	// All finalized txns share the finalized-kndr-txn prefix, so a prefix
+	// scan will return them in the order they were finalized.
+	var finalTxns []*wire.MsgTx
+	c := hghtBucket.Cursor()
+	for k, v := c.Seek(finalizedKndrTxnKey); bytes.HasPrefix(
+		k, finalizedKndrTxnKey); k, v = c.Next() {
+
+		// Deserialize and accumulate each finalized transaction.
+		txn := &wire.MsgTx{}
+		if err := txn.Deserialize(bytes.NewReader(v)); err != nil {
+			return nil, err
+		}
+
+		finalTxns = append(finalTxns, txn)
 	}
 
-	// Otherwise, deserialize and return the finalized transaction.
-	txn := &wire.MsgTx{}
-	if err := txn.Deserialize(bytes.NewReader(finalTxBytes)); err != nil {
-		return nil, err
+	return finalTxns, nil
+}
+
+// removeFinalizedTxns deletes all finalized kindergarten sweep txns, their fee
+// rates, and their broadcast count from the provided height bucket.
+func removeFinalizedTxns(hghtBucket *bolt.Bucket) error {
+	// Collect the keys before deleting them, so that we don't modify the
+	// bucket while iterating over it.
+	var finalTxnKeys [][]byte
+	c := hghtBucket.Cursor()
+	for _, prefix := range [][]byte{
+		finalizedKndrTxnKey, finalizedKndrFeeRateKey, sweepBroadcastsKey,
+	} {
+		for k, _ := c.Seek(prefix); bytes.HasPrefix(
+			k, prefix); k, _ = c.Next() {
+
+			finalTxnKey := make([]byte, len(k))
+			copy(finalTxnKey, k)
+			finalTxnKeys = append(finalTxnKeys, finalTxnKey)
+		}
+	}
+
+	for _, k := range finalTxnKeys {
+		if err := hghtBucket.Delete(k); err != nil {
+			return err
+		}
 	}
 
-	return txn, nil
+	return nil
 }
 
 // getLastGraduatedHeight is a helper method that retrieves the last height for
@@ -1424,6 +3307,10 @@ func (ns *nurseryStore) putLastGraduatedHeight(tx *bolt.Tx, height uint32) error
 // bucket failed because it still has active outputs.
 var errBucketNotEmpty = errors.New("bucket is not empty, cannot be pruned")
 
+// ErrKinderNotFound is returned when an output expected to reside in the
+// kindergarten bucket could not be found.
+var ErrKinderNotFound = errors.New("kindergarten output not found")
+
 // removeOutputFromHeight will delete the given output from the specified
 // height-channel bucket, and attempt to prune the upstream directories if they
 // are empty.
@@ -1523,6 +3410,114 @@ func (ns *nurseryStore) pruneHeight(tx *bolt.Tx, height uint32) (bool, error) {
 	return true, nil
 }
 
+// pruneHeightIndex removes all empty height-channel buckets from the height
+// index. Any height bucket that is left without any entries, including
+// finalized txns, is then removed altogether.
+func (ns *nurseryStore) pruneHeightIndex(chainBucket *bolt.Bucket) error {
+	hghtIndex := chainBucket.Bucket(heightIndexKey)
+	if hghtIndex == nil {
+		return nil
+	}
+
+	// Collect the height buckets before modifying them, as bolt does not
+	// permit the bucket being iterated to be modified.
+	var heights [][]byte
+	if err := hghtIndex.ForEach(func(heightBytes, v []byte) error {
+		if v == nil {
+			heights = append(heights, heightBytes)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, heightBytes := range heights {
+		hghtBucket := hghtIndex.Bucket(heightBytes)
+		if err := removeEmptyBuckets(hghtBucket); err != nil {
+			return err
+		}
+
+		err := removeBucketIfEmpty(hghtIndex, heightBytes)
+		switch {
+		case err == errBucketNotEmpty:
+			continue
+		case err != nil:
+			return err
+		}
+
+		utxnLog.Infof("Height bucket %d pruned",
+			byteOrder.Uint32(heightBytes))
+	}
+
+	return nil
+}
+
+// pruneLimboBalances removes the limbo balance of any channel that no longer
+// has a channel bucket in the channel index.
+func (ns *nurseryStore) pruneLimboBalances(chainBucket *bolt.Bucket) error {
+	limboIndex := chainBucket.Bucket(limboBalanceIndexKey)
+	if limboIndex == nil {
+		return nil
+	}
+
+	chanIndex := chainBucket.Bucket(channelIndexKey)
+
+	var orphans [][]byte
+	if err := limboIndex.ForEach(func(chanBytes, _ []byte) error {
+		if chanIndex == nil || chanIndex.Bucket(chanBytes) == nil {
+			orphans = append(orphans, chanBytes)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, chanBytes := range orphans {
+		if err := limboIndex.Delete(chanBytes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeEmptyBuckets deletes each nested bucket of the provided parent bucket
+// that has no children. Non-bucket entries of the parent are left untouched.
+func removeEmptyBuckets(parent *bolt.Bucket) error {
+	var empty [][]byte
+	if err := parent.ForEach(func(bktName, v []byte) error {
+		// Skip any non-bucket entries.
+		if v != nil {
+			return nil
+		}
+
+		bkt := parent.Bucket(bktName)
+		if bkt == nil {
+			return nil
+		}
+
+		switch err := isBucketEmpty(bkt); {
+		case err == errBucketNotEmpty:
+			return nil
+		case err != nil:
+			return err
+		}
+
+		empty = append(empty, bktName)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, bktName := range empty {
+		if err := parent.DeleteBucket(bktName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // removeBucketIfEmpty attempts to delete a bucket specified by name from the
 // provided parent bucket.
 func removeBucketIfEmpty(parent *bolt.Bucket, bktName []byte) error {
@@ -3,14 +3,25 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/boltdb/bolt"
 	"github.com/btcsuite/btclog"
+	"github.com/davecgh/go-spew/spew"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/roasbeef/btcd/chaincfg/chainhash"
 	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
 )
 
 func init() {
@@ -215,7 +226,7 @@ func TestNurseryStoreIncubate(t *testing.T) {
 			maturityHeight := test.commOutput.ConfHeight() +
 				test.commOutput.BlocksToMaturity()
 
-			err = ns.GraduateKinder(maturityHeight)
+			err = ns.GraduateKinder(maturityHeight, maturityHeight)
 			if err != nil {
 				t.Fatalf("unable to graduate kindergarten class at "+
 					"height %d: %v", maturityHeight, err)
@@ -289,7 +300,7 @@ func TestNurseryStoreIncubate(t *testing.T) {
 				maturityHeight := htlcOutput.ConfHeight() +
 					htlcOutput.BlocksToMaturity()
 
-				err = ns.GraduateKinder(maturityHeight)
+				err = ns.GraduateKinder(maturityHeight, maturityHeight)
 				if err != nil {
 					t.Fatalf("unable to graduate htlc output "+
 						"from kndr to grad: %v", err)
@@ -336,6 +347,43 @@ func TestNurseryStoreIncubate(t *testing.T) {
 	}
 }
 
+// TestNurseryStoreIncubateBatch asserts that the commitment outputs of multiple
+// channels can be incubated along with htlc outputs in a single batch, and
+// that each channel's limbo balance only reflects its own outputs.
+func TestNurseryStoreIncubateBatch(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid1 := kidOutputs[0]
+	kid2 := kidOutputs[1]
+	kid2.originChanPoint = outPoints[5]
+	baby := babyOutputs[1]
+
+	err = ns.IncubateBatch([]*kidOutput{&kid1, &kid2}, []babyOutput{baby})
+	if err != nil {
+		t.Fatalf("unable to incubate batch: %v", err)
+	}
+
+	assertNumChannels(t, ns, 2)
+	assertNumPreschools(t, ns, 2)
+	assertCribAtExpiryHeight(t, ns, &baby)
+
+	assertNumChanOutputs(t, ns, kid1.OriginChanPoint(), 2)
+	assertChanLimboBalance(
+		t, ns, kid1.OriginChanPoint(), kid1.Amount()+baby.Amount(),
+	)
+	assertNumChanOutputs(t, ns, kid2.OriginChanPoint(), 1)
+	assertChanLimboBalance(t, ns, kid2.OriginChanPoint(), kid2.Amount())
+}
+
 // TestNurseryStoreFinalize tests that kindergarten sweep transactions are
 // properly persistted, and that the last finalized height is being set
 // accordingly.
@@ -382,13 +430,13 @@ func TestNurseryStoreFinalize(t *testing.T) {
 	// ensuring that the last finalized height is properly persisted, and
 	// that the finalized transactions are all nil.
 	for i := 0; i < int(maturityHeight); i++ {
-		err = ns.FinalizeKinder(uint32(i), nil)
+		err = ns.FinalizeKinder(uint32(i), nil, nil)
 		if err != nil {
 			t.Fatalf("unable to finalize kndr at height=%d: %v",
 				i, err)
 		}
 		assertLastFinalizedHeight(t, ns, uint32(i))
-		assertFinalizedTxn(t, ns, uint32(i), nil)
+		assertFinalizedTxns(t, ns, uint32(i), nil)
 	}
 
 	// As we have now finalized all heights below the maturity height, we
@@ -398,7 +446,8 @@ func TestNurseryStoreFinalize(t *testing.T) {
 
 	// Now, finalize the kindergarten sweep transaction at the maturity
 	// height.
-	err = ns.FinalizeKinder(maturityHeight, timeoutTx)
+	finalTxns := []*wire.MsgTx{timeoutTx}
+	err = ns.FinalizeKinder(maturityHeight, finalTxns, nil)
 	if err != nil {
 		t.Fatalf("unable to finalize kndr at height=%d: %v",
 			maturityHeight, err)
@@ -408,21 +457,210 @@ func TestNurseryStoreFinalize(t *testing.T) {
 	// the finalized kindergarten sweep txn should be returned at this
 	// height.
 	assertLastFinalizedHeight(t, ns, maturityHeight)
-	assertFinalizedTxn(t, ns, maturityHeight, timeoutTx)
+	assertFinalizedTxns(t, ns, maturityHeight, finalTxns)
 
 	// Lastly, continue to finalize heights above the maturity height. Each
 	// should report having a nil finalized kindergarten sweep txn.
 	for i := maturityHeight + 1; i < maturityHeight+10; i++ {
-		err = ns.FinalizeKinder(uint32(i), nil)
+		err = ns.FinalizeKinder(uint32(i), nil, nil)
 		if err != nil {
 			t.Fatalf("unable to finalize kndr at height=%d: %v",
 				i, err)
 		}
 		assertLastFinalizedHeight(t, ns, uint32(i))
-		assertFinalizedTxn(t, ns, uint32(i), nil)
+		assertFinalizedTxns(t, ns, uint32(i), nil)
+	}
+}
+
+// TestNurseryStoreUpdate asserts that the operations performed within an
+// Update observe each other's writes, and are only persisted if the closure
+// succeeds.
+func TestNurseryStoreUpdate(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := &kidOutputs[3]
+	maturityHeight := kid.MaturityHeight()
+
+	if err := ns.Incubate(kid, nil); err != nil {
+		t.Fatalf("unable to incubate commitment output: %v", err)
+	}
+	if err := ns.PreschoolToKinder(kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	// Defer the output and finalize its maturity height within a single
+	// transaction, which is then aborted.
+	errAbort := errors.New("abort")
+	err = ns.Update(func(store NurseryStore) error {
+		err := store.DeferKinder(kid, maturityHeight, maturityHeight+1)
+		if err != nil {
+			return err
+		}
+
+		err = store.FinalizeKinder(maturityHeight, nil, nil)
+		if err != nil {
+			return err
+		}
+
+		// The writes should be visible within the transaction.
+		assertLastFinalizedHeight(t, store, maturityHeight)
+		assertKndrNotAtMaturityHeight(t, store, kid)
+
+		return errAbort
+	})
+	if err != errAbort {
+		t.Fatalf("expected update to be aborted, got: %v", err)
+	}
+
+	// Since the transaction was aborted, none of its writes should have
+	// been persisted.
+	assertLastFinalizedHeight(t, ns, 0)
+	assertKndrAtMaturityHeight(t, ns, kid)
+
+	// Once the closure succeeds, all of its writes should be persisted.
+	err = ns.Update(func(store NurseryStore) error {
+		err := store.DeferKinder(kid, maturityHeight, maturityHeight+1)
+		if err != nil {
+			return err
+		}
+
+		return store.FinalizeKinder(maturityHeight, nil, nil)
+	})
+	if err != nil {
+		t.Fatalf("unable to update nursery store: %v", err)
+	}
+
+	assertLastFinalizedHeight(t, ns, maturityHeight)
+	assertKndrNotAtMaturityHeight(t, ns, kid)
+
+	_, kgtnOutputs, _, err := ns.FetchClass(maturityHeight + 1)
+	if err != nil {
+		t.Fatalf("unable to fetch class: %v", err)
+	}
+	if len(kgtnOutputs) != 1 ||
+		*kgtnOutputs[0].OutPoint() != *kid.OutPoint() {
+
+		t.Fatalf("expected output %v to be deferred, got %v",
+			kid.OutPoint(), kgtnOutputs)
 	}
 }
 
+// TestNurseryStoreFinalizeMultiple tests that a kindergarten class can be
+// finalized with multiple sweep transactions, as is done when the nursery
+// segregates its sweeps, and that all of them are cleaned up once the class
+// graduates.
+func TestNurseryStoreFinalizeMultiple(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := &kidOutputs[3]
+
+	// Compute the maturity height at which to enter the commitment output.
+	maturityHeight := kid.ConfHeight() + kid.BlocksToMaturity()
+
+	// Incubate the commitment output, and move it to the kindergarten
+	// bucket so that its maturity height is present in the height index.
+	err = ns.Incubate(kid, nil)
+	if err != nil {
+		t.Fatalf("unable to incubate commitment output: %v", err)
+	}
+
+	err = ns.PreschoolToKinder(kid)
+	if err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	// Create a second sweep txn that differs from the first, so that we
+	// can verify both txns are persisted and restored in order.
+	secondTx := timeoutTx.Copy()
+	secondTx.LockTime++
+
+	finalTxns := []*wire.MsgTx{timeoutTx, secondTx}
+	feeRates := []btcutil.Amount{25, 50}
+	err = ns.FinalizeKinder(maturityHeight, finalTxns, feeRates)
+	if err != nil {
+		t.Fatalf("unable to finalize kndr at height=%d: %v",
+			maturityHeight, err)
+	}
+
+	assertLastFinalizedHeight(t, ns, maturityHeight)
+	assertFinalizedTxns(t, ns, maturityHeight, finalTxns)
+	assertKndrAtMaturityHeight(t, ns, kid)
+
+	// The fee rate of each txn should be restored alongside its txid and
+	// swept amount.
+	sweepInfos, err := ns.FinalizedSweepInfo(maturityHeight)
+	if err != nil {
+		t.Fatalf("unable to fetch finalized sweep info: %v", err)
+	}
+	if len(sweepInfos) != len(finalTxns) {
+		t.Fatalf("expected %d sweep infos, got %d", len(finalTxns),
+			len(sweepInfos))
+	}
+	for i, sweepInfo := range sweepInfos {
+		expInfo := SweepInfo{
+			Txid:    finalTxns[i].TxHash(),
+			FeeRate: feeRates[i],
+		}
+		for _, txOut := range finalTxns[i].TxOut {
+			expInfo.SweptAmount += btcutil.Amount(txOut.Value)
+		}
+		if sweepInfo != expInfo {
+			t.Fatalf("expected sweep info %+v, got %+v", expInfo,
+				sweepInfo)
+		}
+	}
+
+	// Record a broadcast followed by a rebroadcast of the sweep txns, the
+	// count should be removed along with the txns upon graduation.
+	for i := uint32(1); i <= 2; i++ {
+		numBroadcasts, err := ns.RecordSweepBroadcast(maturityHeight)
+		if err != nil {
+			t.Fatalf("unable to record sweep broadcast: %v", err)
+		}
+		if numBroadcasts != i {
+			t.Fatalf("expected %d broadcasts, got %d", i,
+				numBroadcasts)
+		}
+	}
+
+	numBroadcasts, err := ns.SweepBroadcasts(maturityHeight)
+	if err != nil {
+		t.Fatalf("unable to fetch sweep broadcasts: %v", err)
+	}
+	if numBroadcasts != 2 {
+		t.Fatalf("expected 2 broadcasts, got %d", numBroadcasts)
+	}
+
+	// Graduating the class should remove all of the finalized txns, along
+	// with the kindergarten output, leaving the height purged.
+	err = ns.GraduateKinder(maturityHeight, maturityHeight)
+	if err != nil {
+		t.Fatalf("unable to graduate kindergarten outputs at height=%d: "+
+			"%v", maturityHeight, err)
+	}
+
+	assertHeightIsPurged(t, ns, maturityHeight)
+	assertChannelMaturity(t, ns, kid.OriginChanPoint(), true)
+}
+
 // TestNurseryStoreGraduate verifies that the nursery store properly removes
 // populated entries from the height index as it is purged, and that the last
 // purged height is set appropriately.
@@ -454,6 +692,9 @@ func TestNurseryStoreGraduate(t *testing.T) {
 		t.Fatalf("unable to incubate commitment output: %v", err)
 	}
 
+	// The full value of the commitment output should now be in limbo.
+	assertChanLimboBalance(t, ns, kid.OriginChanPoint(), kid.Amount())
+
 	// Then, move the commitment output to the kindergarten bucket, such
 	// that it resides in the height index at it's maturity height.
 	err = ns.PreschoolToKinder(kid)
@@ -479,8 +720,12 @@ func TestNurseryStoreGraduate(t *testing.T) {
 	assertKndrAtMaturityHeight(t, ns, kid)
 
 	// Finalize the kindergarten transaction, ensuring that it is a non-nil
-	// value.
-	err = ns.FinalizeKinder(maturityHeight, timeoutTx)
+	// value. The txn spends the commitment output, so that its txid will
+	// be recorded as the channel's sweep upon graduation.
+	sweepTx := timeoutTx.Copy()
+	sweepTx.TxIn[0].PreviousOutPoint = *kid.OutPoint()
+	finalTxns := []*wire.MsgTx{sweepTx}
+	err = ns.FinalizeKinder(maturityHeight, finalTxns, nil)
 	if err != nil {
 		t.Fatalf("unable to finalize kndr at height=%d: %v",
 			maturityHeight, err)
@@ -488,7 +733,7 @@ func TestNurseryStoreGraduate(t *testing.T) {
 
 	// Verify that the maturity height has now been finalized.
 	assertLastFinalizedHeight(t, ns, maturityHeight)
-	assertFinalizedTxn(t, ns, maturityHeight, timeoutTx)
+	assertFinalizedTxns(t, ns, maturityHeight, finalTxns)
 
 	// Finally, purge the non-empty maturity height, and check that returned
 	// class is empty.
@@ -498,257 +743,2703 @@ func TestNurseryStoreGraduate(t *testing.T) {
 			err)
 	}
 
-	err = ns.GraduateKinder(maturityHeight)
+	err = ns.GraduateKinder(maturityHeight, maturityHeight)
 	if err != nil {
 		t.Fatalf("unable to graduate kindergarten outputs at height=%d: "+
 			"%v", maturityHeight, err)
 	}
 
 	assertHeightIsPurged(t, ns, maturityHeight)
-}
 
-// assertNumChanOutputs checks that the channel bucket has the expected number
-// of outputs.
-func assertNumChanOutputs(t *testing.T, ns NurseryStore,
-	chanPoint *wire.OutPoint, expectedNum int) {
+	// Now that the output has graduated, the channel should no longer have
+	// any value in limbo.
+	assertChanLimboBalance(t, ns, kid.OriginChanPoint(), 0)
 
-	var count int
-	err := ns.ForChanOutputs(chanPoint, func([]byte, []byte) error {
-		count++
+	// The graduated output should record the height at which its sweep
+	// confirmed.
+	err = ns.ForChanOutputs(kid.OriginChanPoint(), func(k, v []byte) error {
+		var gradKid kidOutput
+		if err := gradKid.Decode(bytes.NewReader(v)); err != nil {
+			return err
+		}
+		if gradKid.SweepConfHeight() != maturityHeight {
+			t.Fatalf("expected sweep conf height %d, got %d",
+				maturityHeight, gradKid.SweepConfHeight())
+		}
 		return nil
 	})
-
-	if count == 0 && err == ErrContractNotFound {
-		return
-	} else if err != nil {
-		t.Fatalf("unable to count num outputs for channel %v: %v",
-			chanPoint, err)
+	if err != nil {
+		t.Fatalf("unable to iterate channel outputs: %v", err)
 	}
 
-	if count != expectedNum {
-		t.Fatalf("nursery store should have %d outputs, found %d",
-			expectedNum, count)
+	// The txid of the sweep txn should have been recorded for the channel.
+	sweepTxids, err := ns.ChanSweepTxids(kid.OriginChanPoint())
+	if err != nil {
+		t.Fatalf("unable to fetch sweep txids: %v", err)
+	}
+	expTxids := []chainhash.Hash{sweepTx.TxHash()}
+	if !reflect.DeepEqual(sweepTxids, expTxids) {
+		t.Fatalf("expected sweep txids %v, got %v", expTxids,
+			sweepTxids)
 	}
 }
 
-// assertLastFinalizedHeight checks that the nursery stores last finalized
-// height matches the expected height.
-func assertLastFinalizedHeight(t *testing.T, ns NurseryStore,
-	expected uint32) {
+// TestNurseryStoreArchiveChannel tests that archiving a mature channel removes
+// it from the channel index, while retaining its graduated outputs such that
+// they can be retrieved via FetchArchivedGraduations.
+func TestNurseryStoreArchiveChannel(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
 
-	lfh, err := ns.LastFinalizedHeight()
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
 	if err != nil {
-		t.Fatalf("unable to get last finalized height: %v", err)
+		t.Fatalf("unable to open nursery store: %v", err)
 	}
 
-	if lfh != expected {
-		t.Fatalf("expected last finalized height to be %d, got %d",
-			expected, lfh)
+	kid := &kidOutputs[3]
+	maturityHeight := kid.ConfHeight() + kid.BlocksToMaturity()
+
+	if err := ns.Incubate(kid, nil); err != nil {
+		t.Fatalf("unable to incubate commitment output: %v", err)
 	}
-}
 
-// assertLastGraduatedHeight checks that the nursery stores last purged height
-// matches the expected height.
-func assertLastGraduatedHeight(t *testing.T, ns NurseryStore, expected uint32) {
-	lgh, err := ns.LastGraduatedHeight()
-	if err != nil {
-		t.Fatalf("unable to get last graduated height: %v", err)
+	// An immature channel cannot be archived.
+	err = ns.ArchiveChannel(kid.OriginChanPoint())
+	if err != ErrImmatureChannel {
+		t.Fatalf("expected ErrImmatureChannel when archiving "+
+			"immature channel, got: %v", err)
 	}
 
-	if lgh != expected {
-		t.Fatalf("expected last graduated height to be %d, got %d",
-			expected, lgh)
+	if err := ns.PreschoolToKinder(kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+	err = ns.FinalizeKinder(maturityHeight, []*wire.MsgTx{timeoutTx}, nil)
+	if err != nil {
+		t.Fatalf("unable to finalize kndr at height=%d: %v",
+			maturityHeight, err)
+	}
+	if err := ns.GraduateKinder(maturityHeight, maturityHeight); err != nil {
+		t.Fatalf("unable to graduate kindergarten outputs at height=%d: "+
+			"%v", maturityHeight, err)
 	}
-}
 
-// assertNumPreschools loads all preschool outputs and verifies their count
-// matches the expected number.
-func assertNumPreschools(t *testing.T, ns NurseryStore, expected int) {
-	psclOutputs, err := ns.FetchPreschools()
+	// Before the channel is archived, the archive should be empty.
+	archived, err := ns.FetchArchivedGraduations(kid.OriginChanPoint())
 	if err != nil {
-		t.Fatalf("unable to retrieve preschool outputs: %v", err)
+		t.Fatalf("unable to fetch archived graduations: %v", err)
+	}
+	if len(archived) != 0 {
+		t.Fatalf("expected no archived outputs, got %d", len(archived))
 	}
 
-	if len(psclOutputs) != expected {
-		t.Fatalf("expected number of pscl outputs to be %d, got %v",
-			expected, len(psclOutputs))
+	if err := ns.ArchiveChannel(kid.OriginChanPoint()); err != nil {
+		t.Fatalf("unable to archive channel: %v", err)
 	}
-}
 
-// assertNumChannels checks that the nursery has a given number of active
-// channels.
-func assertNumChannels(t *testing.T, ns NurseryStore, expected int) {
-	channels, err := ns.ListChannels()
+	// The channel should no longer be tracked by the nursery store.
+	assertNumChannels(t, ns, 0)
+
+	// However, its graduated output should be retrievable from the
+	// archive.
+	archived, err = ns.FetchArchivedGraduations(kid.OriginChanPoint())
 	if err != nil {
-		t.Fatalf("unable to fetch channels from nursery store: %v",
-			err)
+		t.Fatalf("unable to fetch archived graduations: %v", err)
 	}
-
-	if len(channels) != expected {
-		t.Fatalf("expected number of active channels to be %d, got %d",
-			expected, len(channels))
+	if len(archived) != 1 {
+		t.Fatalf("expected 1 archived output, got %d", len(archived))
+	}
+	if *archived[0].OutPoint() != *kid.OutPoint() {
+		t.Fatalf("expected archived outpoint %v, got %v",
+			kid.OutPoint(), archived[0].OutPoint())
+	}
+	if archived[0].SweepConfHeight() != maturityHeight {
+		t.Fatalf("expected sweep conf height %d, got %d",
+			maturityHeight, archived[0].SweepConfHeight())
 	}
 }
 
-// assertHeightIsPurged checks that the finalized transaction, kindergarten, and
-// htlc outputs at a particular height are all nil.
-func assertHeightIsPurged(t *testing.T, ns NurseryStore,
-	height uint32) {
-
-	finalTx, kndrOutputs, cribOutputs, err := ns.FetchClass(height)
+// TestNurseryStoreDroppedOutputs tests that dropped outputs are persisted for
+// their channel, and remain retrievable after the channel is removed.
+func TestNurseryStoreDroppedOutputs(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
 	if err != nil {
-		t.Fatalf("unable to retrieve class at height=%d: %v",
-			height, err)
+		t.Fatalf("unable to open channel db: %v", err)
 	}
+	defer cleanUp()
 
-	if finalTx != nil {
-		t.Fatalf("height=%d not purged, final txn should be nil", height)
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
 	}
 
-	if kndrOutputs != nil {
-		t.Fatalf("height=%d not purged, kndr outputs should be nil", height)
+	chanPoint := kidOutputs[0].OriginChanPoint()
+
+	// Before any outputs are recorded, none should be returned.
+	dropped, err := ns.FetchDroppedOutputs(chanPoint)
+	if err != nil {
+		t.Fatalf("unable to fetch dropped outputs: %v", err)
+	}
+	if len(dropped) != 0 {
+		t.Fatalf("expected no dropped outputs, got %d", len(dropped))
 	}
 
-	if cribOutputs != nil {
-		t.Fatalf("height=%d not purged, crib outputs should be nil", height)
+	expected := []droppedOutput{
+		{
+			outpoint: outPoints[0],
+			amt:      0,
+			reason:   dropZeroValue,
+		},
+		{
+			outpoint: outPoints[1],
+			amt:      btcutil.Amount(1000),
+			reason:   dropUneconomical,
+		},
+	}
+	if err := ns.RecordDroppedOutputs(chanPoint, expected); err != nil {
+		t.Fatalf("unable to record dropped outputs: %v", err)
 	}
-}
 
-// assertCribAtExpiryHeight loads the class at the given height, and verifies
-// that the given htlc output is one of the crib outputs.
-func assertCribAtExpiryHeight(t *testing.T, ns NurseryStore,
-	htlcOutput *babyOutput) {
+	// Removing the channel should not erase its dropped outputs.
+	if err := ns.RemoveChannel(chanPoint); err != nil {
+		t.Fatalf("unable to remove channel: %v", err)
+	}
 
-	expiryHeight := htlcOutput.expiry
-	_, _, cribOutputs, err := ns.FetchClass(expiryHeight)
+	dropped, err = ns.FetchDroppedOutputs(chanPoint)
 	if err != nil {
-		t.Fatalf("unable to retrieve class at height=%d: %v",
-			expiryHeight, err)
+		t.Fatalf("unable to fetch dropped outputs: %v", err)
+	}
+	if len(dropped) != len(expected) {
+		t.Fatalf("expected %d dropped outputs, got %d", len(expected),
+			len(dropped))
 	}
 
-	for _, crib := range cribOutputs {
-		if reflect.DeepEqual(&crib, htlcOutput) {
-			return
+	for _, want := range expected {
+		var found bool
+		for _, output := range dropped {
+			if reflect.DeepEqual(output, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("dropped output %v not found in %v",
+				spew.Sdump(want), spew.Sdump(dropped))
 		}
 	}
-
-	t.Fatalf("could not find crib output %v at height %d",
-		htlcOutput.OutPoint(), expiryHeight)
 }
 
-// assertCribNotAtExpiryHeight loads the class at the given height, and verifies
-// that the given htlc output is not one of the crib outputs.
+// TestNurseryStoreUngraduateKinder tests that reverting the graduation of a
+// kindergarten output restores it to the kindergarten bucket at its maturity
+// height, along with its limbo balance and the finalized sweep txn.
+func TestNurseryStoreUngraduateKinder(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := &kidOutputs[3]
+	chanPoint := kid.OriginChanPoint()
+	maturityHeight := kid.ConfHeight() + kid.BlocksToMaturity()
+
+	if err := ns.Incubate(kid, nil); err != nil {
+		t.Fatalf("unable to incubate commitment output: %v", err)
+	}
+	if err := ns.PreschoolToKinder(kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	sweepTx := timeoutTx.Copy()
+	sweepTx.TxIn[0].PreviousOutPoint = *kid.OutPoint()
+	finalTxns := []*wire.MsgTx{sweepTx}
+	err = ns.FinalizeKinder(maturityHeight, finalTxns, nil)
+	if err != nil {
+		t.Fatalf("unable to finalize kndr at height=%d: %v",
+			maturityHeight, err)
+	}
+	if err := ns.GraduateKinder(maturityHeight, maturityHeight); err != nil {
+		t.Fatalf("unable to graduate kindergarten outputs at height=%d: "+
+			"%v", maturityHeight, err)
+	}
+
+	assertKndrNotAtMaturityHeight(t, ns, kid)
+	assertChanLimboBalance(t, ns, chanPoint, 0)
+	assertChannelMaturity(t, ns, chanPoint, true)
+
+	// Now, revert the graduation as if the sweep's confirmation had been
+	// reorged out of the chain.
+	err = ns.UngraduateKinder(maturityHeight, []kidOutput{*kid}, finalTxns)
+	if err != nil {
+		t.Fatalf("unable to ungraduate kindergarten outputs: %v", err)
+	}
+
+	// The output should once again be in the kindergarten at its maturity
+	// height, with the finalized sweep txn available for rebroadcast.
+	assertKndrAtMaturityHeight(t, ns, kid)
+	assertFinalizedTxns(t, ns, maturityHeight, finalTxns)
+	assertChanLimboBalance(t, ns, chanPoint, kid.Amount())
+	assertChannelMaturity(t, ns, chanPoint, false)
+
+	sweepTxids, err := ns.ChanSweepTxids(chanPoint)
+	if err != nil {
+		t.Fatalf("unable to fetch sweep txids: %v", err)
+	}
+	if len(sweepTxids) != 0 {
+		t.Fatalf("expected no sweep txids, got %v", sweepTxids)
+	}
+}
+
+// TestNurseryStoreChannelCloseTx asserts that the close txid recorded for a
+// channel can be retrieved while the channel is incubating, and is removed
+// along with the rest of the channel's entries.
+func TestNurseryStoreChannelCloseTx(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := &kidOutputs[0]
+	chanPoint := kid.OriginChanPoint()
+
+	// Before the close txid is recorded, none should be returned.
+	closeTxid, err := ns.ChannelCloseTx(chanPoint)
+	if err != nil {
+		t.Fatalf("unable to fetch close txid: %v", err)
+	}
+	if closeTxid != nil {
+		t.Fatalf("expected no close txid, got %v", closeTxid)
+	}
+
+	if err := ns.Incubate(kid, nil); err != nil {
+		t.Fatalf("unable to incubate output: %v", err)
+	}
+
+	expected := kid.OutPoint().Hash
+	if err := ns.RecordChannelCloseTx(chanPoint, &expected); err != nil {
+		t.Fatalf("unable to record close txid: %v", err)
+	}
+
+	closeTxid, err = ns.ChannelCloseTx(chanPoint)
+	if err != nil {
+		t.Fatalf("unable to fetch close txid: %v", err)
+	}
+	if closeTxid == nil || *closeTxid != expected {
+		t.Fatalf("expected close txid %v, got %v", expected, closeTxid)
+	}
+
+	// Once the channel is removed from the store, its close txid should
+	// no longer be returned.
+	if err := ns.CancelChannel(chanPoint); err != nil {
+		t.Fatalf("unable to cancel channel: %v", err)
+	}
+
+	closeTxid, err = ns.ChannelCloseTx(chanPoint)
+	if err != nil {
+		t.Fatalf("unable to fetch close txid: %v", err)
+	}
+	if closeTxid != nil {
+		t.Fatalf("expected close txid to be removed, got %v", closeTxid)
+	}
+}
+
+// TestNurseryStoreGraduateSweep asserts that the kindergarten outputs at a
+// height can be graduated by each of the height's sweep txns independently,
+// and that the finalized txns are only removed once every output graduated.
+func TestNurseryStoreGraduateSweep(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	// Both outputs belong to the same channel, and mature at the same
+	// height.
+	kid1, kid2 := &kidOutputs[0], &kidOutputs[1]
+	chanPoint := kid1.OriginChanPoint()
+	maturityHeight := kid1.MaturityHeight()
+
+	for _, kid := range []*kidOutput{kid1, kid2} {
+		if err := ns.Incubate(kid, nil); err != nil {
+			t.Fatalf("unable to incubate commitment output: %v",
+				err)
+		}
+		if err := ns.PreschoolToKinder(kid); err != nil {
+			t.Fatalf("unable to move pscl output to kndr: %v", err)
+		}
+	}
+
+	// Each output is swept by its own txn.
+	sweepTx1 := timeoutTx.Copy()
+	sweepTx1.TxIn[0].PreviousOutPoint = *kid1.OutPoint()
+	sweepTx2 := timeoutTx.Copy()
+	sweepTx2.TxIn[0].PreviousOutPoint = *kid2.OutPoint()
+	finalTxns := []*wire.MsgTx{sweepTx1, sweepTx2}
+
+	err = ns.FinalizeKinder(maturityHeight, finalTxns, nil)
+	if err != nil {
+		t.Fatalf("unable to finalize kndr at height=%d: %v",
+			maturityHeight, err)
+	}
+
+	// Graduating the second sweep should only graduate the output it
+	// spends, leaving the finalized txns in place for the first.
+	graduated, err := ns.GraduateSweep(maturityHeight, sweepTx2, 1050)
+	if err != nil {
+		t.Fatalf("unable to graduate sweep: %v", err)
+	}
+	if len(graduated) != 1 || *graduated[0].OutPoint() != *kid2.OutPoint() {
+		t.Fatalf("expected output %v to graduate, got %v",
+			kid2.OutPoint(), graduated)
+	}
+
+	assertKndrAtMaturityHeight(t, ns, kid1)
+	assertKndrNotAtMaturityHeight(t, ns, kid2)
+	assertFinalizedTxns(t, ns, maturityHeight, finalTxns)
+	assertChanLimboBalance(t, ns, chanPoint, kid1.Amount())
+	assertChannelMaturity(t, ns, chanPoint, false)
+
+	sweepTxids, err := ns.ChanSweepTxids(chanPoint)
+	if err != nil {
+		t.Fatalf("unable to fetch sweep txids: %v", err)
+	}
+	if len(sweepTxids) != 1 || sweepTxids[0] != sweepTx2.TxHash() {
+		t.Fatalf("expected sweep txid %v, got %v", sweepTx2.TxHash(),
+			sweepTxids)
+	}
+
+	// Once the first sweep graduates the remaining output, the height
+	// should be purged entirely.
+	graduated, err = ns.GraduateSweep(maturityHeight, sweepTx1, 1051)
+	if err != nil {
+		t.Fatalf("unable to graduate sweep: %v", err)
+	}
+	if len(graduated) != 1 || *graduated[0].OutPoint() != *kid1.OutPoint() {
+		t.Fatalf("expected output %v to graduate, got %v",
+			kid1.OutPoint(), graduated)
+	}
+
+	// Graduating the same sweep again should graduate nothing.
+	graduated, err = ns.GraduateSweep(maturityHeight, sweepTx1, 1051)
+	if err != nil {
+		t.Fatalf("unable to graduate sweep: %v", err)
+	}
+	if len(graduated) != 0 {
+		t.Fatalf("expected no outputs to graduate, got %v", graduated)
+	}
+
+	assertKndrNotAtMaturityHeight(t, ns, kid1)
+	assertHeightIsPurged(t, ns, maturityHeight)
+	assertChanLimboBalance(t, ns, chanPoint, 0)
+	assertChannelMaturity(t, ns, chanPoint, true)
+
+	sweepTxids, err = ns.ChanSweepTxids(chanPoint)
+	if err != nil {
+		t.Fatalf("unable to fetch sweep txids: %v", err)
+	}
+	if len(sweepTxids) != 2 {
+		t.Fatalf("expected 2 sweep txids, got %v", sweepTxids)
+	}
+}
+
+// TestNurseryStoreResolveCrib tests that resolving a crib output moves it to the
+// resolved state in the channel bucket, removes it from the height index, and
+// deducts its value from the channel's limbo balance.
+func TestNurseryStoreResolveCrib(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := &kidOutputs[0]
+	baby := &babyOutputs[0]
+
+	err = ns.Incubate(kid, []babyOutput{*baby})
+	if err != nil {
+		t.Fatalf("unable to incubate outputs: %v", err)
+	}
+
+	chanPoint := kid.OriginChanPoint()
+	assertCribAtExpiryHeight(t, ns, baby)
+	assertNumChanOutputs(t, ns, chanPoint, 2)
+	assertChanLimboBalance(t, ns, chanPoint, kid.Amount()+baby.Amount())
+
+	if err := ns.ResolveCrib(baby); err != nil {
+		t.Fatalf("unable to resolve crib output: %v", err)
+	}
+
+	assertCribNotAtExpiryHeight(t, ns, baby)
+	assertNumChanOutputs(t, ns, chanPoint, 2)
+	assertChanLimboBalance(t, ns, chanPoint, kid.Amount())
+
+	// The output should be retained under the resolved prefix.
+	rslvKey, err := prefixOutputKey(rslvPrefix, baby.OutPoint())
+	if err != nil {
+		t.Fatalf("unable to create resolved key: %v", err)
+	}
+
+	var found bool
+	err = ns.ForChanOutputs(chanPoint, func(k, _ []byte) error {
+		if bytes.Equal(k, rslvKey) {
+			found = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unable to iterate channel outputs: %v", err)
+	}
+	if !found {
+		t.Fatalf("resolved output not found in channel bucket")
+	}
+
+	// Resolving the crib output a second time should be a no-op.
+	if err := ns.ResolveCrib(baby); err != nil {
+		t.Fatalf("unable to resolve crib output: %v", err)
+	}
+	assertNumChanOutputs(t, ns, chanPoint, 2)
+	assertChanLimboBalance(t, ns, chanPoint, kid.Amount())
+
+	// Once the remaining output graduates, the resolved output shouldn't
+	// prevent the channel from being considered mature and removed.
+	if err := ns.PreschoolToKinder(kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+	if err := ns.GraduateKinder(kid.MaturityHeight(), 0); err != nil {
+		t.Fatalf("unable to graduate kindergarten class: %v", err)
+	}
+
+	isMature, err := ns.IsMatureChannel(chanPoint)
+	if err != nil {
+		t.Fatalf("unable to determine channel maturity: %v", err)
+	}
+	if !isMature {
+		t.Fatalf("channel should be mature")
+	}
+
+	if err := ns.RemoveChannel(chanPoint); err != nil {
+		t.Fatalf("unable to remove channel: %v", err)
+	}
+	assertNumChanOutputs(t, ns, chanPoint, 0)
+}
+
+// TestNurseryStoreKinderToPreschool tests that a kindergarten output can be
+// moved back to the preschool bucket, removing it from the height index, and
+// that it can subsequently be promoted again.
+func TestNurseryStoreKinderToPreschool(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[3]
+
+	err = ns.Incubate(&kid, nil)
+	if err != nil {
+		t.Fatalf("unable to incubate commitment output: %v", err)
+	}
+
+	err = ns.PreschoolToKinder(&kid)
+	if err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+	assertNumPreschools(t, ns, 0)
+	assertKndrAtMaturityHeight(t, ns, &kid)
+
+	// Simulate a reorg of the commitment txn, which should remove the
+	// output from the height index and return it to the preschool bucket.
+	err = ns.KinderToPreschool(&kid)
+	if err != nil {
+		t.Fatalf("unable to move kndr output to pscl: %v", err)
+	}
+	assertNumPreschools(t, ns, 1)
+	assertKndrNotAtMaturityHeight(t, ns, &kid)
+	assertNumChanOutputs(t, ns, kid.OriginChanPoint(), 1)
+
+	// Attempting to demote the output again should fail, as it no longer
+	// resides in the kindergarten bucket.
+	err = ns.KinderToPreschool(&kid)
+	if err != ErrKinderNotFound {
+		t.Fatalf("expected ErrKinderNotFound, got: %v", err)
+	}
+
+	// Finally, the output should be promoted once the commitment txn
+	// confirms again, this time at a later height.
+	kid.SetConfHeight(kid.ConfHeight() + 10)
+	err = ns.PreschoolToKinder(&kid)
+	if err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+	assertNumPreschools(t, ns, 0)
+	assertKndrAtMaturityHeight(t, ns, &kid)
+}
+
+// TestNurseryStoreSkipCorruptOutput asserts that a corrupt output record is
+// skipped when fetching preschool outputs, rather than failing to load the
+// remaining outputs.
+func TestNurseryStoreSkipCorruptOutput(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	for i := 2; i < 4; i++ {
+		if err := ns.Incubate(&kidOutputs[i], nil); err != nil {
+			t.Fatalf("unable to incubate output: %v", err)
+		}
+	}
+	assertNumPreschools(t, ns, 2)
+
+	// Overwrite the first output with a record that decodes, but has a
+	// zero amount.
+	corrupt := kidOutputs[2]
+	corrupt.amt = 0
+
+	var b bytes.Buffer
+	if err := corrupt.Encode(&b); err != nil {
+		t.Fatalf("unable to serialize kid output: %v", err)
+	}
+
+	err = ns.db.Update(func(tx *bolt.Tx) error {
+		chanBucket := ns.getChannelBucket(tx, corrupt.OriginChanPoint())
+		pfxOutputKey, err := prefixOutputKey(
+			psclPrefix, corrupt.OutPoint(),
+		)
+		if err != nil {
+			return err
+		}
+
+		return chanBucket.Put(pfxOutputKey, b.Bytes())
+	})
+	if err != nil {
+		t.Fatalf("unable to corrupt output: %v", err)
+	}
+
+	preschools, err := ns.FetchPreschools()
+	if err != nil {
+		t.Fatalf("unable to fetch preschools: %v", err)
+	}
+	if len(preschools) != 1 ||
+		*preschools[0].OutPoint() != *kidOutputs[3].OutPoint() {
+
+		t.Fatalf("expected only output %v, got %v",
+			kidOutputs[3].OutPoint(), spew.Sdump(preschools))
+	}
+}
+
+// TestNurseryStorePrune tests that pruning the nursery store removes empty
+// buckets and orphaned limbo balances, while leaving the outputs that are
+// still being incubated untouched.
+func TestNurseryStorePrune(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[3]
+	if err := ns.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate commitment output: %v", err)
+	}
+	if err := ns.PreschoolToKinder(&kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	// Leave behind an empty height-channel bucket at a later height, as
+	// well as a limbo balance for a channel the store is not tracking.
+	maturityHeight := kid.MaturityHeight()
+	emptyHeight := maturityHeight + 5
+	orphanChanPoint := &outPoints[1]
+	err = ns.db.Update(func(tx *bolt.Tx) error {
+		_, err := ns.createHeightChanBucket(
+			tx, emptyHeight, kid.OriginChanPoint(),
+		)
+		if err != nil {
+			return err
+		}
+
+		return ns.adjustLimboBalance(tx, orphanChanPoint, 1000)
+	})
+	if err != nil {
+		t.Fatalf("unable to create stale entries: %v", err)
+	}
+
+	heights, err := ns.HeightsBelowOrEqual(emptyHeight)
+	if err != nil {
+		t.Fatalf("unable to fetch heights: %v", err)
+	}
+	if len(heights) != 2 {
+		t.Fatalf("expected 2 heights before pruning, got %v",
+			heights)
+	}
+
+	if err := ns.Prune(); err != nil {
+		t.Fatalf("unable to prune nursery store: %v", err)
+	}
+
+	// Only the height of the incubating output should remain.
+	heights, err = ns.HeightsBelowOrEqual(emptyHeight)
+	if err != nil {
+		t.Fatalf("unable to fetch heights: %v", err)
+	}
+	if len(heights) != 1 || heights[0] != maturityHeight {
+		t.Fatalf("expected only height %d after pruning, got %v",
+			maturityHeight, heights)
+	}
+
+	assertKndrAtMaturityHeight(t, ns, &kid)
+	assertNumChanOutputs(t, ns, kid.OriginChanPoint(), 1)
+
+	balance, err := ns.ChanLimboBalance(kid.OriginChanPoint())
+	if err != nil {
+		t.Fatalf("unable to fetch limbo balance: %v", err)
+	}
+	if balance != kid.Amount() {
+		t.Fatalf("expected limbo balance %v, got %v", kid.Amount(),
+			balance)
+	}
+
+	err = ns.db.View(func(tx *bolt.Tx) error {
+		_, ok, err := ns.getLimboBalance(tx, orphanChanPoint)
+		if err != nil {
+			return err
+		}
+		if ok {
+			t.Fatalf("orphaned limbo balance was not removed")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unable to fetch limbo balance: %v", err)
+	}
+}
+
+// TestNurseryStoreCancelChannel verifies that canceling a channel removes all
+// of its outputs and height index entries, regardless of their state, unless
+// its kindergarten outputs are awaiting a finalized sweep txn.
+func TestNurseryStoreCancelChannel(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[0]
+	chanPoint := kid.OriginChanPoint()
+	maturityHeight := kid.MaturityHeight()
+
+	if err := ns.CancelChannel(chanPoint); err != ErrContractNotFound {
+		t.Fatalf("expected ErrContractNotFound, got: %v", err)
+	}
+
+	if err := ns.Incubate(&kid, babyOutputs); err != nil {
+		t.Fatalf("unable to incubate outputs: %v", err)
+	}
+	if err := ns.PreschoolToKinder(&kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	// Once the kindergarten output is finalized in a sweep, the channel
+	// can no longer be canceled.
+	err = ns.FinalizeKinder(maturityHeight, []*wire.MsgTx{timeoutTx}, nil)
+	if err != nil {
+		t.Fatalf("unable to finalize kndr: %v", err)
+	}
+	if err := ns.CancelChannel(chanPoint); err != ErrSweepFinalized {
+		t.Fatalf("expected ErrSweepFinalized, got: %v", err)
+	}
+	assertNumChanOutputs(t, ns, chanPoint, 1+len(babyOutputs))
+
+	// After the sweep graduates the kindergarten output, the remaining
+	// crib outputs can be canceled.
+	err = ns.GraduateKinder(maturityHeight, maturityHeight)
+	if err != nil {
+		t.Fatalf("unable to graduate kndr: %v", err)
+	}
+	if err := ns.CancelChannel(chanPoint); err != nil {
+		t.Fatalf("unable to cancel channel: %v", err)
+	}
+
+	assertNumChannels(t, ns, 0)
+	assertNumChanOutputs(t, ns, chanPoint, 0)
+	assertHeightIsPurged(t, ns, maturityHeight)
+	for i := range babyOutputs {
+		assertHeightIsPurged(t, ns, babyOutputs[i].expiry)
+	}
+}
+
+// TestNurseryStoreForceGraduateChannel verifies that force graduating a
+// channel moves each of its crib and preschool outputs to the graduated
+// state, removing its entries in the height index, after which the channel
+// can be removed.
+func TestNurseryStoreForceGraduateChannel(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[0]
+	baby := babyOutputs[0]
+	chanPoint := kid.OriginChanPoint()
+
+	err = ns.ForceGraduateChannel(chanPoint)
+	if err != ErrContractNotFound {
+		t.Fatalf("expected ErrContractNotFound, got: %v", err)
+	}
+
+	if err := ns.Incubate(&kid, []babyOutput{baby}); err != nil {
+		t.Fatalf("unable to incubate outputs: %v", err)
+	}
+	assertCribAtExpiryHeight(t, ns, &baby)
+	assertChannelMaturity(t, ns, chanPoint, false)
+
+	if err := ns.ForceGraduateChannel(chanPoint); err != nil {
+		t.Fatalf("unable to force graduate channel: %v", err)
+	}
+
+	assertNumPreschools(t, ns, 0)
+	assertNumChanOutputs(t, ns, chanPoint, 2)
+	assertHeightIsPurged(t, ns, baby.expiry)
+	assertChanLimboBalance(t, ns, chanPoint, 0)
+	assertChannelMaturity(t, ns, chanPoint, true)
+	assertCanRemoveChannel(t, ns, chanPoint, true)
+	assertNumChannels(t, ns, 0)
+}
+
+// assertNumChanOutputs checks that the channel bucket has the expected number
+// of outputs.
+func assertNumChanOutputs(t *testing.T, ns NurseryStore,
+	chanPoint *wire.OutPoint, expectedNum int) {
+
+	var count int
+	err := ns.ForChanOutputs(chanPoint, func([]byte, []byte) error {
+		count++
+		return nil
+	})
+
+	if count == 0 && err == ErrContractNotFound {
+		return
+	} else if err != nil {
+		t.Fatalf("unable to count num outputs for channel %v: %v",
+			chanPoint, err)
+	}
+
+	if count != expectedNum {
+		t.Fatalf("nursery store should have %d outputs, found %d",
+			expectedNum, count)
+	}
+}
+
+// assertLastFinalizedHeight checks that the nursery stores last finalized
+// height matches the expected height.
+func assertLastFinalizedHeight(t *testing.T, ns NurseryStore,
+	expected uint32) {
+
+	lfh, err := ns.LastFinalizedHeight()
+	if err != nil {
+		t.Fatalf("unable to get last finalized height: %v", err)
+	}
+
+	if lfh != expected {
+		t.Fatalf("expected last finalized height to be %d, got %d",
+			expected, lfh)
+	}
+}
+
+// assertLastGraduatedHeight checks that the nursery stores last purged height
+// matches the expected height.
+func assertLastGraduatedHeight(t *testing.T, ns NurseryStore, expected uint32) {
+	lgh, err := ns.LastGraduatedHeight()
+	if err != nil {
+		t.Fatalf("unable to get last graduated height: %v", err)
+	}
+
+	if lgh != expected {
+		t.Fatalf("expected last graduated height to be %d, got %d",
+			expected, lgh)
+	}
+}
+
+// assertChanLimboBalance queries the running limbo balance of a channel and
+// verifies that it matches the expected amount.
+func assertChanLimboBalance(t *testing.T, ns NurseryStore,
+	chanPoint *wire.OutPoint, expected btcutil.Amount) {
+
+	balance, err := ns.ChanLimboBalance(chanPoint)
+	if err != nil {
+		t.Fatalf("unable to get limbo balance: %v", err)
+	}
+
+	if balance != expected {
+		t.Fatalf("expected limbo balance to be %v, got %v", expected,
+			balance)
+	}
+}
+
+// assertNumPreschools loads all preschool outputs and verifies their count
+// matches the expected number.
+func assertNumPreschools(t *testing.T, ns NurseryStore, expected int) {
+	psclOutputs, err := ns.FetchPreschools()
+	if err != nil {
+		t.Fatalf("unable to retrieve preschool outputs: %v", err)
+	}
+
+	if len(psclOutputs) != expected {
+		t.Fatalf("expected number of pscl outputs to be %d, got %v",
+			expected, len(psclOutputs))
+	}
+}
+
+// assertNumChannels checks that the nursery has a given number of active
+// channels.
+func assertNumChannels(t *testing.T, ns NurseryStore, expected int) {
+	channels, err := ns.ListChannels()
+	if err != nil {
+		t.Fatalf("unable to fetch channels from nursery store: %v",
+			err)
+	}
+
+	if len(channels) != expected {
+		t.Fatalf("expected number of active channels to be %d, got %d",
+			expected, len(channels))
+	}
+}
+
+// assertHeightIsPurged checks that the finalized transaction, kindergarten, and
+// htlc outputs at a particular height are all nil.
+func assertHeightIsPurged(t *testing.T, ns NurseryStore,
+	height uint32) {
+
+	finalTxns, kndrOutputs, cribOutputs, err := ns.FetchClass(height)
+	if err != nil {
+		t.Fatalf("unable to retrieve class at height=%d: %v",
+			height, err)
+	}
+
+	if finalTxns != nil {
+		t.Fatalf("height=%d not purged, final txns should be nil", height)
+	}
+
+	if kndrOutputs != nil {
+		t.Fatalf("height=%d not purged, kndr outputs should be nil", height)
+	}
+
+	if cribOutputs != nil {
+		t.Fatalf("height=%d not purged, crib outputs should be nil", height)
+	}
+}
+
+// assertCribAtExpiryHeight loads the class at the given height, and verifies
+// that the given htlc output is one of the crib outputs.
+func assertCribAtExpiryHeight(t *testing.T, ns NurseryStore,
+	htlcOutput *babyOutput) {
+
+	expiryHeight := htlcOutput.expiry
+	_, _, cribOutputs, err := ns.FetchClass(expiryHeight)
+	if err != nil {
+		t.Fatalf("unable to retrieve class at height=%d: %v",
+			expiryHeight, err)
+	}
+
+	for _, crib := range cribOutputs {
+		if reflect.DeepEqual(&crib, htlcOutput) {
+			return
+		}
+	}
+
+	t.Fatalf("could not find crib output %v at height %d",
+		htlcOutput.OutPoint(), expiryHeight)
+}
+
+// assertCribNotAtExpiryHeight loads the class at the given height, and verifies
+// that the given htlc output is not one of the crib outputs.
 func assertCribNotAtExpiryHeight(t *testing.T, ns NurseryStore,
 	htlcOutput *babyOutput) {
 
-	expiryHeight := htlcOutput.expiry
-	_, _, cribOutputs, err := ns.FetchClass(expiryHeight)
+	expiryHeight := htlcOutput.expiry
+	_, _, cribOutputs, err := ns.FetchClass(expiryHeight)
+	if err != nil {
+		t.Fatalf("unable to retrieve class at height %d: %v",
+			expiryHeight, err)
+	}
+
+	for _, crib := range cribOutputs {
+		if reflect.DeepEqual(&crib, htlcOutput) {
+			t.Fatalf("found find crib output %v at height %d",
+				htlcOutput.OutPoint(), expiryHeight)
+		}
+	}
+}
+
+// assertFinalizedTxns loads the class at the given height and compares the
+// returned finalized txns to those in the class. It is safe to presented a nil
+// slice of expected transactions.
+func assertFinalizedTxns(t *testing.T, ns NurseryStore, height uint32,
+	exFinalTxns []*wire.MsgTx) {
+
+	finalTxns, _, _, err := ns.FetchClass(height)
+	if err != nil {
+		t.Fatalf("unable to fetch class at height=%d: %v", height,
+			err)
+	}
+
+	if !reflect.DeepEqual(finalTxns, exFinalTxns) {
+		t.Fatalf("expected finalized txns at height=%d "+
+			"to be %v, got %v", height, spew.Sdump(exFinalTxns),
+			spew.Sdump(finalTxns))
+	}
+}
+
+// assertKndrAtMaturityHeight loads the class at the provided height and
+// verifies that the provided kid output is one of the kindergarten outputs
+// returned.
+func assertKndrAtMaturityHeight(t *testing.T, ns NurseryStore,
+	kndrOutput *kidOutput) {
+
+	maturityHeight := kndrOutput.ConfHeight() +
+		kndrOutput.BlocksToMaturity()
+	_, kndrOutputs, _, err := ns.FetchClass(maturityHeight)
+	if err != nil {
+		t.Fatalf("unable to retrieve class at height %d: %v",
+			maturityHeight, err)
+	}
+
+	for _, kndr := range kndrOutputs {
+		if reflect.DeepEqual(&kndr, kndrOutput) {
+			return
+		}
+	}
+
+	t.Fatalf("could not find kndr output %v at height %d",
+		kndrOutput.OutPoint(), maturityHeight)
+}
+
+// assertKndrNotAtMaturityHeight loads the class at the provided height and
+// verifies that the provided kid output is not one of the kindergarten outputs
+// returned.
+func assertKndrNotAtMaturityHeight(t *testing.T, ns NurseryStore,
+	kndrOutput *kidOutput) {
+
+	maturityHeight := kndrOutput.ConfHeight() +
+		kndrOutput.BlocksToMaturity()
+
+	_, kndrOutputs, _, err := ns.FetchClass(maturityHeight)
+	if err != nil {
+		t.Fatalf("unable to retrieve class at height %d: %v",
+			maturityHeight, err)
+	}
+
+	for _, kndr := range kndrOutputs {
+		if reflect.DeepEqual(&kndr, kndrOutput) {
+			t.Fatalf("found find kndr output %v at height %d",
+				kndrOutput.OutPoint(), maturityHeight)
+		}
+	}
+}
+
+// assertChannelMaturity queries the nursery store for the maturity of the given
+// channel, failing if the result does not match the expectedMaturity.
+func assertChannelMaturity(t *testing.T, ns NurseryStore,
+	chanPoint *wire.OutPoint, expectedMaturity bool) {
+
+	isMature, err := ns.IsMatureChannel(chanPoint)
+	if err != nil {
+		t.Fatalf("unable to fetch channel maturity: %v", err)
+	}
+
+	if isMature != expectedMaturity {
+		t.Fatalf("expected channel maturity: %v, actual: %v",
+			expectedMaturity, isMature)
+	}
+}
+
+// assertCanRemoveChannel tries to remove a channel from the nursery store,
+// failing if the result does match expected canRemove.
+func assertCanRemoveChannel(t *testing.T, ns NurseryStore,
+	chanPoint *wire.OutPoint, canRemove bool) {
+
+	err := ns.RemoveChannel(chanPoint)
+	if canRemove && err != nil {
+		t.Fatalf("expected nil when removing active channel, got: %v",
+			err)
+	} else if !canRemove && err != ErrImmatureChannel {
+		t.Fatalf("expected ErrImmatureChannel when removing "+
+			"active channel: %v", err)
+	}
+}
+
+// mockEncoder is implemented by each of the outputs stored by the
+// mockNurseryStore.
+type mockEncoder interface {
+	Encode(w io.Writer) error
+}
+
+// mockStoreFault is an error injected into a method of the mockNurseryStore.
+type mockStoreFault struct {
+	// remaining is the number of calls to the method that will still
+	// fail, or negative if every call should fail.
+	remaining int
+
+	// err is the error returned by the failing calls.
+	err error
+}
+
+// mockNurseryStore is an in-memory NurseryStore, allowing the nursery to be
+// tested without a bolt db. Outputs are stored in their serialized form, under
+// the same prefixed keys used by the nurseryStore. An error can be injected
+// into any method via failNext, such that the nursery's handling of failed
+// writes can be tested deterministically.
+type mockNurseryStore struct {
+	mu sync.Mutex
+
+	// state holds the contents of the store. Each write is applied to a
+	// copy of the state, which only replaces it if the write succeeds,
+	// such that failed writes leave the store untouched.
+	state *mockStoreState
+
+	// faults holds the errors to be returned by each method, keyed by the
+	// method's name.
+	faults map[string]*mockStoreFault
+
+	// calls counts the number of times each method has been called, keyed
+	// by the method's name.
+	calls map[string]int
+}
+
+// A compile-time check to ensure mockNurseryStore implements NurseryStore.
+var _ NurseryStore = (*mockNurseryStore)(nil)
+
+// newMockNurseryStore returns an empty mockNurseryStore.
+func newMockNurseryStore() *mockNurseryStore {
+	return &mockNurseryStore{
+		state:  newMockStoreState(),
+		faults: make(map[string]*mockStoreFault),
+		calls:  make(map[string]int),
+	}
+}
+
+// failNext causes the next n calls to the named method to return the provided
+// error. If n is negative, every call to the method fails.
+func (m *mockNurseryStore) failNext(method string, n int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.faults[method] = &mockStoreFault{
+		remaining: n,
+		err:       err,
+	}
+}
+
+// numCalls returns the number of times the named method has been called,
+// including the calls that failed.
+func (m *mockNurseryStore) numCalls(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.calls[method]
+}
+
+// fault records a call to the named method, returning the error injected into
+// the method if the call should fail.
+//
+// NOTE: This method MUST be called while holding the store's mutex.
+func (m *mockNurseryStore) fault(method string) error {
+	m.calls[method]++
+
+	fault, ok := m.faults[method]
+	if !ok {
+		return nil
+	}
+
+	if fault.remaining > 0 {
+		fault.remaining--
+		if fault.remaining == 0 {
+			delete(m.faults, method)
+		}
+	}
+
+	return fault.err
+}
+
+// update applies the given write to a copy of the store's state, which then
+// replaces the store's state if the write succeeds. The injected error is
+// returned instead if the named method should fail.
+func (m *mockNurseryStore) update(method string,
+	f func(*mockStoreState) error) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.fault(method); err != nil {
+		return err
+	}
+
+	state := m.state.copy()
+	if err := f(state); err != nil {
+		return err
+	}
+	m.state = state
+
+	return nil
+}
+
+// view executes the given read against the store's state. The injected error
+// is returned instead if the named method should fail.
+func (m *mockNurseryStore) view(method string,
+	f func(*mockStoreState) error) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.fault(method); err != nil {
+		return err
+	}
+
+	return f(m.state)
+}
+
+// Update executes the provided closure against a store bound to a copy of the
+// state, which replaces the store's state if the closure returns nil. The
+// bound store shares the injected errors and call counts of its parent.
+//
+// NOTE: As with the nurseryStore, the closure must not use the parent store,
+// as its mutex is held until the closure returns.
+func (m *mockNurseryStore) Update(f func(NurseryStore) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.fault("Update"); err != nil {
+		return err
+	}
+
+	txStore := &mockNurseryStore{
+		state:  m.state.copy(),
+		faults: m.faults,
+		calls:  m.calls,
+	}
+	if err := f(txStore); err != nil {
+		return err
+	}
+	m.state = txStore.state
+
+	return nil
+}
+
+// Incubate stores the commitment output in the preschool bucket, and the htlc
+// outputs in the crib bucket.
+func (m *mockNurseryStore) Incubate(kid *kidOutput, babies []babyOutput) error {
+	var kids []*kidOutput
+	if kid != nil {
+		kids = append(kids, kid)
+	}
+
+	return m.update("Incubate", func(s *mockStoreState) error {
+		return s.incubate(kids, babies)
+	})
+}
+
+// IncubateBatch stores the commitment outputs in the preschool bucket, and the
+// htlc outputs in the crib bucket.
+func (m *mockNurseryStore) IncubateBatch(kids []*kidOutput,
+	babies []babyOutput) error {
+
+	return m.update("IncubateBatch", func(s *mockStoreState) error {
+		return s.incubate(kids, babies)
+	})
+}
+
+// CribToKinder moves the crib output to the kindergarten bucket, indexing it
+// at its maturity height.
+func (m *mockNurseryStore) CribToKinder(bby *babyOutput) error {
+	return m.update("CribToKinder", func(s *mockStoreState) error {
+		chanPoint := bby.OriginChanPoint()
+
+		cribKey, err := mockOutputKey(cribPrefix, bby.OutPoint())
+		if err != nil {
+			return err
+		}
+		s.deleteOutput(chanPoint, cribKey)
+		s.removeFromHeight(bby.expiry, chanPoint, cribKey)
+
+		kndrKey, err := mockOutputKey(kndrPrefix, bby.OutPoint())
+		if err != nil {
+			return err
+		}
+		err = s.putOutput(chanPoint, kndrKey, &bby.kidOutput)
+		if err != nil {
+			return err
+		}
+		s.addToHeight(bby.MaturityHeight(), chanPoint, kndrKey)
+
+		return nil
+	})
+}
+
+// ResolveCrib moves the crib output to the resolved bucket, deducting its value
+// from the channel's limbo balance.
+func (m *mockNurseryStore) ResolveCrib(bby *babyOutput) error {
+	return m.update("ResolveCrib", func(s *mockStoreState) error {
+		chanPoint := bby.OriginChanPoint()
+		outputs, ok := s.channels[*chanPoint]
+		if !ok {
+			return ErrContractNotFound
+		}
+
+		cribKey, err := mockOutputKey(cribPrefix, bby.OutPoint())
+		if err != nil {
+			return err
+		}
+
+		babyBytes, ok := outputs[cribKey]
+		if !ok {
+			return nil
+		}
+		delete(outputs, cribKey)
+		s.removeFromHeight(bby.expiry, chanPoint, cribKey)
+
+		rslvKey, err := mockOutputKey(rslvPrefix, bby.OutPoint())
+		if err != nil {
+			return err
+		}
+		outputs[rslvKey] = babyBytes
+
+		s.adjustLimboBalance(chanPoint, -int64(bby.Amount()))
+
+		return nil
+	})
+}
+
+// PreschoolToKinder moves the preschool output to the kindergarten bucket,
+// indexing it at its maturity height.
+func (m *mockNurseryStore) PreschoolToKinder(kid *kidOutput) error {
+	return m.update("PreschoolToKinder", func(s *mockStoreState) error {
+		chanPoint := kid.OriginChanPoint()
+
+		psclKey, err := mockOutputKey(psclPrefix, kid.OutPoint())
+		if err != nil {
+			return err
+		}
+		s.deleteOutput(chanPoint, psclKey)
+
+		kndrKey, err := mockOutputKey(kndrPrefix, kid.OutPoint())
+		if err != nil {
+			return err
+		}
+		if err := s.putOutput(chanPoint, kndrKey, kid); err != nil {
+			return err
+		}
+		s.addToHeight(kid.MaturityHeight(), chanPoint, kndrKey)
+
+		return nil
+	})
+}
+
+// KinderToPreschool moves the kindergarten output back to the preschool
+// bucket, clearing its confirmation height.
+func (m *mockNurseryStore) KinderToPreschool(kid *kidOutput) error {
+	return m.update("KinderToPreschool", func(s *mockStoreState) error {
+		chanPoint := kid.OriginChanPoint()
+		outputs, ok := s.channels[*chanPoint]
+		if !ok {
+			return ErrContractNotFound
+		}
+
+		kndrKey, err := mockOutputKey(kndrPrefix, kid.OutPoint())
+		if err != nil {
+			return err
+		}
+		if _, ok := outputs[kndrKey]; !ok {
+			return ErrKinderNotFound
+		}
+
+		s.removeFromHeight(kid.MaturityHeight(), chanPoint, kndrKey)
+		delete(outputs, kndrKey)
+
+		psclKid := *kid
+		psclKid.SetConfHeight(0)
+
+		return s.enterPreschool(&psclKid)
+	})
+}
+
+// RekeyPreschool replaces the preschool output stored under the old outpoint
+// with the provided kid output.
+func (m *mockNurseryStore) RekeyPreschool(oldOutPoint *wire.OutPoint,
+	kid *kidOutput) error {
+
+	return m.update("RekeyPreschool", func(s *mockStoreState) error {
+		chanPoint := kid.OriginChanPoint()
+		outputs, ok := s.channels[*chanPoint]
+		if !ok {
+			return fmt.Errorf("channel %v not found", chanPoint)
+		}
+
+		psclKey, err := mockOutputKey(psclPrefix, oldOutPoint)
+		if err != nil {
+			return err
+		}
+		if _, ok := outputs[psclKey]; !ok {
+			return fmt.Errorf("preschool output %v not found",
+				oldOutPoint)
+		}
+		delete(outputs, psclKey)
+
+		return s.enterPreschool(kid)
+	})
+}
+
+// GraduateKinder graduates every kindergarten output at the provided height,
+// removing the height's finalized sweep txns.
+func (m *mockNurseryStore) GraduateKinder(height, sweepHeight uint32) error {
+	return m.update("GraduateKinder", func(s *mockStoreState) error {
+		hght, ok := s.heights[height]
+		if !ok {
+			return nil
+		}
+
+		sweepTxids := make(map[wire.OutPoint]chainhash.Hash)
+		for _, finalTx := range hght.finalTxns {
+			txid := finalTx.TxHash()
+			for _, txIn := range finalTx.TxIn {
+				sweepTxids[txIn.PreviousOutPoint] = txid
+			}
+		}
+		hght.removeFinalizedTxns()
+
+		kids, err := s.heightKids(height)
+		if err != nil {
+			return err
+		}
+
+		for i := range kids {
+			var sweepTxid *chainhash.Hash
+			if txid, ok := sweepTxids[*kids[i].OutPoint()]; ok {
+				sweepTxid = &txid
+			}
+
+			err := s.graduateOutput(height, sweepHeight, &kids[i],
+				sweepTxid)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GraduateSweep graduates the kindergarten outputs at the provided height that
+// are spent by the sweep txn, and returns them. The height's finalized sweep
+// txns are removed once none of its kindergarten outputs remain.
+func (m *mockNurseryStore) GraduateSweep(height uint32, sweepTx *wire.MsgTx,
+	sweepHeight uint32) ([]kidOutput, error) {
+
+	var graduating []kidOutput
+	err := m.update("GraduateSweep", func(s *mockStoreState) error {
+		hght, ok := s.heights[height]
+		if !ok {
+			return nil
+		}
+
+		kids, err := s.heightKids(height)
+		if err != nil {
+			return err
+		}
+
+		for i := range kids {
+			if spendsOutpoint(sweepTx, kids[i].OutPoint()) {
+				graduating = append(graduating, kids[i])
+			}
+		}
+
+		if len(graduating) == len(kids) {
+			hght.removeFinalizedTxns()
+		}
+
+		sweepTxid := sweepTx.TxHash()
+		for i := range graduating {
+			err := s.graduateOutput(height, sweepHeight,
+				&graduating[i], &sweepTxid)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return graduating, nil
+}
+
+// UngraduateKinder restores the graduated outputs to the kindergarten bucket
+// at the provided height, along with the height's finalized sweep txns.
+func (m *mockNurseryStore) UngraduateKinder(height uint32, kids []kidOutput,
+	finalTxns []*wire.MsgTx) error {
+
+	return m.update("UngraduateKinder", func(s *mockStoreState) error {
+		for i := range kids {
+			kid := kids[i]
+			chanPoint := kid.OriginChanPoint()
+			outputs, ok := s.channels[*chanPoint]
+			if !ok {
+				return ErrContractNotFound
+			}
+
+			gradKey, err := mockOutputKey(gradPrefix, kid.OutPoint())
+			if err != nil {
+				return err
+			}
+			if _, ok := outputs[gradKey]; !ok {
+				continue
+			}
+			delete(outputs, gradKey)
+
+			kndrKey, err := mockOutputKey(kndrPrefix, kid.OutPoint())
+			if err != nil {
+				return err
+			}
+
+			kid.sweepConfHeight = 0
+			err = s.putOutput(chanPoint, kndrKey, &kid)
+			if err != nil {
+				return err
+			}
+			s.addToHeight(height, chanPoint, kndrKey)
+			s.adjustLimboBalance(chanPoint, int64(kid.Amount()))
+
+			for _, finalTx := range finalTxns {
+				if !spendsOutpoint(finalTx, kid.OutPoint()) {
+					continue
+				}
+
+				txid := finalTx.TxHash()
+				delete(s.sweepTxids[*chanPoint], txid)
+			}
+		}
+
+		if hght, ok := s.heights[height]; ok {
+			hght.finalTxns = append([]*wire.MsgTx(nil), finalTxns...)
+		}
+
+		return nil
+	})
+}
+
+// DeferKinder moves the kindergarten output's entry in the height index from
+// one height to another.
+func (m *mockNurseryStore) DeferKinder(kid *kidOutput, fromHeight,
+	toHeight uint32) error {
+
+	return m.update("DeferKinder", func(s *mockStoreState) error {
+		chanPoint := kid.OriginChanPoint()
+
+		kndrKey, err := mockOutputKey(kndrPrefix, kid.OutPoint())
+		if err != nil {
+			return err
+		}
+		s.removeFromHeight(fromHeight, chanPoint, kndrKey)
+		s.addToHeight(toHeight, chanPoint, kndrKey)
+
+		return nil
+	})
+}
+
+// RequeueCrib moves the crib output's entry in the height index from the
+// provided height to its expiry height.
+func (m *mockNurseryStore) RequeueCrib(baby *babyOutput,
+	fromHeight uint32) error {
+
+	return m.update("RequeueCrib", func(s *mockStoreState) error {
+		chanPoint := baby.OriginChanPoint()
+
+		cribKey, err := mockOutputKey(cribPrefix, baby.OutPoint())
+		if err != nil {
+			return err
+		}
+		s.removeFromHeight(fromHeight, chanPoint, cribKey)
+		s.addToHeight(baby.expiry, chanPoint, cribKey)
+
+		return nil
+	})
+}
+
+// RekeyCrib replaces the crib output stored under the old outpoint with the
+// given baby output.
+func (m *mockNurseryStore) RekeyCrib(oldOutPoint *wire.OutPoint,
+	baby *babyOutput) error {
+
+	return m.update("RekeyCrib", func(s *mockStoreState) error {
+		chanPoint := baby.OriginChanPoint()
+		outputs, ok := s.channels[*chanPoint]
+		if !ok {
+			return fmt.Errorf("channel %v not found", chanPoint)
+		}
+
+		oldKey, err := mockOutputKey(cribPrefix, oldOutPoint)
+		if err != nil {
+			return err
+		}
+		if _, ok := outputs[oldKey]; !ok {
+			return fmt.Errorf("crib output %v not found",
+				oldOutPoint)
+		}
+
+		cribKey, err := mockOutputKey(cribPrefix, baby.OutPoint())
+		if err != nil {
+			return err
+		}
+		if err := s.putOutput(chanPoint, cribKey, baby); err != nil {
+			return err
+		}
+		s.addToHeight(baby.expiry, chanPoint, cribKey)
+
+		delete(outputs, oldKey)
+		s.removeFromHeight(baby.expiry, chanPoint, oldKey)
+
+		return nil
+	})
+}
+
+// FetchPreschools returns all outputs in the preschool bucket, skipping any
+// that are corrupt.
+func (m *mockNurseryStore) FetchPreschools() ([]kidOutput, error) {
+	var kids []kidOutput
+	err := m.view("FetchPreschools", func(s *mockStoreState) error {
+		for _, chanPoint := range s.chanPoints() {
+			outputs := s.channels[chanPoint]
+			for _, key := range sortedKeys(outputs) {
+				if !strings.HasPrefix(key, string(psclPrefix)) {
+					continue
+				}
+
+				var kid kidOutput
+				err := kid.Decode(bytes.NewReader(outputs[key]))
+				if err == nil {
+					err = kid.validate()
+				}
+				if err != nil {
+					continue
+				}
+
+				kids = append(kids, kid)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return kids, nil
+}
+
+// FetchClass returns the finalized sweep txns, kindergarten outputs, and crib
+// outputs at the provided height, skipping any outputs that are corrupt.
+func (m *mockNurseryStore) FetchClass(
+	height uint32) ([]*wire.MsgTx, []kidOutput, []babyOutput, error) {
+
+	var (
+		finalTxns []*wire.MsgTx
+		kids      []kidOutput
+		babies    []babyOutput
+	)
+	err := m.view("FetchClass", func(s *mockStoreState) error {
+		if hght, ok := s.heights[height]; ok {
+			finalTxns = append(finalTxns, hght.finalTxns...)
+		}
+
+		for _, v := range s.heightOutputs(height, cribPrefix) {
+			var baby babyOutput
+			err := baby.Decode(bytes.NewReader(v))
+			if err == nil {
+				err = baby.validate()
+			}
+			if err != nil {
+				continue
+			}
+
+			babies = append(babies, baby)
+		}
+
+		for _, v := range s.heightOutputs(height, kndrPrefix) {
+			var kid kidOutput
+			err := kid.Decode(bytes.NewReader(v))
+			if err == nil {
+				err = kid.validate()
+			}
+			if err != nil {
+				continue
+			}
+
+			kids = append(kids, kid)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return finalTxns, kids, babies, nil
+}
+
+// FinalizeKinder records the finalized sweep txns and their fee rates at the
+// provided height, which becomes the last finalized height.
+func (m *mockNurseryStore) FinalizeKinder(height uint32,
+	finalTxns []*wire.MsgTx, feeRates []btcutil.Amount) error {
+
+	if feeRates != nil && len(feeRates) != len(finalTxns) {
+		return fmt.Errorf("expected %d fee rates for finalized txns, "+
+			"got %d", len(finalTxns), len(feeRates))
+	}
+
+	return m.update("FinalizeKinder", func(s *mockStoreState) error {
+		s.lastFinalizedHeight = height
+
+		hght, ok := s.heights[height]
+		if !ok || len(finalTxns) == 0 {
+			return nil
+		}
+
+		hght.finalTxns = append([]*wire.MsgTx(nil), finalTxns...)
+		if feeRates != nil {
+			hght.feeRates = append([]btcutil.Amount(nil),
+				feeRates...)
+		}
+
+		return nil
+	})
+}
+
+// FinalizedSweepInfo summarizes each sweep txn finalized at the given height.
+func (m *mockNurseryStore) FinalizedSweepInfo(height uint32) ([]SweepInfo,
+	error) {
+
+	var sweepInfos []SweepInfo
+	err := m.view("FinalizedSweepInfo", func(s *mockStoreState) error {
+		hght, ok := s.heights[height]
+		if !ok {
+			return nil
+		}
+
+		for i, finalTx := range hght.finalTxns {
+			sweepInfo := SweepInfo{
+				Txid: finalTx.TxHash(),
+			}
+			for _, txOut := range finalTx.TxOut {
+				sweepInfo.SweptAmount += btcutil.Amount(
+					txOut.Value,
+				)
+			}
+			if i < len(hght.feeRates) {
+				sweepInfo.FeeRate = hght.feeRates[i]
+			}
+
+			sweepInfos = append(sweepInfos, sweepInfo)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sweepInfos, nil
+}
+
+// FetchFinalizedTxns returns the sweep txns finalized at the given height.
+func (m *mockNurseryStore) FetchFinalizedTxns(height uint32) ([]*wire.MsgTx,
+	error) {
+
+	var finalTxns []*wire.MsgTx
+	err := m.view("FetchFinalizedTxns", func(s *mockStoreState) error {
+		if hght, ok := s.heights[height]; ok {
+			finalTxns = append(finalTxns, hght.finalTxns...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return finalTxns, nil
+}
+
+// RecordSweepBroadcast increments the broadcast count of the sweep txns at the
+// given height, returning the updated count.
+func (m *mockNurseryStore) RecordSweepBroadcast(height uint32) (uint32,
+	error) {
+
+	var numBroadcasts uint32
+	err := m.update("RecordSweepBroadcast", func(s *mockStoreState) error {
+		hght, ok := s.heights[height]
+		if !ok {
+			return fmt.Errorf("no height bucket at height=%d",
+				height)
+		}
+
+		hght.broadcasts++
+		numBroadcasts = hght.broadcasts
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return numBroadcasts, nil
+}
+
+// SweepBroadcasts returns the broadcast count of the sweep txns at the given
+// height.
+func (m *mockNurseryStore) SweepBroadcasts(height uint32) (uint32, error) {
+	var numBroadcasts uint32
+	err := m.view("SweepBroadcasts", func(s *mockStoreState) error {
+		if hght, ok := s.heights[height]; ok {
+			numBroadcasts = hght.broadcasts
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return numBroadcasts, nil
+}
+
+// LastFinalizedHeight returns the last finalized height.
+func (m *mockNurseryStore) LastFinalizedHeight() (uint32, error) {
+	var height uint32
+	err := m.view("LastFinalizedHeight", func(s *mockStoreState) error {
+		height = s.lastFinalizedHeight
+		return nil
+	})
+
+	return height, err
+}
+
+// GraduateHeight records the provided height as the last graduated height.
+func (m *mockNurseryStore) GraduateHeight(height uint32) error {
+	return m.update("GraduateHeight", func(s *mockStoreState) error {
+		s.lastGraduatedHeight = height
+		return nil
+	})
+}
+
+// LastGraduatedHeight returns the last graduated height.
+func (m *mockNurseryStore) LastGraduatedHeight() (uint32, error) {
+	var height uint32
+	err := m.view("LastGraduatedHeight", func(s *mockStoreState) error {
+		height = s.lastGraduatedHeight
+		return nil
+	})
+
+	return height, err
+}
+
+// GraduateBlock records the provided height as the last graduated height,
+// along with the hash of its block.
+func (m *mockNurseryStore) GraduateBlock(height uint32,
+	hash *chainhash.Hash) error {
+
+	return m.update("GraduateBlock", func(s *mockStoreState) error {
+		hashCopy := *hash
+		s.lastGraduatedHeight = height
+		s.lastGraduatedHash = &hashCopy
+		s.lastGraduatedHashHeight = height
+
+		return nil
+	})
+}
+
+// LastGraduatedBlock returns the last graduated height, along with the hash of
+// its block if it was recorded by GraduateBlock.
+func (m *mockNurseryStore) LastGraduatedBlock() (uint32, *chainhash.Hash,
+	error) {
+
+	var (
+		height uint32
+		hash   *chainhash.Hash
+	)
+	err := m.view("LastGraduatedBlock", func(s *mockStoreState) error {
+		height = s.lastGraduatedHeight
+		if s.lastGraduatedHash != nil &&
+			s.lastGraduatedHashHeight == height {
+
+			hashCopy := *s.lastGraduatedHash
+			hash = &hashCopy
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return height, hash, nil
+}
+
+// HeightsBelowOrEqual returns the heights in the height index at or below the
+// provided upper bound, in ascending order.
+func (m *mockNurseryStore) HeightsBelowOrEqual(height uint32) ([]uint32,
+	error) {
+
+	var heights []uint32
+	err := m.view("HeightsBelowOrEqual", func(s *mockStoreState) error {
+		for h := range s.heights {
+			if h <= height {
+				heights = append(heights, h)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(heights, func(i, j int) bool {
+		return heights[i] < heights[j]
+	})
+
+	return heights, nil
+}
+
+// ForChanOutputs invokes the callback with the prefixed key and serialized
+// value of each of the channel's outputs.
+func (m *mockNurseryStore) ForChanOutputs(chanPoint *wire.OutPoint,
+	callback func([]byte, []byte) error) error {
+
+	return m.view("ForChanOutputs", func(s *mockStoreState) error {
+		outputs, ok := s.channels[*chanPoint]
+		if !ok {
+			return ErrContractNotFound
+		}
+
+		for _, key := range sortedKeys(outputs) {
+			err := callback([]byte(key), outputs[key])
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ListChannels returns the channel points of all tracked channels.
+func (m *mockNurseryStore) ListChannels() ([]wire.OutPoint, error) {
+	var chanPoints []wire.OutPoint
+	err := m.view("ListChannels", func(s *mockStoreState) error {
+		chanPoints = s.chanPoints()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chanPoints, nil
+}
+
+// ChannelExists returns true if the channel is being tracked.
+func (m *mockNurseryStore) ChannelExists(chanPoint *wire.OutPoint) (bool,
+	error) {
+
+	var exists bool
+	err := m.view("ChannelExists", func(s *mockStoreState) error {
+		_, exists = s.channels[*chanPoint]
+		return nil
+	})
+
+	return exists, err
+}
+
+// IsMatureChannel returns true if each of the channel's outputs has either
+// graduated or been resolved.
+func (m *mockNurseryStore) IsMatureChannel(chanPoint *wire.OutPoint) (bool,
+	error) {
+
+	mature := true
+	err := m.view("IsMatureChannel", func(s *mockStoreState) error {
+		outputs, ok := s.channels[*chanPoint]
+		if !ok {
+			return ErrContractNotFound
+		}
+
+		for key := range outputs {
+			if !strings.HasPrefix(key, string(gradPrefix)) &&
+				!strings.HasPrefix(key, string(rslvPrefix)) {
+
+				mature = false
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return mature, nil
+}
+
+// RemoveChannel removes the channel along with its graduated outputs.
+func (m *mockNurseryStore) RemoveChannel(chanPoint *wire.OutPoint) error {
+	return m.update("RemoveChannel", func(s *mockStoreState) error {
+		return s.removeChannel(chanPoint, false)
+	})
+}
+
+// ArchiveChannel removes the channel, retaining its graduated outputs in the
+// graduation archive.
+func (m *mockNurseryStore) ArchiveChannel(chanPoint *wire.OutPoint) error {
+	return m.update("ArchiveChannel", func(s *mockStoreState) error {
+		return s.removeChannel(chanPoint, true)
+	})
+}
+
+// CancelChannel removes the channel along with all of its outputs, regardless
+// of their state.
+func (m *mockNurseryStore) CancelChannel(chanPoint *wire.OutPoint) error {
+	return m.update("CancelChannel", func(s *mockStoreState) error {
+		if _, ok := s.channels[*chanPoint]; !ok {
+			return ErrContractNotFound
+		}
+
+		if err := s.cancelChannelHeights(chanPoint); err != nil {
+			return err
+		}
+		s.removeChannelEntries(chanPoint)
+
+		return nil
+	})
+}
+
+// ForceGraduateChannel moves each of the channel's crib, preschool and
+// kindergarten outputs to the graduated state.
+func (m *mockNurseryStore) ForceGraduateChannel(
+	chanPoint *wire.OutPoint) error {
+
+	return m.update("ForceGraduateChannel", func(s *mockStoreState) error {
+		outputs, ok := s.channels[*chanPoint]
+		if !ok {
+			return ErrContractNotFound
+		}
+
+		if err := s.cancelChannelHeights(chanPoint); err != nil {
+			return err
+		}
+
+		for _, key := range sortedKeys(outputs) {
+			var kid kidOutput
+			v := bytes.NewReader(outputs[key])
+			switch {
+			case strings.HasPrefix(key, string(cribPrefix)):
+				var baby babyOutput
+				if err := baby.Decode(v); err != nil {
+					return err
+				}
+				kid = baby.kidOutput
+
+			case strings.HasPrefix(key, string(psclPrefix)),
+				strings.HasPrefix(key, string(kndrPrefix)):
+
+				if err := kid.Decode(v); err != nil {
+					return err
+				}
+
+			default:
+				continue
+			}
+
+			delete(outputs, key)
+			s.adjustLimboBalance(chanPoint, -int64(kid.Amount()))
+
+			gradKey, err := mockOutputKey(gradPrefix, kid.OutPoint())
+			if err != nil {
+				return err
+			}
+			err = s.putOutput(chanPoint, gradKey, &kid)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// FetchArchivedGraduations returns the channel's archived graduated outputs.
+func (m *mockNurseryStore) FetchArchivedGraduations(
+	chanPoint *wire.OutPoint) ([]kidOutput, error) {
+
+	var kids []kidOutput
+	err := m.view("FetchArchivedGraduations", func(s *mockStoreState) error {
+		kids = append(kids, s.archive[*chanPoint]...)
+		return nil
+	})
 	if err != nil {
-		t.Fatalf("unable to retrieve class at height %d: %v",
-			expiryHeight, err)
+		return nil, err
 	}
 
-	for _, crib := range cribOutputs {
-		if reflect.DeepEqual(&crib, htlcOutput) {
-			t.Fatalf("found find crib output %v at height %d",
-				htlcOutput.OutPoint(), expiryHeight)
+	return kids, nil
+}
+
+// RecordDroppedOutputs records the channel's abandoned outputs, replacing any
+// previously recorded output with the same outpoint.
+func (m *mockNurseryStore) RecordDroppedOutputs(chanPoint *wire.OutPoint,
+	outputs []droppedOutput) error {
+
+	return m.update("RecordDroppedOutputs", func(s *mockStoreState) error {
+		dropped := append([]droppedOutput(nil), s.dropped[*chanPoint]...)
+
+	nextOutput:
+		for _, output := range outputs {
+			for i := range dropped {
+				if dropped[i].outpoint == output.outpoint {
+					dropped[i] = output
+					continue nextOutput
+				}
+			}
+
+			dropped = append(dropped, output)
 		}
+		s.dropped[*chanPoint] = dropped
+
+		return nil
+	})
+}
+
+// FetchDroppedOutputs returns the channel's abandoned outputs.
+func (m *mockNurseryStore) FetchDroppedOutputs(
+	chanPoint *wire.OutPoint) ([]droppedOutput, error) {
+
+	var outputs []droppedOutput
+	err := m.view("FetchDroppedOutputs", func(s *mockStoreState) error {
+		outputs = append(outputs, s.dropped[*chanPoint]...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	return outputs, nil
 }
 
-// assertFinalizedTxn loads the class at the given height and compares the
-// returned finalized txn to that in the class. It is safe to presented a nil
-// expected transaction.
-func assertFinalizedTxn(t *testing.T, ns NurseryStore, height uint32,
-	exFinalTx *wire.MsgTx) {
+// ChanLimboBalance returns the channel's limbo balance.
+func (m *mockNurseryStore) ChanLimboBalance(
+	chanPoint *wire.OutPoint) (btcutil.Amount, error) {
 
-	finalTx, _, _, err := ns.FetchClass(height)
+	var balance btcutil.Amount
+	err := m.view("ChanLimboBalance", func(s *mockStoreState) error {
+		balance = s.limboBalances[*chanPoint]
+		return nil
+	})
 	if err != nil {
-		t.Fatalf("unable to fetch class at height=%d: %v", height,
-			err)
+		return 0, err
 	}
 
-	if !reflect.DeepEqual(finalTx, exFinalTx) {
-		t.Fatalf("expected finalized txn at height=%d "+
-			"to be %v, got %v", height, finalTx.TxHash(),
-			exFinalTx.TxHash())
+	return balance, nil
+}
+
+// ChanSweepTxids returns the txids of the sweeps of the channel's outputs.
+func (m *mockNurseryStore) ChanSweepTxids(
+	chanPoint *wire.OutPoint) ([]chainhash.Hash, error) {
+
+	var txids []chainhash.Hash
+	err := m.view("ChanSweepTxids", func(s *mockStoreState) error {
+		for txid := range s.sweepTxids[*chanPoint] {
+			txids = append(txids, txid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	sort.Slice(txids, func(i, j int) bool {
+		return bytes.Compare(txids[i][:], txids[j][:]) < 0
+	})
+
+	return txids, nil
 }
 
-// assertKndrAtMaturityHeight loads the class at the provided height and
-// verifies that the provided kid output is one of the kindergarten outputs
-// returned.
-func assertKndrAtMaturityHeight(t *testing.T, ns NurseryStore,
-	kndrOutput *kidOutput) {
+// RecordChannelCloseTx records the txid of the channel's force close txn.
+func (m *mockNurseryStore) RecordChannelCloseTx(chanPoint *wire.OutPoint,
+	txid *chainhash.Hash) error {
 
-	maturityHeight := kndrOutput.ConfHeight() +
-		kndrOutput.BlocksToMaturity()
-	_, kndrOutputs, _, err := ns.FetchClass(maturityHeight)
+	return m.update("RecordChannelCloseTx", func(s *mockStoreState) error {
+		s.closeTxids[*chanPoint] = *txid
+		return nil
+	})
+}
+
+// ChannelCloseTx returns the txid of the channel's force close txn, or nil if
+// none was recorded.
+func (m *mockNurseryStore) ChannelCloseTx(
+	chanPoint *wire.OutPoint) (*chainhash.Hash, error) {
+
+	var closeTxid *chainhash.Hash
+	err := m.view("ChannelCloseTx", func(s *mockStoreState) error {
+		if txid, ok := s.closeTxids[*chanPoint]; ok {
+			closeTxid = &txid
+		}
+		return nil
+	})
 	if err != nil {
-		t.Fatalf("unable to retrieve class at height %d: %v",
-			maturityHeight, err)
+		return nil, err
 	}
 
-	for _, kndr := range kndrOutputs {
-		if reflect.DeepEqual(&kndr, kndrOutput) {
-			return
+	return closeTxid, nil
+}
+
+// Prune removes empty heights from the height index, along with the limbo
+// balances of channels that are no longer tracked.
+func (m *mockNurseryStore) Prune() error {
+	return m.update("Prune", func(s *mockStoreState) error {
+		for height, hght := range s.heights {
+			if len(hght.outputs) == 0 && len(hght.finalTxns) == 0 &&
+				hght.broadcasts == 0 {
+
+				delete(s.heights, height)
+			}
+		}
+
+		for chanPoint := range s.limboBalances {
+			if _, ok := s.channels[chanPoint]; !ok {
+				delete(s.limboBalances, chanPoint)
+			}
+		}
+
+		return nil
+	})
+}
+
+// mockHeight holds the contents of a height in the mockNurseryStore's height
+// index.
+type mockHeight struct {
+	// outputs holds the prefixed keys of the outputs at this height, by
+	// channel point.
+	outputs map[wire.OutPoint]map[string]struct{}
+
+	// finalTxns are the sweep txns finalized at this height, along with
+	// their fee rates.
+	finalTxns []*wire.MsgTx
+	feeRates  []btcutil.Amount
+
+	// broadcasts is the number of times the finalized txns have been
+	// broadcast.
+	broadcasts uint32
+}
+
+// removeFinalizedTxns removes the finalized txns, their fee rates, and their
+// broadcast count from the height.
+func (h *mockHeight) removeFinalizedTxns() {
+	h.finalTxns = nil
+	h.feeRates = nil
+	h.broadcasts = 0
+}
+
+// mockStoreState holds the contents of a mockNurseryStore.
+type mockStoreState struct {
+	// channels holds the serialized outputs of each channel, keyed by
+	// their prefixed outpoints.
+	channels map[wire.OutPoint]map[string][]byte
+
+	// heights is the height index, holding the outputs to be processed at
+	// each height.
+	heights map[uint32]*mockHeight
+
+	limboBalances map[wire.OutPoint]btcutil.Amount
+	sweepTxids    map[wire.OutPoint]map[chainhash.Hash]struct{}
+	closeTxids    map[wire.OutPoint]chainhash.Hash
+	archive       map[wire.OutPoint][]kidOutput
+	dropped       map[wire.OutPoint][]droppedOutput
+
+	lastFinalizedHeight uint32
+	lastGraduatedHeight uint32
+
+	// lastGraduatedHash is the block hash recorded by GraduateBlock, which
+	// is only valid while lastGraduatedHashHeight matches the last
+	// graduated height.
+	lastGraduatedHash       *chainhash.Hash
+	lastGraduatedHashHeight uint32
+}
+
+// newMockStoreState returns an empty mockStoreState.
+func newMockStoreState() *mockStoreState {
+	return &mockStoreState{
+		channels:      make(map[wire.OutPoint]map[string][]byte),
+		heights:       make(map[uint32]*mockHeight),
+		limboBalances: make(map[wire.OutPoint]btcutil.Amount),
+		sweepTxids: make(
+			map[wire.OutPoint]map[chainhash.Hash]struct{},
+		),
+		closeTxids: make(map[wire.OutPoint]chainhash.Hash),
+		archive:    make(map[wire.OutPoint][]kidOutput),
+		dropped:    make(map[wire.OutPoint][]droppedOutput),
+	}
+}
+
+// copy returns a deep copy of the state. The serialized outputs and finalized
+// txns are shared, as they are never modified in place.
+func (s *mockStoreState) copy() *mockStoreState {
+	c := newMockStoreState()
+
+	for chanPoint, outputs := range s.channels {
+		c.channels[chanPoint] = make(map[string][]byte, len(outputs))
+		for key, v := range outputs {
+			c.channels[chanPoint][key] = v
 		}
 	}
 
-	t.Fatalf("could not find kndr output %v at height %d",
-		kndrOutput.OutPoint(), maturityHeight)
+	for height, hght := range s.heights {
+		hghtCopy := &mockHeight{
+			outputs: make(map[wire.OutPoint]map[string]struct{}),
+			finalTxns: append(
+				[]*wire.MsgTx(nil), hght.finalTxns...,
+			),
+			feeRates: append(
+				[]btcutil.Amount(nil), hght.feeRates...,
+			),
+			broadcasts: hght.broadcasts,
+		}
+		for chanPoint, keys := range hght.outputs {
+			keysCopy := make(map[string]struct{}, len(keys))
+			for key := range keys {
+				keysCopy[key] = struct{}{}
+			}
+			hghtCopy.outputs[chanPoint] = keysCopy
+		}
+		c.heights[height] = hghtCopy
+	}
+
+	for chanPoint, balance := range s.limboBalances {
+		c.limboBalances[chanPoint] = balance
+	}
+	for chanPoint, txids := range s.sweepTxids {
+		txidsCopy := make(map[chainhash.Hash]struct{}, len(txids))
+		for txid := range txids {
+			txidsCopy[txid] = struct{}{}
+		}
+		c.sweepTxids[chanPoint] = txidsCopy
+	}
+	for chanPoint, txid := range s.closeTxids {
+		c.closeTxids[chanPoint] = txid
+	}
+	for chanPoint, kids := range s.archive {
+		c.archive[chanPoint] = append([]kidOutput(nil), kids...)
+	}
+	for chanPoint, dropped := range s.dropped {
+		c.dropped[chanPoint] = append([]droppedOutput(nil), dropped...)
+	}
+
+	c.lastFinalizedHeight = s.lastFinalizedHeight
+	c.lastGraduatedHeight = s.lastGraduatedHeight
+	c.lastGraduatedHash = s.lastGraduatedHash
+	c.lastGraduatedHashHeight = s.lastGraduatedHashHeight
+
+	return c
 }
 
-// assertKndrNotAtMaturityHeight loads the class at the provided height and
-// verifies that the provided kid output is not one of the kindergarten outputs
-// returned.
-func assertKndrNotAtMaturityHeight(t *testing.T, ns NurseryStore,
-	kndrOutput *kidOutput) {
+// incubate stores the commitment outputs in the preschool bucket, and the
+// htlc outputs in the crib bucket, adding their values to the limbo balances
+// of their channels.
+func (s *mockStoreState) incubate(kids []*kidOutput,
+	babies []babyOutput) error {
 
-	maturityHeight := kndrOutput.ConfHeight() +
-		kndrOutput.BlocksToMaturity()
+	for _, kid := range kids {
+		if err := s.enterPreschool(kid); err != nil {
+			return err
+		}
+		s.adjustLimboBalance(kid.OriginChanPoint(), int64(kid.Amount()))
+	}
 
-	_, kndrOutputs, _, err := ns.FetchClass(maturityHeight)
+	for i := range babies {
+		baby := &babies[i]
+		chanPoint := baby.OriginChanPoint()
+
+		cribKey, err := mockOutputKey(cribPrefix, baby.OutPoint())
+		if err != nil {
+			return err
+		}
+		if err := s.putOutput(chanPoint, cribKey, baby); err != nil {
+			return err
+		}
+		s.addToHeight(baby.expiry, chanPoint, cribKey)
+		s.adjustLimboBalance(chanPoint, int64(baby.Amount()))
+	}
+
+	return nil
+}
+
+// enterPreschool stores the kid output in the preschool bucket.
+func (s *mockStoreState) enterPreschool(kid *kidOutput) error {
+	psclKey, err := mockOutputKey(psclPrefix, kid.OutPoint())
 	if err != nil {
-		t.Fatalf("unable to retrieve class at height %d: %v",
-			maturityHeight, err)
+		return err
 	}
 
-	for _, kndr := range kndrOutputs {
-		if reflect.DeepEqual(&kndr, kndrOutput) {
-			t.Fatalf("found find kndr output %v at height %d",
-				kndrOutput.OutPoint(), maturityHeight)
+	return s.putOutput(kid.OriginChanPoint(), psclKey, kid)
+}
+
+// graduateOutput moves the kindergarten output at the given height to the
+// graduated state, recording the height at which its sweep confirmed and, if
+// known, the txid of the sweep.
+func (s *mockStoreState) graduateOutput(height, sweepHeight uint32,
+	kid *kidOutput, sweepTxid *chainhash.Hash) error {
+
+	chanPoint := kid.OriginChanPoint()
+
+	kndrKey, err := mockOutputKey(kndrPrefix, kid.OutPoint())
+	if err != nil {
+		return err
+	}
+	s.removeFromHeight(height, chanPoint, kndrKey)
+
+	outputs, ok := s.channels[*chanPoint]
+	if !ok {
+		return ErrContractNotFound
+	}
+	delete(outputs, kndrKey)
+
+	s.adjustLimboBalance(chanPoint, -int64(kid.Amount()))
+
+	if sweepTxid != nil {
+		txids, ok := s.sweepTxids[*chanPoint]
+		if !ok {
+			txids = make(map[chainhash.Hash]struct{})
+			s.sweepTxids[*chanPoint] = txids
 		}
+		txids[*sweepTxid] = struct{}{}
 	}
-}
 
-// assertChannelMaturity queries the nursery store for the maturity of the given
-// channel, failing if the result does not match the expectedMaturity.
-func assertChannelMaturity(t *testing.T, ns NurseryStore,
-	chanPoint *wire.OutPoint, expectedMaturity bool) {
+	gradKid := *kid
+	gradKid.sweepConfHeight = sweepHeight
 
-	isMature, err := ns.IsMatureChannel(chanPoint)
+	gradKey, err := mockOutputKey(gradPrefix, kid.OutPoint())
 	if err != nil {
-		t.Fatalf("unable to fetch channel maturity: %v", err)
+		return err
 	}
 
-	if isMature != expectedMaturity {
-		t.Fatalf("expected channel maturity: %v, actual: %v",
-			expectedMaturity, isMature)
+	return s.putOutput(chanPoint, gradKey, &gradKid)
+}
+
+// removeChannel removes the channel if all of its outputs have graduated or
+// been resolved, optionally archiving its graduated outputs.
+func (s *mockStoreState) removeChannel(chanPoint *wire.OutPoint,
+	archive bool) error {
+
+	outputs, ok := s.channels[*chanPoint]
+	if !ok {
+		return nil
+	}
+
+	var archived []kidOutput
+	for _, key := range sortedKeys(outputs) {
+		switch {
+		case strings.HasPrefix(key, string(rslvPrefix)):
+			continue
+
+		case !strings.HasPrefix(key, string(gradPrefix)):
+			return ErrImmatureChannel
+		}
+
+		var kid kidOutput
+		if err := kid.Decode(bytes.NewReader(outputs[key])); err != nil {
+			return err
+		}
+		archived = append(archived, kid)
+
+		// As with the nurseryStore, the channel is removed from the
+		// height at which each graduated output matured.
+		if hght, ok := s.heights[kid.MaturityHeight()]; ok {
+			delete(hght.outputs, *chanPoint)
+		}
 	}
+
+	if archive {
+		s.archive[*chanPoint] = append(
+			s.archive[*chanPoint], archived...,
+		)
+	}
+	s.removeChannelEntries(chanPoint)
+
+	return nil
 }
 
-// assertCanRemoveChannel tries to remove a channel from the nursery store,
-// failing if the result does match expected canRemove.
-func assertCanRemoveChannel(t *testing.T, ns NurseryStore,
-	chanPoint *wire.OutPoint, canRemove bool) {
+// removeChannelEntries removes the channel's outputs, along with its limbo
+// balance, sweep txids and close txid.
+func (s *mockStoreState) removeChannelEntries(chanPoint *wire.OutPoint) {
+	delete(s.channels, *chanPoint)
+	delete(s.limboBalances, *chanPoint)
+	delete(s.sweepTxids, *chanPoint)
+	delete(s.closeTxids, *chanPoint)
+}
 
-	err := ns.RemoveChannel(chanPoint)
-	if canRemove && err != nil {
-		t.Fatalf("expected nil when removing active channel, got: %v",
-			err)
-	} else if !canRemove && err != ErrImmatureChannel {
-		t.Fatalf("expected ErrImmatureChannel when removing "+
-			"active channel: %v", err)
+// cancelChannelHeights removes the channel from each height at which it has
+// outputs. ErrSweepFinalized is returned if any of the channel's kindergarten
+// outputs are spent by a height's finalized sweep txns.
+func (s *mockStoreState) cancelChannelHeights(chanPoint *wire.OutPoint) error {
+	for height, hght := range s.heights {
+		keys, ok := hght.outputs[*chanPoint]
+		if !ok {
+			continue
+		}
+
+		if len(hght.finalTxns) > 0 {
+			for key := range keys {
+				if strings.HasPrefix(key, string(kndrPrefix)) {
+					return ErrSweepFinalized
+				}
+			}
+		}
+
+		delete(hght.outputs, *chanPoint)
+		if len(hght.outputs) == 0 {
+			delete(s.heights, height)
+		}
+	}
+
+	return nil
+}
+
+// putOutput serializes the output and stores it under the given key in the
+// channel's outputs, tracking the channel if it is not yet known.
+func (s *mockStoreState) putOutput(chanPoint *wire.OutPoint, key string,
+	output mockEncoder) error {
+
+	var b bytes.Buffer
+	if err := output.Encode(&b); err != nil {
+		return err
+	}
+
+	outputs, ok := s.channels[*chanPoint]
+	if !ok {
+		outputs = make(map[string][]byte)
+		s.channels[*chanPoint] = outputs
+	}
+	outputs[key] = b.Bytes()
+
+	return nil
+}
+
+// deleteOutput removes the output stored under the given key from the
+// channel's outputs. The channel remains tracked even if it has no outputs
+// left.
+func (s *mockStoreState) deleteOutput(chanPoint *wire.OutPoint, key string) {
+	if outputs, ok := s.channels[*chanPoint]; ok {
+		delete(outputs, key)
+	}
+}
+
+// addToHeight indexes the channel's output stored under the given key at the
+// provided height.
+func (s *mockStoreState) addToHeight(height uint32, chanPoint *wire.OutPoint,
+	key string) {
+
+	hght, ok := s.heights[height]
+	if !ok {
+		hght = &mockHeight{
+			outputs: make(map[wire.OutPoint]map[string]struct{}),
+		}
+		s.heights[height] = hght
+	}
+
+	keys, ok := hght.outputs[*chanPoint]
+	if !ok {
+		keys = make(map[string]struct{})
+		hght.outputs[*chanPoint] = keys
+	}
+	keys[key] = struct{}{}
+}
+
+// removeFromHeight removes the channel's output stored under the given key
+// from the provided height. As with the nurseryStore, the height is pruned
+// along with its finalized txns once it has no outputs left.
+func (s *mockStoreState) removeFromHeight(height uint32,
+	chanPoint *wire.OutPoint, key string) {
+
+	hght, ok := s.heights[height]
+	if !ok {
+		return
+	}
+
+	keys, ok := hght.outputs[*chanPoint]
+	if !ok {
+		return
+	}
+
+	delete(keys, key)
+	if len(keys) > 0 {
+		return
+	}
+
+	delete(hght.outputs, *chanPoint)
+	if len(hght.outputs) == 0 {
+		delete(s.heights, height)
+	}
+}
+
+// heightOutputs returns the serialized outputs at the provided height whose
+// keys begin with the given prefix.
+func (s *mockStoreState) heightOutputs(height uint32, prefix []byte) [][]byte {
+	hght, ok := s.heights[height]
+	if !ok {
+		return nil
+	}
+
+	var chanPoints []wire.OutPoint
+	for chanPoint := range hght.outputs {
+		chanPoints = append(chanPoints, chanPoint)
+	}
+	sortOutPoints(chanPoints)
+
+	var values [][]byte
+	for _, chanPoint := range chanPoints {
+		outputs := s.channels[chanPoint]
+
+		var keys []string
+		for key := range hght.outputs[chanPoint] {
+			if strings.HasPrefix(key, string(prefix)) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if v, ok := outputs[key]; ok {
+				values = append(values, v)
+			}
+		}
+	}
+
+	return values
+}
+
+// heightKids decodes the kindergarten outputs at the provided height.
+func (s *mockStoreState) heightKids(height uint32) ([]kidOutput, error) {
+	var kids []kidOutput
+	for _, v := range s.heightOutputs(height, kndrPrefix) {
+		var kid kidOutput
+		if err := kid.Decode(bytes.NewReader(v)); err != nil {
+			return nil, err
+		}
+		kids = append(kids, kid)
+	}
+
+	return kids, nil
+}
+
+// adjustLimboBalance applies the delta to the channel's limbo balance, which
+// is never allowed to drop below zero.
+func (s *mockStoreState) adjustLimboBalance(chanPoint *wire.OutPoint,
+	delta int64) {
+
+	balance := int64(s.limboBalances[*chanPoint]) + delta
+	if balance < 0 {
+		balance = 0
+	}
+	s.limboBalances[*chanPoint] = btcutil.Amount(balance)
+}
+
+// chanPoints returns the channel points of all tracked channels, in the order
+// of their serialized form.
+func (s *mockStoreState) chanPoints() []wire.OutPoint {
+	var chanPoints []wire.OutPoint
+	for chanPoint := range s.channels {
+		chanPoints = append(chanPoints, chanPoint)
+	}
+	sortOutPoints(chanPoints)
+
+	return chanPoints
+}
+
+// mockOutputKey returns the prefixed outpoint under which an output is stored
+// by the mockNurseryStore.
+func mockOutputKey(prefix []byte, outpoint *wire.OutPoint) (string, error) {
+	key, err := prefixOutputKey(prefix, outpoint)
+	if err != nil {
+		return "", err
+	}
+
+	return string(key), nil
+}
+
+// sortedKeys returns the keys of the given outputs in ascending order.
+func sortedKeys(outputs map[string][]byte) []string {
+	keys := make([]string, 0, len(outputs))
+	for key := range outputs {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// sortOutPoints sorts the outpoints in the order of their serialized form,
+// matching the order in which bolt would iterate over them.
+func sortOutPoints(outpoints []wire.OutPoint) {
+	sort.Slice(outpoints, func(i, j int) bool {
+		cmp := bytes.Compare(outpoints[i].Hash[:], outpoints[j].Hash[:])
+		if cmp != 0 {
+			return cmp < 0
+		}
+
+		return outpoints[i].Index < outpoints[j].Index
+	})
 }
@@ -1061,7 +1061,8 @@ func (r *rpcServer) forceCloseChan(channel *lnwallet.LightningChannel) (*chainha
 
 	// Send the closed channel summary over to the utxoNursery in order to
 	// have its outputs swept back into the wallet once they're mature.
-	if err := r.server.utxoNursery.IncubateOutputs(closeSummary); err != nil {
+	err = r.server.utxoNursery.IncubateOutputs(closeSummary, nil, 0)
+	if err != nil {
 		return nil, nil, err
 	}
 
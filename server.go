@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -19,6 +20,7 @@ import (
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/discovery"
 	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/routing"
 	"github.com/roasbeef/btcd/blockchain"
@@ -322,17 +324,21 @@ func newServer(listenAddrs []string, chanDB *channeldb.DB, cc *chainControl,
 	}
 
 	s.utxoNursery = newUtxoNursery(&NurseryConfig{
-		ChainIO:   cc.chainIO,
-		ConfDepth: 1,
-		DB:        chanDB,
-		Estimator: cc.feeEstimator,
-		GenSweepScript: func() ([]byte, error) {
-			return newSweepPkScript(cc.wallet)
+		ChainIO:         cc.chainIO,
+		CommitConfDepth: 1,
+		DB:              chanDB,
+		Estimator:       cc.feeEstimator,
+		GenSweepScript: func(
+			addrType lnwallet.AddressType) ([]byte, error) {
+
+			return newSweepPkScript(cc.wallet, addrType)
 		},
+		HtlcConfDepth:      1,
 		Notifier:           cc.chainNotifier,
 		PublishTransaction: cc.wallet.PublishTransaction,
 		Signer:             cc.wallet.Cfg.Signer,
 		Store:              utxnStore,
+		SweepConfDepth:     1,
 	})
 
 	// Construct a closure that wraps the htlcswitch's CloseLink method.
@@ -349,7 +355,7 @@ func newServer(listenAddrs []string, chanDB *channeldb.DB, cc *chainControl,
 		DB:        chanDB,
 		Estimator: s.cc.feeEstimator,
 		GenSweepScript: func() ([]byte, error) {
-			return newSweepPkScript(cc.wallet)
+			return newSweepPkScript(cc.wallet, lnwallet.WitnessPubKey)
 		},
 		Notifier:           cc.chainNotifier,
 		PublishTransaction: cc.wallet.PublishTransaction,
@@ -403,7 +409,7 @@ func (s *server) Start() error {
 	if err := s.htlcSwitch.Start(); err != nil {
 		return err
 	}
-	if err := s.utxoNursery.Start(); err != nil {
+	if err := s.utxoNursery.Start(context.Background()); err != nil {
 		return err
 	}
 	if err := s.breachArbiter.Start(); err != nil {
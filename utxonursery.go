@@ -2,21 +2,29 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
+	"math/rand"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/lnwallet/btcwallet"
 	"github.com/roasbeef/btcd/blockchain"
+	"github.com/roasbeef/btcd/chaincfg/chainhash"
 	"github.com/roasbeef/btcd/txscript"
 	"github.com/roasbeef/btcd/wire"
 	"github.com/roasbeef/btcutil"
+	"github.com/roasbeef/btcwallet/wallet/txrules"
 )
 
 //                          SUMMARY OF OUTPUT STATES
@@ -67,7 +75,8 @@ import (
 //    confirmation of the UTXO we are trying to spend, contained in either the
 //    commitment txn or htlc timeout txn. Once the maturity height is reached,
 //    the utxo nursery will sweep all KNDR outputs scheduled for that height
-//    using a single txn.
+//    using a single txn, or one txn per witness type if the nursery has been
+//    configured to segregate its sweeps.
 //
 //    NOTE: Due to the fact that KNDR outputs can be dynamically aggregated and
 //    swept, we make precautions to finalize the KNDR outputs at a particular
@@ -167,40 +176,437 @@ var (
 	// ErrContractNotFound is returned when the nursery is unable to
 	// retrieve information about a queried contract.
 	ErrContractNotFound = fmt.Errorf("unable to locate contract")
+
+	// ErrNurseryShuttingDown is returned when an operation is abandoned
+	// because the nursery is shutting down.
+	ErrNurseryShuttingDown = fmt.Errorf("utxo nursery shutting down")
+
+	// ErrDustSweep is returned when the output of a sweep txn would be
+	// below the dust limit after paying fees.
+	ErrDustSweep = fmt.Errorf("sweep output below dust limit")
+
+	// ErrZeroFeeEstimate is returned when the fee estimator yields a fee
+	// rate of zero, e.g. because it hasn't yet been initialized. A sweep
+	// paying no fee would never be relayed, so it's instead retried at a
+	// later height.
+	ErrZeroFeeEstimate = fmt.Errorf("fee estimator returned a zero fee " +
+		"rate")
+
+	// ErrFeeBudgetTooLow is returned when the fee budgets of the outputs
+	// of a sweep txn don't permit paying the minimum fee rate required
+	// for it to be relayed. Rather than exceeding the budgets, the outputs
+	// are deferred.
+	ErrFeeBudgetTooLow = fmt.Errorf("fee budget below minimum fee rate")
+
+	// ErrNurseryDraining is returned when a new incubation is requested
+	// after the nursery has begun draining.
+	ErrNurseryDraining = fmt.Errorf("utxo nursery draining")
+
+	// ErrTxAlreadyPublished signals that a txn could not be published
+	// because the backend already knows of it, either in its mempool or in
+	// the chain. This is benign, as the txn has already been broadcast.
+	ErrTxAlreadyPublished = fmt.Errorf("transaction already in mempool " +
+		"or chain")
+
+	// ErrTxFeeTooLow signals that a txn was rejected by the backend for
+	// paying an insufficient fee. The txn must be replaced with one paying
+	// a higher fee before it can be relayed.
+	ErrTxFeeTooLow = fmt.Errorf("transaction fee too low")
+
+	// ErrPublishConnectivity signals that a txn could not be published due
+	// to a connectivity issue with the backend. Publication should be
+	// retried once the backend is reachable.
+	ErrPublishConnectivity = fmt.Errorf("unable to reach backend to " +
+		"publish transaction")
+
+	// ErrNoFinalizedSweep is returned when a finalized sweep txn is
+	// requested for a height that has not been finalized.
+	ErrNoFinalizedSweep = fmt.Errorf("no finalized sweep txn at height")
+
+	// ErrMultipleFinalizedSweeps is returned when a single finalized sweep
+	// txn is requested for a height at which several sweep txns were
+	// finalized. FinalizedSweepTxns should be used instead.
+	ErrMultipleFinalizedSweeps = fmt.Errorf("multiple sweep txns " +
+		"finalized at height")
+
+	// ErrPrematureCribSweep is returned when the timeout txn of a crib
+	// output is about to be broadcast at a height preceding its CLTV
+	// expiry, which the backend would reject as non-final.
+	ErrPrematureCribSweep = fmt.Errorf("crib output swept before its " +
+		"expiry height")
+
+	// ErrChainDiverged is returned when the block recorded at the nursery's
+	// last graduated height is no longer part of the main chain, indicating
+	// that the chain reorganized past heights the nursery already
+	// processed, e.g. while the node was offline.
+	ErrChainDiverged = fmt.Errorf("chain diverged from last graduated " +
+		"block")
+
+	// errIncubationCanceled is returned when a state transition is
+	// abandoned because the incubation of the output's channel has been
+	// canceled.
+	errIncubationCanceled = fmt.Errorf("incubation canceled")
+)
+
+// classifyPublishErr maps an error returned from PublishTransaction onto one of
+// ErrTxAlreadyPublished, ErrTxFeeTooLow, or ErrPublishConnectivity. Since the
+// chain backends only surface these conditions as error strings, the error
+// message is inspected for the phrasing used by btcd and bitcoind. Errors that
+// cannot be classified are returned unmodified.
+func classifyPublishErr(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch err {
+	case ErrTxAlreadyPublished, ErrTxFeeTooLow, ErrPublishConnectivity:
+		return err
+	}
+
+	msg := strings.ToLower(err.Error())
+	containsAny := func(substrs ...string) bool {
+		for _, substr := range substrs {
+			if strings.Contains(msg, substr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case containsAny("insufficient fee", "insufficient priority",
+		"min relay fee not met", "mempool min fee not met",
+		"fee not met", "fee too low"):
+
+		return ErrTxFeeTooLow
+
+	case containsAny("already have transaction", "already exists",
+		"txn-already-in-mempool", "txn-already-known",
+		"already in block chain", "already spent"):
+
+		return ErrTxAlreadyPublished
+
+	case containsAny("connection refused", "connection reset",
+		"broken pipe", "i/o timeout", "not connected",
+		"network is unreachable", "client has been shutdown"):
+
+		return ErrPublishConnectivity
+
+	// btcd prefixes all txn rejections with "TX rejected:". Historically
+	// the nursery has treated these as the txn having already been
+	// published, so we preserve this for rejections not classified above.
+	case strings.Contains(err.Error(), "TX rejected:"):
+		return ErrTxAlreadyPublished
+	}
+
+	return err
+}
+
+const (
+	// defaultConfDepth is the number of confirmations the nursery waits for
+	// before considering a commitment, htlc timeout, or sweep txn
+	// confirmed, unless otherwise specified in the NurseryConfig.
+	defaultConfDepth = 1
+
+	// defaultIncubateRetries is the number of times the nursery will retry
+	// persisting a new incubation request if the nursery store write fails,
+	// unless otherwise specified in the NurseryConfig.
+	defaultIncubateRetries = 3
+
+	// defaultIncubateRetryBackoff is the initial duration the nursery will
+	// wait between attempts to persist a new incubation request, unless
+	// otherwise specified in the NurseryConfig. The duration doubles after
+	// each failed attempt.
+	defaultIncubateRetryBackoff = 100 * time.Millisecond
+
+	// defaultTransitionRetries is the number of times the nursery will
+	// retry persisting a state transition of a confirmed output, unless
+	// otherwise specified in the NurseryConfig.
+	defaultTransitionRetries = 5
+
+	// defaultTransitionRetryBackoff is the initial duration the nursery
+	// will wait between attempts to persist a state transition, unless
+	// otherwise specified in the NurseryConfig.
+	defaultTransitionRetryBackoff = 100 * time.Millisecond
+
+	// targetBlockInterval is the expected number of seconds between
+	// blocks, used to estimate the maturity height of outputs with time
+	// based relative locks.
+	targetBlockInterval = 600
+
+	// medianTimeBlocks is the number of previous blocks whose timestamps
+	// are used to compute the median-time-past.
+	medianTimeBlocks = 11
+
+	// urgentSweepConfTarget is the confirmation target, in blocks, used to
+	// estimate the fee rate of sweeps containing urgent outputs.
+	urgentSweepConfTarget = 1
+
+	// defaultSweepConfTarget is the default confirmation target, in
+	// blocks, used to estimate the fee rate of sweeps with no urgent
+	// outputs.
+	defaultSweepConfTarget = 6
+
+	// relaxedSweepConfTarget is the minimum confirmation target, in
+	// blocks, used to estimate the fee rate of sweeps consisting solely of
+	// outputs that can't be claimed by anyone else.
+	relaxedSweepConfTarget = 36
+
+	// absurdFeePerWeight is the fee rate, in satoshis per unit of weight,
+	// above which an estimate is assumed to be erroneous, as sweeping at
+	// it would likely destroy most of the value being swept.
+	absurdFeePerWeight = 10000
 )
 
 // NurseryConfig abstracts the required subsystems used by the utxo nursery. An
 // instance of NurseryConfig is passed to newUtxoNursery during instantiation.
 type NurseryConfig struct {
+	// BatchCribSweeps, if set, instructs the nursery to attempt combining
+	// the presigned htlc timeout txns of crib outputs expiring at the same
+	// height into a single txn. This is only possible for timeout txns
+	// whose signatures commit solely to their own input and output, all
+	// others are broadcast individually.
+	BatchCribSweeps bool
+
+	// BroadcastJitter, if non-zero, is the maximum random delay inserted
+	// between consecutive broadcasts at the same height. This spreads out
+	// the burst of txns broadcast when many outputs mature together, such
+	// that a fee spike caused by our own sweeps does not leave all of them
+	// underpriced, and the backend isn't flooded with txns.
+	BroadcastJitter time.Duration
+
 	// ChainIO is used by the utxo nursery to determine the current block
 	// height, which drives the incubation of the nursery's outputs.
 	ChainIO lnwallet.BlockChainIO
 
-	// ConfDepth is the number of blocks the nursery store waits before
-	// determining outputs in the chain as confirmed.
-	ConfDepth uint32
+	// CommitConfDepth is the number of confirmations required for a
+	// commitment txn before its outputs are promoted from the preschool
+	// into the kindergarten. As the commitment may have been broadcast by
+	// our counterparty, this should be deep enough to resist reorg based
+	// theft. If zero, defaultConfDepth is used.
+	CommitConfDepth uint32
+
+	// CommitConfRegistrationInterval, if non-zero, is the minimum time
+	// between successive registrations of commitment confirmation
+	// notifications with the Notifier. This throttles the registrations
+	// made while reloading the preschool or incubating many channels at
+	// once, such that a slow notifier is not flooded with requests.
+	CommitConfRegistrationInterval time.Duration
+
+	// CpfpSweeper crafts the child-pays-for-parent txns that bump the fee
+	// of stuck commitment txns, and is required if CpfpThreshold is
+	// non-zero. Since an anchor output alone can't pay for its commitment
+	// txn, the returned txn is expected to spend wallet inputs as well.
+	CpfpSweeper Sweeper
+
+	// CpfpThreshold, if non-zero, is the number of blocks a commitment txn
+	// may remain unconfirmed after its outputs were incubated before the
+	// nursery attempts to bump its fee, by spending our anchor output in
+	// a child-pays-for-parent txn. The attempt is repeated every
+	// CpfpThreshold blocks until the commitment txn confirms.
+	CpfpThreshold uint32
 
 	// DB provides access to a user's channels, such that they can be marked
 	// fully closed after incubation has concluded.
 	DB *channeldb.DB
 
+	// DeriveSweepScript, if non-nil, is used in place of GenSweepScript to
+	// derive the script to which a sweep txn pays from the outpoint of the
+	// first output it spends. If the function is deterministic, so are the
+	// txids of the nursery's sweep txns, even across attempts made before
+	// the sweep txns were finalized. This is intended for testing.
+	DeriveSweepScript func(lnwallet.AddressType, *wire.OutPoint) ([]byte,
+		error)
+
+	// EconomicalSweepThreshold, if non-zero, is the minimum value that an
+	// output must retain after deducting the estimated cost of sweeping it
+	// for the nursery to incubate it. Outputs falling below the threshold
+	// are abandoned rather than incubated, as sweeping them would cost
+	// more than they are worth. A threshold of one satoshi abandons only
+	// those outputs whose sweep cost meets or exceeds their value.
+	EconomicalSweepThreshold btcutil.Amount
+
 	// Estimator is used when crafting sweep transactions to estimate the
 	// necessary fee relative to the expected size of the sweep transaction.
 	Estimator lnwallet.FeeEstimator
 
-	// GenSweepScript generates a P2WKH script belonging to the wallet where
-	// funds can be swept.
-	GenSweepScript func() ([]byte, error)
+	// ExternalSweeper, if non-nil, is handed the mature kindergarten
+	// outputs at each height instead of the nursery crafting and
+	// broadcasting their sweep txns itself. The nursery then watches for
+	// the confirmation of the txid reported by the sweeper, and graduates
+	// the outputs as usual. Unlike Sweeper, the nursery neither persists
+	// nor broadcasts the sweep txns.
+	ExternalSweeper ExternalSweeper
+
+	// FallbackFeeRate, if non-zero, is the fee rate, in satoshis per unit
+	// of weight, used for sweeps when the fee estimator fails, panics, or
+	// returns an absurd estimate. The rate remains subject to MinFeeRate
+	// and MaxFeeRate. If zero, sweeps are instead retried once the
+	// estimator recovers.
+	FallbackFeeRate btcutil.Amount
+
+	// GenSweepScript generates a script of the given address type belonging
+	// to the wallet where funds can be swept.
+	GenSweepScript func(lnwallet.AddressType) ([]byte, error)
+
+	// GraduationConfDepth is the number of confirmations required for the
+	// sweep txn of a channel's final outputs before the channel is marked
+	// fully closed, providing an extra margin of safety after the outputs
+	// have graduated at SweepConfDepth. Until then, the channel remains in
+	// the nursery's reports as swept, awaiting final confirmations. If not
+	// greater than SweepConfDepth, channels are closed as soon as all of
+	// their outputs have graduated.
+	//
+	// NOTE: This serves as the nursery's reorg safety depth, and is
+	// independent of when records are deleted from the nursery store. The
+	// height index is pruned as soon as its outputs leave it, or by
+	// Prune, while a channel's outputs are only removed once the channel
+	// is closed at this depth.
+	GraduationConfDepth uint32
+
+	// HandoffOutputs, if non-nil, is called with the outputs of each newly
+	// incubated channel, allowing them to be handed off to an external
+	// service, e.g. a watchtower, that can sweep them if the node is
+	// offline. The nursery continues to track the outputs, but will not
+	// broadcast sweeps of outputs that have been spent externally, which
+	// instead graduate once the external sweep confirms.
+	HandoffOutputs func([]HandoffOutput) error
+
+	// HtlcConfDepth is the number of confirmations required for an htlc
+	// timeout txn before its output is promoted from the crib into the
+	// kindergarten. Since the CSV delay that follows already provides a
+	// buffer against reorgs, this may be set lower than CommitConfDepth to
+	// shorten the two-stage recovery of htlc outputs. If zero,
+	// defaultConfDepth is used.
+	HtlcConfDepth uint32
+
+	// HtlcSweepDeadline, if non-zero, is the number of blocks past its
+	// maturity height by which the sweep of an htlc derived kindergarten
+	// output should confirm, before the counterparty may race us to claim
+	// the htlc. As the deadline approaches, the confirmation target of the
+	// output's sweep is lowered, escalating to the next block once the
+	// deadline is imminent.
+	HtlcSweepDeadline uint32
+
+	// IncubateRetries is the number of times the nursery retries writing a
+	// new incubation request to the nursery store before failing the
+	// request, and deferring it to the next block. If zero,
+	// defaultIncubateRetries is used.
+	IncubateRetries uint32
+
+	// IncubateRetryBackoff is the initial delay between attempts to write a
+	// new incubation request to the nursery store, which is doubled after
+	// each failure. If zero, defaultIncubateRetryBackoff is used.
+	IncubateRetryBackoff time.Duration
+
+	// MaxConfWatchers, if non-zero, bounds the number of goroutines that
+	// may concurrently watch for the confirmation of commitment, htlc
+	// timeout, and sweep txns. Once the limit is reached, additional
+	// watchers are queued, and their notifications are only registered
+	// once a running watcher exits. This prevents a storm of notifier
+	// registrations when a large number of outputs are reloaded at
+	// startup.
+	MaxConfWatchers uint32
+
+	// MaxFeeRate is the maximum fee rate, in satoshis per unit of weight,
+	// that the nursery will pay to sweep outputs. The estimated fee rate is
+	// clamped to this value if it is non-zero.
+	MaxFeeRate btcutil.Amount
+
+	// Metrics receives instrumentation about the outputs incubated by the
+	// nursery. If nil, the collected metrics are discarded.
+	Metrics NurseryMetrics
+
+	// MinFeeRate is the minimum fee rate, in satoshis per unit of weight,
+	// that the nursery will pay to sweep outputs. The estimated fee rate is
+	// raised to this value if it is non-zero.
+	MinFeeRate btcutil.Amount
+
+	// MinRelayFeeRate is the network's minimum relay fee rate, in
+	// satoshis per unit of weight. Sweep txns paying less would not
+	// propagate, so if non-zero, the final fee rate of each sweep is
+	// raised to this floor, taking precedence over MaxFeeRate. The fee
+	// budgets of the swept outputs are never exceeded to do so, instead
+	// the sweep is deferred.
+	MinRelayFeeRate btcutil.Amount
 
 	// Notifier provides the utxo nursery the ability to subscribe to
 	// transaction confirmation events, which advance outputs through their
 	// persistence state transitions.
 	Notifier chainntnfs.ChainNotifier
 
+	// OnChannelMatured, if non-nil, is invoked once all outputs of a
+	// channel have graduated and the channel has been marked fully closed,
+	// just before it is removed from the nursery store. The total value
+	// recovered by the channel's sweeps is provided. If the nursery
+	// restarts before the channel is removed, the callback will be invoked
+	// again upon the channel's removal.
+	OnChannelMatured func(chanPoint wire.OutPoint, recovered btcutil.Amount)
+
+	// OnFeeEstimationError, if non-nil, is invoked whenever the fee
+	// estimator fails to provide a usable fee rate for the given
+	// confirmation target, allowing the failure to be surfaced to the
+	// operator. The hook may be invoked while holding the nursery's mutex,
+	// and thus must not call back into the nursery.
+	OnFeeEstimationError func(confTarget uint32, err error)
+
+	// OnSweepBroadcast, if non-nil, is invoked each time the nursery
+	// successfully broadcasts a txn, along with the outputs concerned. For
+	// kindergarten sweeps, these are the outputs spent by the txn. For
+	// htlc timeout txns, this is the crib output created by the txn, while
+	// the htlc output it spends can be found in the txn's input. As txns
+	// are rebroadcast until they confirm, the hook may be invoked more than
+	// once for the same txn. The hook is invoked while holding the
+	// nursery's mutex, and thus must not call back into the nursery.
+	OnSweepBroadcast func(tx *wire.MsgTx, outputs []CsvSpendableOutput)
+
+	// OutputFeeBudget, if non-zero, is the maximum fee, in satoshis, that
+	// any single output may contribute towards the sweep transaction that
+	// spends it. If an output's budget would be exceeded at the estimated
+	// fee rate, the sweep's fee rate is lowered to remain within budget,
+	// accepting a slower confirmation instead.
+	OutputFeeBudget btcutil.Amount
+
+	// OutputFeeBudgetRatio, if non-zero, caps the fee that any single
+	// output may contribute towards its sweep transaction to the given
+	// fraction of the output's value. If OutputFeeBudget is also set, the
+	// lower of the two budgets applies.
+	OutputFeeBudgetRatio float64
+
+	// OutputSelector determines which of the mature kindergarten outputs
+	// at a height are swept, and how they're grouped into sweep txns. If
+	// nil, all mature outputs are swept together, subject to the grouping
+	// by sweep destination, priority and witness type.
+	OutputSelector OutputSelector
+
+	// PruneInterval, if non-zero, is the number of blocks between
+	// successive prunings of the nursery store, which remove the empty
+	// buckets left behind as outputs leave it.
+	PruneInterval uint32
+
 	// PublishTransaction facilitates the process of broadcasting a signed
 	// transaction to the appropriate network.
 	PublishTransaction func(*wire.MsgTx) error
 
+	// RebroadcastWarnThreshold, if non-zero, is the number of times the
+	// finalized sweep txns at a height may be rebroadcast without
+	// confirming before the nursery warns that the sweep appears stuck,
+	// and may need its fee bumped.
+	RebroadcastWarnThreshold uint32
+
+	// RetainGraduated, if true, causes the graduated outputs of a fully
+	// closed channel to be moved to an archive when the channel is removed
+	// from the nursery store, rather than being deleted. The archived
+	// outputs can be retrieved via FetchArchivedGraduations.
+	RetainGraduated bool
+
+	// SegregateSweeps, if true, causes the nursery to sweep kindergarten
+	// outputs maturing at the same height in separate transactions, one
+	// for each witness type. This prevents commitment outputs from being
+	// linked on-chain to htlc outputs. By default, all kindergarten outputs
+	// at a height are swept together in a single transaction.
+	SegregateSweeps bool
+
 	// Signer is used by the utxo nursery to generate valid witnesses at the
 	// time the incubated outputs need to be spent.
 	Signer lnwallet.Signer
@@ -208,6 +614,167 @@ type NurseryConfig struct {
 	// Store provides access to and modification of the persistent state
 	// maintained about the utxo nursery's incubating outputs.
 	Store NurseryStore
+
+	// SweepAddressType is the type of wallet address to which outputs are
+	// swept, unless a sweep script was provided upon incubation. If
+	// unknown, outputs are swept to a p2wkh address. Since the wallet
+	// can't generate taproot addresses, the nursery refuses to start with
+	// TaprootPubKey unless DeriveSweepScript is set.
+	SweepAddressType lnwallet.AddressType
+
+	// SweepBufferMaxValue restricts sweep buffering to kindergarten outputs
+	// valued below this amount. If zero, any output may be buffered.
+	SweepBufferMaxValue btcutil.Amount
+
+	// SweepBufferWindow is the maximum number of blocks past its maturity
+	// that the nursery may defer sweeping a kindergarten output, so that it
+	// can be swept together with outputs maturing shortly after in a single
+	// transaction. If zero, outputs are swept as soon as they mature.
+	SweepBufferWindow uint32
+
+	// SweepConfDepth is the number of confirmations required for one of
+	// our sweep txns before the outputs it spends are graduated. Since the
+	// sweeps are our own txns, this may be set lower than CommitConfDepth.
+	// If zero, defaultConfDepth is used.
+	SweepConfDepth uint32
+
+	// SweepConfTarget is the confirmation target, in blocks, passed to the
+	// fee estimator when sweeping outputs that aren't urgent. A larger
+	// target trades confirmation speed for lower fees. Urgent outputs,
+	// such as those of a revoked commitment, override this on a per-output
+	// basis through their SweepPriority, and always target the next
+	// block. If zero, defaultSweepConfTarget is used.
+	SweepConfTarget uint32
+
+	// SweepNoDelayImmediately, if set, causes commitment outputs without a
+	// relative timelock to be swept as soon as the commitment txn
+	// confirms, rather than once the nursery processes the next block.
+	// Their height is finalized ahead of time, along with any other
+	// kindergarten outputs it holds.
+	SweepNoDelayImmediately bool
+
+	// Sweeper, if non-nil, crafts the txns that sweep mature kindergarten
+	// outputs back into the wallet, replacing the nursery's own sweep
+	// construction. The nursery continues to decide when outputs are
+	// swept, and persists and broadcasts the returned txns. If nil, the
+	// nursery crafts the sweep txns itself.
+	Sweeper Sweeper
+
+	// TransitionRetries is the number of times the nursery retries
+	// persisting a state transition of a confirmed output before giving
+	// up. If zero, defaultTransitionRetries is used.
+	TransitionRetries uint32
+
+	// TransitionRetryBackoff is the initial duration the nursery waits
+	// between attempts to persist a state transition, doubling after each
+	// failed attempt. If zero, defaultTransitionRetryBackoff is used.
+	TransitionRetryBackoff time.Duration
+}
+
+// NurseryMetrics is an interface used by the utxo nursery to export
+// instrumentation about its incubating outputs, e.g. to a metrics backend.
+type NurseryMetrics interface {
+	// ObserveTimeInState records the number of blocks an output spent in
+	// the given state, i.e. crib, pscl, or kndr, before transitioning to
+	// its next state. The number of blocks the output was expected to
+	// spend in that state, as dictated by its timelock, is also provided,
+	// and will be zero for states not bounded by a timelock.
+	ObserveTimeInState(state string, actual, expected uint32)
+
+	// AddOutputsIncubated increments the number of outputs that have
+	// entered the given state, i.e. crib or pscl, upon incubation.
+	AddOutputsIncubated(state string, n int)
+
+	// AddOutputsSwept increments the number of outputs in the given state
+	// whose spending txn has been broadcast. For crib outputs, this is
+	// their presigned htlc timeout txn, while for kndr outputs this is the
+	// sweep txn returning their funds to the wallet.
+	AddOutputsSwept(state string, n int)
+
+	// AddOutputsGraduated increments the number of outputs whose sweep txn
+	// has confirmed, completing their incubation.
+	AddOutputsGraduated(n int)
+
+	// SetLimboBalance records the total value of all outputs currently
+	// being incubated by the nursery.
+	SetLimboBalance(balance btcutil.Amount)
+}
+
+// noopNurseryMetrics is a NurseryMetrics implementation that discards all
+// observations. It is used if no metrics backend is provided.
+type noopNurseryMetrics struct{}
+
+// ObserveTimeInState is a no-op.
+func (noopNurseryMetrics) ObserveTimeInState(string, uint32, uint32) {}
+
+// AddOutputsIncubated is a no-op.
+func (noopNurseryMetrics) AddOutputsIncubated(string, int) {}
+
+// AddOutputsSwept is a no-op.
+func (noopNurseryMetrics) AddOutputsSwept(string, int) {}
+
+// AddOutputsGraduated is a no-op.
+func (noopNurseryMetrics) AddOutputsGraduated(int) {}
+
+// SetLimboBalance is a no-op.
+func (noopNurseryMetrics) SetLimboBalance(btcutil.Amount) {}
+
+// OutputSelector is an interface used by the utxo nursery to select which of
+// the mature kindergarten outputs at a height are swept, allowing strategies
+// such as sweeping the largest outputs first up to a weight budget.
+type OutputSelector interface {
+	// SelectOutputs partitions the mature kindergarten outputs at the
+	// given height into the sets that should each be swept together.
+	// Outputs that aren't included in any set are deferred to the next
+	// height, where they will be offered again. A set may still be split
+	// across several sweep txns if its outputs have different sweep
+	// destinations or priorities.
+	SelectOutputs(height uint32, outputs []kidOutput) [][]kidOutput
+}
+
+// allOutputsSelector is the default OutputSelector, which sweeps all of the
+// mature outputs at a height together.
+type allOutputsSelector struct{}
+
+// SelectOutputs returns all of the provided outputs as a single set.
+func (allOutputsSelector) SelectOutputs(_ uint32,
+	outputs []kidOutput) [][]kidOutput {
+
+	return [][]kidOutput{outputs}
+}
+
+// HandoffOutput describes an incubating output that is handed off to an
+// external service, such as a watchtower, allowing it to sweep the output on
+// our behalf if the node remains offline past the output's maturity.
+type HandoffOutput struct {
+	// OutPoint is the outpoint of the CSV delayed output to be swept. For
+	// htlc outputs, this is the output of the timeout txn.
+	OutPoint wire.OutPoint
+
+	// OriginChanPoint is the channel point of the channel from which the
+	// output originated.
+	OriginChanPoint wire.OutPoint
+
+	// Amount is the value of the output.
+	Amount btcutil.Amount
+
+	// WitnessType determines the witness required to spend the output.
+	WitnessType lnwallet.WitnessType
+
+	// SignDesc is the sign descriptor required to sign for the output.
+	SignDesc lnwallet.SignDescriptor
+
+	// BlocksToMaturity is the relative CSV delay that must pass after the
+	// output confirms before it may be swept.
+	BlocksToMaturity uint32
+
+	// Expiry is the absolute height at which TimeoutTx may be broadcast.
+	// This is zero for commitment outputs.
+	Expiry uint32
+
+	// TimeoutTx is the presigned htlc timeout txn that creates OutPoint,
+	// or nil for commitment outputs.
+	TimeoutTx *wire.MsgTx
 }
 
 // utxoNursery is a system dedicated to incubating time-locked outputs created
@@ -219,36 +786,200 @@ type NurseryConfig struct {
 // the source wallet, returning the outputs so they can be used within future
 // channels, or regular Bitcoin transactions.
 type utxoNursery struct {
-	started uint32
-	stopped uint32
+	started  uint32
+	stopped  uint32
+	draining uint32
 
 	cfg *NurseryConfig
 
 	mu         sync.Mutex
 	bestHeight uint32
 
-	quit chan struct{}
-	wg   sync.WaitGroup
+	// pendingIncubations holds incubation requests that could not be
+	// persisted to the nursery store, and will be retried by the incubator
+	// upon the arrival of each new block.
+	pendingIncubations []*incubationRequest
+
+	// handoffSpends records the txid of the transaction that spent each
+	// output handed off via the HandoffOutputs callback, as detected by
+	// the chain notifier. This allows the nursery to avoid broadcasting
+	// sweeps of outputs that have already been swept externally.
+	handoffSpends map[wire.OutPoint]chainhash.Hash
+
+	// sweepWatches records the txids of the sweep txns whose confirmations
+	// are being watched, such that a sweep broadcast again before it
+	// confirms, e.g. by SweepMatureOutputs, isn't watched twice.
+	sweepWatches map[chainhash.Hash]struct{}
+
+	// unpublishedTxns holds the txns that could not be published due to a
+	// connectivity issue with the backend, keyed by their txid. They are
+	// republished upon the arrival of each new block until the backend
+	// accepts them.
+	unpublishedTxns map[chainhash.Hash]*unpublishedTx
+
+	// finalConfHeights records the channels whose outputs have all
+	// graduated, but whose sweeps have yet to reach GraduationConfDepth.
+	// Each channel is mapped to the height at which it will be marked
+	// fully closed.
+	finalConfHeights map[wire.OutPoint]uint32
+
+	// chanCancels holds a channel for each incubating channel, which is
+	// closed if the channel's incubation is canceled, signaling its
+	// confirmation watchers to exit.
+	chanCancels map[wire.OutPoint]chan struct{}
+
+	// eventClients holds the active nursery event subscriptions, keyed by
+	// their subscription id.
+	eventClients      map[uint64]*NurseryEventSubscription
+	nextEventClientID uint64
+
+	// graduations tracks the goroutines waiting to graduate the outputs of
+	// broadcast sweep txns, allowing Drain to wait for them to complete.
+	graduations sync.WaitGroup
+
+	// confWatchers is a semaphore bounding the number of running
+	// confirmation watchers. It is nil if MaxConfWatchers is zero.
+	confWatchers chan struct{}
+
+	// commitConfQueue holds the preschool outputs whose commitment
+	// confirmation notifications have yet to be registered by the
+	// commitConfRegistrar. It is only used if CommitConfRegistrationInterval
+	// is non-zero. As queued outputs are already persisted in the preschool
+	// bucket, any registrations still queued upon shutdown are made again
+	// by reloadPreschool upon restart. The commitConfQueued channel
+	// signals the registrar that new outputs have been queued.
+	commitConfQueue  []*commitConfRegistration
+	commitConfQueued chan struct{}
+
+	// commitConfInflight is the registration the commitConfRegistrar is
+	// currently making without holding the mutex. It is cleared if the
+	// incubation of its channel is stopped in the meantime, such that
+	// no watcher is launched for it.
+	commitConfInflight *commitConfRegistration
+
+	// after returns a channel that is sent upon once the given duration
+	// has elapsed, pacing the commitConfRegistrar. It defaults to
+	// time.After.
+	after func(time.Duration) <-chan time.Time
+
+	// paused is set while the incubator is paused, during which the blocks
+	// it receives are queued in pausedEpochs, to be processed in order
+	// once resumed. The resumed channel signals the incubator to process
+	// the queued blocks.
+	paused       bool
+	pausedEpochs []*chainntnfs.BlockEpoch
+	resumed      chan struct{}
+
+	quit     chan struct{}
+	quitOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// validate checks that the configured options can be honored, such that the
+// nursery refuses to start rather than silently deviating from them.
+func (c *NurseryConfig) validate() error {
+	switch c.SweepAddressType {
+	case lnwallet.WitnessPubKey, lnwallet.NestedWitnessPubKey,
+		lnwallet.PubKeyHash:
+
+	// None of the wallets are able to generate taproot addresses, so
+	// sweeping to them requires the sweep scripts to be derived by
+	// DeriveSweepScript. Otherwise, every sweep would be deferred as its
+	// script couldn't be generated.
+	case lnwallet.TaprootPubKey:
+		if c.DeriveSweepScript == nil {
+			return fmt.Errorf("taproot sweep addresses require " +
+				"DeriveSweepScript")
+		}
+
+	default:
+		return fmt.Errorf("unsupported sweep address type %d",
+			c.SweepAddressType)
+	}
+
+	if c.CpfpThreshold > 0 && c.CpfpSweeper == nil {
+		return fmt.Errorf("CpfpThreshold requires a CpfpSweeper")
+	}
+
+	return nil
 }
 
 // newUtxoNursery creates a new instance of the utxoNursery from a
 // ChainNotifier and LightningWallet instance.
 func newUtxoNursery(cfg *NurseryConfig) *utxoNursery {
+	if cfg.CommitConfDepth == 0 {
+		cfg.CommitConfDepth = defaultConfDepth
+	}
+	if cfg.HtlcConfDepth == 0 {
+		cfg.HtlcConfDepth = defaultConfDepth
+	}
+	if cfg.SweepConfDepth == 0 {
+		cfg.SweepConfDepth = defaultConfDepth
+	}
+	if cfg.SweepConfTarget == 0 {
+		cfg.SweepConfTarget = defaultSweepConfTarget
+	}
+	if cfg.SweepAddressType == lnwallet.UnknownAddressType {
+		cfg.SweepAddressType = lnwallet.WitnessPubKey
+	}
+	if cfg.Metrics == nil {
+		cfg.Metrics = noopNurseryMetrics{}
+	}
+	if cfg.OutputSelector == nil {
+		cfg.OutputSelector = allOutputsSelector{}
+	}
+	if cfg.IncubateRetries == 0 {
+		cfg.IncubateRetries = defaultIncubateRetries
+	}
+	if cfg.IncubateRetryBackoff == 0 {
+		cfg.IncubateRetryBackoff = defaultIncubateRetryBackoff
+	}
+	if cfg.TransitionRetries == 0 {
+		cfg.TransitionRetries = defaultTransitionRetries
+	}
+	if cfg.TransitionRetryBackoff == 0 {
+		cfg.TransitionRetryBackoff = defaultTransitionRetryBackoff
+	}
+
+	var confWatchers chan struct{}
+	if cfg.MaxConfWatchers > 0 {
+		confWatchers = make(chan struct{}, cfg.MaxConfWatchers)
+	}
+
 	return &utxoNursery{
-		cfg:  cfg,
-		quit: make(chan struct{}),
+		cfg:              cfg,
+		handoffSpends:    make(map[wire.OutPoint]chainhash.Hash),
+		sweepWatches:     make(map[chainhash.Hash]struct{}),
+		unpublishedTxns:  make(map[chainhash.Hash]*unpublishedTx),
+		finalConfHeights: make(map[wire.OutPoint]uint32),
+		chanCancels:      make(map[wire.OutPoint]chan struct{}),
+		eventClients:     make(map[uint64]*NurseryEventSubscription),
+		confWatchers:     confWatchers,
+		commitConfQueued: make(chan struct{}, 1),
+		after:            time.After,
+		resumed:          make(chan struct{}, 1),
+		quit:             make(chan struct{}),
 	}
 }
 
 // Start launches all goroutines the utxoNursery needs to properly carry out
-// its duties.
-func (u *utxoNursery) Start() error {
+// its duties. Replaying the heights missed while the nursery was offline can
+// take a while, and is aborted if either the provided context is canceled or
+// the nursery is stopped in the meantime.
+func (u *utxoNursery) Start(ctx context.Context) error {
 	if !atomic.CompareAndSwapUint32(&u.started, 0, 1) {
 		return nil
 	}
 
 	utxnLog.Tracef("Starting UTXO nursery")
 
+	if err := u.cfg.validate(); err != nil {
+		return err
+	}
+
+	ctx, cancel := u.withQuit(ctx)
+	defer cancel()
+
 	// 1. Start watching for new blocks, as this will drive the nursery
 	// store's state machine.
 
@@ -258,7 +989,7 @@ func (u *utxoNursery) Start() error {
 	// during the time the UTXO nursery was unavailable.
 	newBlockChan, err := u.cfg.Notifier.RegisterBlockEpochNtfn()
 	if err != nil {
-		return err
+		return fmt.Errorf("unable to register for blocks: %v", err)
 	}
 
 	// 2. Flush all fully-graduated channels from the pipeline.
@@ -268,18 +999,38 @@ func (u *utxoNursery) Start() error {
 	pendingCloseChans, err := u.cfg.DB.FetchClosedChannels(true)
 	if err != nil {
 		newBlockChan.Cancel()
-		return err
+		return fmt.Errorf("unable to fetch pending close channels: %v",
+			err)
+	}
+
+	// If extra confirmations are required before closing graduated
+	// channels, we measure them from the current height, as the height at
+	// which their sweeps confirmed is not retained across restarts.
+	var startHeight uint32
+	if u.cfg.GraduationConfDepth > u.cfg.SweepConfDepth {
+		_, bestHeight, err := u.cfg.ChainIO.GetBestBlock()
+		if err != nil {
+			newBlockChan.Cancel()
+			return fmt.Errorf("unable to fetch best block: %v", err)
+		}
+		startHeight = uint32(bestHeight)
 	}
 
 	// Ensure that all mature channels have been marked as fully closed in
 	// the channeldb.
+	u.mu.Lock()
 	for _, pendingClose := range pendingCloseChans {
-		err := u.closeAndRemoveIfMature(&pendingClose.ChanPoint)
+		err := u.closeOrAwaitFinalConfs(
+			&pendingClose.ChanPoint, startHeight,
+		)
 		if err != nil {
+			u.mu.Unlock()
 			newBlockChan.Cancel()
-			return err
+			return fmt.Errorf("unable to close Channel(%s): %v",
+				&pendingClose.ChanPoint, err)
 		}
 	}
+	u.mu.Unlock()
 
 	// TODO(conner): check if any fully closed channels can be removed from
 	// utxn.
@@ -290,7 +1041,8 @@ func (u *utxoNursery) Start() error {
 	lastGraduatedHeight, err := u.cfg.Store.LastGraduatedHeight()
 	if err != nil {
 		newBlockChan.Cancel()
-		return err
+		return fmt.Errorf("unable to fetch last graduated height: %v",
+			err)
 	}
 
 	// 2. Restart spend ntfns for any preschool outputs, which are waiting
@@ -301,18 +1053,36 @@ func (u *utxoNursery) Start() error {
 	// any failures during startup to ensure they terminate.
 	if err := u.reloadPreschool(lastGraduatedHeight); err != nil {
 		newBlockChan.Cancel()
-		close(u.quit)
-		return err
+		u.signalQuit()
+		return fmt.Errorf("unable to reload preschool outputs: %v", err)
+	}
+
+	// Resume watching for the spends of any outputs handed off to an
+	// external service, such that we don't broadcast sweeps that conflict
+	// with its own.
+	if err := u.rewatchHandoffs(lastGraduatedHeight); err != nil {
+		newBlockChan.Cancel()
+		u.signalQuit()
+		return fmt.Errorf("unable to rewatch handed off outputs: %v",
+			err)
 	}
 
 	// 3. Replay all crib and kindergarten outputs from last pruned to
 	// current best height.
-	if err := u.reloadClasses(lastGraduatedHeight); err != nil {
+	err = u.reloadClasses(ctx, lastGraduatedHeight)
+	if err != nil {
 		newBlockChan.Cancel()
-		close(u.quit)
+		u.signalQuit()
 		return err
 	}
 
+	// 4. Begin registering the commitment confirmations queued while
+	// reloading the preschool, if registrations are throttled.
+	if u.cfg.CommitConfRegistrationInterval > 0 {
+		u.wg.Add(1)
+		go u.commitConfRegistrar()
+	}
+
 	u.wg.Add(1)
 	go u.incubator(newBlockChan)
 
@@ -328,45 +1098,342 @@ func (u *utxoNursery) Stop() error {
 
 	utxnLog.Infof("UTXO nursery shutting down")
 
-	close(u.quit)
+	u.signalQuit()
 	u.wg.Wait()
 
 	return nil
 }
 
+// signalQuit closes the nursery's quit channel. As Stop may be called while
+// Start is aborting a replay, this is safe to call more than once.
+func (u *utxoNursery) signalQuit() {
+	u.quitOnce.Do(func() {
+		close(u.quit)
+	})
+}
+
+// withQuit returns a child of the given context that is additionally canceled
+// once the nursery's quit channel is closed. The returned cancel func must be
+// called to release the goroutine watching the quit channel.
+func (u *utxoNursery) withQuit(
+	ctx context.Context) (context.Context, context.CancelFunc) {
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-u.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// Drain gracefully shuts down the utxoNursery. The nursery immediately stops
+// accepting new incubations and broadcasting new sweeps, but waits up to the
+// given timeout for the sweeps it has already broadcast to confirm and have
+// their outputs graduated within the nursery store, before stopping as in
+// Stop. This avoids redundantly rebroadcasting these sweeps after a restart.
+// An error is returned if the timeout expires before all in-flight
+// graduations complete.
+func (u *utxoNursery) Drain(timeout time.Duration) error {
+	// Mark the nursery as draining while holding the mutex, such that no
+	// new graduations are tracked once we begin waiting below.
+	u.mu.Lock()
+	alreadyDraining := !atomic.CompareAndSwapUint32(&u.draining, 0, 1)
+	u.mu.Unlock()
+
+	if alreadyDraining {
+		return nil
+	}
+
+	utxnLog.Infof("UTXO nursery draining in-flight graduations")
+
+	graduated := make(chan struct{})
+	go func() {
+		u.graduations.Wait()
+		close(graduated)
+	}()
+
+	var drainErr error
+	select {
+	case <-graduated:
+	case <-time.After(timeout):
+		drainErr = fmt.Errorf("timed out after %v waiting for "+
+			"in-flight graduations", timeout)
+	case <-u.quit:
+	}
+
+	if err := u.Stop(); err != nil {
+		return err
+	}
+
+	return drainErr
+}
+
+// Pause stops the incubator from processing new blocks, such that no new
+// sweeps or htlc timeout txns are broadcast, e.g. while the fee backend is
+// swapped. Unlike Stop, the confirmation watchers of outputs already in
+// flight remain active, allowing them to advance as usual. The heights of the
+// blocks received while paused are queued until Resume is called.
+func (u *utxoNursery) Pause() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.paused {
+		return
+	}
+	u.paused = true
+
+	utxnLog.Infof("UTXO nursery paused, new heights will be processed " +
+		"once resumed")
+}
+
+// Resume resumes the processing of new blocks by the incubator after a call to
+// Pause. The heights received while paused are processed first, in the order
+// in which their blocks arrived.
+func (u *utxoNursery) Resume() {
+	u.mu.Lock()
+	if !u.paused {
+		u.mu.Unlock()
+		return
+	}
+	u.paused = false
+	numQueued := len(u.pausedEpochs)
+	u.mu.Unlock()
+
+	utxnLog.Infof("UTXO nursery resumed, processing %d queued heights",
+		numQueued)
+
+	select {
+	case u.resumed <- struct{}{}:
+	default:
+	}
+}
+
+// nextEpochs queues the given block epoch, if any, behind the blocks received
+// while the incubator was paused. Unless the incubator is still paused, all
+// queued epochs are then returned to be processed in order.
+func (u *utxoNursery) nextEpochs(
+	epoch *chainntnfs.BlockEpoch) []*chainntnfs.BlockEpoch {
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if epoch != nil {
+		u.pausedEpochs = append(u.pausedEpochs, epoch)
+	}
+
+	if u.paused {
+		if epoch != nil {
+			utxnLog.Infof("Incubator paused, deferring height=%d",
+				epoch.Height)
+		}
+		return nil
+	}
+
+	epochs := u.pausedEpochs
+	u.pausedEpochs = nil
+
+	return epochs
+}
+
 // IncubateOutputs sends a request to utxoNursery to incubate the outputs
 // defined within the summary of a closed channel. Individually, as all outputs
-// reach maturity, they'll be swept back into the wallet.
+// reach maturity, they'll be swept back into the wallet. If sweepPkScript is
+// non-empty, the outputs will instead be swept to the provided script. If
+// confDepth is non-zero, it overrides CommitConfDepth as the number of
+// confirmations the channel's commitment txn requires before its outputs are
+// promoted to the kindergarten, e.g. to guard high-value channels against
+// deeper reorgs.
+//
+// NOTE: Only the outgoing htlcs of the summary are incubated. Incoming htlcs
+// can't be swept via the success path, as the summary carries neither their
+// preimages nor the counterparty's signature for the second-level success
+// txn, and lnwallet.HtlcAcceptedSuccess has no witness generator.
 func (u *utxoNursery) IncubateOutputs(
-	closeSummary *lnwallet.ForceCloseSummary) error {
+	closeSummary *lnwallet.ForceCloseSummary, sweepPkScript []byte,
+	confDepth uint32) error {
+
+	if atomic.LoadUint32(&u.draining) == 1 {
+		return ErrNurseryDraining
+	}
+
+	// If configured, determine the fee rate against which we'll decide
+	// whether each output is worth incubating. If we're unable to estimate
+	// the fee rate, we incubate all outputs rather than risk abandoning
+	// outputs that would have been economical to sweep.
+	var economicalFeeRate btcutil.Amount
+	if u.cfg.EconomicalSweepThreshold > 0 {
+		_, feePerWeight, err := u.sweepFeeRate(u.cfg.SweepConfTarget)
+		if err != nil {
+			utxnLog.Warnf("Unable to estimate fee rate, incubating "+
+				"all outputs of Channel(%s): %v",
+				&closeSummary.ChanPoint, err)
+		} else {
+			economicalFeeRate = feePerWeight
+		}
+	}
+
+	// 1. Build all the spendable outputs that we will try to incubate.
+	req := u.newIncubationRequest(
+		closeSummary, sweepPkScript, confDepth, economicalFeeRate,
+	)
+
+	// If there are no outputs to incubate for this channel, we simply mark
+	// the channel as fully closed.
+	if len(req.kidOutputs) == 0 && len(req.htlcOutputs) == 0 {
+		utxnLog.Infof("Channel(%s) has no outputs to incubate, "+
+			"marking fully closed.", &closeSummary.ChanPoint)
+		u.recordDroppedOutputs(req)
+		return u.cfg.DB.MarkChanFullyClosed(&closeSummary.ChanPoint)
+	}
+
+	utxnLog.Infof("Incubating Channel(%s) num-commit-outputs=%d, "+
+		"num-htlcs=%d", &closeSummary.ChanPoint, len(req.kidOutputs),
+		len(req.htlcOutputs))
+
+	// 2. Persist the outputs we intended to sweep in the nursery store.
+	return u.beginIncubation(req)
+}
+
+// IncubateOutputsBatch behaves like IncubateOutputs for the summaries of many
+// channels that were force closed at once, e.g. because a peer went offline.
+// Rather than acquiring the nursery's mutex and writing to the nursery store
+// once per channel, the outputs of all channels are persisted within a single
+// write. All outputs are swept back into the wallet.
+func (u *utxoNursery) IncubateOutputsBatch(
+	closeSummaries []*lnwallet.ForceCloseSummary) error {
+
+	if atomic.LoadUint32(&u.draining) == 1 {
+		return ErrNurseryDraining
+	}
+
+	// As in IncubateOutputs, the fee rate used to decide whether each
+	// output is worth incubating is estimated once for all channels.
+	var economicalFeeRate btcutil.Amount
+	if u.cfg.EconomicalSweepThreshold > 0 {
+		_, feePerWeight, err := u.sweepFeeRate(u.cfg.SweepConfTarget)
+		if err != nil {
+			utxnLog.Warnf("Unable to estimate fee rate, incubating "+
+				"all outputs of %d channels: %v",
+				len(closeSummaries), err)
+		} else {
+			economicalFeeRate = feePerWeight
+		}
+	}
+
+	reqs := make([]*incubationRequest, 0, len(closeSummaries))
+	for _, closeSummary := range closeSummaries {
+		req := u.newIncubationRequest(
+			closeSummary, nil, 0, economicalFeeRate,
+		)
+
+		if len(req.kidOutputs) == 0 && len(req.htlcOutputs) == 0 {
+			utxnLog.Infof("Channel(%s) has no outputs to "+
+				"incubate, marking fully closed.",
+				&closeSummary.ChanPoint)
+			u.recordDroppedOutputs(req)
+
+			err := u.cfg.DB.MarkChanFullyClosed(
+				&closeSummary.ChanPoint,
+			)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		utxnLog.Infof("Incubating Channel(%s) "+
+			"num-commit-outputs=%d, num-htlcs=%d",
+			&closeSummary.ChanPoint, len(req.kidOutputs),
+			len(req.htlcOutputs))
+
+		reqs = append(reqs, req)
+	}
+
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	return u.beginIncubation(reqs...)
+}
+
+// newIncubationRequest builds the incubation request for the outputs of the
+// given force closed channel, omitting any outputs that are zero-valued or
+// uneconomical to sweep at the provided fee rate. The omitted outputs are
+// carried by the request, such that they're recorded as dropped within the
+// nursery store, along with the txid of the channel's force close txn, as the
+// request is incubated. A non-zero confDepth is persisted with the
+// outputs, overriding CommitConfDepth for the channel.
+func (u *utxoNursery) newIncubationRequest(
+	closeSummary *lnwallet.ForceCloseSummary, sweepPkScript []byte,
+	confDepth uint32, economicalFeeRate btcutil.Amount) *incubationRequest {
 
 	nHtlcs := len(closeSummary.HtlcResolutions)
 
 	var (
-		commOutput  *kidOutput
+		kidOutputs  []kidOutput
 		htlcOutputs = make([]babyOutput, 0, nHtlcs)
 	)
 
-	// 1. Build all the spendable outputs that we will try to incubate.
+	// Keep track of the outputs we choose not to incubate, so that their
+	// abandonment is recorded.
+	var dropped []droppedOutput
+	dropOutput := func(kid *kidOutput, reason dropReason) {
+		dropped = append(dropped, droppedOutput{
+			outpoint: *kid.OutPoint(),
+			amt:      kid.Amount(),
+			reason:   reason,
+		})
+	}
+
+	// addKidOutput incubates the given commitment output in the
+	// preschool, unless it's worth nothing or too small to be swept.
+	addKidOutput := func(kid kidOutput) {
+		switch {
+		// We'll skip any zero value'd outputs as this indicates we
+		// don't have a settled balance within the commitment
+		// transaction.
+		case kid.Amount() == 0:
+			dropOutput(&kid, dropZeroValue)
+
+		case !u.isEconomical(&kid, economicalFeeRate):
+			dropOutput(&kid, dropUneconomical)
+
+		default:
+			kid.feeBudget = u.feeBudget(kid.Amount())
+			kid.sweepPkScript = sweepPkScript
+			kid.sweepFeeRate = closeSummary.SweepFeeRate
+			kid.confDepth = confDepth
+			kidOutputs = append(kidOutputs, kid)
+		}
+	}
 
 	// It could be that our to-self output was below the dust limit. In that
 	// case the SignDescriptor would be nil and we would not have that
 	// output to incubate.
 	if closeSummary.SelfOutputSignDesc != nil {
-		selfOutput := makeKidOutput(
+		addKidOutput(makeKidOutput(
 			&closeSummary.SelfOutpoint,
 			&closeSummary.ChanPoint,
 			closeSummary.SelfOutputMaturity,
 			lnwallet.CommitmentTimeLock,
 			closeSummary.SelfOutputSignDesc,
-		)
+		))
+	}
 
-		// We'll skip any zero value'd outputs as this indicates we
-		// don't have a settled balance within the commitment
-		// transaction.
-		if selfOutput.Amount() > 0 {
-			commOutput = &selfOutput
-		}
+	// Our anchor output, if any, carries no relative delay, such that it
+	// matures as soon as the commitment txn confirms.
+	if anchorRes := closeSummary.AnchorResolution; anchorRes != nil {
+		addKidOutput(makeKidOutput(
+			&anchorRes.AnchorOutpoint,
+			&closeSummary.ChanPoint,
+			0,
+			lnwallet.CommitmentAnchor,
+			&anchorRes.AnchorSignDesc,
+		))
 	}
 
 	for i := range closeSummary.HtlcResolutions {
@@ -385,820 +1452,5950 @@ func (u *utxoNursery) IncubateOutputs(
 			&htlcRes,
 		)
 
-		if htlcOutput.Amount() > 0 {
+		switch {
+		case htlcOutput.Amount() == 0:
+			dropOutput(&htlcOutput.kidOutput, dropZeroValue)
+
+		case !u.isEconomical(&htlcOutput.kidOutput, economicalFeeRate):
+			dropOutput(&htlcOutput.kidOutput, dropUneconomical)
+
+		default:
+			htlcOutput.feeBudget = u.feeBudget(htlcOutput.Amount())
+			htlcOutput.sweepPkScript = sweepPkScript
+			htlcOutput.sweepFeeRate = closeSummary.SweepFeeRate
+			htlcOutput.confDepth = confDepth
 			htlcOutputs = append(htlcOutputs, htlcOutput)
 		}
-
 	}
 
-	// If there are no outputs to incubate for this channel, we simply mark
-	// the channel as fully closed.
-	if commOutput == nil && len(htlcOutputs) == 0 {
-		utxnLog.Infof("Channel(%s) has no outputs to incubate, "+
-			"marking fully closed.", &closeSummary.ChanPoint)
-		return u.cfg.DB.MarkChanFullyClosed(&closeSummary.ChanPoint)
+	req := &incubationRequest{
+		chanPoint:   closeSummary.ChanPoint,
+		kidOutputs:  kidOutputs,
+		htlcOutputs: htlcOutputs,
+		dropped:     dropped,
 	}
 
-	utxnLog.Infof("Incubating Channel(%s) has-commit=%v, num-htlcs=%d",
-		&closeSummary.ChanPoint, commOutput != nil, len(htlcOutputs))
+	// The txid of the force close txn from which the incubated outputs
+	// originate is recorded, such that the close can be looked up while
+	// the channel is being incubated.
+	hasOutputs := len(kidOutputs) > 0 || len(htlcOutputs) > 0
+	if hasOutputs && closeSummary.CloseTx != nil {
+		closeTxid := closeSummary.CloseTx.TxHash()
+		req.closeTxid = &closeTxid
+	}
 
-	u.mu.Lock()
-	defer u.mu.Unlock()
+	return req
+}
 
-	// 2. Persist the outputs we intended to sweep in the nursery store
-	if err := u.cfg.Store.Incubate(commOutput, htlcOutputs); err != nil {
-		utxnLog.Errorf("unable to begin incubation of Channel(%s): %v",
-			&closeSummary.ChanPoint, err)
-		return err
+// IncubateBreachOutputs sends a request to utxoNursery to incubate the
+// CSV-delayed outputs of a justice txn broadcast in response to a breach of the
+// provided channel. Each output is tracked as a preschool output that is
+// promoted once the justice txn confirms, and swept after blocksToMaturity
+// blocks have elapsed. If sweepPkScript is non-empty, the outputs will be
+// swept to the provided script rather than back into the wallet.
+//
+// NOTE: Unlike IncubateOutputs, the channel will not be marked fully closed if
+// none of the outputs are incubated, as closing a breached channel remains the
+// responsibility of the breach arbiter.
+func (u *utxoNursery) IncubateBreachOutputs(chanPoint *wire.OutPoint,
+	outputs []breachedOutput, blocksToMaturity uint32,
+	sweepPkScript []byte) error {
+
+	if atomic.LoadUint32(&u.draining) == 1 {
+		return ErrNurseryDraining
 	}
 
-	// 3. If we are incubating a preschool output, register for a
-	// confirmation notification that will transition it to the kindergarten
-	// bucket.
-	if commOutput != nil {
-		return u.registerCommitConf(commOutput, u.bestHeight)
+	// If configured, determine the fee rate against which we'll decide
+	// whether each output is worth incubating.
+	var economicalFeeRate btcutil.Amount
+	if u.cfg.EconomicalSweepThreshold > 0 {
+		_, feePerWeight, err := u.sweepFeeRate(u.cfg.SweepConfTarget)
+		if err != nil {
+			utxnLog.Warnf("Unable to estimate fee rate, incubating "+
+				"all breach outputs of Channel(%s): %v",
+				chanPoint, err)
+		} else {
+			economicalFeeRate = feePerWeight
+		}
 	}
 
-	return nil
-}
+	var (
+		kids    []kidOutput
+		dropped []droppedOutput
+	)
+	for i := range outputs {
+		bo := &outputs[i]
 
-// NurseryReport attempts to return a nursery report stored for the target
-// outpoint. A nursery report details the maturity/sweeping progress for a
-// contract that was previously force closed. If a report entry for the target
-// chanPoint is unable to be constructed, then an error will be returned.
-func (u *utxoNursery) NurseryReport(
-	chanPoint *wire.OutPoint) (*contractMaturityReport, error) {
+		kid := makeKidOutput(
+			&bo.outpoint, chanPoint, blocksToMaturity,
+			bo.witnessType, &bo.signDesc,
+		)
 
-	u.mu.Lock()
-	defer u.mu.Unlock()
+		var reason dropReason
+		switch {
+		case kid.Amount() == 0:
+			reason = dropZeroValue
 
-	utxnLog.Infof("NurseryReport: building nursery report for channel %v",
-		chanPoint)
+		case !u.isEconomical(&kid, economicalFeeRate):
+			reason = dropUneconomical
 
-	report := &contractMaturityReport{
-		chanPoint: *chanPoint,
+		default:
+			kid.feeBudget = u.feeBudget(kid.Amount())
+			kid.sweepPkScript = sweepPkScript
+			kids = append(kids, kid)
+			continue
+		}
+
+		dropped = append(dropped, droppedOutput{
+			outpoint: *kid.OutPoint(),
+			amt:      kid.Amount(),
+			reason:   reason,
+		})
 	}
 
-	if err := u.cfg.Store.ForChanOutputs(chanPoint, func(k, v []byte) error {
-		switch {
-		case bytes.HasPrefix(k, cribPrefix):
-			// Cribs outputs are the only kind currently stored as
-			// baby outputs.
-			var baby babyOutput
-			err := baby.Decode(bytes.NewReader(v))
-			if err != nil {
-				return err
-			}
+	// If none of the outputs are incubated, the dropped outputs are
+	// recorded on their own.
+	if len(kids) == 0 {
+		u.recordDroppedOutputs(&incubationRequest{
+			chanPoint: *chanPoint,
+			dropped:   dropped,
+		})
+		return nil
+	}
 
-			// Each crib output represents a stage one htlc, and
-			// will contribute towards the limbo balance.
-			report.AddLimboStage1Htlc(&baby)
+	utxnLog.Infof("Incubating %d breach outputs of Channel(%s)",
+		len(kids), chanPoint)
 
-		case bytes.HasPrefix(k, psclPrefix),
-			bytes.HasPrefix(k, kndrPrefix),
-			bytes.HasPrefix(k, gradPrefix):
+	// The outputs are incubated by a single request, such that they're
+	// persisted atomically along with the dropped outputs.
+	return u.beginIncubation(&incubationRequest{
+		chanPoint:  *chanPoint,
+		kidOutputs: kids,
+		dropped:    dropped,
+	})
+}
 
-			// All others states can be deserialized as kid outputs.
-			var kid kidOutput
-			err := kid.Decode(bytes.NewReader(v))
-			if err != nil {
-				return err
-			}
+// recordDroppedOutputs records the dropped outputs of an incubation request
+// that has no outputs to incubate, such that it's clear where their funds
+// went. A failure to do so is logged, as there's nothing to incubate.
+func (u *utxoNursery) recordDroppedOutputs(req *incubationRequest) {
+	if len(req.dropped) == 0 {
+		return
+	}
 
-			// Now, use the state prefixes to determine how the this
-			// output should be represented in the nursery report.
-			// An output's funds are always in limbo until reaching
-			// the graduate state.
-			switch {
-			case bytes.HasPrefix(k, psclPrefix):
-				// Preschool outputs are awaiting the
-				// confirmation of the commitment transaction.
-				report.AddLimboCommitment(&kid)
+	u.mu.Lock()
+	defer u.mu.Unlock()
 
-			case bytes.HasPrefix(k, kndrPrefix):
-				// Kindergarten outputs may originate from
-				// either the commitment transaction or an htlc.
-				// We can distinguish them via their witness
-				// types.
-				switch kid.WitnessType() {
-				case lnwallet.CommitmentTimeLock:
-					// The commitment transaction has been
-					// confirmed, and we are waiting the CSV
-					// delay to expire.
-					report.AddLimboCommitment(&kid)
+	err := u.cfg.Store.RecordDroppedOutputs(&req.chanPoint, req.dropped)
+	if err != nil {
+		utxnLog.Errorf("Unable to record %d dropped outputs of "+
+			"Channel(%s): %v", len(req.dropped), &req.chanPoint,
+			err)
+	}
+}
 
-				case lnwallet.HtlcOfferedTimeout:
-					// The htlc timeout transaction has
-					// confirmed, and the CSV delay has
-					// begun ticking.
-					report.AddLimboStage2Htlc(&kid)
-				}
+// CancelIncubation stops the incubation of the given channel, for use when its
+// force close has been superseded, e.g. by a cooperative close, or because the
+// commitment txn was reorged out of the chain. The confirmation watchers of
+// the channel's outputs are stopped, and all of its outputs are removed from
+// the nursery store without the channel being marked fully closed. If any of
+// the channel's kindergarten outputs have been included in a finalized sweep
+// txn, ErrSweepFinalized is returned and the incubation continues.
+func (u *utxoNursery) CancelIncubation(chanPoint *wire.OutPoint) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
 
-			case bytes.HasPrefix(k, gradPrefix):
-				// Graduate outputs are those whose funds have
-				// been swept back into the wallet. Each output
-				// will contribute towards the recovered
-				// balance.
-				switch kid.WitnessType() {
-				case lnwallet.CommitmentTimeLock:
-					// The commitment output was
-					// successfully swept back into a
-					// regular p2wkh output.
-					report.AddRecoveredCommitment(&kid)
+	wasPending := u.dropPendingIncubations(chanPoint)
 
-				case lnwallet.HtlcOfferedTimeout:
-					// This htlc output successfully resides
-					// in a p2wkh output belonging to the
-					// user.
-					report.AddRecoveredHtlc(&kid)
-				}
-			}
+	// Collect the outpoints of the channel's outputs, such that we can
+	// clear any state we hold for them once they are removed.
+	outpoints, err := u.chanOutPoints(chanPoint)
+	switch {
+	case err == ErrContractNotFound && wasPending:
+		utxnLog.Infof("Canceled pending incubation of Channel(%s)",
+			chanPoint)
+		return nil
 
-		default:
-		}
+	case err != nil:
+		return err
+	}
 
-		return nil
-	}); err != nil {
-		return nil, err
+	if err := u.cfg.Store.CancelChannel(chanPoint); err != nil {
+		return err
 	}
 
-	return report, nil
+	u.stopChanIncubation(chanPoint, outpoints)
+
+	utxnLog.Infof("Canceled incubation of Channel(%s), removed %d "+
+		"outputs", chanPoint, len(outpoints))
+
+	return nil
 }
 
-// reloadPreschool re-initializes the chain notifier with all of the outputs
-// that had been saved to the "preschool" database bucket prior to shutdown.
-func (u *utxoNursery) reloadPreschool(heightHint uint32) error {
-	psclOutputs, err := u.cfg.Store.FetchPreschools()
-	if err != nil {
+// MarkChannelResolved graduates all of the given channel's outputs without
+// sweeping them, for operator-assisted recovery of channels whose outputs were
+// swept out-of-band, e.g. by an external tool. The confirmation watchers of
+// the channel's outputs are stopped, after which the channel is marked fully
+// closed and removed from the nursery store. If any of the channel's
+// kindergarten outputs have been included in a finalized sweep txn,
+// ErrSweepFinalized is returned and the incubation continues.
+func (u *utxoNursery) MarkChannelResolved(chanPoint *wire.OutPoint) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	wasPending := u.dropPendingIncubations(chanPoint)
+
+	outpoints, err := u.chanOutPoints(chanPoint)
+	switch {
+	case err == ErrContractNotFound && wasPending:
+		utxnLog.Infof("Dropped pending incubation of resolved "+
+			"Channel(%s)", chanPoint)
+		return nil
+
+	case err != nil:
 		return err
 	}
 
-	for i := range psclOutputs {
-		err := u.registerCommitConf(&psclOutputs[i], heightHint)
+	if err := u.cfg.Store.ForceGraduateChannel(chanPoint); err != nil {
+		return err
+	}
+
+	u.stopChanIncubation(chanPoint, outpoints)
+
+	utxnLog.Infof("Marked Channel(%s) as resolved, graduated %d outputs",
+		chanPoint, len(outpoints))
+
+	return u.closeAndRemoveIfMature(chanPoint)
+}
+
+// dropPendingIncubations removes any incubation of the given channel that has
+// yet to be persisted, returning true if one was found.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) dropPendingIncubations(chanPoint *wire.OutPoint) bool {
+	var (
+		stillPending []*incubationRequest
+		wasPending   bool
+	)
+	for _, req := range u.pendingIncubations {
+		if req.chanPoint == *chanPoint {
+			wasPending = true
+			continue
+		}
+		stillPending = append(stillPending, req)
+	}
+	u.pendingIncubations = stillPending
+
+	return wasPending
+}
+
+// chanOutPoints returns the outpoints of all outputs the nursery store holds
+// for the given channel, regardless of their state.
+func (u *utxoNursery) chanOutPoints(
+	chanPoint *wire.OutPoint) ([]wire.OutPoint, error) {
+
+	var outpoints []wire.OutPoint
+	err := u.cfg.Store.ForChanOutputs(chanPoint, func(k, _ []byte) error {
+		var outpoint wire.OutPoint
+		err := readOutpoint(bytes.NewReader(k[4:]), &outpoint)
 		if err != nil {
 			return err
 		}
+
+		outpoints = append(outpoints, outpoint)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return outpoints, nil
 }
 
-// reloadClasses reinitializes any height-dependent state transitions for which
-// the utxonursery has not recevied confirmation, and replays the graduation of
-// all kindergarten and crib outputs for heights that have not been finalized.
-// This allows the nursery to reinitialize all state to continue sweeping
-// outputs, even in the event that we missed blocks while offline. reloadClasses
-// is called during the startup of the UTXO Nursery.
-func (u *utxoNursery) reloadClasses(lastGradHeight uint32) error {
-	// Begin by loading all of the still-active heights up to and including
-	// the last height we successfully graduated.
-	activeHeights, err := u.cfg.Store.HeightsBelowOrEqual(lastGradHeight)
-	if err != nil {
-		return err
+// stopChanIncubation stops the confirmation watchers of the given channel, and
+// clears any state held for the provided outpoints of its outputs, once the
+// channel's outputs no longer await incubation.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) stopChanIncubation(chanPoint *wire.OutPoint,
+	outpoints []wire.OutPoint) {
+
+	if canceled, ok := u.chanCancels[*chanPoint]; ok {
+		close(canceled)
+		delete(u.chanCancels, *chanPoint)
 	}
 
-	if len(activeHeights) > 0 {
-		utxnLog.Infof("Re-registering confirmations for %d already "+
-			"graduated heights below height=%d", len(activeHeights),
-			lastGradHeight)
+	for _, outpoint := range outpoints {
+		delete(u.handoffSpends, outpoint)
 	}
+	delete(u.finalConfHeights, *chanPoint)
 
-	// Attempt to re-register notifications for any outputs still at these
-	// heights.
-	for _, classHeight := range activeHeights {
-		utxnLog.Debugf("Attempting to regraduate outputs at height=%v",
-			classHeight)
+	u.dropQueuedCommitConfs(chanPoint)
 
-		if err = u.regraduateClass(classHeight); err != nil {
-			utxnLog.Errorf("Failed to regraduate outputs at "+
-				"height=%v: %v", classHeight, err)
-			return err
-		}
+	u.updateLimboBalance()
+}
+
+// chanCancel returns the channel that is closed if the incubation of the given
+// channel is canceled, creating it if necessary.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex, or
+// during startup.
+func (u *utxoNursery) chanCancel(chanPoint *wire.OutPoint) chan struct{} {
+	canceled, ok := u.chanCancels[*chanPoint]
+	if !ok {
+		canceled = make(chan struct{})
+		u.chanCancels[*chanPoint] = canceled
 	}
 
-	// Get the most recently mined block.
-	_, bestHeight, err := u.cfg.ChainIO.GetBestBlock()
-	if err != nil {
-		return err
+	return canceled
+}
+
+// beginIncubation persists the outputs of the incubation requests in the
+// nursery store, retrying with an exponential backoff in case of transient
+// failures. If the requests still can't be persisted, an error is returned,
+// as the requests would be lost upon restart. They are nonetheless queued such
+// that the incubator can try again once the next block arrives, in case the
+// caller doesn't retry.
+func (u *utxoNursery) beginIncubation(reqs ...*incubationRequest) error {
+	desc := fmt.Sprintf("Channel(%s)", &reqs[0].chanPoint)
+	if len(reqs) > 1 {
+		desc = fmt.Sprintf("%d channels", len(reqs))
 	}
 
-	// If we haven't yet seen any registered force closes, or we're already
-	// caught up with the current best chain, then we can exit early.
-	if lastGradHeight == 0 || uint32(bestHeight) == lastGradHeight {
-		return nil
+	err := u.retryLocked(
+		fmt.Sprintf("begin incubation of %s", desc),
+		u.cfg.IncubateRetries, u.cfg.IncubateRetryBackoff,
+		func() error {
+			return u.incubate(reqs...)
+		},
+	)
+	if err == nil || err == ErrNurseryShuttingDown {
+		return err
 	}
 
-	utxnLog.Infof("Processing outputs from missed blocks. Starting with "+
-		"blockHeight=%v, to current blockHeight=%v", lastGradHeight,
-		bestHeight)
+	// We were unable to persist the incubation requests, queue them so
+	// that the incubator can try again once the next block arrives.
+	// Since the queue only lives in memory, the caller must still be told
+	// of the failure, such that it can retry after a restart. Repeated
+	// requests are deduplicated once persisted.
+	utxnLog.Errorf("Unable to begin incubation of %s, will retry at "+
+		"next block: %v", desc, err)
 
-	// Loop through and check for graduating outputs at each of the missed
-	// block heights.
-	for curHeight := lastGradHeight + 1; curHeight <= uint32(bestHeight); curHeight++ {
-		utxnLog.Debugf("Attempting to graduate outputs at height=%v",
-			curHeight)
+	u.mu.Lock()
+	u.pendingIncubations = append(u.pendingIncubations, reqs...)
+	u.mu.Unlock()
 
-		if err := u.graduateClass(curHeight); err != nil {
-			utxnLog.Errorf("Failed to graduate outputs at "+
-				"height=%v: %v", curHeight, err)
+	return fmt.Errorf("unable to persist incubation of %s: %v", desc, err)
+}
+
+// retryLocked executes f while holding the nursery's mutex, retrying up to the
+// given number of times with an exponential backoff if it fails. The mutex is
+// released between attempts, such that the nursery can make progress
+// elsewhere. If all attempts fail, the last error is returned. If the nursery
+// shuts down while waiting to retry, ErrNurseryShuttingDown is returned, while
+// errIncubationCanceled is returned without retrying.
+func (u *utxoNursery) retryLocked(desc string, retries uint32,
+	backoff time.Duration, f func() error) error {
+
+	var err error
+	for i := uint32(0); i <= retries; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(backoff):
+				backoff *= 2
+			case <-u.quit:
+				return ErrNurseryShuttingDown
+			}
+		}
+
+		u.mu.Lock()
+		err = f()
+		u.mu.Unlock()
+		if err == nil || err == errIncubationCanceled {
 			return err
 		}
-	}
 
-	utxnLog.Infof("UTXO Nursery is now fully synced")
+		utxnLog.Warnf("Unable to %s, attempt %d: %v", desc, i+1, err)
+	}
 
-	return nil
+	return err
 }
 
-// regraduateClass handles the steps involved in re-registering for
-// confirmations for all still-active outputs at a particular height. This is
-// used during restarts to ensure that any still-pending state transitions are
-// properly registered, so they can be driven by the chain notifier. No
-// transactions or signing are done as a result of this step.
-func (u *utxoNursery) regraduateClass(classHeight uint32) error {
-	// Fetch all information about the crib and kindergarten outputs at this
-	// height. In addition to the outputs, we also retrieve the finalized
-	// kindergarten sweep txn, which will be nil if we have not attempted
-	// this height before, or if no kindergarten outputs exist at this
-	// height.
-	finalTx, kgtnOutputs, cribOutputs, err := u.cfg.Store.FetchClass(
-		classHeight)
-	if err != nil {
-		return err
+// feeBudget computes the maximum fee that an output of the given value may
+// contribute towards its sweep transaction, as determined by the nursery's
+// configured budgets. A zero value indicates the output has no budget.
+func (u *utxoNursery) feeBudget(amt btcutil.Amount) btcutil.Amount {
+	budget := u.cfg.OutputFeeBudget
+
+	if u.cfg.OutputFeeBudgetRatio > 0 {
+		ratioBudget := btcutil.Amount(
+			float64(amt) * u.cfg.OutputFeeBudgetRatio,
+		)
+		if budget == 0 || ratioBudget < budget {
+			budget = ratioBudget
+		}
 	}
 
-	if finalTx != nil {
-		utxnLog.Infof("Re-registering confirmation for kindergarten "+
-			"sweep transaction at height=%d ", classHeight)
+	return budget
+}
+
+// incubationRequest holds the outputs of a force closed channel that are to be
+// incubated by the nursery.
+type incubationRequest struct {
+	// chanPoint is the channel point of the force closed channel.
+	chanPoint wire.OutPoint
+
+	// kidOutputs are the outputs that await the confirmation of the txn
+	// creating them in the preschool, i.e. our commitment and anchor
+	// outputs, or the outputs of a justice txn.
+	kidOutputs []kidOutput
+
+	// htlcOutputs are the outgoing htlc outputs in the commitment txn.
+	htlcOutputs []babyOutput
+
+	// dropped are the outputs of the channel that were abandoned rather
+	// than incubated, which are recorded along with the incubated
+	// outputs.
+	dropped []droppedOutput
 
-		err = u.registerSweepConf(finalTx, kgtnOutputs, classHeight)
+	// closeTxid is the txid of the channel's force close txn, if known,
+	// which is recorded along with the incubated outputs.
+	closeTxid *chainhash.Hash
+}
+
+// incubate persists the outputs of the incubation requests in the nursery
+// store within a single write, and registers for the confirmation of each
+// commitment output.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) incubate(reqs ...*incubationRequest) error {
+	var (
+		pending []*incubationRequest
+		kids    []*kidOutput
+		babies  []babyOutput
+	)
+	for _, req := range reqs {
+		req, err := u.prepareIncubation(req)
 		if err != nil {
-			utxnLog.Errorf("Failed to re-register for kindergarten "+
-				"sweep transaction at height=%d: %v",
-				classHeight, err)
 			return err
 		}
+		if req == nil {
+			continue
+		}
+
+		// The height at which each output enters the nursery is
+		// persisted along with it, such that the time spent in the
+		// crib or preschool state can be reported across restarts.
+		for i := range req.kidOutputs {
+			req.kidOutputs[i].entryHeight = u.bestHeight
+		}
+		for i := range req.htlcOutputs {
+			req.htlcOutputs[i].entryHeight = u.bestHeight
+		}
+
+		pending = append(pending, req)
+		for i := range req.kidOutputs {
+			kids = append(kids, &req.kidOutputs[i])
+		}
+		babies = append(babies, req.htlcOutputs...)
 	}
 
-	if len(cribOutputs) == 0 {
+	if len(pending) == 0 {
 		return nil
 	}
 
-	utxnLog.Infof("Re-registering confirmation for first-stage HTLC "+
-		"outputs at height=%d ", classHeight)
-
-	// Now, we broadcast all pre-signed htlc txns from the crib outputs at
-	// this height. There is no need to finalize these txns, since the txid
-	// is predetermined when signed in the wallet.
-	for i := range cribOutputs {
-		err = u.registerTimeoutConf(&cribOutputs[i], classHeight)
-		if err != nil {
-			utxnLog.Errorf("Failed to re-register first-stage "+
-				"HTLC output %v", cribOutputs[i].OutPoint())
+	// The dropped outputs and close txid of each channel are persisted
+	// atomically with its outputs, and only once the request has been
+	// found not to be a duplicate.
+	err := u.cfg.Store.Update(func(store NurseryStore) error {
+		if err := store.IncubateBatch(kids, babies); err != nil {
 			return err
 		}
+
+		for _, req := range pending {
+			err := recordIncubationDetails(store, req)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	u.updateLimboBalance()
+
+	// Now that the outputs have been persisted, begin tracking each of the
+	// channels. A failure to do so for one channel shouldn't prevent the
+	// others from being tracked.
+	var trackErr error
+	for _, req := range pending {
+		err := u.trackIncubation(req)
+		if err != nil && trackErr == nil {
+			trackErr = err
+		}
+	}
+
+	return trackErr
+}
+
+// recordIncubationDetails persists the dropped outputs and close txid of the
+// incubation request within the provided store, which is bound to the store
+// transaction incubating the request's outputs.
+func recordIncubationDetails(store NurseryStore,
+	req *incubationRequest) error {
+
+	if len(req.dropped) > 0 {
+		err := store.RecordDroppedOutputs(&req.chanPoint, req.dropped)
+		if err != nil {
+			return fmt.Errorf("unable to record %d dropped outputs "+
+				"of Channel(%s): %v", len(req.dropped),
+				&req.chanPoint, err)
+		}
+	}
+
+	if req.closeTxid != nil {
+		err := store.RecordChannelCloseTx(&req.chanPoint, req.closeTxid)
+		if err != nil {
+			return fmt.Errorf("unable to record close txid %v of "+
+				"Channel(%s): %v", req.closeTxid,
+				&req.chanPoint, err)
+		}
+	}
+
+	return nil
+}
+
+// prepareIncubation returns the incubation request with any outputs already
+// tracked by the nursery store omitted, and any overdue htlc outputs
+// rescheduled for the next block. If none of the request's outputs remain, nil
+// is returned.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) prepareIncubation(
+	req *incubationRequest) (*incubationRequest, error) {
+
+	// If the channel is already being tracked, e.g. because the caller
+	// retried a request that had already succeeded, we only incubate the
+	// outputs that aren't yet tracked. Otherwise, we'd double count their
+	// limbo balance and watch for their confirmations twice.
+	exists, err := u.cfg.Store.ChannelExists(&req.chanPoint)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		req, err = u.untrackedOutputs(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(req.kidOutputs) == 0 && len(req.htlcOutputs) == 0 {
+			utxnLog.Infof("Channel(%s) is already incubating, "+
+				"ignoring duplicate request", &req.chanPoint)
+			return nil, nil
+		}
+	}
+
+	// If the timelock of any htlc output has already expired, its crib
+	// height would never be revisited by the nursery. We'll schedule such
+	// outputs for the next block instead.
+	for i := range req.htlcOutputs {
+		baby := &req.htlcOutputs[i]
+		if u.bestHeight == 0 || baby.expiry > u.bestHeight {
+			continue
+		}
+
+		utxnLog.Warnf("Found overdue htlc output %v with expiry=%d at "+
+			"best height=%d, scheduling broadcast at height=%d",
+			baby.OutPoint(), baby.expiry, u.bestHeight,
+			u.bestHeight+1)
+
+		baby.expiry = u.bestHeight + 1
+	}
+
+	return req, nil
+}
+
+// trackIncubation begins tracking the outputs of an incubation request that
+// have been persisted in the nursery store, and registers for the confirmation
+// of the preschool outputs if present.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) trackIncubation(req *incubationRequest) error {
+	if len(req.kidOutputs) > 0 {
+		u.cfg.Metrics.AddOutputsIncubated(
+			string(psclPrefix), len(req.kidOutputs),
+		)
+	}
+	if len(req.htlcOutputs) > 0 {
+		u.cfg.Metrics.AddOutputsIncubated(
+			string(cribPrefix), len(req.htlcOutputs),
+		)
+	}
+
+	for i := range req.kidOutputs {
+		u.notifyEvent(newOutputEvent(
+			NurseryEventIncubated, psclPrefix, &req.kidOutputs[i],
+		))
+	}
+	for i := range req.htlcOutputs {
+		u.notifyEvent(newOutputEvent(
+			NurseryEventIncubated, cribPrefix,
+			&req.htlcOutputs[i].kidOutput,
+		))
+	}
+
+	// If configured, hand off the outputs to an external service. We
+	// continue to track the outputs locally regardless of the outcome.
+	if u.cfg.HandoffOutputs != nil {
+		if err := u.handoffOutputs(req); err != nil {
+			utxnLog.Errorf("Unable to hand off outputs of "+
+				"Channel(%s): %v", &req.chanPoint, err)
+		}
+	}
+
+	// If we are incubating preschool outputs, register for confirmation
+	// notifications that will transition them to the kindergarten bucket.
+	for i := range req.kidOutputs {
+		err := u.registerCommitConf(&req.kidOutputs[i], u.bestHeight)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// untrackedOutputs returns a copy of the provided incubation request, omitting
+// any outputs that the nursery store already tracks in some state for the
+// request's channel.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) untrackedOutputs(
+	req *incubationRequest) (*incubationRequest, error) {
+
+	// Each output is keyed by its state prefix followed by its outpoint,
+	// the latter of which remains the same as it changes state.
+	tracked := make(map[wire.OutPoint]struct{})
+	err := u.cfg.Store.ForChanOutputs(&req.chanPoint, func(k, _ []byte) error {
+		var outpoint wire.OutPoint
+		err := readOutpoint(bytes.NewReader(k[4:]), &outpoint)
+		if err != nil {
+			return err
+		}
+		tracked[outpoint] = struct{}{}
+
+		return nil
+	})
+	if err != nil && err != ErrContractNotFound {
+		return nil, err
+	}
+
+	untracked := &incubationRequest{
+		chanPoint: req.chanPoint,
+	}
+	for i := range req.kidOutputs {
+		if _, ok := tracked[*req.kidOutputs[i].OutPoint()]; ok {
+			continue
+		}
+		untracked.kidOutputs = append(
+			untracked.kidOutputs, req.kidOutputs[i],
+		)
+	}
+	for i := range req.htlcOutputs {
+		if _, ok := tracked[*req.htlcOutputs[i].OutPoint()]; ok {
+			continue
+		}
+		untracked.htlcOutputs = append(
+			untracked.htlcOutputs, req.htlcOutputs[i],
+		)
+	}
+
+	return untracked, nil
+}
+
+// handoffOutputs delivers the outputs of an incubation request to the
+// configured HandoffOutputs callback, and registers for spend notifications of
+// each output so that the nursery can detect when they have been swept by the
+// external service.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) handoffOutputs(req *incubationRequest) error {
+	outputs := make(
+		[]HandoffOutput, 0, len(req.kidOutputs)+len(req.htlcOutputs),
+	)
+	for i := range req.kidOutputs {
+		kid := &req.kidOutputs[i]
+		outputs = append(outputs, HandoffOutput{
+			OutPoint:         *kid.OutPoint(),
+			OriginChanPoint:  *kid.OriginChanPoint(),
+			Amount:           kid.Amount(),
+			WitnessType:      kid.WitnessType(),
+			SignDesc:         *kid.SignDesc(),
+			BlocksToMaturity: kid.BlocksToMaturity(),
+		})
+	}
+	for i := range req.htlcOutputs {
+		baby := &req.htlcOutputs[i]
+		outputs = append(outputs, HandoffOutput{
+			OutPoint:         *baby.OutPoint(),
+			OriginChanPoint:  *baby.OriginChanPoint(),
+			Amount:           baby.Amount(),
+			WitnessType:      baby.WitnessType(),
+			SignDesc:         *baby.SignDesc(),
+			BlocksToMaturity: baby.BlocksToMaturity(),
+			Expiry:           baby.expiry,
+			TimeoutTx:        baby.timeoutTx,
+		})
+	}
+
+	if err := u.cfg.HandoffOutputs(outputs); err != nil {
+		return err
+	}
+
+	utxnLog.Infof("Handed off %d outputs of Channel(%s)", len(outputs),
+		&req.chanPoint)
+
+	for i := range outputs {
+		outpoint := outputs[i].OutPoint
+		spendEvent, err := u.cfg.Notifier.RegisterSpendNtfn(
+			&outpoint, u.bestHeight,
+		)
+		if err != nil {
+			return err
+		}
+
+		u.wg.Add(1)
+		go u.waitForHandoffSpend(outpoint, spendEvent)
+	}
+
+	return nil
+}
+
+// waitForHandoffSpend waits for a handed off output to be spent, and records
+// the txid of the spending transaction.
+// NOTE: This method MUST be called as a go routine.
+func (u *utxoNursery) waitForHandoffSpend(outpoint wire.OutPoint,
+	spendEvent *chainntnfs.SpendEvent) {
+
+	defer u.wg.Done()
+
+	select {
+	case spendDetail, ok := <-spendEvent.Spend:
+		if !ok {
+			utxnLog.Errorf("Notification chan closed, can't detect "+
+				"spend of handed off output %v", outpoint)
+			return
+		}
+
+		u.mu.Lock()
+		u.handoffSpends[outpoint] = *spendDetail.SpenderTxHash
+		u.mu.Unlock()
+
+	case <-u.quit:
+		spendEvent.Cancel()
+	}
+}
+
+// sweptExternally returns the first kindergarten output spent by the given
+// sweep txn that was handed off, and has already been spent by a different
+// transaction, or nil if none were.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex, or
+// during startup.
+func (u *utxoNursery) sweptExternally(sweepTx *wire.MsgTx,
+	kgtnOutputs []kidOutput) *kidOutput {
+
+	sweepTxID := sweepTx.TxHash()
+	for _, output := range spentKinders(sweepTx, kgtnOutputs) {
+		kid := output.(*kidOutput)
+
+		spenderTxID, ok := u.handoffSpends[*kid.OutPoint()]
+		if ok && spenderTxID != sweepTxID {
+			return kid
+		}
+	}
+
+	return nil
+}
+
+// rewatchHandoffs re-registers for the spend notifications of the outputs
+// handed off via the HandoffOutputs callback, as the spends detected prior to
+// a restart are only held in memory. The outputs aren't handed off again, as
+// the external service is expected to retain them.
+//
+// NOTE: This method MUST be called during startup.
+func (u *utxoNursery) rewatchHandoffs(heightHint uint32) error {
+	if u.cfg.HandoffOutputs == nil {
+		return nil
+	}
+
+	chanPoints, err := u.cfg.Store.ListChannels()
+	if err != nil {
+		return err
+	}
+
+	hints := make(map[wire.OutPoint]uint32)
+	for i := range chanPoints {
+		err := u.handoffSpendHints(&chanPoints[i], heightHint, hints)
+		if err != nil {
+			return err
+		}
+	}
+
+	for outpoint, hint := range hints {
+		outpoint := outpoint
+
+		spendEvent, err := u.cfg.Notifier.RegisterSpendNtfn(
+			&outpoint, hint,
+		)
+		if err != nil {
+			return err
+		}
+
+		u.wg.Add(1)
+		go u.waitForHandoffSpend(outpoint, spendEvent)
+	}
+
+	if len(hints) > 0 {
+		utxnLog.Infof("Rewatching %d handed off outputs", len(hints))
+	}
+
+	return nil
+}
+
+// handoffSpendHints adds the outputs of the given channel that we may still
+// sweep to the provided map, along with the height hint for their spend
+// notifications. The confirmation height of each output is used as its hint,
+// falling back to the provided height hint for outputs that have yet to
+// confirm. Corrupt outputs are logged and skipped.
+func (u *utxoNursery) handoffSpendHints(chanPoint *wire.OutPoint,
+	heightHint uint32, hints map[wire.OutPoint]uint32) error {
+
+	err := u.cfg.Store.ForChanOutputs(chanPoint, func(k, v []byte) error {
+		var (
+			kid kidOutput
+			err error
+		)
+		switch {
+		case bytes.HasPrefix(k, cribPrefix):
+			var baby babyOutput
+			err = baby.Decode(bytes.NewReader(v))
+			if err == nil {
+				err = baby.validate()
+			}
+			kid = baby.kidOutput
+
+		case bytes.HasPrefix(k, psclPrefix),
+			bytes.HasPrefix(k, kndrPrefix):
+
+			err = kid.Decode(bytes.NewReader(v))
+			if err == nil {
+				err = kid.validate()
+			}
+
+		// Graduated and resolved outputs can no longer be swept by us.
+		default:
+			return nil
+		}
+		if err != nil {
+			utxnLog.Errorf("Unable to rewatch corrupt output %x of "+
+				"Channel(%s): %v", k[4:], chanPoint, err)
+			return nil
+		}
+
+		hints[*kid.OutPoint()] = heightHint
+		if kid.ConfHeight() != 0 {
+			hints[*kid.OutPoint()] = kid.ConfHeight()
+		}
+
+		return nil
+	})
+	if err != nil && err != ErrContractNotFound {
+		return err
+	}
+
+	return nil
+}
+
+// retryPendingIncubations attempts to persist any incubation requests that
+// previously failed to be written to the nursery store. Requests that fail
+// again remain queued until the next attempt.
+func (u *utxoNursery) retryPendingIncubations() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var stillPending []*incubationRequest
+	for _, req := range u.pendingIncubations {
+		if err := u.incubate(req); err != nil {
+			utxnLog.Errorf("Unable to begin incubation of "+
+				"Channel(%s), will retry at next block: %v",
+				&req.chanPoint, err)
+
+			stillPending = append(stillPending, req)
+			continue
+		}
+
+		utxnLog.Infof("Incubation of Channel(%s) succeeded after "+
+			"retry", &req.chanPoint)
+	}
+
+	u.pendingIncubations = stillPending
+}
+
+// NurseryReport attempts to return a nursery report stored for the target
+// outpoint. A nursery report details the maturity/sweeping progress for a
+// contract that was previously force closed. If a report entry for the target
+// chanPoint is unable to be constructed, then an error will be returned.
+func (u *utxoNursery) NurseryReport(
+	chanPoint *wire.OutPoint) (*contractMaturityReport, error) {
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	utxnLog.Infof("NurseryReport: building nursery report for channel %v",
+		chanPoint)
+
+	return u.nurseryReport(chanPoint)
+}
+
+// AllNurseryReports returns a nursery report for each channel currently being
+// incubated by the nursery, keyed by channel point. This allows all in-progress
+// recoveries to be enumerated without knowing their channel points up front.
+func (u *utxoNursery) AllNurseryReports() (
+	map[wire.OutPoint]*contractMaturityReport, error) {
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	chanPoints, err := u.cfg.Store.ListChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	utxnLog.Infof("AllNurseryReports: building nursery reports for %d "+
+		"channels", len(chanPoints))
+
+	reports := make(map[wire.OutPoint]*contractMaturityReport,
+		len(chanPoints))
+	for i := range chanPoints {
+		report, err := u.nurseryReport(&chanPoints[i])
+		if err != nil {
+			return nil, err
+		}
+		reports[chanPoints[i]] = report
+	}
+
+	return reports, nil
+}
+
+// nurseryReport builds the nursery report for the target channel point.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) nurseryReport(
+	chanPoint *wire.OutPoint) (*contractMaturityReport, error) {
+
+	_, awaitingFinalConfs := u.finalConfHeights[*chanPoint]
+	report := &contractMaturityReport{
+		chanPoint:          *chanPoint,
+		awaitingFinalConfs: awaitingFinalConfs,
+		byWitnessType:      make(map[lnwallet.WitnessType]int),
+	}
+
+	// Track the outstanding kindergarten outputs, so that we can estimate
+	// the fee required to sweep them.
+	var kgtnOutputs []kidOutput
+
+	// Crib outputs are only added to the report once all other outputs
+	// have been visited, such that we can skip any htlc that has already
+	// been counted in a later stage.
+	var (
+		cribOutputs []babyOutput
+		kidStates   = make(map[wire.OutPoint]struct{})
+	)
+
+	if err := u.cfg.Store.ForChanOutputs(chanPoint, func(k, v []byte) error {
+		switch {
+		case bytes.HasPrefix(k, cribPrefix),
+			bytes.HasPrefix(k, rslvPrefix):
+
+			// Crib and resolved outputs are the only kinds
+			// currently stored as baby outputs. A single corrupt
+			// output is omitted from the report, rather than
+			// preventing it from being generated.
+			var baby babyOutput
+			err := baby.Decode(bytes.NewReader(v))
+			if err == nil {
+				err = baby.validate()
+			}
+			if err != nil {
+				utxnLog.Errorf("Omitting corrupt output %x of "+
+					"Channel(%s) from report: %v", k[4:],
+					chanPoint, err)
+				return nil
+			}
+
+			// Resolved outputs were claimed by another txn, and
+			// no longer contribute towards the limbo balance.
+			if bytes.HasPrefix(k, rslvPrefix) {
+				report.AddOutput(
+					rslvPrefix, &baby.kidOutput, baby.expiry,
+				)
+				return nil
+			}
+
+			cribOutputs = append(cribOutputs, baby)
+
+		case bytes.HasPrefix(k, psclPrefix),
+			bytes.HasPrefix(k, kndrPrefix),
+			bytes.HasPrefix(k, gradPrefix):
+
+			// All others states can be deserialized as kid outputs.
+			var kid kidOutput
+			err := kid.Decode(bytes.NewReader(v))
+			if err == nil {
+				err = kid.validate()
+			}
+			if err != nil {
+				utxnLog.Errorf("Omitting corrupt output %x of "+
+					"Channel(%s) from report: %v", k[4:],
+					chanPoint, err)
+				return nil
+			}
+			kidStates[*kid.OutPoint()] = struct{}{}
+
+			// Now, use the state prefixes to determine how the this
+			// output should be represented in the nursery report.
+			// An output's funds are always in limbo until reaching
+			// the graduate state.
+			var state []byte
+			switch {
+			case bytes.HasPrefix(k, psclPrefix):
+				// Preschool outputs are awaiting the
+				// confirmation of the commitment transaction.
+				state = psclPrefix
+				report.AddLimboCommitment(&kid)
+				report.AddImmatureOutput(&kid)
+
+			case bytes.HasPrefix(k, kndrPrefix):
+				state = kndrPrefix
+				kgtnOutputs = append(kgtnOutputs, kid)
+				report.AddImmatureOutput(&kid)
+
+				// Kindergarten outputs may originate from
+				// either the commitment transaction or an htlc.
+				// We can distinguish them via their witness
+				// types.
+				switch kid.WitnessType() {
+				case lnwallet.CommitmentTimeLock:
+					// The commitment transaction has been
+					// confirmed, and we are waiting the CSV
+					// delay to expire.
+					report.AddLimboCommitment(&kid)
+
+				case lnwallet.CommitmentAnchor:
+					// The commitment transaction has been
+					// confirmed, and the anchor output is
+					// awaiting its sweep.
+					report.AddLimboCommitment(&kid)
+
+				case lnwallet.HtlcOfferedTimeout:
+					// The htlc timeout transaction has
+					// confirmed, and the CSV delay has
+					// begun ticking.
+					report.AddLimboStage2Htlc(&kid)
+				}
+
+			case bytes.HasPrefix(k, gradPrefix):
+				state = gradPrefix
+
+				// Graduate outputs are those whose funds have
+				// been swept back into the wallet. Each output
+				// will contribute towards the recovered
+				// balance.
+				switch kid.WitnessType() {
+				case lnwallet.CommitmentTimeLock,
+					lnwallet.CommitmentAnchor:
+
+					// The commitment output was
+					// successfully swept back into a
+					// regular p2wkh output.
+					report.AddRecoveredCommitment(&kid)
+
+				case lnwallet.HtlcOfferedTimeout:
+					// This htlc output successfully resides
+					// in a p2wkh output belonging to the
+					// user.
+					report.AddRecoveredHtlc(&kid)
+				}
+			}
+
+			report.AddOutput(state, &kid, kid.MaturityHeight())
+
+			// Estimate how long the output will remain in its
+			// current state, relative to our best height.
+			output := &report.outputs[len(report.outputs)-1]
+			switch {
+			case bytes.HasPrefix(k, psclPrefix):
+				output.confsRemaining = u.commitConfsRemaining(
+					&kid,
+				)
+
+			case bytes.HasPrefix(k, kndrPrefix):
+				output.blocksRemaining = u.blocksRemaining(
+					kid.MaturityHeight(),
+				)
+			}
+
+		default:
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// Each crib output represents a stage one htlc, and will contribute
+	// towards the limbo balance. Since an htlc keeps its outpoint as it
+	// moves from the crib into the kindergarten, and CribToKinder does so
+	// atomically, both records should never coexist. Should a stale crib
+	// record remain nonetheless, the htlc is only counted in its later
+	// stage, rather than being counted twice.
+	for i := range cribOutputs {
+		baby := &cribOutputs[i]
+		if _, ok := kidStates[*baby.OutPoint()]; ok {
+			utxnLog.Warnf("Omitting stale crib output %v of "+
+				"Channel(%s) from report, output has already "+
+				"advanced", baby.OutPoint(), chanPoint)
+			continue
+		}
+
+		report.AddLimboStage1Htlc(baby)
+		report.AddImmatureOutput(&baby.kidOutput)
+		report.AddOutput(cribPrefix, &baby.kidOutput, baby.expiry)
+	}
+
+	// Determine how many times the sweeps of the kindergarten outputs have
+	// been rebroadcast without confirming.
+	if len(kgtnOutputs) > 0 {
+		rebroadcasts, err := u.chanSweepRebroadcasts(chanPoint)
+		if err != nil {
+			return nil, err
+		}
+		report.sweepRebroadcasts = rebroadcasts
+	}
+
+	// Estimate the fee that will be paid to sweep the kindergarten outputs
+	// at the current fee rate. Failing to do so shouldn't prevent the rest
+	// of the report from being returned.
+	recoveryFee, err := u.estimateRecoveryFee(kgtnOutputs)
+	if err != nil {
+		utxnLog.Warnf("Unable to estimate recovery fee for "+
+			"ChannelPoint(%v): %v", chanPoint, err)
+	}
+	report.recoveryFee = recoveryFee
+
+	droppedOutputs, err := u.cfg.Store.FetchDroppedOutputs(chanPoint)
+	if err != nil {
+		return nil, err
+	}
+	report.droppedOutputs = droppedOutputs
+
+	closeTxid, err := u.cfg.Store.ChannelCloseTx(chanPoint)
+	if err != nil {
+		return nil, err
+	}
+	report.closeTxid = closeTxid
+
+	return report, nil
+}
+
+// commitConfsRemaining returns the number of confirmations the commitment txn
+// of the given preschool output still requires, as of our best height, before
+// the output is promoted to the kindergarten. If the confirmation height of
+// the commitment txn is unknown, it is assumed to be unconfirmed.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) commitConfsRemaining(kid *kidOutput) uint32 {
+	confDepth := u.commitConfDepth(kid)
+
+	confHeight := kid.ConfHeight()
+	if confHeight == 0 || u.bestHeight < confHeight {
+		return confDepth
+	}
+
+	numConfs := u.bestHeight - confHeight + 1
+	if numConfs >= confDepth {
+		return 0
+	}
+
+	return confDepth - numConfs
+}
+
+// commitConfDepth returns the number of confirmations required for the
+// commitment txn of the given output before it's promoted to the kindergarten,
+// which is the output's own confirmation depth if one was provided upon
+// incubation, and CommitConfDepth otherwise.
+func (u *utxoNursery) commitConfDepth(kid *kidOutput) uint32 {
+	if kid.ConfDepth() != 0 {
+		return kid.ConfDepth()
+	}
+
+	return u.cfg.CommitConfDepth
+}
+
+// blocksRemaining returns the number of blocks remaining until the given
+// height is reached, as of our best height.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) blocksRemaining(height uint32) uint32 {
+	if u.bestHeight >= height {
+		return 0
+	}
+
+	return height - u.bestHeight
+}
+
+// chanSweepRebroadcasts returns the greatest number of times the finalized
+// sweep txns spending the channel's kindergarten outputs have been rebroadcast.
+// Since outputs may have been deferred past their maturity height, the
+// outputs are located by inspecting each finalized class.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) chanSweepRebroadcasts(
+	chanPoint *wire.OutPoint) (uint32, error) {
+
+	lastFinalizedHeight, err := u.cfg.Store.LastFinalizedHeight()
+	if err != nil {
+		return 0, err
+	}
+
+	activeHeights, err := u.cfg.Store.HeightsBelowOrEqual(
+		lastFinalizedHeight,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var maxRebroadcasts uint32
+	for _, height := range activeHeights {
+		_, kgtnOutputs, _, err := u.cfg.Store.FetchClass(height)
+		if err != nil {
+			return 0, err
+		}
+
+		if !containsChanOutput(kgtnOutputs, chanPoint) {
+			continue
+		}
+
+		numBroadcasts, err := u.cfg.Store.SweepBroadcasts(height)
+		if err != nil {
+			return 0, err
+		}
+
+		if numBroadcasts > 0 && numBroadcasts-1 > maxRebroadcasts {
+			maxRebroadcasts = numBroadcasts - 1
+		}
+	}
+
+	return maxRebroadcasts, nil
+}
+
+// nurseryStatus summarizes the progress of the nursery, allowing operators to
+// determine whether it has caught up with the chain.
+type nurseryStatus struct {
+	// bestHeight is the height most recently processed by the nursery.
+	bestHeight uint32
+
+	// lastFinalizedHeight is the last height for which the nursery
+	// finalized its kindergarten sweep txns.
+	lastFinalizedHeight uint32
+
+	// lastGraduatedHeight is the last height for which the nursery
+	// graduated all outputs, from which replay begins upon restart.
+	lastGraduatedHeight uint32
+
+	// numCrib, numPscl, numKndr, numGrad, and numRslv are the number of
+	// outputs tracked by the nursery in each of the respective states.
+	numCrib uint32
+	numPscl uint32
+	numKndr uint32
+	numGrad uint32
+	numRslv uint32
+}
+
+// NurseryStatus returns the nursery's best height, its last finalized and
+// graduated heights, and the number of outputs it tracks in each state.
+func (u *utxoNursery) NurseryStatus() (*nurseryStatus, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	lastFinalizedHeight, err := u.cfg.Store.LastFinalizedHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	lastGraduatedHeight, err := u.cfg.Store.LastGraduatedHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &nurseryStatus{
+		bestHeight:          u.bestHeight,
+		lastFinalizedHeight: lastFinalizedHeight,
+		lastGraduatedHeight: lastGraduatedHeight,
+	}
+
+	chanPoints, err := u.cfg.Store.ListChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range chanPoints {
+		err := u.cfg.Store.ForChanOutputs(&chanPoints[i],
+			func(k, _ []byte) error {
+				switch {
+				case bytes.HasPrefix(k, cribPrefix):
+					status.numCrib++
+				case bytes.HasPrefix(k, psclPrefix):
+					status.numPscl++
+				case bytes.HasPrefix(k, kndrPrefix):
+					status.numKndr++
+				case bytes.HasPrefix(k, gradPrefix):
+					status.numGrad++
+				case bytes.HasPrefix(k, rslvPrefix):
+					status.numRslv++
+				}
+
+				return nil
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return status, nil
+}
+
+// StuckOutputInfo describes an output that has remained in the nursery well
+// past the height at which it should have advanced, as returned by
+// StuckOutputs.
+type StuckOutputInfo struct {
+	// OutPoint is the outpoint of the stuck output.
+	OutPoint wire.OutPoint
+
+	// ChanPoint is the channel point of the channel the output originated
+	// from.
+	ChanPoint wire.OutPoint
+
+	// State is the state prefix of the output, either "crib" or "kndr".
+	State string
+
+	// Amount is the value of the output.
+	Amount btcutil.Amount
+
+	// DueHeight is the height at which the output should have advanced.
+	// For crib outputs, this is the expiry of the htlc timeout txn. For
+	// kindergarten outputs, this is their maturity height.
+	DueHeight uint32
+
+	// BlocksOverdue is the number of blocks that have passed since
+	// DueHeight, relative to the nursery's best height.
+	BlocksOverdue uint32
+}
+
+// StuckOutputs returns the kindergarten outputs whose maturity height passed
+// more than olderThan blocks ago without having graduated, and the crib outputs
+// whose expiry passed more than olderThan blocks ago without their timeout txn
+// confirming. Such outputs indicate that a sweep is wedged, e.g. due to a low
+// fee rate or missed notification, and requires an operator's attention. If the
+// nursery has yet to learn of the best height, no outputs are returned.
+func (u *utxoNursery) StuckOutputs(
+	olderThan uint32) ([]StuckOutputInfo, error) {
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.bestHeight == 0 {
+		return nil, nil
+	}
+
+	chanPoints, err := u.cfg.Store.ListChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	var stuckOutputs []StuckOutputInfo
+	for i := range chanPoints {
+		err := u.cfg.Store.ForChanOutputs(&chanPoints[i],
+			func(k, v []byte) error {
+				var (
+					state     []byte
+					kid       *kidOutput
+					dueHeight uint32
+					err       error
+				)
+				switch {
+				case bytes.HasPrefix(k, cribPrefix):
+					var baby babyOutput
+					err = baby.Decode(bytes.NewReader(v))
+					if err == nil {
+						err = baby.validate()
+					}
+					state, kid = cribPrefix, &baby.kidOutput
+					dueHeight = baby.expiry
+
+				case bytes.HasPrefix(k, kndrPrefix):
+					kid = &kidOutput{}
+					err = kid.Decode(bytes.NewReader(v))
+					if err == nil {
+						err = kid.validate()
+					}
+					state = kndrPrefix
+					dueHeight = kid.MaturityHeight()
+
+				default:
+					return nil
+				}
+
+				// A single corrupt output is skipped, rather
+				// than preventing the others from being
+				// reported.
+				if err != nil {
+					utxnLog.Errorf("Skipping corrupt "+
+						"output %x of Channel(%s): %v",
+						k[4:], &chanPoints[i], err)
+					return nil
+				}
+
+				if u.bestHeight <= dueHeight+olderThan {
+					return nil
+				}
+
+				info := StuckOutputInfo{
+					OutPoint:      *kid.OutPoint(),
+					ChanPoint:     chanPoints[i],
+					State:         string(state),
+					Amount:        kid.Amount(),
+					DueHeight:     dueHeight,
+					BlocksOverdue: u.bestHeight - dueHeight,
+				}
+				stuckOutputs = append(stuckOutputs, info)
+
+				return nil
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return stuckOutputs, nil
+}
+
+// reloadPreschool re-initializes the chain notifier with all of the outputs
+// that had been saved to the "preschool" database bucket prior to shutdown.
+func (u *utxoNursery) reloadPreschool(heightHint uint32) error {
+	psclOutputs, err := u.cfg.Store.FetchPreschools()
+	if err != nil {
+		return fmt.Errorf("unable to fetch preschool outputs: %v", err)
+	}
+
+	for i := range psclOutputs {
+		kid := &psclOutputs[i]
+		err := u.registerCommitConf(kid, heightHint)
+		if err != nil {
+			return fmt.Errorf("unable to register commitment conf "+
+				"for output %v of Channel(%s): %v",
+				kid.OutPoint(), kid.OriginChanPoint(), err)
+		}
+	}
+
+	return nil
+}
+
+// reloadClasses reinitializes any height-dependent state transitions for which
+// the utxonursery has not recevied confirmation, and replays the graduation of
+// all kindergarten and crib outputs for heights that have not been finalized.
+// This allows the nursery to reinitialize all state to continue sweeping
+// outputs, even in the event that we missed blocks while offline. The hash of
+// each replayed block is recorded, and verified against the chain backend
+// before replaying the next height, such that ErrChainDiverged is returned
+// rather than finalizing sweeps against a chain that no longer contains the
+// blocks we processed. reloadClasses is called during the startup of the UTXO
+// Nursery, and returns the context's error if it is canceled before all
+// heights have been replayed.
+func (u *utxoNursery) reloadClasses(ctx context.Context,
+	lastGradHeight uint32) error {
+
+	// Begin by loading all of the still-active heights up to and including
+	// the last height we successfully graduated.
+	activeHeights, err := u.cfg.Store.HeightsBelowOrEqual(lastGradHeight)
+	if err != nil {
+		return fmt.Errorf("unable to fetch active heights below "+
+			"height=%d: %v", lastGradHeight, err)
+	}
+
+	if len(activeHeights) > 0 {
+		utxnLog.Infof("Re-registering confirmations for %d already "+
+			"graduated heights below height=%d", len(activeHeights),
+			lastGradHeight)
+	}
+
+	// Attempt to re-register notifications for any outputs still at these
+	// heights.
+	for _, classHeight := range activeHeights {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		utxnLog.Debugf("Attempting to regraduate outputs at height=%v",
+			classHeight)
+
+		if err = u.regraduateClass(classHeight); err != nil {
+			utxnLog.Errorf("Failed to regraduate outputs at "+
+				"height=%v: %v", classHeight, err)
+			return fmt.Errorf("unable to regraduate outputs at "+
+				"height=%d: %v", classHeight, err)
+		}
+	}
+
+	// Get the most recently mined block.
+	_, bestHeight, err := u.cfg.ChainIO.GetBestBlock()
+	if err != nil {
+		return fmt.Errorf("unable to fetch best block: %v", err)
+	}
+
+	// Before processing any missed blocks, ensure that the chain we are
+	// about to replay still builds upon the last block we graduated.
+	if err := u.verifyGraduatedBlock(); err != nil {
+		return err
+	}
+
+	// If we haven't yet seen any registered force closes, or we're already
+	// caught up with the current best chain, then we can exit early.
+	if lastGradHeight == 0 || uint32(bestHeight) == lastGradHeight {
+		return nil
+	}
+
+	utxnLog.Infof("Processing outputs from missed blocks. Starting with "+
+		"blockHeight=%v, to current blockHeight=%v", lastGradHeight,
+		bestHeight)
+
+	// Loop through and check for graduating outputs at each of the missed
+	// block heights.
+	for curHeight := lastGradHeight + 1; curHeight <= uint32(bestHeight); curHeight++ {
+		utxnLog.Debugf("Attempting to graduate outputs at height=%v",
+			curHeight)
+
+		blockHash, err := u.cfg.ChainIO.GetBlockHash(int64(curHeight))
+		if err != nil {
+			return fmt.Errorf("unable to fetch block hash at "+
+				"height=%d: %v", curHeight, err)
+		}
+
+		err = u.graduateClass(ctx, curHeight, blockHash)
+		if err != nil {
+			if err == ctx.Err() {
+				utxnLog.Infof("Aborted processing missed "+
+					"blocks at height=%v: %v", curHeight,
+					err)
+				return err
+			}
+
+			utxnLog.Errorf("Failed to graduate outputs at "+
+				"height=%v: %v", curHeight, err)
+			return fmt.Errorf("unable to graduate outputs at "+
+				"height=%d: %v", curHeight, err)
+		}
+
+		// The chain may have reorganized while we were processing
+		// this height, in which case we must not build upon it.
+		if err := u.verifyGraduatedBlock(); err != nil {
+			return err
+		}
+	}
+
+	utxnLog.Infof("UTXO Nursery is now fully synced")
+
+	return nil
+}
+
+// verifyGraduatedBlock ensures that the block recorded at the nursery's last
+// graduated height is still part of the main chain according to the chain
+// backend. If the block has been replaced, ErrChainDiverged is returned.
+// Heights graduated without recording their block hash can't be verified, and
+// are assumed to be valid.
+func (u *utxoNursery) verifyGraduatedBlock() error {
+	height, hash, err := u.cfg.Store.LastGraduatedBlock()
+	if err != nil {
+		return fmt.Errorf("unable to fetch last graduated block: %v",
+			err)
+	}
+	if hash == nil {
+		return nil
+	}
+
+	chainHash, err := u.cfg.ChainIO.GetBlockHash(int64(height))
+	if err != nil {
+		return fmt.Errorf("unable to fetch block hash at height=%d: %v",
+			height, err)
+	}
+
+	if *chainHash != *hash {
+		utxnLog.Criticalf("Block %v at last graduated height=%d has "+
+			"been replaced by %v, chain diverged from the one "+
+			"processed by the nursery", hash, height, chainHash)
+
+		return ErrChainDiverged
+	}
+
+	return nil
+}
+
+// regraduateClass handles the steps involved in re-registering for
+// confirmations for all still-active outputs at a particular height. This is
+// used during restarts to ensure that any still-pending state transitions are
+// properly registered, so they can be driven by the chain notifier. No
+// transactions or signing are done as a result of this step.
+func (u *utxoNursery) regraduateClass(classHeight uint32) error {
+	// Fetch all information about the crib and kindergarten outputs at this
+	// height. In addition to the outputs, we also retrieve the finalized
+	// kindergarten sweep txns, which will be empty if we have not attempted
+	// this height before, or if no kindergarten outputs exist at this
+	// height.
+	finalTxns, kgtnOutputs, cribOutputs, err := u.cfg.Store.FetchClass(
+		classHeight)
+	if err != nil {
+		return fmt.Errorf("unable to fetch class: %v", err)
+	}
+
+	if len(finalTxns) > 0 {
+		utxnLog.Infof("Re-registering confirmation for %d kindergarten "+
+			"sweep transaction(s) at height=%d ", len(finalTxns),
+			classHeight)
+
+		err = u.registerSweepConf(finalTxns, kgtnOutputs, classHeight)
+		if err != nil {
+			utxnLog.Errorf("Failed to re-register for kindergarten "+
+				"sweep transaction at height=%d: %v",
+				classHeight, err)
+			return fmt.Errorf("unable to register sweep conf: %v",
+				err)
+		}
+	}
+
+	// Since sweeps delegated to an external sweeper are not persisted, we
+	// hand off the outputs again to learn the txid of their sweep.
+	if u.cfg.ExternalSweeper != nil && len(kgtnOutputs) > 0 {
+		err = u.handoffSweep(classHeight, kgtnOutputs)
+		if err != nil {
+			utxnLog.Errorf("Failed to re-hand off kindergarten "+
+				"outputs at height=%d: %v", classHeight, err)
+			return fmt.Errorf("unable to hand off sweep: %v", err)
+		}
+	}
+
+	if len(cribOutputs) == 0 {
+		return nil
+	}
+
+	utxnLog.Infof("Re-registering confirmation for first-stage HTLC "+
+		"outputs at height=%d ", classHeight)
+
+	// Now, we broadcast all pre-signed htlc txns from the crib outputs at
+	// this height. There is no need to finalize these txns, since the txid
+	// is predetermined when signed in the wallet.
+	for i := range cribOutputs {
+		err = u.registerTimeoutConf(&cribOutputs[i], classHeight)
+		if err != nil {
+			utxnLog.Errorf("Failed to re-register first-stage "+
+				"HTLC output %v", cribOutputs[i].OutPoint())
+			return fmt.Errorf("unable to register timeout conf "+
+				"for output %v: %v", cribOutputs[i].OutPoint(),
+				err)
+
+		}
+	}
+
+	return nil
+}
+
+// incubator is tasked with driving all state transitions that are dependent on
+// the current height of the blockchain. As new blocks arrive, the incubator
+// will attempt spend outputs at the latest height. The asynchronous
+// confirmation of these spends will either 1) move a crib output into the
+// kindergarten bucket or 2) move a kindergarten output into the graduated
+// bucket.
+func (u *utxoNursery) incubator(newBlockChan *chainntnfs.BlockEpochEvent) {
+	defer u.wg.Done()
+	defer newBlockChan.Cancel()
+
+	ctx, cancel := u.withQuit(context.Background())
+	defer cancel()
+
+	for {
+		select {
+		case epoch, ok := <-newBlockChan.Epochs:
+			// If the epoch channel has been closed, then the
+			// ChainNotifier is exiting which means the daemon is
+			// as well. Therefore, we exit early also in order to
+			// ensure the daemon shuts down gracefully, yet
+			// swiftly.
+			if !ok {
+				return
+			}
+
+			// TODO(roasbeef): if the BlockChainIO is rescanning
+			// will give stale data
+
+			// While draining, we only wait for the sweeps that
+			// have already been broadcast, the new height will be
+			// processed after the nursery restarts.
+			if atomic.LoadUint32(&u.draining) == 1 {
+				continue
+			}
+
+			// While paused, the height is queued. Otherwise, it is
+			// processed after any heights queued while paused.
+			u.processEpochs(ctx, u.nextEpochs(epoch))
+
+		case <-u.resumed:
+			if atomic.LoadUint32(&u.draining) == 1 {
+				continue
+			}
+
+			u.processEpochs(ctx, u.nextEpochs(nil))
+
+		case <-u.quit:
+			return
+		}
+	}
+}
+
+// processEpochs processes each of the given blocks in order, stopping early if
+// the context is canceled.
+func (u *utxoNursery) processEpochs(ctx context.Context,
+	epochs []*chainntnfs.BlockEpoch) {
+
+	for _, epoch := range epochs {
+		if ctx.Err() != nil {
+			return
+		}
+
+		u.processEpoch(ctx, epoch)
+	}
+}
+
+// processEpoch handles the arrival of a new block, graduating the outputs at
+// its height.
+func (u *utxoNursery) processEpoch(ctx context.Context,
+	epoch *chainntnfs.BlockEpoch) {
+
+	// Before processing the new height, retry any incubation requests
+	// that we previously failed to persist, and any txns we previously
+	// failed to publish.
+	u.retryPendingIncubations()
+	u.republishTxns()
+
+	// A new block has just been connected to the main chain, which means
+	// we might be able to graduate crib or kindergarten outputs at this
+	// height. This involves broadcasting any presigned htlc timeout txns,
+	// as well as signing and broadcasting a sweep txn that spends from all
+	// kindergarten outputs at this height.
+	height := uint32(epoch.Height)
+	err := u.graduateClass(ctx, height, epoch.Hash)
+	if err != nil {
+		utxnLog.Errorf("error while graduating class at height=%d: %v",
+			height, err)
+
+		// TODO(conner): signal fatal error to daemon
+	}
+
+	// Close any graduated channels whose sweeps have now reached
+	// GraduationConfDepth.
+	u.closeFinalizedChannels(height)
+
+	// Bump the fee of any commitment txns that have remained unconfirmed
+	// for too long.
+	u.bumpStuckCommitments(height)
+
+	// Now that this height's outputs have left the height index,
+	// periodically prune the nursery store's empty buckets.
+	u.maybePruneStore(height)
+}
+
+// maybePruneStore prunes the nursery store if the provided height falls on the
+// configured PruneInterval.
+func (u *utxoNursery) maybePruneStore(height uint32) {
+	if u.cfg.PruneInterval == 0 || height%u.cfg.PruneInterval != 0 {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := u.cfg.Store.Prune(); err != nil {
+		utxnLog.Errorf("Unable to prune nursery store at "+
+			"height=%d: %v", height, err)
+		return
+	}
+
+	utxnLog.Debugf("Pruned nursery store at height=%d", height)
+}
+
+// graduateClass handles the steps involved in spending outputs whose CSV or
+// CLTV delay expires at the nursery's current height. This method is called
+// each time a new block arrives, or during startup to catch up on heights we
+// may have missed while the nursery was offline. If the hash of the block at
+// this height is known, it is recorded along with the height, allowing a later
+// replay to detect whether the block was reorged out. If the context is
+// canceled, possibly while waiting to acquire the nursery's mutex, the height
+// is left untouched and the context's error is returned.
+func (u *utxoNursery) graduateClass(ctx context.Context, classHeight uint32,
+	blockHash *chainhash.Hash) error {
+
+	// Record this height as the nursery's current best height.
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	u.bestHeight = classHeight
+
+	// First, finalize the kindergarten sweep txns for this height, or
+	// restore them if they were finalized previously.
+	if _, err := u.finalizeHeight(classHeight); err != nil {
+		return fmt.Errorf("unable to finalize height: %v", err)
+	}
+
+	// Then, broadcast the finalized sweep txns along with any presigned
+	// htlc timeout txns maturing at this height.
+	if err := u.broadcastHeight(classHeight); err != nil {
+		return fmt.Errorf("unable to broadcast height: %v", err)
+	}
+
+	var err error
+	if blockHash == nil {
+		err = u.cfg.Store.GraduateHeight(classHeight)
+	} else {
+		err = u.cfg.Store.GraduateBlock(classHeight, blockHash)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to graduate height: %v", err)
+	}
+
+	return nil
+}
+
+// FinalizeHeight signs and persists the kindergarten sweep txns for the
+// outputs maturing at the given height, without broadcasting them. If the
+// height has already been finalized, the previously finalized txns are
+// returned, ensuring that we never sign different txns for the same height.
+// As finalization must proceed in order of height, any active heights below
+// the given height that have yet to be finalized are finalized first. Heights
+// above the best height can't be finalized. Together with BroadcastHeight,
+// this allows external tooling to inspect a sweep before deciding when to
+// broadcast it.
+func (u *utxoNursery) FinalizeHeight(height uint32) ([]*wire.MsgTx, error) {
+	_, bestHeight, err := u.cfg.ChainIO.GetBestBlock()
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch best block: %v", err)
+	}
+	if height > uint32(bestHeight) {
+		return nil, fmt.Errorf("unable to finalize height=%d above "+
+			"best height=%d", height, bestHeight)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	// The next unfinalized height is located anew after each
+	// finalization, as finalizing a height may defer its outputs to a
+	// later one.
+	for {
+		nextHeight, err := u.nextUnfinalizedHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		if nextHeight == 0 || nextHeight == height {
+			break
+		}
+
+		if _, err := u.finalizeHeight(nextHeight); err != nil {
+			return nil, fmt.Errorf("unable to finalize "+
+				"height=%d: %v", nextHeight, err)
+		}
+	}
+
+	return u.finalizeHeight(height)
+}
+
+// nextUnfinalizedHeight returns the lowest active height at or below the given
+// height that has yet to be finalized, or zero if there is none.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) nextUnfinalizedHeight(height uint32) (uint32, error) {
+	lastFinalizedHeight, err := u.cfg.Store.LastFinalizedHeight()
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch last finalized height: %v",
+			err)
+	}
+
+	activeHeights, err := u.cfg.Store.HeightsBelowOrEqual(height)
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch active heights below "+
+			"height=%d: %v", height, err)
+	}
+
+	for _, activeHeight := range activeHeights {
+		if activeHeight > lastFinalizedHeight {
+			return activeHeight, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// BroadcastHeight publishes the finalized kindergarten sweep txns and the
+// presigned htlc timeout txns for the given height, and registers for their
+// confirmations so that the nursery can continue to track the outputs. The
+// height should be finalized via FinalizeHeight beforehand, otherwise only the
+// htlc timeout txns will be broadcast.
+func (u *utxoNursery) BroadcastHeight(height uint32) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.broadcastHeight(height)
+}
+
+// FinalizedSweepInfo returns the txid, fee rate, and swept amount of each
+// kindergarten sweep txn finalized at the given height. This is useful when
+// diagnosing a sweep that has yet to confirm.
+func (u *utxoNursery) FinalizedSweepInfo(height uint32) ([]SweepInfo, error) {
+	return u.cfg.Store.FinalizedSweepInfo(height)
+}
+
+// FinalizedSweepTx returns the kindergarten sweep txn finalized at the given
+// height, byte-for-byte as it was persisted, allowing an operator to manually
+// rebroadcast a stuck sweep or hand it to a third-party accelerator.
+// ErrNoFinalizedSweep is returned if the height has not been finalized, and
+// ErrMultipleFinalizedSweeps if its outputs were split across several txns.
+func (u *utxoNursery) FinalizedSweepTx(height uint32) (*wire.MsgTx, error) {
+	finalTxns, err := u.FinalizedSweepTxns(height)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(finalTxns) {
+	case 0:
+		return nil, ErrNoFinalizedSweep
+	case 1:
+		return finalTxns[0], nil
+	default:
+		return nil, ErrMultipleFinalizedSweeps
+	}
+}
+
+// FinalizedSweepTxns returns all kindergarten sweep txns finalized at the
+// given height, in the order in which they were finalized.
+func (u *utxoNursery) FinalizedSweepTxns(height uint32) ([]*wire.MsgTx,
+	error) {
+
+	return u.cfg.Store.FetchFinalizedTxns(height)
+}
+
+// ChanLimboBalance returns the total value of the outputs of the given channel
+// that are still being incubated by the nursery. Unlike NurseryReport, this
+// does not require decoding each of the channel's outputs.
+func (u *utxoNursery) ChanLimboBalance(
+	chanPoint *wire.OutPoint) (btcutil.Amount, error) {
+
+	return u.cfg.Store.ChanLimboBalance(chanPoint)
+}
+
+// FetchArchivedGraduations returns the graduated outputs of the given channel
+// that were archived upon its removal from the nursery store. Outputs are only
+// archived if the nursery is configured with RetainGraduated.
+func (u *utxoNursery) FetchArchivedGraduations(
+	chanPoint *wire.OutPoint) ([]kidOutput, error) {
+
+	return u.cfg.Store.FetchArchivedGraduations(chanPoint)
+}
+
+// SweepMatureOutputs immediately broadcasts the sweep txns for the kindergarten
+// outputs of the given channel that have reached maturity, without waiting for
+// the next block to arrive. Heights that have not yet been finalized are
+// finalized in order, such that the nursery never signs different sweep txns
+// for the same height, and notifications are registered that will graduate
+// the outputs once their sweeps confirm.
+func (u *utxoNursery) SweepMatureOutputs(chanPoint *wire.OutPoint) error {
+	_, bestHeight, err := u.cfg.ChainIO.GetBestBlock()
+	if err != nil {
+		return fmt.Errorf("unable to fetch best block: %v", err)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	lastFinalizedHeight, err := u.cfg.Store.LastFinalizedHeight()
+	if err != nil {
+		return fmt.Errorf("unable to fetch last finalized height: %v",
+			err)
+	}
+
+	// Since finalization must proceed in order of height, we finalize
+	// every non-empty height up to our best height.
+	activeHeights, err := u.cfg.Store.HeightsBelowOrEqual(
+		uint32(bestHeight),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to fetch active heights below "+
+			"height=%d: %v", bestHeight, err)
+	}
+
+	var numSwept int
+	for _, height := range activeHeights {
+		if height > lastFinalizedHeight {
+			if _, err := u.finalizeHeight(height); err != nil {
+				return fmt.Errorf("unable to finalize "+
+					"height=%d: %v", height, err)
+			}
+		}
+
+		// Mature outputs may have been deferred past their maturity
+		// height, e.g. to be aggregated with other outputs, so we
+		// locate the channel's outputs by inspecting each class.
+		finalTxns, kgtnOutputs, _, err := u.cfg.Store.FetchClass(height)
+		if err != nil {
+			return fmt.Errorf("unable to fetch class at "+
+				"height=%d: %v", height, err)
+		}
+
+		if len(finalTxns) == 0 ||
+			!containsChanOutput(kgtnOutputs, chanPoint) {
+
+			continue
+		}
+
+		utxnLog.Infof("Sweeping mature outputs of Channel(%s) at "+
+			"height=%d", chanPoint, height)
+
+		// Now, broadcast the finalized sweep txns at this height and
+		// register for their confirmation.
+		err = u.sweepGraduatingKinders(height, finalTxns, kgtnOutputs)
+		if err != nil {
+			return fmt.Errorf("unable to sweep outputs of "+
+				"Channel(%s) at height=%d: %v", chanPoint,
+				height, err)
+		}
+		numSwept++
+	}
+
+	if numSwept == 0 {
+		utxnLog.Infof("Channel(%s) has no mature kindergarten outputs "+
+			"to sweep", chanPoint)
+	}
+
+	return nil
+}
+
+// BuildSweepTxPreview returns the sweep txn the nursery would craft for the
+// channel's current kindergarten outputs, regardless of whether they have
+// matured. The txn is neither persisted nor broadcast, and it may differ from
+// the txn eventually broadcast, e.g. due to changes in the fee rate, outputs
+// of other channels maturing at the same height, or the segregation of
+// outputs by witness type.
+func (u *utxoNursery) BuildSweepTxPreview(
+	chanPoint *wire.OutPoint) (*wire.MsgTx, error) {
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var kgtnOutputs []kidOutput
+	err := u.cfg.Store.ForChanOutputs(chanPoint, func(k, v []byte) error {
+		if !bytes.HasPrefix(k, kndrPrefix) {
+			return nil
+		}
+
+		// A corrupt output is skipped, as it would never be swept by
+		// the nursery either.
+		var kid kidOutput
+		err := kid.Decode(bytes.NewReader(v))
+		if err == nil {
+			err = kid.validate()
+		}
+		if err != nil {
+			utxnLog.Errorf("Skipping corrupt output %x of "+
+				"Channel(%s): %v", k[4:], chanPoint, err)
+			return nil
+		}
+		kgtnOutputs = append(kgtnOutputs, kid)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(kgtnOutputs) == 0 {
+		return nil, fmt.Errorf("channel %v has no kindergarten "+
+			"outputs to sweep", chanPoint)
+	}
+
+	sweepTx, _, err := u.createSweepTx(u.bestHeight, kgtnOutputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return sweepTx, nil
+}
+
+// ProjectedSweep projects the sweep txns that would be crafted for the
+// kindergarten outputs bucketed at the given height, returning their total
+// estimated weight, the total fee they would pay at the current fee rate, and
+// the number of inputs they would spend. The outputs are grouped into txns and
+// priced just as in createSweepTxns, but nothing is signed or broadcast. Sets
+// of outputs too small to be swept, or whose fee budgets are too low, are left
+// out, as they would be deferred.
+// Since no sweep script is derived, outputs lacking one are assumed to be swept
+// to a p2wkh output. A height without kindergarten outputs yields a zero
+// projection.
+func (u *utxoNursery) ProjectedSweep(height uint32) (uint64, btcutil.Amount,
+	int, error) {
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	_, kgtnOutputs, _, err := u.cfg.Store.FetchClass(height)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	kgtnOutputs, _ = excludeImmatureKinders(height, kgtnOutputs)
+	outputSets, _ := u.selectOutputs(height, kgtnOutputs)
+
+	var (
+		weight    uint64
+		fee       btcutil.Amount
+		numInputs int
+	)
+	for _, outputs := range outputSets {
+		sweepClasses, classes := u.groupSweepClasses(height, outputs)
+		for _, class := range sweepClasses {
+			classOutputs := classes[class]
+			estimate, err := u.estimateSweep(height, classOutputs)
+			var txFee btcutil.Amount
+			if err == nil {
+				txFee, err = u.estimateSweepFee(estimate)
+			}
+			switch {
+			case err == ErrDustSweep, err == ErrFeeBudgetTooLow:
+				continue
+			case err != nil:
+				return 0, 0, 0, err
+			}
+
+			weight += estimate.weight
+			fee += txFee
+			numInputs += len(estimate.inputs)
+		}
+	}
+
+	return weight, fee, numInputs, nil
+}
+
+// containsChanOutput returns true if any of the provided kindergarten outputs
+// originates from the given channel.
+func containsChanOutput(kgtnOutputs []kidOutput, chanPoint *wire.OutPoint) bool {
+	for i := range kgtnOutputs {
+		if *kgtnOutputs[i].OriginChanPoint() == *chanPoint {
+			return true
+		}
+	}
+
+	return false
+}
+
+// finalizeHeight is the internal implementation of FinalizeHeight. The
+// deferral of outputs and the persistence of the sweep txns are performed
+// within a single store transaction, such that a crash mid-finalization can't
+// leave the height finalized with a partial set of its deferrals, or vice
+// versa.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) finalizeHeight(classHeight uint32) ([]*wire.MsgTx,
+	error) {
+
+	var finalTxns []*wire.MsgTx
+	err := u.cfg.Store.Update(func(store NurseryStore) error {
+		var err error
+		finalTxns, err = u.finalizeHeightTx(store, classHeight)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return finalTxns, nil
+}
+
+// finalizeHeightTx finalizes the given height using the provided store, which
+// is bound to the store transaction of finalizeHeight.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) finalizeHeightTx(store NurseryStore,
+	classHeight uint32) ([]*wire.MsgTx, error) {
+
+	// Fetch all information about the kindergarten outputs at this height.
+	// In addition to the outputs, we also retrieve the finalized
+	// kindergarten sweep txns, which will be empty if we have not attempted
+	// this height before, or if no kindergarten outputs exist at this
+	// height.
+	finalTxns, kgtnOutputs, _, err := store.FetchClass(classHeight)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch class: %v", err)
+	}
+
+	// Load the last finalized height, so we can determine if the
+	// kindergarten sweep txns should be crafted.
+	lastFinalizedHeight, err := store.LastFinalizedHeight()
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch last finalized "+
+			"height: %v", err)
+	}
+
+	// If we have processed this height before, the finalized txns have
+	// already been restored from the nursery store.
+	if classHeight <= lastFinalizedHeight {
+		return finalTxns, nil
+	}
+
+	// Outputs with time based relative locks are only estimated to mature
+	// at this height, so we defer any whose lock has not yet expired
+	// according to the median-time-past.
+	kgtnOutputs, err = u.deferImmatureKinders(
+		store, classHeight, kgtnOutputs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to defer immature outputs: %v",
+			err)
+	}
+
+	// If sweep buffering is enabled, the mature outputs may be deferred so
+	// that they can be swept together with outputs maturing shortly after.
+	kgtnOutputs, err = u.bufferKinders(
+		store, classHeight, lastFinalizedHeight, kgtnOutputs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to buffer outputs: %v", err)
+	}
+
+	// Next, we finalize the graduating kindergarten outputs, by
+	// signing the sweep transactions that spend from them. These txns are
+	// persisted such that we never broadcast different txns for the same
+	// height. This allows us to recover from failures, and watch for the
+	// correct txids.
+	var feeRates []btcutil.Amount
+	if len(kgtnOutputs) > 0 {
+		var deferredOutputs []kidOutput
+		switch {
+		// If sweeps are delegated to an external sweeper, no txns are
+		// crafted, and the outputs are instead handed off once the
+		// height is broadcast. We only defer those that aren't yet
+		// spendable.
+		case u.cfg.ExternalSweeper != nil:
+			_, deferredOutputs = excludeImmatureKinders(
+				classHeight, kgtnOutputs,
+			)
+
+		default:
+			finalTxns, feeRates, deferredOutputs, err =
+				u.createSweepTxns(classHeight, kgtnOutputs)
+
+			// A failure to craft the sweep txns, e.g. because the
+			// wallet is unable to generate a sweep script,
+			// shouldn't prevent the crib outputs at this height
+			// from being broadcast, nor the height from being
+			// graduated. Instead, all of the outputs are deferred,
+			// such that the sweep is retried at the next height.
+			if err != nil {
+				utxnLog.Errorf("Failed to create sweep txn at "+
+					"height=%d, retrying %d outputs at "+
+					"next height: %v", classHeight,
+					len(kgtnOutputs), err)
+
+				finalTxns, feeRates = nil, nil
+				deferredOutputs = kgtnOutputs
+			}
+		}
+
+		// Outputs that are too small to be swept on their own are
+		// deferred to the next height, where they will be aggregated
+		// with any other maturing outputs. The same applies to any
+		// outputs found to be immature, or whose witness couldn't be
+		// generated.
+		for i := range deferredOutputs {
+			err := store.DeferKinder(
+				&deferredOutputs[i], classHeight,
+				classHeight+1,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("unable to defer "+
+					"output %v: %v",
+					deferredOutputs[i].OutPoint(), err)
+			}
+		}
+	}
+
+	// Persist the kindergarten sweep txns to the nursery store. It is safe
+	// to store an empty set of txns, which happens if there are no
+	// graduating kindergarten outputs.
+	err = store.FinalizeKinder(classHeight, finalTxns, feeRates)
+	if err != nil {
+		utxnLog.Errorf("Failed to finalize kindergarten at "+
+			"height=%d", classHeight)
+
+		return nil, fmt.Errorf("unable to finalize kindergarten: %v",
+			err)
+	}
+
+	// Log if the finalized transactions are non-trivial.
+	if len(finalTxns) > 0 {
+		utxnLog.Infof("Finalized kindergarten at height=%d "+
+			"with %d sweep txn(s)", classHeight, len(finalTxns))
+	}
+
+	return finalTxns, nil
+}
+
+// broadcastHeight is the internal implementation of BroadcastHeight.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) broadcastHeight(classHeight uint32) error {
+	// Fetch all information about the crib and kindergarten outputs at this
+	// height, along with any finalized kindergarten sweep txns.
+	finalTxns, kgtnOutputs, cribOutputs, err := u.cfg.Store.FetchClass(
+		classHeight)
+	if err != nil {
+		return fmt.Errorf("unable to fetch class: %v", err)
+	}
+
+	// Consecutive broadcasts at this height are spaced out by a random
+	// delay. The delayed txns are broadcast by a separate goroutine once
+	// the height has been processed, such that the nursery's mutex isn't
+	// held while waiting.
+	delayed := newDelayedBroadcasts()
+	defer u.publishDelayed(delayed)
+
+	// Now that the kindergarten sweep txns have either been finalized or
+	// restored, broadcast the txns, and set up notifications that will
+	// transition the swept kindergarten outputs into graduated outputs.
+	if len(finalTxns) > 0 {
+		err := u.broadcastKinderSweeps(classHeight, finalTxns,
+			kgtnOutputs, delayed)
+		if err != nil {
+			utxnLog.Errorf("Failed to sweep %d kindergarten outputs "+
+				"at height=%d: %v", len(kgtnOutputs), classHeight,
+				err)
+			return fmt.Errorf("unable to sweep %d kindergarten "+
+				"outputs: %v", len(kgtnOutputs), err)
+		}
+	}
+
+	// If sweeps are delegated to an external sweeper, no txns were
+	// finalized, so we hand off the kindergarten outputs instead.
+	if u.cfg.ExternalSweeper != nil && len(kgtnOutputs) > 0 {
+		err := u.handoffSweep(classHeight, kgtnOutputs)
+		if err != nil {
+			utxnLog.Errorf("Failed to hand off %d kindergarten "+
+				"outputs at height=%d: %v", len(kgtnOutputs),
+				classHeight, err)
+			return fmt.Errorf("unable to hand off %d kindergarten "+
+				"outputs: %v", len(kgtnOutputs), err)
+		}
+	}
+
+	// If batching was requested, combine the timeout txns whose
+	// signatures permit it into a single txn.
+	if u.cfg.BatchCribSweeps && len(cribOutputs) > 1 {
+		cribOutputs, err = u.batchCribOutputs(classHeight, cribOutputs)
+		if err != nil {
+			return fmt.Errorf("unable to batch crib outputs: %v",
+				err)
+		}
+	}
+
+	// Now, we broadcast all pre-signed htlc txns from the crib outputs at
+	// this height. There is no need to finalize these txns, since the txid
+	// is predetermined when signed in the wallet.
+	for i := range cribOutputs {
+		err := u.sweepCribOutput(classHeight, &cribOutputs[i], delayed)
+		switch {
+		// The crib output has been requeued at its expiry height,
+		// allowing the remaining outputs to proceed.
+		case err == ErrPrematureCribSweep:
+			continue
+
+		case err != nil:
+			utxnLog.Errorf("Failed to sweep first-stage HTLC "+
+				"(CLTV-delayed) output %v",
+				cribOutputs[i].OutPoint())
+			return fmt.Errorf("unable to sweep crib output %v: %v",
+				cribOutputs[i].OutPoint(), err)
+		}
+	}
+
+	return nil
+}
+
+// bufferKinders determines whether the sweep of the mature kindergarten outputs
+// at the given height should be deferred, so that the outputs can be swept
+// together with outputs maturing shortly after in a single transaction. The
+// outputs are deferred to the next height holding kindergarten outputs, as
+// long as no output would be deferred more than SweepBufferWindow blocks past
+// its maturity height. If any output is not eligible for buffering, all
+// outputs are returned to be swept immediately, since the smaller outputs can
+// be aggregated with it at no extra delay. Otherwise, the outputs are moved to
+// the later height within the provided store, and an empty set is returned.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) bufferKinders(store NurseryStore, classHeight,
+	lastFinalizedHeight uint32, kgtnOutputs []kidOutput) ([]kidOutput,
+	error) {
+
+	if u.cfg.SweepBufferWindow == 0 || len(kgtnOutputs) == 0 {
+		return kgtnOutputs, nil
+	}
+
+	// Compute the latest height to which all outputs can be deferred,
+	// bailing if any output is too large to be buffered.
+	deadline := uint32(math.MaxUint32)
+	for i := range kgtnOutputs {
+		kid := &kgtnOutputs[i]
+
+		if u.cfg.SweepBufferMaxValue != 0 &&
+			kid.Amount() >= u.cfg.SweepBufferMaxValue {
+
+			return kgtnOutputs, nil
+		}
+
+		kidDeadline := kid.MaturityHeight() + u.cfg.SweepBufferWindow
+		if kidDeadline < deadline {
+			deadline = kidDeadline
+		}
+	}
+
+	if deadline <= classHeight {
+		return kgtnOutputs, nil
+	}
+
+	// Search for the next height within the deadline that has yet to be
+	// finalized, and contains kindergarten outputs we can aggregate with.
+	activeHeights, err := store.HeightsBelowOrEqual(deadline)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch active heights below "+
+			"height=%d: %v", deadline, err)
+	}
+
+	var nextHeight uint32
+	for _, height := range activeHeights {
+		if height <= classHeight || height <= lastFinalizedHeight {
+			continue
+		}
+
+		_, nextOutputs, _, err := store.FetchClass(height)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch class at "+
+				"height=%d: %v", height, err)
+		}
+
+		if len(nextOutputs) > 0 {
+			nextHeight = height
+			break
+		}
+	}
+
+	if nextHeight == 0 {
+		return kgtnOutputs, nil
+	}
+
+	utxnLog.Infof("Deferring sweep of %d kindergarten outputs from "+
+		"height=%d to height=%d", len(kgtnOutputs), classHeight,
+		nextHeight)
+
+	for i := range kgtnOutputs {
+		err := store.DeferKinder(
+			&kgtnOutputs[i], classHeight, nextHeight,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("unable to defer output %v to "+
+				"height=%d: %v", kgtnOutputs[i].OutPoint(),
+				nextHeight, err)
+		}
+	}
+
+	return nil, nil
+}
+
+// deferImmatureKinders returns the subset of the kindergarten outputs at the
+// given height that are mature. Outputs with time based relative locks that
+// have not yet expired according to the median-time-past are deferred to the
+// next height within the provided store.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) deferImmatureKinders(store NurseryStore,
+	classHeight uint32, kgtnOutputs []kidOutput) ([]kidOutput, error) {
+
+	var (
+		matureOutputs = make([]kidOutput, 0, len(kgtnOutputs))
+		tipMTP        int64
+		tipMTPKnown   bool
+	)
+	for i := range kgtnOutputs {
+		kid := &kgtnOutputs[i]
+		if !kid.IsTimeLocked() {
+			matureOutputs = append(matureOutputs, *kid)
+			continue
+		}
+
+		// The spending txn will be included in the block after this
+		// height, so the lock is evaluated against the median-time-past
+		// of the block at this height.
+		if !tipMTPKnown {
+			var err error
+			tipMTP, err = u.medianTimePast(classHeight)
+			if err != nil {
+				return nil, fmt.Errorf("unable to compute "+
+					"median time past at height=%d: %v",
+					classHeight, err)
+			}
+			tipMTPKnown = true
+		}
+
+		// Per BIP 68, the lock is measured from the median-time-past
+		// of the block preceding the one that confirmed the output.
+		confMTP, err := u.medianTimePast(kid.ConfHeight() - 1)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compute median time "+
+				"past for output %v: %v", kid.OutPoint(), err)
+		}
+
+		lockSeconds := int64(kid.Sequence()&wire.SequenceLockTimeMask) <<
+			wire.SequenceLockTimeGranularity
+		if tipMTP >= confMTP+lockSeconds {
+			matureOutputs = append(matureOutputs, *kid)
+			continue
+		}
+
+		utxnLog.Infof("Deferring time locked kindergarten output %v "+
+			"from height=%d, matures %d seconds after median time "+
+			"past %d", kid.OutPoint(), classHeight, lockSeconds,
+			confMTP)
+
+		err = store.DeferKinder(kid, classHeight, classHeight+1)
+		if err != nil {
+			return nil, fmt.Errorf("unable to defer output %v: %v",
+				kid.OutPoint(), err)
+		}
+	}
+
+	return matureOutputs, nil
+
+}
+
+// medianTimePast computes the median-time-past, in unix seconds, of the block
+// at the given height, using the timestamps of the block and those preceding
+// it.
+func (u *utxoNursery) medianTimePast(height uint32) (int64, error) {
+	timestamps := make([]int64, 0, medianTimeBlocks)
+	for i := uint32(0); i < medianTimeBlocks && i <= height; i++ {
+		blockHash, err := u.cfg.ChainIO.GetBlockHash(
+			int64(height - i),
+		)
+		if err != nil {
+			return 0, err
+		}
+
+		block, err := u.cfg.ChainIO.GetBlock(blockHash)
+		if err != nil {
+			return 0, err
+		}
+
+		timestamps = append(timestamps, block.Header.Timestamp.Unix())
+	}
+
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i] < timestamps[j]
+	})
+
+	return timestamps[len(timestamps)/2], nil
+}
+
+// sweepClass identifies a set of kindergarten outputs that may be swept
+// together within a single transaction.
+type sweepClass struct {
+	// pkScript is the destination script of the outputs, an empty value
+	// indicates that they are to be swept back into the wallet.
+	pkScript string
+
+	// witnessType is the witness type of the outputs, only populated if
+	// the nursery is configured to segregate its sweeps.
+	witnessType lnwallet.WitnessType
+
+	// priority is the sweep priority of the outputs, which determines the
+	// confirmation target of their sweep txn.
+	priority SweepPriority
+}
+
+// createSweepTxns accepts a list of kindergarten outputs, and partitions them
+// into the sets that should be swept together. The configured OutputSelector
+// first determines which outputs are swept, and which of them may share a
+// sweep txn, after which the outputs of each set are always grouped by
+// their sweep destination and priority tier, such that urgent outputs don't
+// force the others to pay a next-block fee rate, and if the nursery is
+// configured to segregate its sweeps, they are further grouped by witness
+// type. A signed sweep txn is then
+// generated for each set, in the order in which the set's first output was
+// encountered, along with the fee rate paid by each txn. Sets whose sweep
+// output would be dust are not swept, and their outputs are returned so that
+// they can be aggregated with other maturing outputs. Likewise, any output that
+// isn't selected, isn't yet spendable at the provided height, or whose witness
+// can't be generated, is excluded from the sweep txns and returned.
+func (u *utxoNursery) createSweepTxns(height uint32,
+	kgtnOutputs []kidOutput) ([]*wire.MsgTx, []btcutil.Amount, []kidOutput,
+	error) {
+
+	// Although the outputs were selected by their maturity height, verify
+	// that each of them is actually spendable, as an immature input would
+	// render the sweep txn invalid.
+	kgtnOutputs, immatureOutputs := excludeImmatureKinders(
+		height, kgtnOutputs,
+	)
+
+	// Consult the OutputSelector as to which of the mature outputs are
+	// swept, and which of them are swept together. Any output it leaves
+	// out is deferred.
+	outputSets, unselected := u.selectOutputs(height, kgtnOutputs)
+
+	var (
+		finalTxns       []*wire.MsgTx
+		feeRates        []btcutil.Amount
+		deferredOutputs = append(immatureOutputs, unselected...)
+	)
+	for _, outputs := range outputSets {
+		sweepClasses, classes := u.groupSweepClasses(height, outputs)
+		for _, class := range sweepClasses {
+			sweepTx, feeRate, excluded, err :=
+				u.createPartialSweepTx(height, classes[class])
+			if err != nil {
+				return nil, nil, nil, err
+			}
+
+			deferredOutputs = append(deferredOutputs, excluded...)
+			if sweepTx == nil {
+				continue
+			}
+
+			finalTxns = append(finalTxns, sweepTx)
+			feeRates = append(feeRates, feeRate)
+		}
+	}
+
+	return finalTxns, feeRates, deferredOutputs, nil
+}
+
+// selectOutputs partitions the provided mature kindergarten outputs into sets
+// using the configured OutputSelector, returning the sets along with the
+// outputs that weren't selected. Outputs the selector returns that aren't
+// among the provided outputs, or that it has already included in a previous
+// set, are ignored, as they would otherwise result in invalid or conflicting
+// sweep txns.
+func (u *utxoNursery) selectOutputs(height uint32,
+	kgtnOutputs []kidOutput) ([][]kidOutput, []kidOutput) {
+
+	candidates := make(map[wire.OutPoint]struct{}, len(kgtnOutputs))
+	for _, kid := range kgtnOutputs {
+		candidates[*kid.OutPoint()] = struct{}{}
+	}
+
+	var outputSets [][]kidOutput
+	for _, outputs := range u.cfg.OutputSelector.SelectOutputs(
+		height, kgtnOutputs,
+	) {
+
+		var outputSet []kidOutput
+		for _, kid := range outputs {
+			if _, ok := candidates[*kid.OutPoint()]; !ok {
+				utxnLog.Warnf("Ignoring output %v selected "+
+					"for sweep at height=%d, not a "+
+					"candidate", kid.OutPoint(), height)
+				continue
+			}
+			delete(candidates, *kid.OutPoint())
+
+			outputSet = append(outputSet, kid)
+		}
+
+		if len(outputSet) > 0 {
+			outputSets = append(outputSets, outputSet)
+		}
+	}
+
+	var unselected []kidOutput
+	for _, kid := range kgtnOutputs {
+		if _, ok := candidates[*kid.OutPoint()]; ok {
+			unselected = append(unselected, kid)
+		}
+	}
+
+	return outputSets, unselected
+}
+
+// groupSweepClasses groups the provided kindergarten outputs by sweep class,
+// returning the classes in the order in which each was first seen, such that
+// the resulting set of txns is deterministic.
+func (u *utxoNursery) groupSweepClasses(height uint32,
+	kgtnOutputs []kidOutput) ([]sweepClass, map[sweepClass][]kidOutput) {
+
+	var (
+		sweepClasses []sweepClass
+		classes      = make(map[sweepClass][]kidOutput)
+	)
+	for _, kid := range kgtnOutputs {
+		class := sweepClass{
+			pkScript: string(kid.SweepPkScript()),
+			priority: kid.SweepPriority(),
+		}
+
+		// Htlc outputs whose deadline is imminent are swept alongside
+		// the urgent outputs, rather than forcing the others of their
+		// tier to pay a next-block fee rate.
+		if u.sweepConfTarget(&kid, height) == urgentSweepConfTarget {
+			class.priority = SweepPriorityUrgent
+		}
+		if u.cfg.SegregateSweeps {
+			class.witnessType = kid.WitnessType()
+		}
+
+		if _, ok := classes[class]; !ok {
+			sweepClasses = append(sweepClasses, class)
+		}
+		classes[class] = append(classes[class], kid)
+	}
+
+	return sweepClasses, classes
+}
+
+// witnessError is returned by sweepCsvSpendableOutputsTxn if it's unable to
+// generate the witness for one of its inputs.
+type witnessError struct {
+	outpoint wire.OutPoint
+	err      error
+}
+
+// Error returns a human readable description of the witness error.
+func (e *witnessError) Error() string {
+	return fmt.Sprintf("unable to generate witness for input %v: %v",
+		e.outpoint, e.err)
+}
+
+// createPartialSweepTx crafts a sweep txn for the provided kindergarten outputs
+// using createSweepTx. If the witness of any output can't be generated, e.g.
+// because of a bad sign descriptor, the output is excluded and the sweep txn is
+// crafted for the remaining outputs, such that a single output can't prevent
+// the others from being swept. The outputs that weren't swept are returned
+// along with the txn, which is nil if none of the outputs remain, or if the
+// remaining outputs would only produce a dust output.
+func (u *utxoNursery) createPartialSweepTx(height uint32,
+	kgtnOutputs []kidOutput) (*wire.MsgTx, btcutil.Amount, []kidOutput,
+	error) {
+
+	var excluded []kidOutput
+	for len(kgtnOutputs) > 0 {
+		sweepTx, feeRate, err := u.createSweepTx(height, kgtnOutputs)
+		switch witErr := err.(type) {
+		case nil:
+			return sweepTx, feeRate, excluded, nil
+
+		case *witnessError:
+			utxnLog.Errorf("Excluding kindergarten output %v from "+
+				"sweep: %v", witErr.outpoint, witErr.err)
+
+			remaining := make([]kidOutput, 0, len(kgtnOutputs))
+			for _, kid := range kgtnOutputs {
+				if *kid.OutPoint() == witErr.outpoint {
+					excluded = append(excluded, kid)
+					continue
+				}
+				remaining = append(remaining, kid)
+			}
+
+			// Guard against looping forever if the failing input
+			// isn't one of our outputs.
+			if len(remaining) == len(kgtnOutputs) {
+				return nil, 0, nil, err
+			}
+			kgtnOutputs = remaining
+
+		default:
+			// Outputs too small to be swept on their own are
+			// returned, such that they can be aggregated with other
+			// maturing outputs. Likewise, outputs whose fee budgets
+			// can't pay for a relayable sweep are returned, such
+			// that their sweep is retried at a later height.
+			switch err {
+			case ErrDustSweep:
+				return nil, 0, append(excluded, kgtnOutputs...),
+					nil
+
+			case ErrFeeBudgetTooLow:
+				utxnLog.Warnf("Deferring sweep of %d "+
+					"outputs at height=%d: %v",
+					len(kgtnOutputs), height, err)
+
+				return nil, 0, append(excluded, kgtnOutputs...),
+					nil
+			}
+
+			return nil, 0, nil, err
+		}
+	}
+
+	return nil, 0, excluded, nil
+}
+
+// excludeImmatureKinders partitions the provided kindergarten outputs into
+// those whose relative lock has expired at the given height, and those that
+// aren't yet spendable. Outputs with time based relative locks are considered
+// mature, as their maturity is instead verified using the median-time-past.
+func excludeImmatureKinders(height uint32,
+	kgtnOutputs []kidOutput) ([]kidOutput, []kidOutput) {
+
+	var matureOutputs, immatureOutputs []kidOutput
+	for _, kid := range kgtnOutputs {
+		if kid.timeLocked || (kid.ConfHeight() != 0 &&
+			kid.ConfHeight()+kid.BlocksToMaturity() <= height) {
+
+			matureOutputs = append(matureOutputs, kid)
+			continue
+		}
+
+		utxnLog.Warnf("Excluding kindergarten output %v from sweep "+
+			"at height=%d, conf_height=%d, blocks_to_maturity=%d",
+			kid.OutPoint(), height, kid.ConfHeight(),
+			kid.BlocksToMaturity())
+
+		immatureOutputs = append(immatureOutputs, kid)
+	}
+
+	return matureOutputs, immatureOutputs
+}
+
+// sweepScript returns the script to which the provided kindergarten outputs
+// will be swept. This is the sweep script of the first output, or if it has
+// none, a script belonging to the wallet.
+func (u *utxoNursery) sweepScript(kgtnOutputs []kidOutput) ([]byte, error) {
+	if pkScript := kgtnOutputs[0].SweepPkScript(); len(pkScript) > 0 {
+		return pkScript, nil
+	}
+
+	if u.cfg.DeriveSweepScript != nil {
+		return u.cfg.DeriveSweepScript(
+			u.cfg.SweepAddressType, kgtnOutputs[0].OutPoint(),
+		)
+	}
+
+	return u.cfg.GenSweepScript(u.cfg.SweepAddressType)
+}
+
+// createSweepTx accepts accepts a list of kindergarten outputs, and signs and
+// generates a signed txn that spends from them. This method also makes an
+// accurate fee estimate before generating the required witnesses. All outputs
+// are swept to the sweep script of the first output, or to a script generated
+// by the wallet if it has none. The fee rate is estimated as of the given
+// height, and the fee rate paid by the txn is also returned.
+func (u *utxoNursery) createSweepTx(height uint32,
+	kgtnOutputs []kidOutput) (*wire.MsgTx, btcutil.Amount, error) {
+
+	if len(kgtnOutputs) == 0 {
+		return nil, 0, fmt.Errorf("no kindergarten outputs to sweep")
+	}
+
+	// If an external sweeper has been configured, defer the construction
+	// of the sweep txn to it.
+	if u.cfg.Sweeper != nil {
+		return u.sweepInputs(kgtnOutputs)
+	}
+
+	// Determine the receiving script to which the funds will be swept.
+	pkScript, err := u.sweepScript(kgtnOutputs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Create a transaction which sweeps all the newly mature outputs into
+	// a output controlled by the wallet.
+	// TODO(roasbeef): can be more intelligent about buffering outputs to
+	// be more efficient on-chain.
+	estimate, err := u.estimateSweep(height, kgtnOutputs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return u.sweepCsvSpendableOutputsTxn(
+		estimate.weight, estimate.confTarget, estimate.feeRateHint,
+		estimate.maxFeePerWeight, pkScript, estimate.inputs,
+	)
+}
+
+// sweepEstimate summarizes the inputs of a prospective sweep txn, along with
+// the weight and fee parameters that its construction depends on.
+type sweepEstimate struct {
+	// inputs are the csv spendable outputs that the txn will spend.
+	inputs []CsvSpendableOutput
+
+	// weight is the estimated weight of the fully signed txn.
+	weight uint64
+
+	// confTarget is the confirmation target of the most urgent input.
+	confTarget uint32
+
+	// feeRateHint is the highest fee rate preferred by any of the inputs,
+	// zero if the live fee estimate should be used.
+	feeRateHint btcutil.Amount
+
+	// maxFeePerWeight is the highest fee rate permitted by the fee budgets
+	// of the inputs, zero if no input has a budget.
+	maxFeePerWeight btcutil.Amount
+}
+
+// estimateSweepFee computes the fee that the sweep txn described by the given
+// estimate would pay, using the same fee rate bounds and fee computation as
+// sweepCsvSpendableOutputsTxn. ErrDustSweep is returned if the fee would
+// consume the entire value of the inputs.
+func (u *utxoNursery) estimateSweepFee(
+	estimate *sweepEstimate) (btcutil.Amount, error) {
+
+	_, feePerWeight, err := u.sweepTxFeeRate(
+		estimate.confTarget, estimate.feeRateHint,
+		estimate.maxFeePerWeight,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var totalIn btcutil.Amount
+	for _, input := range estimate.inputs {
+		totalIn += input.Amount()
+	}
+
+	fee, _, err := computeSweepFee(estimate.weight, feePerWeight, totalIn)
+	if err != nil {
+		return 0, err
+	}
+
+	return fee, nil
+}
+
+// estimateSweep computes the weight of a txn sweeping the given kindergarten
+// outputs, along with the confirmation target and fee rate bounds
+// derived from the outputs as of the given height. An error is returned if the
+// weight of any input can't be estimated.
+func (u *utxoNursery) estimateSweep(height uint32,
+	kgtnOutputs []kidOutput) (*sweepEstimate, error) {
+
+	// Assemble the kindergarten class into a slice csv spendable outputs,
+	// while also computing an estimate for the total transaction weight.
+	var (
+		csvSpendableOutputs []CsvSpendableOutput
+		weightEstimate      lnwallet.TxWeightEstimator
+	)
+
+	// Allocate enough room for each of the kindergarten outputs.
+	csvSpendableOutputs = make([]CsvSpendableOutput, 0, len(kgtnOutputs))
+
+	// Our sweep transaction will pay to a single output, ensure it
+	// contributes to our weight estimate.
+	u.addSweepOutputWeight(&weightEstimate, kgtnOutputs)
+
+	// Track the highest fee rate permitted by the fee budgets of the
+	// outputs being swept, a value of zero indicates that no output has a
+	// budget.
+	var maxFeePerWeight btcutil.Amount
+
+	// Track the highest fee rate preferred by any of the outputs, such
+	// that none of them are swept at a lower rate than requested. A value
+	// of zero indicates that the live fee estimate should be used.
+	var feeRateHint btcutil.Amount
+
+	// Likewise, estimate the fee rate using the confirmation target of the
+	// most urgent output being swept.
+	var confTarget uint32
+
+	// For each kindergarten output, use its witness type to determine the
+	// estimate weight of its witness.
+	for i := range kgtnOutputs {
+		input := &kgtnOutputs[i]
+
+		// Refuse to craft the sweep if we are unable to estimate the
+		// weight of any input, rather than silently omitting it.
+		witnessWeight, err := sweepWitnessSize(input.WitnessType())
+		if err != nil {
+			return nil, fmt.Errorf("unable to sweep kindergarten "+
+				"output %v: %v", input.OutPoint(), err)
+		}
+
+		// Add the kindergarten output's input and witness to our
+		// running estimate.
+		weightEstimate.AddWitnessInput(witnessWeight)
+
+		// If this output has a fee budget, compute the fee rate at
+		// which the weight it adds to the transaction would exhaust its
+		// budget, and lower our maximum fee rate accordingly. A budget
+		// smaller than the weight of the input can't pay even the
+		// lowest non-zero fee rate.
+		if input.FeeBudget() > 0 {
+			inputWeight := btcutil.Amount(
+				lnwallet.InputSize*blockchain.WitnessScaleFactor +
+					witnessWeight,
+			)
+			budgetFeePerWeight := input.FeeBudget() / inputWeight
+			if budgetFeePerWeight == 0 {
+				return nil, ErrFeeBudgetTooLow
+			}
+			if maxFeePerWeight == 0 ||
+				budgetFeePerWeight < maxFeePerWeight {
+
+				maxFeePerWeight = budgetFeePerWeight
+			}
+		}
+
+		if input.SweepFeeRate() > feeRateHint {
+			feeRateHint = input.SweepFeeRate()
+		}
+
+		target := u.sweepConfTarget(input, height)
+		if confTarget == 0 || target < confTarget {
+			confTarget = target
+		}
+
+		// Include this input in the transaction.
+		csvSpendableOutputs = append(csvSpendableOutputs, input)
+	}
+
+	return &sweepEstimate{
+		inputs:          csvSpendableOutputs,
+		weight:          uint64(weightEstimate.Weight()),
+		confTarget:      confTarget,
+		feeRateHint:     feeRateHint,
+		maxFeePerWeight: maxFeePerWeight,
+	}, nil
+}
+
+// sweepInputs crafts a txn sweeping the given kindergarten outputs using the
+// configured Sweeper, returning the txn along with the fee rate it pays. Since
+// the nursery will only graduate outputs swept by their height's txns, the
+// returned txn must spend every one of the outputs.
+func (u *utxoNursery) sweepInputs(kgtnOutputs []kidOutput) (*wire.MsgTx,
+	btcutil.Amount, error) {
+
+	inputs := make([]CsvSpendableOutput, 0, len(kgtnOutputs))
+	for i := range kgtnOutputs {
+		inputs = append(inputs, &kgtnOutputs[i])
+	}
+
+	sweepTx, err := u.cfg.Sweeper.SweepInputs(inputs)
+	if err != nil {
+		return nil, 0, err
+	}
+	if sweepTx == nil {
+		return nil, 0, fmt.Errorf("sweeper returned no sweep tx")
+	}
+
+	// Ensure that each of the kindergarten outputs is spent by the txn.
+	spent := make(map[wire.OutPoint]struct{}, len(sweepTx.TxIn))
+	for _, txIn := range sweepTx.TxIn {
+		spent[txIn.PreviousOutPoint] = struct{}{}
+	}
+
+	var totalIn btcutil.Amount
+	for i := range kgtnOutputs {
+		kid := &kgtnOutputs[i]
+		if _, ok := spent[*kid.OutPoint()]; !ok {
+			return nil, 0, fmt.Errorf("sweep tx %v does not spend "+
+				"kindergarten output %v", sweepTx.TxHash(),
+				kid.OutPoint())
+		}
+		totalIn += kid.Amount()
+	}
+
+	// The sweeper may fund the txn with additional inputs, in which case
+	// we're unable to determine the fee it pays, and record a fee rate of
+	// zero.
+	if len(sweepTx.TxIn) != len(kgtnOutputs) {
+		return sweepTx, 0, nil
+	}
+
+	var totalOut btcutil.Amount
+	for _, txOut := range sweepTx.TxOut {
+		totalOut += btcutil.Amount(txOut.Value)
+	}
+	if totalOut > totalIn {
+		return sweepTx, 0, nil
+	}
+
+	txWeight := blockchain.GetTransactionWeight(btcutil.NewTx(sweepTx))
+	if txWeight == 0 {
+		return sweepTx, 0, nil
+	}
+
+	return sweepTx, (totalIn - totalOut) / btcutil.Amount(txWeight), nil
+}
+
+// sweepWitnessSize returns the size of the witness required to sweep an output
+// of the given witness type. An error is returned for witness types that
+// GenWitnessFunc is unable to sign, e.g. HtlcAcceptedSuccess, as the output
+// could never be swept.
+func sweepWitnessSize(witnessType lnwallet.WitnessType) (int, error) {
+	switch witnessType {
+	case lnwallet.CommitmentTimeLock:
+		return lnwallet.ToLocalTimeoutWitnessSize, nil
+
+	case lnwallet.CommitmentNoDelay:
+		return lnwallet.P2WKHWitnessSize, nil
+
+	case lnwallet.CommitmentRevoke:
+		return lnwallet.ToLocalPenaltyWitnessSize, nil
+
+	case lnwallet.HtlcOfferedRevoke:
+		return lnwallet.OfferedHtlcPenaltyWitnessSize, nil
+
+	case lnwallet.HtlcAcceptedRevoke:
+		return lnwallet.AcceptedHtlcPenaltyWitnessSize, nil
+
+	case lnwallet.HtlcOfferedTimeout:
+		return lnwallet.OfferedHtlcTimeoutWitnessSize, nil
+
+	case lnwallet.CommitmentAnchor:
+		return lnwallet.AnchorWitnessSize, nil
+
+	default:
+		return 0, fmt.Errorf("unsupported witness type: %v",
+			witnessType)
+	}
+}
+
+// estimateRecoveryFee estimates the fee that will be paid to sweep the given
+// kindergarten outputs in a single transaction at the current fee rate. The
+// estimate uses the same weight estimation and fee computation as
+// createSweepTx, such that the fee budgets of the outputs and the minimum relay
+// fee rate are respected.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) estimateRecoveryFee(
+	kgtnOutputs []kidOutput) (btcutil.Amount, error) {
+
+	if len(kgtnOutputs) == 0 {
+		return 0, nil
+	}
+
+	estimate, err := u.estimateSweep(u.bestHeight, kgtnOutputs)
+	if err != nil {
+		return 0, err
+	}
+
+	return u.estimateSweepFee(estimate)
+}
+
+// isEconomical determines whether the given output retains at least the
+// EconomicalSweepThreshold after deducting the cost of its input to a sweep txn
+// paying the given fee rate. A zero fee rate indicates that the check is
+// disabled. Each abandoned output is logged, along with the reason why.
+func (u *utxoNursery) isEconomical(kid *kidOutput,
+	feePerWeight btcutil.Amount) bool {
+
+	if feePerWeight == 0 {
+		return true
+	}
+
+	// If we're unable to estimate the weight of the output's witness, we
+	// leave it to the sweep to surface the error.
+	witnessWeight, err := sweepWitnessSize(kid.WitnessType())
+	if err != nil {
+		return true
+	}
+
+	inputWeight := btcutil.Amount(
+		lnwallet.InputSize*blockchain.WitnessScaleFactor + witnessWeight,
+	)
+	sweepCost := feePerWeight * inputWeight
+
+	if kid.Amount()-sweepCost >= u.cfg.EconomicalSweepThreshold {
+		return true
+	}
+
+	utxnLog.Infof("Abandoning output %v of Channel(%s), value=%v is "+
+		"uneconomical to sweep at an estimated cost of %v, threshold=%v",
+		kid.OutPoint(), kid.OriginChanPoint(), kid.Amount(), sweepCost,
+		u.cfg.EconomicalSweepThreshold)
+
+	return false
+}
+
+// sweepFeeRate queries the fee estimator for the fee rate at which sweeps
+// targeting confirmation within confTarget blocks should be published,
+// returning both the estimated rate and the rate after clamping it to the
+// configured bounds. If the estimator fails, the FallbackFeeRate is used in
+// its place, if configured.
+func (u *utxoNursery) sweepFeeRate(confTarget uint32) (btcutil.Amount,
+	btcutil.Amount, error) {
+
+	estimatedFeePerWeight, err := u.estimateFeeRate(confTarget)
+	if err != nil {
+		if u.cfg.OnFeeEstimationError != nil {
+			u.cfg.OnFeeEstimationError(confTarget, err)
+		}
+		if u.cfg.FallbackFeeRate == 0 {
+			return 0, 0, err
+		}
+
+		utxnLog.Warnf("Unable to estimate fee rate for conf target "+
+			"%d, using fallback of %v sat/weight: %v", confTarget,
+			int64(u.cfg.FallbackFeeRate), err)
+
+		estimatedFeePerWeight = u.cfg.FallbackFeeRate
+	}
+
+	// Clamp the estimated fee rate to the configured bounds, guarding
+	// against a misbehaving fee estimator.
+	return estimatedFeePerWeight, u.clampFeeRate(estimatedFeePerWeight), nil
+}
+
+// estimateFeeRate queries the fee estimator for the given confirmation target,
+// converting a panic within the estimator into an error, and rejecting
+// estimates that are non-positive or above absurdFeePerWeight. A zero estimate
+// results in ErrZeroFeeEstimate.
+func (u *utxoNursery) estimateFeeRate(
+	confTarget uint32) (feePerWeight btcutil.Amount, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			feePerWeight = 0
+			err = fmt.Errorf("fee estimator panicked: %v", r)
+		}
+	}()
+
+	feePerWeight, err = u.cfg.Estimator.EstimateFeePerWeight(confTarget)
+	switch {
+	case err != nil:
+		return 0, err
+
+	case feePerWeight == 0:
+		return 0, ErrZeroFeeEstimate
+
+	case feePerWeight < 0 || feePerWeight > absurdFeePerWeight:
+		return 0, fmt.Errorf("absurd fee rate estimate of %v "+
+			"sat/weight", int64(feePerWeight))
+	}
+
+	return feePerWeight, nil
+}
+
+// clampFeeRate bounds the given fee rate, in satoshis per unit of weight, by
+// the configured MinFeeRate and MaxFeeRate.
+func (u *utxoNursery) clampFeeRate(feePerWeight btcutil.Amount) btcutil.Amount {
+	if u.cfg.MinFeeRate != 0 && feePerWeight < u.cfg.MinFeeRate {
+		feePerWeight = u.cfg.MinFeeRate
+	}
+	if u.cfg.MaxFeeRate != 0 && feePerWeight > u.cfg.MaxFeeRate {
+		feePerWeight = u.cfg.MaxFeeRate
+	}
+
+	return feePerWeight
+}
+
+// addSweepOutputWeight adds the weight of the output sweeping the given
+// kindergarten outputs to the estimate. The size of a sweep script provided
+// upon incubation is known exactly, otherwise the outputs are swept to a wallet
+// address of the configured SweepAddressType.
+func (u *utxoNursery) addSweepOutputWeight(
+	weightEstimate *lnwallet.TxWeightEstimator, kgtnOutputs []kidOutput) {
+
+	if pkScript := kgtnOutputs[0].SweepPkScript(); len(pkScript) > 0 {
+		weightEstimate.AddOutput(pkScript)
+		return
+	}
+
+	switch u.cfg.SweepAddressType {
+	case lnwallet.NestedWitnessPubKey:
+		weightEstimate.AddP2SHOutput()
+
+	case lnwallet.PubKeyHash:
+		weightEstimate.AddP2PKHOutput()
+
+	case lnwallet.TaprootPubKey:
+		weightEstimate.AddP2TROutput()
+
+	default:
+		weightEstimate.AddP2WKHOutput()
+	}
+}
+
+// sweepCsvSpendableOutputsTxn creates a final sweeping transaction with all
+// witnesses in place for all inputs using the provided txn fee. The created
+// transaction has a single output sending all the funds to pkScript, after
+// accounting for the fee estimate, which targets confirmation within
+// confTarget blocks. If feeRateHint is non-zero, it is used in place of the
+// live fee estimate. If maxFeePerWeight is non-zero, the
+// estimated fee rate will be capped to this value, such that the fee budgets
+// of the inputs are respected. The fee rate paid by the transaction, in
+// satoshis per unit of weight, is returned alongside it.
+func (u *utxoNursery) sweepCsvSpendableOutputsTxn(txWeight uint64,
+	confTarget uint32, feeRateHint, maxFeePerWeight btcutil.Amount,
+	pkScript []byte,
+	inputs []CsvSpendableOutput) (*wire.MsgTx, btcutil.Amount, error) {
+
+	// Sum up the total value contained in the inputs.
+	var totalSum btcutil.Amount
+	for _, o := range inputs {
+		totalSum += o.Amount()
+	}
+
+	// Using the txn weight estimate, compute the required txn fee.
+	estimatedFeePerWeight, feePerWeight, err := u.sweepTxFeeRate(
+		confTarget, feeRateHint, maxFeePerWeight,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// A zero fee txn won't be relayed, and its confirmation would never
+	// be observed, stranding the inputs. Refuse to craft it, such that the
+	// inputs are deferred to the next height.
+	if feePerWeight == 0 {
+		return nil, 0, ErrZeroFeeEstimate
+	}
+
+	// Using the final fee rate, compute the txn fee and sweep as much as
+	// possible after subtracting it.
+	txFee, sweepAmt, err := computeSweepFee(txWeight, feePerWeight, totalSum)
+	if err != nil && err != ErrDustSweep {
+		return nil, 0, fmt.Errorf("unable to sweep %v at fee rate of %v "+
+			"sat/weight: %v", totalSum, int64(feePerWeight), err)
+	}
+
+	// Refuse to craft a sweep whose output would be rejected as dust, the
+	// inputs will instead be aggregated with other maturing outputs.
+	dustLimit := txrules.GetDustThreshold(
+		len(pkScript), txrules.DefaultRelayFeePerKb,
+	)
+	if err == ErrDustSweep || sweepAmt < dustLimit {
+		utxnLog.Infof("Not sweeping %d outputs totaling %v, output "+
+			"after fees at %v sat/weight would be below the dust "+
+			"limit of %v", len(inputs), totalSum,
+			int64(feePerWeight), dustLimit)
+
+		return nil, 0, ErrDustSweep
+	}
+
+	utxnLog.Infof("Sweeping %v with fee=%v at effective fee rate of %v "+
+		"sat/weight, estimated rate was %v sat/weight", totalSum,
+		txFee, int64(feePerWeight), int64(estimatedFeePerWeight))
+
+	// Create the sweep transaction that we will be building. We use
+	// version 2 as it is required for CSV. The txn will sweep the amount
+	// after fees to the pkscript generated above.
+	sweepTx := wire.NewMsgTx(2)
+	sweepTx.AddTxOut(&wire.TxOut{
+		PkScript: pkScript,
+		Value:    int64(sweepAmt),
+	})
+
+	// Add all of our inputs, including the respective CSV delays.
+	for _, input := range inputs {
+		sweepTx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: *input.OutPoint(),
+			Sequence:         input.Sequence(),
+		})
+	}
+
+	// Before signing the transaction, check to ensure that it meets some
+	// basic validity requirements.
+	// TODO(conner): add more control to sanity checks, allowing us to delay
+	// spending "problem" outputs, e.g. possibly batching with other classes
+	// if fees are too low.
+	btx := btcutil.NewTx(sweepTx)
+	if err := blockchain.CheckTransactionSanity(btx); err != nil {
+		return nil, 0, err
+	}
+
+	hashCache := txscript.NewTxSigHashes(sweepTx)
+
+	// With all the inputs in place, use each output's unique witness
+	// function to generate the final witness required for spending.
+	addWitness := func(idx int, tso CsvSpendableOutput) error {
+		witness, err := tso.BuildWitness(u.cfg.Signer, sweepTx, hashCache, idx)
+		if err != nil {
+			return err
+		}
+
+		sweepTx.TxIn[idx].Witness = witness
+
+		return nil
+	}
+
+	for i, input := range inputs {
+		if err := addWitness(i, input); err != nil {
+			return nil, 0, &witnessError{
+				outpoint: *input.OutPoint(),
+				err:      err,
+			}
+		}
+	}
+
+	return sweepTx, feePerWeight, nil
+}
+
+// sweepConfTarget returns the confirmation target used to estimate the fee rate
+// of the kindergarten output's sweep at the given height. This is the target of
+// the output's priority tier, unless the output is derived from an htlc and the
+// HtlcSweepDeadline is configured. The sweep of such an output then targets
+// confirmation within half of the blocks remaining until its deadline, such
+// that a sweep that has been delayed, e.g. by buffering or a dust output, is
+// escalated towards the next block as the deadline approaches.
+func (u *utxoNursery) sweepConfTarget(kid *kidOutput, height uint32) uint32 {
+	target := kid.SweepPriority().ConfTarget(u.cfg.SweepConfTarget)
+	if u.cfg.HtlcSweepDeadline == 0 || !isHtlcOutput(kid) {
+		return target
+	}
+
+	deadline := kid.MaturityHeight() + u.cfg.HtlcSweepDeadline
+	if height >= deadline {
+		return urgentSweepConfTarget
+	}
+
+	deadlineTarget := (deadline - height) / 2
+	if deadlineTarget < urgentSweepConfTarget {
+		deadlineTarget = urgentSweepConfTarget
+	}
+	if deadlineTarget < target {
+		return deadlineTarget
+	}
+
+	return target
+}
+
+// sweepTxFeeRate determines the fee rate, in satoshis per unit of weight, at
+// which a sweep txn with the given confirmation target and fee rate bounds will
+// be crafted. If the outputs carry a preferred fee rate, it takes precedence
+// over the live estimate, though it remains subject to the configured bounds.
+// Both the estimated and the final fee rate are returned.
+func (u *utxoNursery) sweepTxFeeRate(confTarget uint32, feeRateHint,
+	maxFeePerWeight btcutil.Amount) (btcutil.Amount, btcutil.Amount,
+	error) {
+
+	var estimatedFeePerWeight, feePerWeight btcutil.Amount
+	if feeRateHint > 0 {
+		estimatedFeePerWeight = feeRateHint
+		feePerWeight = u.clampFeeRate(feeRateHint)
+	} else {
+		var err error
+		estimatedFeePerWeight, feePerWeight, err = u.sweepFeeRate(
+			confTarget,
+		)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	// Never exceed the fee rate permitted by the fee budgets of our
+	// inputs, opting for a slower confirmation instead.
+	if maxFeePerWeight != 0 && feePerWeight > maxFeePerWeight {
+		utxnLog.Infof("Capping sweep fee rate at %v sat/weight to "+
+			"respect output fee budgets, estimated rate was %v "+
+			"sat/weight", int64(maxFeePerWeight),
+			int64(feePerWeight))
+
+		feePerWeight = maxFeePerWeight
+	}
+
+	// A sweep paying less than the minimum relay fee would never reach
+	// the miners, so the floor overrides the other bounds. However, we
+	// refuse to exceed the fee budgets of the inputs to do so.
+	if u.cfg.MinRelayFeeRate != 0 && feePerWeight < u.cfg.MinRelayFeeRate {
+		if maxFeePerWeight != 0 &&
+			u.cfg.MinRelayFeeRate > maxFeePerWeight {
+
+			return 0, 0, ErrFeeBudgetTooLow
+		}
+
+		utxnLog.Infof("Raising sweep fee rate of %v sat/weight to "+
+			"minimum relay fee rate of %v sat/weight",
+			int64(feePerWeight), int64(u.cfg.MinRelayFeeRate))
+
+		feePerWeight = u.cfg.MinRelayFeeRate
+	}
+
+	return estimatedFeePerWeight, feePerWeight, nil
+}
+
+// computeSweepFee computes the fee for a sweep txn of the given weight at the
+// given fee rate, denominated in satoshis per unit of weight, along with the
+// value remaining for the sweep output after paying the fee from totalIn. A
+// zero fee rate results in a zero fee. An error is returned if the weight is
+// zero, if the fee rate or total input is negative, or if the fee overflows.
+// If the fee would consume the entire input value, ErrDustSweep is returned.
+func computeSweepFee(weight uint64, feePerWeight,
+	totalIn btcutil.Amount) (btcutil.Amount, btcutil.Amount, error) {
+
+	switch {
+	case weight == 0:
+		return 0, 0, fmt.Errorf("unable to compute fee for sweep " +
+			"txn with zero weight")
+
+	case feePerWeight < 0:
+		return 0, 0, fmt.Errorf("invalid negative fee rate: %v "+
+			"sat/weight", int64(feePerWeight))
+
+	case totalIn < 0:
+		return 0, 0, fmt.Errorf("invalid negative sweep input "+
+			"value: %v", totalIn)
+	}
+
+	// Ensure that multiplying the weight by the fee rate cannot overflow
+	// the int64 backing a btcutil.Amount.
+	if feePerWeight != 0 &&
+		weight > uint64(math.MaxInt64/int64(feePerWeight)) {
+
+		return 0, 0, fmt.Errorf("fee for sweep txn of weight %d at "+
+			"%v sat/weight overflows", weight, int64(feePerWeight))
+	}
+	fee := btcutil.Amount(weight) * feePerWeight
+
+	// The fee must leave a positive value for the sweep output, otherwise
+	// the txn would be invalid.
+	if fee >= totalIn {
+		return 0, 0, ErrDustSweep
+	}
+
+	return fee, totalIn - fee, nil
+}
+
+// sweepGraduatingKinders generates and broadcasts the transactions that
+// transfer control of funds from a channel commitment transaction to the
+// user's wallet.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex, or
+// during startup.
+func (u *utxoNursery) sweepGraduatingKinders(classHeight uint32,
+	finalTxns []*wire.MsgTx, kgtnOutputs []kidOutput) error {
+
+	delayed := newDelayedBroadcasts()
+	defer u.publishDelayed(delayed)
+
+	return u.broadcastKinderSweeps(
+		classHeight, finalTxns, kgtnOutputs, delayed,
+	)
+}
+
+// broadcastKinderSweeps broadcasts the finalized sweep txns at the given
+// height, and registers for their confirmation. Any broadcast to be spaced out
+// from the previous one is added to the delayed broadcasts instead.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex, or
+// during startup.
+func (u *utxoNursery) broadcastKinderSweeps(classHeight uint32,
+	finalTxns []*wire.MsgTx, kgtnOutputs []kidOutput,
+	delayed *delayedBroadcasts) error {
+
+	var numPublished int
+	for _, finalTx := range finalTxns {
+		finalTx := finalTx
+
+		// The outputs of a sweep txn that has already confirmed may
+		// have graduated, while the height's other sweeps have yet to.
+		if len(spentKinders(finalTx, kgtnOutputs)) == 0 {
+			continue
+		}
+
+		// An external service we handed the outputs off to, or another
+		// instance of the nursery operating on the same channel state,
+		// may have already swept the inputs with a conflicting txn.
+		// Our sweep would then be rejected, and its confirmation never
+		// delivered, so the outputs are instead graduated once the
+		// conflicting sweep confirms.
+		spent := u.sweptExternally(finalTx, kgtnOutputs)
+		if spent != nil {
+			utxnLog.Infof("Skipping broadcast of sweep tx "+
+				"(txid=%v), input %v swept externally",
+				finalTx.TxHash(), spent.OutPoint())
+		} else {
+			spent = u.spentOnChain(finalTx, kgtnOutputs)
+			if spent != nil {
+				utxnLog.Warnf("Skipping broadcast of sweep tx "+
+					"(txid=%v), input %v already spent "+
+					"on-chain", finalTx.TxHash(),
+					spent.OutPoint())
+			}
+		}
+
+		if spent != nil {
+			err := u.registerConflictingSpend(
+				classHeight, finalTxns, spent, kgtnOutputs,
+			)
+			if err != nil {
+				return err
+			}
+
+			// The outputs of the conflicted sweep are excluded,
+			// such that no confirmation is registered for it.
+			kgtnOutputs = unsweptKinders(finalTx, kgtnOutputs)
+			continue
+		}
+
+		sweptOutputs := spentKinders(finalTx, kgtnOutputs)
+		if u.delayBroadcast(
+			delayed, classHeight, kndrPrefix, finalTx, sweptOutputs,
+		) {
+			continue
+		}
+
+		utxnLog.Infof("Sweeping %v CSV-delayed outputs with sweep tx "+
+			"(txid=%v): %v", len(finalTx.TxIn), finalTx.TxHash(),
+			newLogClosure(func() string {
+				return spew.Sdump(finalTx)
+			}),
+		)
+
+		// With the sweep transaction fully signed, broadcast the
+		// transaction to the network. Additionally, we can stop
+		// tracking these outputs as they've just been swept.
+		err := classifyPublishErr(u.cfg.PublishTransaction(finalTx))
+		switch err {
+		case nil, ErrTxAlreadyPublished:
+
+		// If the backend is unreachable, we still register for the
+		// sweep's confirmation, and republish the txn upon the arrival
+		// of the next block. This prevents a transient failure from
+		// aborting the sweeps of the remaining outputs.
+		case ErrPublishConnectivity:
+			utxnLog.Warnf("Unable to broadcast sweep tx (txid=%v), "+
+				"will retry at next block: %v",
+				finalTx.TxHash(), err)
+			u.queueRepublish(
+				classHeight, kndrPrefix, finalTx, sweptOutputs,
+			)
+			continue
+
+		default:
+			utxnLog.Errorf("unable to broadcast sweep tx: %v, %v",
+				err, spew.Sdump(finalTx))
+			return err
+		}
+		numPublished++
+
+		u.txPublished(kndrPrefix, finalTx, sweptOutputs)
+	}
+
+	// Record the broadcast, so that we can detect sweeps that are
+	// repeatedly rebroadcast without confirming.
+	if numPublished > 0 {
+		if err := u.recordSweepBroadcast(classHeight); err != nil {
+			return err
+		}
+	}
+
+	return u.registerSweepConf(finalTxns, kgtnOutputs, classHeight)
+}
+
+// handoffSweep hands off the mature kindergarten outputs at the given height to
+// the configured ExternalSweeper, and registers for the confirmation of the
+// sweep txn it reports, upon which the outputs are graduated.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex, or
+// during startup.
+func (u *utxoNursery) handoffSweep(classHeight uint32,
+	kgtnOutputs []kidOutput) error {
+
+	inputs := make([]CsvSpendableOutput, 0, len(kgtnOutputs))
+	for i := range kgtnOutputs {
+		inputs = append(inputs, &kgtnOutputs[i])
+	}
+
+	sweepTxID, err := u.cfg.ExternalSweeper.SweepOutputs(inputs)
+	if err != nil {
+		return err
+	}
+	if sweepTxID == nil {
+		return fmt.Errorf("external sweeper returned no sweep txid")
+	}
+
+	utxnLog.Infof("Handed off %d kindergarten outputs at height=%d to "+
+		"external sweeper, sweep txid=%v", len(kgtnOutputs),
+		classHeight, sweepTxID)
+
+	u.cfg.Metrics.AddOutputsSwept(string(kndrPrefix), len(kgtnOutputs))
+
+	for i := range kgtnOutputs {
+		u.notifyEvent(newOutputEvent(
+			NurseryEventSwept, kndrPrefix, &kgtnOutputs[i],
+		))
+	}
+
+	desc := fmt.Sprintf("external sweep confirmation of %d "+
+		"kindergarten outputs at height=%d", len(kgtnOutputs),
+		classHeight)
+
+	return u.startConfWatcher(desc, func() (func(), error) {
+		confChan, err := u.cfg.Notifier.RegisterConfirmationsNtfn(
+			sweepTxID, u.cfg.SweepConfDepth, classHeight,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		tracked := atomic.LoadUint32(&u.draining) == 0
+		if tracked {
+			u.graduations.Add(1)
+		}
+
+		return func() {
+			u.waitForExternalSweepConf(
+				classHeight, *sweepTxID, kgtnOutputs, confChan,
+				tracked,
+			)
+		}, nil
+	})
+}
+
+// waitForExternalSweepConf watches for the confirmation of a sweep txn crafted
+// by the ExternalSweeper, and graduates the kindergarten outputs it swept once
+// confirmed. Since the nursery doesn't hold the txn, it can't rebroadcast it,
+// and thus doesn't watch for its confirmation to be reorged out of the chain.
+// If tracked is set, the goroutine is counted towards the in-flight
+// graduations awaited by Drain.
+//
+// NOTE: This method MUST be called as a go routine.
+func (u *utxoNursery) waitForExternalSweepConf(classHeight uint32,
+	sweepTxID chainhash.Hash, kgtnOutputs []kidOutput,
+	confChan *chainntnfs.ConfirmationEvent, tracked bool) {
+
+	defer u.wg.Done()
+	if tracked {
+		defer u.graduations.Done()
+	}
+
+	for {
+		select {
+		case txConfirmation, ok := <-confChan.Confirmed:
+			if !ok {
+				confChan = u.reregisterConf(
+					&sweepTxID, u.cfg.SweepConfDepth,
+					classHeight,
+				)
+				if confChan == nil {
+					return
+				}
+				continue
+			}
+
+			u.graduateKinders(
+				classHeight, nil, kgtnOutputs,
+				txConfirmation.BlockHeight,
+			)
+			return
+
+		case <-u.quit:
+			return
+		}
+	}
+}
+
+// spentKinders returns the kindergarten outputs that are spent by the given
+// sweep txn.
+func spentKinders(sweepTx *wire.MsgTx,
+	kgtnOutputs []kidOutput) []CsvSpendableOutput {
+
+	spent := make(map[wire.OutPoint]struct{}, len(sweepTx.TxIn))
+	for _, txIn := range sweepTx.TxIn {
+		spent[txIn.PreviousOutPoint] = struct{}{}
+	}
+
+	var outputs []CsvSpendableOutput
+	for i := range kgtnOutputs {
+		if _, ok := spent[*kgtnOutputs[i].OutPoint()]; ok {
+			outputs = append(outputs, &kgtnOutputs[i])
+		}
+	}
+
+	return outputs
+}
+
+// sweptKinders behaves like spentKinders, but returns copies of the spent
+// kindergarten outputs.
+func sweptKinders(sweepTx *wire.MsgTx, kgtnOutputs []kidOutput) []kidOutput {
+	spent := spentKinders(sweepTx, kgtnOutputs)
+
+	outputs := make([]kidOutput, 0, len(spent))
+	for _, output := range spent {
+		outputs = append(outputs, *output.(*kidOutput))
+	}
+
+	return outputs
+}
+
+// unsweptKinders returns copies of the kindergarten outputs that aren't spent
+// by the given sweep txn.
+func unsweptKinders(sweepTx *wire.MsgTx,
+	kgtnOutputs []kidOutput) []kidOutput {
+
+	spent := make(map[wire.OutPoint]struct{}, len(sweepTx.TxIn))
+	for _, txIn := range sweepTx.TxIn {
+		spent[txIn.PreviousOutPoint] = struct{}{}
+	}
+
+	outputs := make([]kidOutput, 0, len(kgtnOutputs))
+	for _, kid := range kgtnOutputs {
+		if _, ok := spent[*kid.OutPoint()]; !ok {
+			outputs = append(outputs, kid)
+		}
+	}
+
+	return outputs
+}
+
+// sweepValues returns the total value of the provided kindergarten outputs
+// spent by the sweep txn, along with the fee paid by the txn and the net value
+// it returns to the wallet.
+func sweepValues(sweepTx *wire.MsgTx,
+	kgtnOutputs []kidOutput) (btcutil.Amount, btcutil.Amount,
+	btcutil.Amount) {
+
+	var totalIn, netSwept btcutil.Amount
+	for _, output := range spentKinders(sweepTx, kgtnOutputs) {
+		totalIn += output.Amount()
+	}
+	for _, txOut := range sweepTx.TxOut {
+		netSwept += btcutil.Amount(txOut.Value)
+	}
+
+	return totalIn, totalIn - netSwept, netSwept
+}
+
+// delayedBroadcasts collects the txns at a height whose broadcast is spaced out
+// from the previous one by a random delay of up to BroadcastJitter.
+type delayedBroadcasts struct {
+	// seen maps the txid of each txn broadcast at the height so far to
+	// whether its broadcast was delayed.
+	seen map[chainhash.Hash]bool
+
+	// txns are the txns to be broadcast after a delay, in order.
+	txns []*wire.MsgTx
+}
+
+// newDelayedBroadcasts returns an empty set of delayed broadcasts.
+func newDelayedBroadcasts() *delayedBroadcasts {
+	return &delayedBroadcasts{
+		seen: make(map[chainhash.Hash]bool),
+	}
+}
+
+// delayBroadcast returns true if the broadcast of the given txn, spending
+// outputs in the given nursery state, should be delayed. This is the case for
+// all but the first txn broadcast at a height if BroadcastJitter is set. A
+// delayed txn is queued for republication, such that its broadcast is
+// accounted for, or retried if the delayed broadcast fails, upon the arrival
+// of the next block. A txn spending several outputs, e.g. a batched timeout
+// txn, is only broadcast once.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex, or
+// during startup.
+func (u *utxoNursery) delayBroadcast(delayed *delayedBroadcasts,
+	height uint32, state []byte, tx *wire.MsgTx,
+	outputs []CsvSpendableOutput) bool {
+
+	if u.cfg.BroadcastJitter <= 0 {
+		return false
+	}
+
+	txid := tx.TxHash()
+	isDelayed, ok := delayed.seen[txid]
+	switch {
+	// Repeated broadcasts of a txn that has been broadcast immediately
+	// are reported as already published.
+	case ok && !isDelayed:
+		return false
+
+	case !ok && len(delayed.seen) == 0:
+		delayed.seen[txid] = false
+		return false
+
+	case !ok:
+		delayed.seen[txid] = true
+		delayed.txns = append(delayed.txns, tx)
+	}
+
+	u.queueRepublish(height, state, tx, outputs)
+
+	return true
+}
+
+// publishDelayed launches a goroutine that broadcasts the delayed txns, waiting
+// for a random duration of up to BroadcastJitter before each.
+func (u *utxoNursery) publishDelayed(delayed *delayedBroadcasts) {
+	if len(delayed.txns) == 0 {
+		return
+	}
+
+	txns := make([]*wire.MsgTx, len(delayed.txns))
+	copy(txns, delayed.txns)
+
+	u.wg.Add(1)
+	go u.broadcastWithJitter(txns)
+}
+
+// broadcastWithJitter broadcasts the given txns, waiting for a random duration
+// of up to BroadcastJitter before each. It operates solely on the provided
+// txns, and never acquires the nursery's mutex. The txns remain queued for
+// republication, so a failed broadcast is merely logged, and retried upon the
+// arrival of the next block.
+//
+// NOTE: This method MUST be called as a go routine.
+func (u *utxoNursery) broadcastWithJitter(txns []*wire.MsgTx) {
+	defer u.wg.Done()
+
+	for _, tx := range txns {
+		if err := u.waitBroadcastJitter(); err != nil {
+			return
+		}
+
+		err := classifyPublishErr(u.cfg.PublishTransaction(tx))
+		switch err {
+		case nil, ErrTxAlreadyPublished:
+			utxnLog.Infof("Published delayed tx (txid=%v)",
+				tx.TxHash())
+
+		default:
+			utxnLog.Warnf("Unable to publish delayed tx "+
+				"(txid=%v), will retry at next block: %v",
+				tx.TxHash(), err)
+		}
+	}
+}
+
+// waitBroadcastJitter waits for a random duration of up to BroadcastJitter,
+// and is used to space out consecutive broadcasts. If the nursery shuts down
+// while waiting, ErrNurseryShuttingDown is returned.
+//
+// NOTE: This method MUST NOT be called while holding the nursery's mutex.
+func (u *utxoNursery) waitBroadcastJitter() error {
+	if u.cfg.BroadcastJitter <= 0 {
+		return nil
+	}
+
+	delay := time.Duration(rand.Int63n(int64(u.cfg.BroadcastJitter)))
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-u.quit:
+		return ErrNurseryShuttingDown
+	}
+}
+
+// recordSweepBroadcast increments the broadcast count of the finalized sweep
+// txns at the given height, warning if they have been rebroadcast more than
+// the configured threshold without confirming.
+func (u *utxoNursery) recordSweepBroadcast(classHeight uint32) error {
+	numBroadcasts, err := u.cfg.Store.RecordSweepBroadcast(classHeight)
+	if err != nil {
+		return err
+	}
+
+	// The first broadcast is not counted as a rebroadcast.
+	rebroadcasts := numBroadcasts - 1
+	if u.cfg.RebroadcastWarnThreshold != 0 &&
+		rebroadcasts > u.cfg.RebroadcastWarnThreshold {
+
+		utxnLog.Warnf("Sweep txns at height=%d have been rebroadcast "+
+			"%d times without confirming, they may need their fee "+
+			"bumped", classHeight, rebroadcasts)
+	}
+
+	return nil
+}
+
+// unpublishedTx is a txn the nursery was unable to publish due to a
+// connectivity issue with the backend.
+type unpublishedTx struct {
+	// height is the height at which the txn was to be broadcast.
+	height uint32
+
+	// state is the prefix of the nursery state of the outputs spent by
+	// the txn, either cribPrefix or kndrPrefix.
+	state []byte
+
+	// tx is the htlc timeout txn or kindergarten sweep txn.
+	tx *wire.MsgTx
+
+	// outputs are the nursery outputs spent by the txn.
+	outputs []CsvSpendableOutput
+}
+
+// queueRepublish records a txn that could not be published due to a
+// connectivity issue with the backend, such that it's republished upon the
+// arrival of the next block. The outputs of a txn already queued, e.g. an htlc
+// timeout txn spending several crib outputs, are merged.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex, or
+// during startup.
+func (u *utxoNursery) queueRepublish(height uint32, state []byte,
+	tx *wire.MsgTx, outputs []CsvSpendableOutput) {
+
+	txid := tx.TxHash()
+	unpublished, ok := u.unpublishedTxns[txid]
+	if !ok {
+		u.unpublishedTxns[txid] = &unpublishedTx{
+			height:  height,
+			state:   state,
+			tx:      tx,
+			outputs: outputs,
+		}
+		return
+	}
+
+	for _, output := range outputs {
+		if !containsOutput(unpublished.outputs, output.OutPoint()) {
+			unpublished.outputs = append(unpublished.outputs, output)
+		}
+	}
+}
+
+// containsOutput returns true if the given outputs include the outpoint.
+func containsOutput(outputs []CsvSpendableOutput, op *wire.OutPoint) bool {
+	for _, output := range outputs {
+		if *output.OutPoint() == *op {
+			return true
+		}
+	}
+
+	return false
+}
+
+// republishTxns attempts to publish the txns that previously could not be
+// published due to a connectivity issue with the backend. Txns that still
+// can't be published are retried upon the arrival of the next block.
+func (u *utxoNursery) republishTxns() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for txid, unpublished := range u.unpublishedTxns {
+		err := classifyPublishErr(
+			u.cfg.PublishTransaction(unpublished.tx),
+		)
+		switch err {
+		case nil, ErrTxAlreadyPublished:
+
+		case ErrPublishConnectivity:
+			utxnLog.Warnf("Unable to republish tx (txid=%v) from "+
+				"height=%d, will retry at next block: %v", txid,
+				unpublished.height, err)
+			continue
+
+		default:
+			utxnLog.Errorf("Unable to republish tx (txid=%v) from "+
+				"height=%d: %v", txid, unpublished.height, err)
+			delete(u.unpublishedTxns, txid)
+			continue
+		}
+
+		utxnLog.Infof("Republished tx (txid=%v) from height=%d", txid,
+			unpublished.height)
+
+		u.txPublished(unpublished.state, unpublished.tx,
+			unpublished.outputs)
+
+		if !bytes.Equal(unpublished.state, kndrPrefix) {
+			continue
+		}
+
+		err = u.recordSweepBroadcast(unpublished.height)
+		if err != nil {
+			utxnLog.Errorf("Unable to record broadcast of sweep "+
+				"txns at height=%d: %v", unpublished.height, err)
+		}
+	}
+}
+
+// txPublished records that the given htlc timeout txn or kindergarten sweep
+// txn, spending outputs in the given nursery state, has been accepted by the
+// backend. Kindergarten outputs are reported as swept only once their sweep
+// has been published.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex, or
+// during startup.
+func (u *utxoNursery) txPublished(state []byte, tx *wire.MsgTx,
+	outputs []CsvSpendableOutput) {
+
+	delete(u.unpublishedTxns, tx.TxHash())
+
+	u.cfg.Metrics.AddOutputsSwept(string(state), len(outputs))
+
+	if u.cfg.OnSweepBroadcast != nil {
+		u.cfg.OnSweepBroadcast(tx, outputs)
+	}
+
+	if !bytes.Equal(state, kndrPrefix) {
+		return
+	}
+
+	for _, output := range outputs {
+		kid, ok := output.(*kidOutput)
+		if !ok {
+			continue
+		}
+
+		u.notifyEvent(newOutputEvent(NurseryEventSwept, state, kid))
+	}
+}
+
+// registerSweepConf is responsible for registering the finalized kindergarten
+// sweep transactions at a height for confirmation notifications. For each
+// sweep txn spending any of the provided kindergarten outputs, a goroutine will
+// be spawned that waits for its confirmation, and graduates the outputs it
+// spends within the nursery store. Sweep txns whose outputs have all
+// graduated already are skipped.
+func (u *utxoNursery) registerSweepConf(finalTxns []*wire.MsgTx,
+	kgtnOutputs []kidOutput, heightHint uint32) error {
+
+	for _, finalTx := range finalTxns {
+		err := u.registerSweepTxConf(
+			heightHint, finalTxns, finalTx, kgtnOutputs,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// registerSweepTxConf registers for the confirmation of the given sweep txn,
+// upon which the kindergarten outputs it spends are graduated. The txns
+// finalized at the height are provided, such that they can be restored if the
+// confirmation is reorged out of the chain. Nothing is registered if the txn
+// spends none of the outputs, or if its confirmation is already being watched.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex, or
+// during startup.
+func (u *utxoNursery) registerSweepTxConf(heightHint uint32,
+	finalTxns []*wire.MsgTx, sweepTx *wire.MsgTx,
+	kgtnOutputs []kidOutput) error {
+
+	sweptOutputs := sweptKinders(sweepTx, kgtnOutputs)
+	if len(sweptOutputs) == 0 {
+		return nil
+	}
+
+	sweepTxID := sweepTx.TxHash()
+	if _, ok := u.sweepWatches[sweepTxID]; ok {
+		utxnLog.Debugf("Sweep tx %v is already registered for confs",
+			sweepTxID)
+		return nil
+	}
+	u.sweepWatches[sweepTxID] = struct{}{}
+
+	desc := fmt.Sprintf("sweep confirmation of %d kindergarten "+
+		"outputs at height=%d", len(sweptOutputs), heightHint)
+
+	return u.startConfWatcher(desc, func() (func(), error) {
+		notifier := u.cfg.Notifier
+		confChan, err := notifier.RegisterConfirmationsNtfn(
+			&sweepTxID, u.cfg.SweepConfDepth, heightHint)
+		if err != nil {
+			utxnLog.Errorf("unable to register notification "+
+				"for sweep confirmation: %v", sweepTxID)
+			delete(u.sweepWatches, sweepTxID)
+			return nil, err
+		}
+
+		utxnLog.Infof("Registering sweep tx %v for confs at "+
+			"height=%d", sweepTxID, heightHint)
+
+		// Sweeps registered while draining are not waited upon, as
+		// Drain may already be waiting for the graduations tracked so
+		// far.
+		tracked := atomic.LoadUint32(&u.draining) == 0
+		if tracked {
+			u.graduations.Add(1)
+		}
+
+		return func() {
+			u.waitForSweepConf(
+				heightHint, finalTxns, sweepTx, sweptOutputs,
+				confChan, tracked,
+			)
+		}, nil
+	})
+}
+
+// spentOnChain returns the first kindergarten output spent by the given sweep
+// txn that the ChainIO reports as already spent, or nil if none are. Failures
+// to query the ChainIO are ignored, as they don't indicate a conflicting
+// spend.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex, or
+// during startup.
+func (u *utxoNursery) spentOnChain(sweepTx *wire.MsgTx,
+	kgtnOutputs []kidOutput) *kidOutput {
+
+	if u.cfg.ChainIO == nil {
+		return nil
+	}
+
+	for _, output := range spentKinders(sweepTx, kgtnOutputs) {
+		kid := output.(*kidOutput)
+
+		_, err := u.cfg.ChainIO.GetUtxo(kid.OutPoint(), kid.ConfHeight())
+		if err == btcwallet.ErrOutputSpent {
+			return kid
+		}
+	}
+
+	return nil
+}
+
+// registerConflictingSpend registers for the spend of the provided
+// kindergarten output, which was found to be spent on-chain before our sweep
+// at the given height was broadcast. The txn spending it is treated as the
+// sweep of the outputs it spends, which graduate once it confirms.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex, or
+// during startup.
+func (u *utxoNursery) registerConflictingSpend(classHeight uint32,
+	finalTxns []*wire.MsgTx, kid *kidOutput, kgtnOutputs []kidOutput) error {
+
+	spendEvent, err := u.cfg.Notifier.RegisterSpendNtfn(
+		kid.OutPoint(), kid.ConfHeight(),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to register spend notification for "+
+			"output %v: %v", kid.OutPoint(), err)
+	}
+
+	u.wg.Add(1)
+	go u.waitForConflictingSpend(
+		classHeight, finalTxns, kgtnOutputs, spendEvent,
+	)
+
+	return nil
+}
+
+// waitForConflictingSpend waits for the spend detail of a kindergarten output
+// that was swept by a conflicting txn, and registers for the confirmation of
+// the spending txn as if it were one of the sweeps finalized at the height.
+// NOTE: This method MUST be called as a go routine.
+func (u *utxoNursery) waitForConflictingSpend(classHeight uint32,
+	finalTxns []*wire.MsgTx, kgtnOutputs []kidOutput,
+	spendEvent *chainntnfs.SpendEvent) {
+
+	defer u.wg.Done()
+
+	var spendDetail *chainntnfs.SpendDetail
+	select {
+	case detail, ok := <-spendEvent.Spend:
+		if !ok {
+			utxnLog.Errorf("Notification chan closed, can't detect "+
+				"conflicting sweep at height=%d", classHeight)
+			return
+		}
+		spendDetail = detail
+
+	case <-u.quit:
+		spendEvent.Cancel()
+		return
+	}
+
+	utxnLog.Infof("Kindergarten output %v at height=%d swept by "+
+		"conflicting txn %v", spendDetail.SpentOutPoint, classHeight,
+		spendDetail.SpenderTxHash)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	err := u.registerSweepTxConf(
+		classHeight, finalTxns, spendDetail.SpendingTx, kgtnOutputs,
+	)
+	if err != nil {
+		utxnLog.Errorf("Unable to register confirmation of "+
+			"conflicting sweep %v, will retry upon restart: %v",
+			spendDetail.SpenderTxHash, err)
+	}
+}
+
+// startConfWatcher launches a goroutine that waits upon the notifications
+// registered by the given register closure, which returns the function to be
+// run by the goroutine. The closure is always executed while holding the
+// nursery's mutex. If MaxConfWatchers watchers are already running, the
+// registration is queued until one of them exits, in which case a failure to
+// register is logged rather than returned.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex, or
+// during startup.
+func (u *utxoNursery) startConfWatcher(desc string,
+	register func() (func(), error)) error {
+
+	if u.confWatchers != nil {
+		select {
+		case u.confWatchers <- struct{}{}:
+		default:
+			u.queueConfWatcher(desc, register)
+			return nil
+		}
+	}
+
+	wait, err := register()
+	if err != nil {
+		u.releaseConfWatcher()
+		return err
+	}
+
+	u.wg.Add(1)
+	go func() {
+		defer u.releaseConfWatcher()
+		wait()
+	}()
+
+	return nil
+}
+
+// queueConfWatcher launches a goroutine that registers and runs a watcher
+// once the number of running watchers falls below MaxConfWatchers.
+func (u *utxoNursery) queueConfWatcher(desc string,
+	register func() (func(), error)) {
+
+	utxnLog.Debugf("Queueing %s, %d confirmation watchers already "+
+		"running", desc, cap(u.confWatchers))
+
+	u.wg.Add(1)
+	go func() {
+		select {
+		case u.confWatchers <- struct{}{}:
+		case <-u.quit:
+			u.wg.Done()
+			return
+		}
+		defer u.releaseConfWatcher()
+
+		u.mu.Lock()
+		wait, err := register()
+		u.mu.Unlock()
+		if err != nil {
+			utxnLog.Errorf("Unable to register %s, will retry "+
+				"upon restart: %v", desc, err)
+			u.wg.Done()
+			return
+		}
+
+		// The watcher marks the goroutine as done upon exiting.
+		wait()
+	}()
+}
+
+// reregisterConf registers a new confirmation notification for the given txid
+// after the notification channel of a previous registration was closed. This
+// happens when the nursery is shutting down, in which case nil is returned,
+// but also when the chain notifier tears down a single subscription, e.g.
+// while restarting, in which case the watcher should continue with the new
+// registration. If registration fails, nil is returned, and the output will
+// not advance until the nursery restarts.
+func (u *utxoNursery) reregisterConf(txid *chainhash.Hash, numConfs,
+	heightHint uint32) *chainntnfs.ConfirmationEvent {
+
+	select {
+	case <-u.quit:
+		return nil
+	default:
+	}
+
+	utxnLog.Warnf("Confirmation notification chan closed for txid=%v, "+
+		"re-registering", txid)
+
+	confChan, err := u.cfg.Notifier.RegisterConfirmationsNtfn(
+		txid, numConfs, heightHint,
+	)
+	if err != nil {
+		utxnLog.Errorf("Unable to re-register confirmation "+
+			"notification for txid=%v, will retry upon restart: %v",
+			txid, err)
+		return nil
+	}
+
+	return confChan
+}
+
+// releaseConfWatcher frees the slot held by a confirmation watcher, allowing a
+// queued watcher to proceed.
+func (u *utxoNursery) releaseConfWatcher() {
+	if u.confWatchers != nil {
+		<-u.confWatchers
+	}
+}
+
+// waitForSweepConf watches for the confirmation of one of the sweep
+// transactions finalized at a height, which spends the provided kindergarten
+// outputs. Once confirmed, the nursery will mark those outputs as fully
+// graduated, regardless of whether the height's other sweep txns have
+// confirmed, and proceed to mark any mature channels as fully closed in
+// channeldb. If tracked is set, the goroutine is counted towards the in-flight
+// graduations awaited by Drain.
+// NOTE(conner): this method MUST be called as a go routine.
+func (u *utxoNursery) waitForSweepConf(classHeight uint32,
+	finalTxns []*wire.MsgTx, sweepTx *wire.MsgTx, kgtnOutputs []kidOutput,
+	confChan *chainntnfs.ConfirmationEvent, tracked bool) {
+
+	defer u.wg.Done()
+
+	// The graduation is no longer in-flight once the outputs have been
+	// graduated, even though we may continue to watch for a reorg.
+	graduationDone := func() {
+		if tracked {
+			u.graduations.Done()
+			tracked = false
+		}
+	}
+	defer graduationDone()
+
+	// The sweep txn is no longer watched once it confirms, or the watcher
+	// exits, such that it can be registered anew, e.g. if its
+	// confirmation is reorged out of the chain.
+	sweepTxID := sweepTx.TxHash()
+	watching := true
+	stopWatching := func() {
+		if watching {
+			u.mu.Lock()
+			delete(u.sweepWatches, sweepTxID)
+			delete(u.unpublishedTxns, sweepTxID)
+			u.mu.Unlock()
+			watching = false
+		}
+	}
+	defer stopWatching()
+
+	// Only the outputs spent by this sweep txn are graduated upon its
+	// confirmation, such that a stuck sweep of the height's other outputs
+	// doesn't hold them back.
+	var sweepHeight uint32
+	for confirmed := false; !confirmed; {
+		select {
+		case txConfirmation, ok := <-confChan.Confirmed:
+			if !ok {
+				confChan = u.reregisterConf(
+					&sweepTxID, u.cfg.SweepConfDepth,
+					classHeight,
+				)
+				if confChan == nil {
+					return
+				}
+				continue
+			}
+
+			sweepHeight = txConfirmation.BlockHeight
+			confirmed = true
+
+		case <-u.quit:
+			return
+		}
+	}
+	stopWatching()
+
+	if !u.graduateKinders(classHeight, sweepTx, kgtnOutputs, sweepHeight) {
+		return
+	}
+	graduationDone()
+
+	u.watchSweepReorg(classHeight, finalTxns, kgtnOutputs, confChan,
+		sweepHeight)
+}
+
+// graduateKinders marks the kindergarten outputs at the given height, whose
+// sweeps confirmed at sweepHeight, as graduated, and attempts to close any
+// channels whose outputs have all graduated. If a sweep txn is provided, only
+// the outputs it spends are graduated, otherwise the entire kindergarten class
+// at the height is. The returned boolean indicates whether the outputs were
+// graduated, which they aren't if the sweep's outputs have already graduated.
+func (u *utxoNursery) graduateKinders(classHeight uint32, sweepTx *wire.MsgTx,
+	kgtnOutputs []kidOutput, sweepHeight uint32) bool {
+
+	// Mark the confirmed kindergarten outputs as graduated.
+	graduated := kgtnOutputs
+	err := u.retryLocked(
+		fmt.Sprintf("graduate %d kindergarten outputs at height=%d",
+			len(kgtnOutputs), classHeight),
+		u.cfg.TransitionRetries, u.cfg.TransitionRetryBackoff,
+		func() error {
+			if sweepTx == nil {
+				return u.cfg.Store.GraduateKinder(
+					classHeight, sweepHeight,
+				)
+			}
+
+			var err error
+			graduated, err = u.cfg.Store.GraduateSweep(
+				classHeight, sweepTx, sweepHeight,
+			)
+			return err
+		},
+	)
+	switch {
+	case err == ErrNurseryShuttingDown:
+		return false
+	case err != nil:
+		utxnLog.Criticalf("Unable to graduate %d kindergarten outputs "+
+			"at height=%d, outputs will remain in limbo until "+
+			"restart: %v", len(kgtnOutputs), classHeight, err)
+		return false
+
+	// If the sweep's outputs have already graduated, e.g. by an earlier
+	// confirmation of the same sweep, there's nothing left to report.
+	case sweepTx != nil && len(graduated) == 0:
+		utxnLog.Debugf("Kindergarten outputs at height=%d swept by "+
+			"tx %v have already graduated", classHeight,
+			sweepTx.TxHash())
+		return false
+	}
+	kgtnOutputs = graduated
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	utxnLog.Infof("Graduated %d kindergarten outputs from height=%d",
+		len(kgtnOutputs), classHeight)
+
+	u.cfg.Metrics.AddOutputsGraduated(len(kgtnOutputs))
+	u.updateLimboBalance()
+
+	for i := range kgtnOutputs {
+		u.notifyEvent(newOutputEvent(
+			NurseryEventGraduated, gradPrefix, &kgtnOutputs[i],
+		))
+	}
+
+	// Report the value the sweep recovered from limbo, such that it can
+	// be reconciled against the limbo balance without inspecting the txn.
+	if sweepTx != nil {
+		totalIn, fee, netSwept := sweepValues(sweepTx, kgtnOutputs)
+		sweepTxid := sweepTx.TxHash()
+
+		utxnLog.Infof("Sweep txn %v confirmed at height=%d, swept "+
+			"total=%v from limbo, fee=%v, net swept=%v", sweepTxid,
+			sweepHeight, totalIn, fee, netSwept)
+
+		u.notifyEvent(&NurseryEvent{
+			Type:      NurseryEventSweepConfirmed,
+			Amount:    totalIn,
+			SweepTxid: sweepTxid,
+			Fee:       fee,
+			NetSwept:  netSwept,
+		})
+	}
+
+	// Report the number of blocks each output spent in the kindergarten
+	// state, measured from the confirmation of the output until the
+	// confirmation of its sweep.
+	for i := range kgtnOutputs {
+		kid := &kgtnOutputs[i]
+		u.observeTimeInState(kndrPrefix, kid.ConfHeight(), sweepHeight,
+			kid.BlocksToMaturity())
+	}
+
+	// Iterate over the kid outputs and construct a set of all channel
+	// points to which they belong.
+	var possibleCloses = make(map[wire.OutPoint]struct{})
+	for _, kid := range kgtnOutputs {
+		possibleCloses[*kid.OriginChanPoint()] = struct{}{}
+
+	}
+
+	// Attempt to close each channel, only doing so if all of the channel's
+	// outputs have been graduated. A failure to close one channel should
+	// not prevent the others from being closed.
+	for chanPoint := range possibleCloses {
+		err := u.closeOrAwaitFinalConfs(&chanPoint, sweepHeight)
+		if err != nil {
+			utxnLog.Errorf("Failed to close and remove channel %v: "+
+				"%v", chanPoint, err)
+			continue
+		}
+	}
+
+	return true
+}
+
+// watchSweepReorg watches for the confirmation of the sweep txn spending the
+// given kindergarten outputs, which confirmed at sweepHeight, to be reorged out
+// of the chain. Since graduated channels are not closed until their sweeps
+// reach GraduationConfDepth, the graduation can be safely reverted until then,
+// after which the watch ends. If GraduationConfDepth does not exceed
+// SweepConfDepth, channels are closed as soon as their outputs graduate, and no
+// watch is performed.
+func (u *utxoNursery) watchSweepReorg(classHeight uint32,
+	finalTxns []*wire.MsgTx, kgtnOutputs []kidOutput,
+	confChan *chainntnfs.ConfirmationEvent, sweepHeight uint32) {
+
+	if u.cfg.GraduationConfDepth <= u.cfg.SweepConfDepth {
+		return
+	}
+
+	blockEpochs, err := u.cfg.Notifier.RegisterBlockEpochNtfn()
+	if err != nil {
+		utxnLog.Errorf("Unable to register for blocks, can't watch "+
+			"for reorg of sweeps at height=%d: %v", classHeight,
+			err)
+		return
+	}
+	defer blockEpochs.Cancel()
+
+	closeHeight := sweepHeight + u.cfg.GraduationConfDepth - 1
+	for {
+		// A reorg is always followed by the connection of the blocks
+		// of the new chain, so we check for a disconnected sweep
+		// confirmation as each block arrives.
+		select {
+		case reorgDepth, ok := <-confChan.NegativeConf:
+			if ok {
+				u.revertGraduation(classHeight, finalTxns,
+					kgtnOutputs, reorgDepth)
+				return
+			}
+
+		default:
+		}
+
+		select {
+		case epoch, ok := <-blockEpochs.Epochs:
+			if !ok {
+				return
+			}
+
+			// Once the sweeps have reached GraduationConfDepth,
+			// the channels will be closed, and can no longer be
+			// reverted.
+			if uint32(epoch.Height) >= closeHeight {
+				return
+			}
+
+		case <-u.quit:
+			return
+		}
+	}
+}
+
+// revertGraduation moves kindergarten outputs whose sweep confirmation was
+// reorged out of the chain from the graduated state back to the kindergarten,
+// and rebroadcasts their finalized sweep txn. All of the txns finalized at the
+// height are provided, such that they can be restored in the nursery store.
+func (u *utxoNursery) revertGraduation(classHeight uint32,
+	finalTxns []*wire.MsgTx, kgtnOutputs []kidOutput, reorgDepth int32) {
+
+	utxnLog.Warnf("Confirmation of sweep txns at height=%d reorged out "+
+		"of chain, depth=%d, reverting graduation of %d outputs",
+		classHeight, reorgDepth, len(kgtnOutputs))
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	err := u.cfg.Store.UngraduateKinder(classHeight, kgtnOutputs, finalTxns)
+	if err != nil {
+		utxnLog.Criticalf("Unable to revert graduation of %d "+
+			"kindergarten outputs at height=%d: %v",
+			len(kgtnOutputs), classHeight, err)
+		return
+	}
+
+	// The channels of the reverted outputs are no longer mature, so they
+	// must not be closed once the reorged sweeps would have reached
+	// GraduationConfDepth.
+	for i := range kgtnOutputs {
+		delete(u.finalConfHeights, *kgtnOutputs[i].OriginChanPoint())
+	}
+
+	u.updateLimboBalance()
+
+	err = u.sweepGraduatingKinders(classHeight, finalTxns, kgtnOutputs)
+	if err != nil {
+		utxnLog.Errorf("Unable to rebroadcast sweep txns at "+
+			"height=%d: %v", classHeight, err)
+	}
+}
+
+// sweepCribOutput broadcasts the crib output's htlc timeout txn, and sets up a
+// notification that will advance it to the kindergarten bucket upon
+// confirmation. If the broadcast is to be spaced out from the previous one, it
+// is added to the delayed broadcasts instead.
+func (u *utxoNursery) sweepCribOutput(classHeight uint32, baby *babyOutput,
+	delayed *delayedBroadcasts) error {
+
+	// The store indexes crib outputs by their CLTV expiry, though we
+	// don't rely on it here, as a timeout txn broadcast before its expiry
+	// would be rejected. Instead, the output is requeued at its expiry
+	// height so that it is revisited once the timeout txn is final.
+	if baby.expiry > classHeight {
+		utxnLog.Errorf("Refusing to broadcast timeout tx of crib "+
+			"output %v at height=%d, expiry=%d: requeuing",
+			baby.OutPoint(), classHeight, baby.expiry)
+
+		err := u.cfg.Store.RequeueCrib(baby, classHeight)
+		if err != nil {
+			return fmt.Errorf("unable to requeue crib output %v: %v",
+				baby.OutPoint(), err)
+		}
+
+		return ErrPrematureCribSweep
+
+	}
+
+	// Before broadcasting the presigned timeout txn, ensure that it was not
+	// corrupted while persisted, so that we can surface a meaningful error.
+	//
+	// NOTE: If the presigned txn was lost, e.g. after restoring from seed,
+	// we're unable to reconstruct it. The first-stage htlc output can only
+	// be spent with the remote party's signature, while our sign
+	// descriptor only describes the second-stage output of the timeout txn.
+	if err := validateTimeoutTx(baby); err != nil {
+		utxnLog.Errorf("Refusing to broadcast timeout tx of crib "+
+			"output %v: %v", baby.OutPoint(), err)
+		return err
+	}
+
+	outputs := []CsvSpendableOutput{baby}
+	if u.delayBroadcast(
+		delayed, classHeight, cribPrefix, baby.timeoutTx, outputs,
+	) {
+		return u.registerTimeoutConf(baby, classHeight)
+	}
+
+	utxnLog.Infof("Publishing CTLV-delayed HTLC output using timeout tx "+
+		"(txid=%v): %v", baby.timeoutTx.TxHash(),
+		newLogClosure(func() string {
+			return spew.Sdump(baby.timeoutTx)
+		}),
+	)
+
+	// Broadcast HTLC transaction. Connectivity failures are not fatal, as
+	// the timeout txn will be republished upon the arrival of the next
+	// block, so we still register for its confirmation.
+	err := classifyPublishErr(u.cfg.PublishTransaction(baby.timeoutTx))
+	switch err {
+	case nil, ErrTxAlreadyPublished:
+		u.txPublished(cribPrefix, baby.timeoutTx, outputs)
+
+	case ErrPublishConnectivity:
+		utxnLog.Warnf("Unable to broadcast baby tx (txid=%v), will "+
+			"retry at next block: %v", baby.timeoutTx.TxHash(), err)
+		u.queueRepublish(
+			classHeight, cribPrefix, baby.timeoutTx, outputs,
+		)
+
+	default:
+		utxnLog.Errorf("Unable to broadcast baby tx: "+
+			"%v, %v", err,
+			spew.Sdump(baby.timeoutTx))
+		return err
+	}
+
+	return u.registerTimeoutConf(baby, classHeight)
+}
+
+// validateTimeoutTx performs basic sanity checks on the presigned htlc timeout
+// txn of a crib output. Since the crib output's outpoint refers to the output
+// of the timeout txn that will be swept from the kindergarten, we verify that
+// the txn creates this outpoint with the expected value.
+func validateTimeoutTx(baby *babyOutput) error {
+	outpoint := baby.OutPoint()
+
+	if baby.timeoutTx == nil {
+		return fmt.Errorf("crib output %v is missing its timeout tx",
+			outpoint)
+	}
+
+	btx := btcutil.NewTx(baby.timeoutTx)
+	if err := blockchain.CheckTransactionSanity(btx); err != nil {
+		return fmt.Errorf("timeout tx for crib output %v failed "+
+			"sanity check: %v", outpoint, err)
+	}
+
+	txid := baby.timeoutTx.TxHash()
+	if txid != outpoint.Hash {
+		return fmt.Errorf("timeout tx for crib output %v has "+
+			"mismatched txid %v", outpoint, txid)
+	}
+
+	if int(outpoint.Index) >= len(baby.timeoutTx.TxOut) {
+		return fmt.Errorf("timeout tx for crib output %v has only %d "+
+			"outputs", outpoint, len(baby.timeoutTx.TxOut))
+	}
+
+	txOut := baby.timeoutTx.TxOut[outpoint.Index]
+	if btcutil.Amount(txOut.Value) != baby.Amount() {
+		return fmt.Errorf("timeout tx for crib output %v pays %v, "+
+			"expected %v", outpoint, btcutil.Amount(txOut.Value),
+			baby.Amount())
+	}
+
+	return nil
+}
+
+// isBatchableTimeoutTx returns true if the given presigned htlc timeout txn
+// may be combined with others into a single txn. This requires the txn to have
+// a single input and output, and both signatures in its witness to be made
+// with SIGHASH_SINGLE|SIGHASH_ANYONECANPAY, such that they commit only to the
+// input being signed and its corresponding output.
+func isBatchableTimeoutTx(timeoutTx *wire.MsgTx) bool {
+	if len(timeoutTx.TxIn) != 1 || len(timeoutTx.TxOut) != 1 {
+		return false
+	}
+
+	// The timeout witness is structured as:
+	//   <0> <receiver sig> <sender sig> <0> <witness script>
+	witness := timeoutTx.TxIn[0].Witness
+	if len(witness) != 5 {
+		return false
+	}
+
+	const batchSigHash = txscript.SigHashSingle |
+		txscript.SigHashAnyOneCanPay
+	for _, sig := range witness[1:3] {
+		if len(sig) == 0 ||
+			txscript.SigHashType(sig[len(sig)-1]) != batchSigHash {
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// batchCribOutputs combines the presigned htlc timeout txns of the provided
+// crib outputs into a single txn wherever their signatures permit, i.e. for
+// timeout txns signed with SIGHASH_SINGLE|SIGHASH_ANYONECANPAY that share the
+// same version and lock time. Each input of a batched txn is paired with the
+// output at the same index, as required by SIGHASH_SINGLE. The crib outputs of
+// the combined txns are re-keyed to the outputs of the batched txn within a
+// single store transaction. The returned crib outputs hold those of each
+// batched txn adjacently, followed by the remaining outputs, which are to be
+// broadcast individually.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) batchCribOutputs(classHeight uint32,
+	cribOutputs []babyOutput) ([]babyOutput, error) {
+
+	type batchKey struct {
+		version  int32
+		lockTime uint32
+	}
+
+	var (
+		batchKeys []batchKey
+		batches   = make(map[batchKey][]*babyOutput)
+		unbatched []babyOutput
+	)
+	for i := range cribOutputs {
+		baby := &cribOutputs[i]
+
+		// Outputs whose timeout txns can't yet be broadcast are left
+		// to be requeued, while corrupt ones are surfaced when they're
+		// broadcast individually.
+		if baby.expiry > classHeight || validateTimeoutTx(baby) != nil ||
+			!isBatchableTimeoutTx(baby.timeoutTx) {
+
+			unbatched = append(unbatched, *baby)
+			continue
+		}
+
+		key := batchKey{
+			version:  baby.timeoutTx.Version,
+			lockTime: baby.timeoutTx.LockTime,
+		}
+		if _, ok := batches[key]; !ok {
+			batchKeys = append(batchKeys, key)
+		}
+		batches[key] = append(batches[key], baby)
+	}
+
+	var (
+		rekeyed      []babyOutput
+		oldOutPoints []wire.OutPoint
+	)
+	for _, key := range batchKeys {
+		babies := batches[key]
+		if len(babies) < 2 {
+			unbatched = append(unbatched, *babies[0])
+			continue
+		}
+
+		batchTx := wire.NewMsgTx(key.version)
+		batchTx.LockTime = key.lockTime
+		for _, baby := range babies {
+			txIn := *baby.timeoutTx.TxIn[0]
+			batchTx.AddTxIn(&txIn)
+			batchTx.AddTxOut(baby.timeoutTx.TxOut[0])
+		}
+		batchTxID := batchTx.TxHash()
+
+		for j, baby := range babies {
+			rekeyedBaby := *baby
+			rekeyedBaby.outpoint = wire.OutPoint{
+				Hash:  batchTxID,
+				Index: uint32(j),
+			}
+			rekeyedBaby.timeoutTx = batchTx
+
+			rekeyed = append(rekeyed, rekeyedBaby)
+			oldOutPoints = append(oldOutPoints, *baby.OutPoint())
+		}
+
+		utxnLog.Infof("Batched %d htlc timeout txns at height=%d into "+
+			"txid=%v", len(babies), classHeight, batchTxID)
+	}
+
+	if len(rekeyed) == 0 {
+		return cribOutputs, nil
+	}
+
+	err := u.cfg.Store.Update(func(store NurseryStore) error {
+		for i := range rekeyed {
+			err := store.RekeyCrib(&oldOutPoints[i], &rekeyed[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(rekeyed, unbatched...), nil
+}
+
+// registerTimeoutConf is responsible for subscribing to confirmation
+// notification for an htlc timeout transaction. If successful, a goroutine will
+// be spawned that will transition the provided baby output into the
+// kindergarten state within the nursery store.
+func (u *utxoNursery) registerTimeoutConf(baby *babyOutput, heightHint uint32) error {
+	desc := fmt.Sprintf("promotion of htlc output %v", baby.OutPoint())
+
+	return u.startConfWatcher(desc, func() (func(), error) {
+		birthTxID := baby.timeoutTx.TxHash()
+
+		// Register for the confirmation of presigned htlc txn.
+		confChan, err := u.cfg.Notifier.RegisterConfirmationsNtfn(
+			&birthTxID, u.cfg.HtlcConfDepth, heightHint)
+		if err != nil {
+			return nil, err
+		}
+
+		// The htlc output spent by the timeout txn may already have
+		// been spent by another txn, e.g. the counterparty's success
+		// txn, in which case the timeout txn will never confirm. Watch
+		// for any spend of the htlc output, such that this can be
+		// detected.
+		htlcOutpoint := baby.timeoutTx.TxIn[0].PreviousOutPoint
+		spendEvent, err := u.cfg.Notifier.RegisterSpendNtfn(
+			&htlcOutpoint, heightHint,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		utxnLog.Infof("Htlc output %v registered for promotion "+
+			"notification.", baby.OutPoint())
+
+		canceled := u.chanCancel(baby.OriginChanPoint())
+		return func() {
+			u.waitForTimeoutConf(
+				baby, confChan, spendEvent, canceled,
+				heightHint,
+			)
+		}, nil
+	})
+}
+
+// waitForTimeoutConf watches for the confirmation of an htlc timeout
+// transaction, and attempts to move the htlc output from the crib bucket to the
+// kindergarten bucket upon success. If the htlc output is instead spent by a
+// different transaction, the crib output is resolved without being promoted.
+// The goroutine exits once the canceled channel is closed.
+func (u *utxoNursery) waitForTimeoutConf(baby *babyOutput,
+	confChan *chainntnfs.ConfirmationEvent,
+	spendEvent *chainntnfs.SpendEvent, canceled <-chan struct{},
+	heightHint uint32) {
+
+	defer u.wg.Done()
+	defer spendEvent.Cancel()
+
+	timeoutTxID := baby.timeoutTx.TxHash()
+	spendChan := spendEvent.Spend
+
+	// Once we stop watching the timeout txn, it no longer needs to be
+	// republished.
+	defer func() {
+		u.mu.Lock()
+		delete(u.unpublishedTxns, timeoutTxID)
+		u.mu.Unlock()
+	}()
+
+	for confirmed := false; !confirmed; {
+		select {
+		case txConfirmation, ok := <-confChan.Confirmed:
+			if !ok {
+				confChan = u.reregisterConf(
+					&timeoutTxID, u.cfg.HtlcConfDepth,
+					heightHint,
+				)
+				if confChan == nil {
+					return
+				}
+				continue
+			}
+
+			baby.SetConfHeight(txConfirmation.BlockHeight)
+			confirmed = true
+
+		case spendDetail, ok := <-spendChan:
+			// If the spend notification can't be delivered, we
+			// continue to wait for the timeout txn to confirm.
+			if !ok {
+				utxnLog.Warnf("Spend notification chan closed "+
+					"for htlc output of baby output %v",
+					baby.OutPoint())
+				spendChan = nil
+				continue
+			}
+
+			// If the htlc output was spent by our timeout txn, it
+			// will advance normally once the txn confirms.
+			if *spendDetail.SpenderTxHash == timeoutTxID {
+				spendChan = nil
+				continue
+			}
+
+			u.resolveCribSpend(baby, spendDetail.SpenderTxHash,
+				uint32(spendDetail.SpendingHeight))
+			return
+
+		case <-canceled:
+			return
+
+		case <-u.quit:
+			return
+		}
+	}
+
+	err := u.retryLocked(
+		fmt.Sprintf("move htlc output %v from crib to kindergarten",
+			baby.OutPoint()),
+		u.cfg.TransitionRetries, u.cfg.TransitionRetryBackoff,
+		func() error {
+			select {
+			case <-canceled:
+				return errIncubationCanceled
+			default:
+			}
+
+			return u.cfg.Store.CribToKinder(baby)
+		},
+	)
+	switch {
+	case err == ErrNurseryShuttingDown, err == errIncubationCanceled:
+		return
+	case err != nil:
+		utxnLog.Criticalf("Unable to move htlc output %v from crib "+
+			"to kindergarten bucket, output will not be swept "+
+			"until restart: %v", baby.OutPoint(), err)
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	utxnLog.Infof("Htlc output %v promoted to "+
+		"kindergarten", baby.OutPoint())
+
+	u.notifyEvent(newOutputEvent(
+		NurseryEventEnrolled, kndrPrefix, &baby.kidOutput,
+	))
+
+	// The crib output was expected to remain in the crib until its CLTV
+	// expired, so we report the time spent relative to its expiry.
+	if entryHeight := baby.EntryHeight(); entryHeight != 0 {
+		var expected uint32
+		if baby.expiry > entryHeight {
+			expected = baby.expiry - entryHeight
+		}
+
+		u.observeTimeInState(cribPrefix, entryHeight,
+			baby.ConfHeight(), expected)
+	}
+}
+
+// resolveCribSpend marks a crib output resolved if its htlc output was spent by
+// a txn other than its timeout txn, as the timeout txn can never confirm and the
+// output must not advance to the kindergarten state. If this was the last
+// ungraduated output of the channel, the channel is closed once the spend,
+// confirmed at spendHeight, reaches GraduationConfDepth.
+func (u *utxoNursery) resolveCribSpend(baby *babyOutput,
+	spenderTxID *chainhash.Hash, spendHeight uint32) {
+
+	utxnLog.Warnf("Htlc output of baby output %v was spent by txid=%v "+
+		"rather than timeout txid=%v, marking resolved",
+		baby.OutPoint(), spenderTxID, baby.timeoutTx.TxHash())
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := u.cfg.Store.ResolveCrib(baby); err != nil {
+		utxnLog.Errorf("Unable to resolve baby output %v: %v",
+			baby.OutPoint(), err)
+		return
+	}
+
+	u.updateLimboBalance()
+
+	u.notifyEvent(newOutputEvent(
+		NurseryEventResolved, rslvPrefix, &baby.kidOutput,
+	))
+
+	chanPoint := baby.OriginChanPoint()
+	if err := u.closeOrAwaitFinalConfs(chanPoint, spendHeight); err != nil {
+		utxnLog.Errorf("Unable to close channel=%v: %v", chanPoint,
+			err)
+	}
+}
+
+// bumpStuckCommitments attempts to bump the fee of each commitment txn that
+// has remained unconfirmed for a multiple of CpfpThreshold blocks since its
+// outputs were incubated, by publishing a child-pays-for-parent txn spending
+// our anchor output on it. Commitment txns without an anchor output for us
+// can't be bumped, and are only reported. Should the commitment txn confirm,
+// the kindergarten sweep of the anchor output finds it spent by the CPFP txn,
+// and graduates it once the CPFP txn confirms.
+func (u *utxoNursery) bumpStuckCommitments(height uint32) {
+	if u.cfg.CpfpThreshold == 0 {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	psclOutputs, err := u.cfg.Store.FetchPreschools()
+	if err != nil {
+		utxnLog.Errorf("Unable to fetch preschool outputs: %v", err)
+		return
+	}
+
+	// Collect the anchor output of each stuck commitment txn, if any.
+	// Outputs of justice txns, and those incubated before their entry
+	// heights were persisted, are ignored.
+	stuck := make(map[chainhash.Hash]*kidOutput)
+	for i := range psclOutputs {
+		kid := &psclOutputs[i]
+
+		switch kid.WitnessType() {
+		case lnwallet.CommitmentTimeLock, lnwallet.CommitmentAnchor:
+		default:
+			continue
+		}
+
+		entryHeight := kid.EntryHeight()
+		if entryHeight == 0 || height <= entryHeight {
+			continue
+		}
+		if (height-entryHeight)%u.cfg.CpfpThreshold != 0 {
+			continue
+		}
+
+		commitTxid := kid.OutPoint().Hash
+		if _, ok := stuck[commitTxid]; !ok {
+			stuck[commitTxid] = nil
+		}
+		if kid.WitnessType() == lnwallet.CommitmentAnchor {
+			stuck[commitTxid] = kid
+		}
+	}
+
+	for commitTxid, anchor := range stuck {
+		if anchor == nil {
+			utxnLog.Warnf("Commitment txn %v is unconfirmed, but "+
+				"has no anchor output to bump its fee",
+				commitTxid)
+			continue
+		}
+
+		numBlocks := height - anchor.EntryHeight()
+		utxnLog.Infof("Commitment txn %v unconfirmed for %d blocks, "+
+			"bumping its fee via anchor output %v", commitTxid,
+			numBlocks, anchor.OutPoint())
+
+		cpfpTx, err := u.cfg.CpfpSweeper.SweepInputs(
+			[]CsvSpendableOutput{anchor},
+		)
+		if err != nil {
+			utxnLog.Errorf("Unable to create CPFP txn for "+
+				"commitment txn %v: %v", commitTxid, err)
+			continue
+		}
+
+		err = classifyPublishErr(u.cfg.PublishTransaction(cpfpTx))
+		if err != nil && err != ErrTxAlreadyPublished {
+			utxnLog.Errorf("Unable to broadcast CPFP txn %v for "+
+				"commitment txn %v, will retry in %d blocks: %v",
+				cpfpTx.TxHash(), commitTxid,
+				u.cfg.CpfpThreshold, err)
+		}
+	}
+}
+
+// commitConfRegistration is a commitment confirmation notification awaiting
+// registration by the commitConfRegistrar.
+type commitConfRegistration struct {
+	kid        *kidOutput
+	heightHint uint32
+}
+
+// registerCommitConf is responsible for subscribing to the confirmation of a
+// commitment transaction. If successful, the provided preschool output will be
+// moved persistently into the kindergarten state within the nursery store. If
+// CommitConfRegistrationInterval is non-zero, the registration is queued to be
+// made by the commitConfRegistrar instead.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex, or
+// during startup.
+func (u *utxoNursery) registerCommitConf(kid *kidOutput, heightHint uint32) error {
+	if u.cfg.CommitConfRegistrationInterval > 0 {
+		u.commitConfQueue = append(u.commitConfQueue,
+			&commitConfRegistration{
+				kid:        kid,
+				heightHint: heightHint,
+			},
+		)
+
+		select {
+		case u.commitConfQueued <- struct{}{}:
+		default:
+		}
+
+		return nil
+	}
+
+	return u.startCommitConfWatcher(kid, heightHint)
+}
+
+// dropQueuedCommitConfs removes the queued commitment confirmation
+// registrations of the given channel's outputs.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) dropQueuedCommitConfs(chanPoint *wire.OutPoint) {
+	var stillQueued []*commitConfRegistration
+	for _, reg := range u.commitConfQueue {
+		if *reg.kid.OriginChanPoint() == *chanPoint {
+			continue
+		}
+		stillQueued = append(stillQueued, reg)
 	}
+	u.commitConfQueue = stillQueued
 
-	return nil
+	inflight := u.commitConfInflight
+	if inflight != nil && *inflight.kid.OriginChanPoint() == *chanPoint {
+		u.commitConfInflight = nil
+	}
 }
 
-// incubator is tasked with driving all state transitions that are dependent on
-// the current height of the blockchain. As new blocks arrive, the incubator
-// will attempt spend outputs at the latest height. The asynchronous
-// confirmation of these spends will either 1) move a crib output into the
-// kindergarten bucket or 2) move a kindergarten output into the graduated
-// bucket.
-func (u *utxoNursery) incubator(newBlockChan *chainntnfs.BlockEpochEvent) {
+// commitConfRegistrar registers the commitment confirmation notifications
+// queued by registerCommitConf in order, waiting at least
+// CommitConfRegistrationInterval between successive registrations. The
+// registrations are made without holding the nursery's mutex, such that a slow
+// notifier doesn't stall the nursery. Failed registrations are queued again
+// behind the registrations already queued.
+//
+// NOTE: This MUST be run as a goroutine.
+func (u *utxoNursery) commitConfRegistrar() {
 	defer u.wg.Done()
-	defer newBlockChan.Cancel()
 
 	for {
-		select {
-		case epoch, ok := <-newBlockChan.Epochs:
-			// If the epoch channel has been closed, then the
-			// ChainNotifier is exiting which means the daemon is
-			// as well. Therefore, we exit early also in order to
-			// ensure the daemon shuts down gracefully, yet
-			// swiftly.
-			if !ok {
+		u.mu.Lock()
+		if len(u.commitConfQueue) == 0 {
+			u.mu.Unlock()
+
+			select {
+			case <-u.commitConfQueued:
+				continue
+			case <-u.quit:
 				return
 			}
+		}
 
-			// TODO(roasbeef): if the BlockChainIO is rescanning
-			// will give stale data
+		reg := u.commitConfQueue[0]
+		u.commitConfQueue = u.commitConfQueue[1:]
+		u.commitConfInflight = reg
+		u.mu.Unlock()
 
-			// A new block has just been connected to the main
-			// chain, which means we might be able to graduate crib
-			// or kindergarten outputs at this height. This involves
-			// broadcasting any presigned htlc timeout txns, as well
-			// as signing and broadcasting a sweep txn that spends
-			// from all kindergarten outputs at this height.
-			height := uint32(epoch.Height)
-			if err := u.graduateClass(height); err != nil {
-				utxnLog.Errorf("error while graduating "+
-					"class at height=%d: %v", height, err)
+		confChan, spendEvent, err := u.registerCommitNtfns(
+			reg.kid, reg.heightHint,
+		)
+
+		u.mu.Lock()
+		dropped := u.commitConfInflight == nil
+		u.commitConfInflight = nil
+
+		switch {
+		case err != nil && !dropped:
+			utxnLog.Errorf("Unable to register confirmation of "+
+				"commitment outpoint %v, will retry: %v",
+				reg.kid.OutPoint(), err)
+			u.commitConfQueue = append(u.commitConfQueue, reg)
+
+		// If the incubation of the output's channel was stopped while
+		// registering, the notifications are no longer needed.
+		case err == nil && dropped:
+			if spendEvent != nil {
+				spendEvent.Cancel()
+			}
 
-				// TODO(conner): signal fatal error to daemon
+		case err == nil:
+			desc := fmt.Sprintf("confirmation of commitment "+
+				"outpoint %v", reg.kid.OutPoint())
+			err := u.startConfWatcher(desc, func() (func(), error) {
+				return u.commitConfWaiter(
+					reg.kid, reg.heightHint, confChan,
+					spendEvent,
+				), nil
+			})
+			if err != nil {
+				utxnLog.Errorf("Unable to start %s: %v", desc,
+					err)
 			}
+		}
+		remaining := len(u.commitConfQueue)
+		u.mu.Unlock()
+
+		utxnLog.Debugf("%d commitment confirmation registrations "+
+			"remain queued", remaining)
 
+		select {
+		case <-u.after(u.cfg.CommitConfRegistrationInterval):
 		case <-u.quit:
 			return
 		}
 	}
 }
 
-// graduateClass handles the steps involved in spending outputs whose CSV or
-// CLTV delay expires at the nursery's current height. This method is called
-// each time a new block arrives, or during startup to catch up on heights we
-// may have missed while the nursery was offline.
-func (u *utxoNursery) graduateClass(classHeight uint32) error {
-	// Record this height as the nursery's current best height.
-	u.mu.Lock()
-	defer u.mu.Unlock()
-
-	u.bestHeight = classHeight
-
-	// Fetch all information about the crib and kindergarten outputs at this
-	// height. In addition to the outputs, we also retrieve the finalized
-	// kindergarten sweep txn, which will be nil if we have not attempted
-	// this height before, or if no kindergarten outputs exist at this
-	// height.
-	finalTx, kgtnOutputs, cribOutputs, err := u.cfg.Store.FetchClass(
-		classHeight)
-	if err != nil {
-		return err
-	}
+// startCommitConfWatcher registers for the confirmation of the commitment txn
+// of the given preschool output, and launches a watcher that promotes the
+// output to the kindergarten once it confirms.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex, or
+// during startup.
+func (u *utxoNursery) startCommitConfWatcher(kid *kidOutput,
+	heightHint uint32) error {
 
-	// Load the last finalized height, so we can determine if the
-	// kindergarten sweep txn should be crafted.
-	lastFinalizedHeight, err := u.cfg.Store.LastFinalizedHeight()
-	if err != nil {
-		return err
-	}
+	desc := fmt.Sprintf("confirmation of commitment outpoint %v",
+		kid.OutPoint())
 
-	// If we haven't processed this height before, we finalize the
-	// graduating kindergarten outputs, by signing a sweep transaction that
-	// spends from them. This txn is persisted such that we never broadcast
-	// a different txn for the same height. This allows us to recover from
-	// failures, and watch for the correct txid.
-	if classHeight > lastFinalizedHeight {
-		// If this height has never been finalized, we have never
-		// generated a sweep txn for this height. Generate one if there
-		// are kindergarten outputs to be spent.
-		if len(kgtnOutputs) > 0 {
-			finalTx, err = u.createSweepTx(kgtnOutputs)
-			if err != nil {
-				utxnLog.Errorf("Failed to create sweep txn at "+
-					"height=%d", classHeight)
-				return err
-			}
+	return u.startConfWatcher(desc, func() (func(), error) {
+		confChan, spendEvent, err := u.registerCommitNtfns(
+			kid, heightHint,
+		)
+		if err != nil {
+			return nil, err
 		}
 
-		// Persist the kindergarten sweep txn to the nursery store. It
-		// is safe to store a nil finalTx, which happens if there are no
-		// graduating kindergarten outputs.
-		err = u.cfg.Store.FinalizeKinder(classHeight, finalTx)
-		if err != nil {
-			utxnLog.Errorf("Failed to finalize kindergarten at "+
-				"height=%d", classHeight)
+		return u.commitConfWaiter(
+			kid, heightHint, confChan, spendEvent,
+		), nil
+	})
+}
 
-			return err
-		}
+// registerCommitNtfns registers for the confirmation of the commitment txn of
+// the given preschool output, and for the spend of its channel's funding
+// outpoint. As it only interacts with the notifier, it may be called without
+// holding the nursery's mutex.
+func (u *utxoNursery) registerCommitNtfns(kid *kidOutput,
+	heightHint uint32) (*chainntnfs.ConfirmationEvent,
+	*chainntnfs.SpendEvent, error) {
 
-		// Log if the finalized transaction is non-trivial.
-		if finalTx != nil {
-			utxnLog.Infof("Finalized kindergarten at height=%d ",
-				classHeight)
-		}
-	}
+	txID := kid.OutPoint().Hash
 
-	// Now that the kindergarten sweep txn has either been finalized or
-	// restored, broadcast the txn, and set up notifications that will
-	// transition the swept kindergarten outputs into graduated outputs.
-	if finalTx != nil {
-		err := u.sweepGraduatingKinders(classHeight, finalTx,
-			kgtnOutputs)
-		if err != nil {
-			utxnLog.Errorf("Failed to sweep %d kindergarten outputs "+
-				"at height=%d: %v", len(kgtnOutputs), classHeight,
-				err)
-			return err
-		}
+	confChan, err := u.cfg.Notifier.RegisterConfirmationsNtfn(&txID,
+		u.commitConfDepth(kid), heightHint)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Now, we broadcast all pre-signed htlc txns from the crib outputs at
-	// this height. There is no need to finalize these txns, since the txid
-	// is predetermined when signed in the wallet.
-	for i := range cribOutputs {
-		err := u.sweepCribOutput(classHeight, &cribOutputs[i])
+	// The commitment txn we expect may never confirm, e.g. if a different
+	// commitment of the channel confirms instead. So we also watch the
+	// channel's funding outpoint, such that we learn of whichever txn
+	// actually closed the channel. Since this is only a fallback, failing
+	// to register is not fatal.
+	var spendEvent *chainntnfs.SpendEvent
+	if isCommitmentOutput(kid) {
+		spendEvent, err = u.cfg.Notifier.RegisterSpendNtfn(
+			kid.OriginChanPoint(), heightHint,
+		)
 		if err != nil {
-			utxnLog.Errorf("Failed to sweep first-stage HTLC "+
-				"(CLTV-delayed) output %v",
-				cribOutputs[i].OutPoint())
-			return err
+			utxnLog.Warnf("Unable to register spend notification "+
+				"for funding outpoint %v: %v",
+				kid.OriginChanPoint(), err)
+			spendEvent = nil
 		}
 	}
 
-	return u.cfg.Store.GraduateHeight(classHeight)
+	utxnLog.Infof("Commitment outpoint %v registered for confirmation "+
+		"notification.", kid.OutPoint())
+
+	return confChan, spendEvent, nil
 }
 
-// craftSweepTx accepts accepts a list of kindergarten outputs, and signs and
-// generates a signed txn that spends from them. This method also makes an
-// accurate fee estimate before generating the required witnesses.
-func (u *utxoNursery) createSweepTx(kgtnOutputs []kidOutput) (*wire.MsgTx, error) {
-	// Create a transaction which sweeps all the newly mature outputs into
-	// a output controlled by the wallet.
-	// TODO(roasbeef): can be more intelligent about buffering outputs to
-	// be more efficient on-chain.
+// commitConfWaiter returns the function run by the watcher of the given
+// preschool output, which waits upon the registered notifications.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex, or
+// during startup.
+func (u *utxoNursery) commitConfWaiter(kid *kidOutput, heightHint uint32,
+	confChan *chainntnfs.ConfirmationEvent,
+	spendEvent *chainntnfs.SpendEvent) func() {
+
+	canceled := u.chanCancel(kid.OriginChanPoint())
+	return func() {
+		u.waitForCommitConf(
+			kid, confChan, spendEvent, canceled, heightHint,
+		)
+	}
+}
 
-	// Assemble the kindergarten class into a slice csv spendable outputs,
-	// while also computing an estimate for the total transaction weight.
-	var (
-		csvSpendableOutputs []CsvSpendableOutput
-		weightEstimate      lnwallet.TxWeightEstimator
-	)
+// waitForCommitConf is intended to be run as a goroutine that will wait until a
+// channel force close commitment transaction has been included in a confirmed
+// block. Once the transaction has been confirmed (as reported by the Chain
+// Notifier), waitForCommitConf will delete the output from the "preschool"
+// database bucket and atomically add it to the "kindergarten" database bucket.
+// This is the second step in the output incubation process. If the
+// confirmation is later reorged out of the chain, the output is moved back to
+// the "preschool" bucket until the commitment transaction confirms again. If a
+// spend event for the channel's funding outpoint is provided, and the channel
+// is closed by a different txn, the output is reconciled against that txn. The
+// goroutine exits once the canceled channel is closed.
+func (u *utxoNursery) waitForCommitConf(kid *kidOutput,
+	confChan *chainntnfs.ConfirmationEvent,
+	spendEvent *chainntnfs.SpendEvent, canceled <-chan struct{},
+	heightHint uint32) {
 
-	// Allocate enough room for each of the kindergarten outputs.
-	csvSpendableOutputs = make([]CsvSpendableOutput, 0, len(kgtnOutputs))
+	defer u.wg.Done()
 
-	// Our sweep transaction will pay to a single segwit p2wkh address,
-	// ensure it contributes to our weight estimate.
-	weightEstimate.AddP2WKHOutput()
+	var spendChan <-chan *chainntnfs.SpendDetail
+	if spendEvent != nil {
+		defer spendEvent.Cancel()
+		spendChan = spendEvent.Spend
+	}
 
-	// For each kindergarten output, use its witness type to determine the
-	// estimate weight of its witness.
-	for i := range kgtnOutputs {
-		input := &kgtnOutputs[i]
+	txID := kid.OutPoint().Hash
 
-		var witnessWeight int
-		switch input.WitnessType() {
-		case lnwallet.CommitmentTimeLock:
-			witnessWeight = lnwallet.ToLocalTimeoutWitnessSize
+	// If the notification is re-registered after the output has been
+	// promoted, the confirmation will be delivered again, and must not
+	// promote the output a second time.
+	var promoted bool
+	for {
+		select {
+		case txConfirmation, ok := <-confChan.Confirmed:
+			if !ok {
+				confChan = u.reregisterConf(
+					&txID, u.commitConfDepth(kid),
+					heightHint,
+				)
+				if confChan == nil {
+					return
+				}
+				continue
+			}
 
-		case lnwallet.HtlcOfferedTimeout:
-			witnessWeight = lnwallet.OfferedHtlcTimeoutWitnessSize
+			if promoted {
+				continue
+			}
 
-		default:
-			utxnLog.Warnf("kindergarten output in nursery store "+
-				"contains unexpected witness type: %v",
-				input.WitnessType())
-			continue
-		}
+			kid.SetConfHeight(txConfirmation.BlockHeight)
+			if !u.promotePreschool(kid, canceled) {
+				return
+			}
+			promoted = true
+
+			// Outputs without a relative timelock are spendable as
+			// soon as the commitment txn confirms, so there is no
+			// need to wait for their height to be processed.
+			if u.cfg.SweepNoDelayImmediately &&
+				kid.BlocksToMaturity() == 0 {
+
+				if err := u.sweepNoDelayKinder(kid); err != nil {
+					utxnLog.Errorf("Unable to immediately "+
+						"sweep commitment output %v, "+
+						"will sweep once its height "+
+						"is processed: %v",
+						kid.OutPoint(), err)
+				}
+			}
 
-		// Add the kindergarten output's input and witness to our
-		// running estimate.
-		weightEstimate.AddWitnessInput(witnessWeight)
+		// The chain notifier will deliver another confirmation on the
+		// same event once the commitment txn is confirmed again, so
+		// there is no need to register a new notification.
+		case reorgDepth, ok := <-confChan.NegativeConf:
+			if !ok {
+				confChan = u.reregisterConf(
+					&txID, u.commitConfDepth(kid),
+					heightHint,
+				)
+				if confChan == nil {
+					return
+				}
+				continue
+			}
 
-		// Include this input in the transaction.
-		csvSpendableOutputs = append(csvSpendableOutputs, input)
-	}
+			if !u.demoteKinder(kid, reorgDepth) {
+				return
+			}
+			promoted = false
 
-	txWeight := uint64(weightEstimate.Weight())
-	return u.sweepCsvSpendableOutputsTxn(txWeight, csvSpendableOutputs)
-}
+		// The funding outpoint is only spent once, so we stop watching
+		// it after the first notification.
+		case spendDetail, ok := <-spendChan:
+			spendChan = nil
+			if !ok {
+				utxnLog.Warnf("Spend notification chan closed "+
+					"for funding outpoint of commitment "+
+					"output %v", kid.OutPoint())
+				continue
+			}
 
-// sweepCsvSpendableOutputsTxn creates a final sweeping transaction with all
-// witnesses in place for all inputs using the provided txn fee. The created
-// transaction has a single output sending all the funds back to the source
-// wallet, after accounting for the fee estimate.
-func (u *utxoNursery) sweepCsvSpendableOutputsTxn(txWeight uint64,
-	inputs []CsvSpendableOutput) (*wire.MsgTx, error) {
+			if promoted || *spendDetail.SpenderTxHash == txID {
+				continue
+			}
 
-	// Generate the receiving script to which the funds will be swept.
-	pkScript, err := u.cfg.GenSweepScript()
-	if err != nil {
-		return nil, err
-	}
+			newConfChan := u.reconcileCommitment(
+				kid, spendDetail, heightHint,
+			)
+			if newConfChan == nil {
+				continue
+			}
 
-	// Sum up the total value contained in the inputs.
-	var totalSum btcutil.Amount
-	for _, o := range inputs {
-		totalSum += o.Amount()
-	}
+			confChan = newConfChan
+			txID = kid.OutPoint().Hash
 
-	// Using the txn weight estimate, compute the required txn fee.
-	feePerWeight, err := u.cfg.Estimator.EstimateFeePerWeight(6)
-	if err != nil {
-		return nil, err
+		case <-canceled:
+			return
+
+		case <-u.quit:
+			return
+		}
 	}
-	txFee := btcutil.Amount(txWeight) * feePerWeight
+}
 
-	// Sweep as much possible, after subtracting txn fees.
-	sweepAmt := int64(totalSum - txFee)
+// isCommitmentOutput returns true if the kid output is an output of the
+// channel's commitment txn, which spends the channel's funding outpoint, rather
+// than e.g. an output of a justice txn.
+func isCommitmentOutput(kid *kidOutput) bool {
+	switch kid.WitnessType() {
+	case lnwallet.CommitmentTimeLock, lnwallet.CommitmentNoDelay,
+		lnwallet.CommitmentAnchor:
 
-	// Create the sweep transaction that we will be building. We use
-	// version 2 as it is required for CSV. The txn will sweep the amount
-	// after fees to the pkscript generated above.
-	sweepTx := wire.NewMsgTx(2)
-	sweepTx.AddTxOut(&wire.TxOut{
-		PkScript: pkScript,
-		Value:    sweepAmt,
-	})
+		return true
 
-	// Add all of our inputs, including the respective CSV delays.
-	for _, input := range inputs {
-		sweepTx.AddTxIn(&wire.TxIn{
-			PreviousOutPoint: *input.OutPoint(),
-			// TODO(roasbeef): assumes pure block delays
-			Sequence: input.BlocksToMaturity(),
-		})
+	default:
+		return false
 	}
+}
 
-	// Before signing the transaction, check to ensure that it meets some
-	// basic validity requirements.
-	// TODO(conner): add more control to sanity checks, allowing us to delay
-	// spending "problem" outputs, e.g. possibly batching with other classes
-	// if fees are too low.
-	btx := btcutil.NewTx(sweepTx)
-	if err := blockchain.CheckTransactionSanity(btx); err != nil {
-		return nil, err
-	}
+// isHtlcOutput returns true if the kid output is derived from one of the
+// channel's htlcs, rather than from its commitment or justice txns.
+func isHtlcOutput(kid *kidOutput) bool {
+	switch kid.WitnessType() {
+	case lnwallet.HtlcOfferedTimeout, lnwallet.HtlcAcceptedSuccess:
+		return true
 
-	hashCache := txscript.NewTxSigHashes(sweepTx)
+	default:
+		return false
+	}
+}
 
-	// With all the inputs in place, use each output's unique witness
-	// function to generate the final witness required for spending.
-	addWitness := func(idx int, tso CsvSpendableOutput) error {
-		witness, err := tso.BuildWitness(u.cfg.Signer, sweepTx, hashCache, idx)
-		if err != nil {
-			return err
+// reconcileCommitment handles the funding outpoint of a preschool output's
+// channel being spent by a txn other than the expected commitment txn. If the
+// spending txn pays the preschool output's script and amount, e.g. because it
+// is a variant of the same commitment, the output is re-keyed to the matching
+// output of the spending txn, and the confirmation of the spending txn is
+// registered in its place. Otherwise, or if re-keying fails, nil is returned
+// and the caller continues to wait upon the original commitment txn.
+func (u *utxoNursery) reconcileCommitment(kid *kidOutput,
+	spendDetail *chainntnfs.SpendDetail,
+	heightHint uint32) *chainntnfs.ConfirmationEvent {
+
+	spenderTxID := *spendDetail.SpenderTxHash
+	oldOutPoint := *kid.OutPoint()
+
+	signOutput := kid.SignDesc().Output
+	outputIndex := -1
+	for i, txOut := range spendDetail.SpendingTx.TxOut {
+		if signOutput != nil && txOut.Value == signOutput.Value &&
+			bytes.Equal(txOut.PkScript, signOutput.PkScript) {
+
+			outputIndex = i
+			break
 		}
-
-		sweepTx.TxIn[idx].Witness = witness
-
+	}
+	if outputIndex < 0 {
+		utxnLog.Criticalf("Channel(%s) closed by txn %v instead of "+
+			"commitment txn %v, and commitment output %v was not "+
+			"found in it", kid.OriginChanPoint(), spenderTxID,
+			oldOutPoint.Hash, oldOutPoint)
 		return nil
 	}
 
-	for i, input := range inputs {
-		if err := addWitness(i, input); err != nil {
-			return nil, err
-		}
+	newOutPoint := wire.OutPoint{
+		Hash:  spenderTxID,
+		Index: uint32(outputIndex),
 	}
 
-	return sweepTx, nil
-}
+	utxnLog.Warnf("Channel(%s) closed by txn %v instead of commitment "+
+		"txn %v, moving commitment output %v to %v",
+		kid.OriginChanPoint(), spenderTxID, oldOutPoint.Hash,
+		oldOutPoint, newOutPoint)
+
+	err := u.retryLocked(
+		fmt.Sprintf("rekey commitment output %v to %v", oldOutPoint,
+			newOutPoint),
+		u.cfg.TransitionRetries, u.cfg.TransitionRetryBackoff,
+		func() error {
+			rekeyed := *kid
+			rekeyed.outpoint = newOutPoint
+			err := u.cfg.Store.RekeyPreschool(&oldOutPoint, &rekeyed)
+			if err != nil {
+				return err
+			}
 
-// sweepGraduatingKinders generates and broadcasts the transaction that
-// transfers control of funds from a channel commitment transaction to the
-// user's wallet.
-func (u *utxoNursery) sweepGraduatingKinders(classHeight uint32,
-	finalTx *wire.MsgTx, kgtnOutputs []kidOutput) error {
+			kid.outpoint = newOutPoint
 
-	utxnLog.Infof("Sweeping %v CSV-delayed outputs with sweep tx "+
-		"(txid=%v): %v", len(kgtnOutputs), finalTx.TxHash(),
-		newLogClosure(func() string {
-			return spew.Sdump(finalTx)
-		}),
+			return nil
+		},
 	)
-
-	// With the sweep transaction fully signed, broadcast the transaction
-	// to the network. Additionally, we can stop tracking these outputs as
-	// they've just been swept.
-	// TODO(conner): handle concrete error types returned from publication
-	if err := u.cfg.PublishTransaction(finalTx); err != nil &&
-		!strings.Contains(err.Error(), "TX rejected:") {
-		utxnLog.Errorf("unable to broadcast sweep tx: %v, %v",
-			err, spew.Sdump(finalTx))
-		return err
+	if err != nil {
+		if err != ErrNurseryShuttingDown {
+			utxnLog.Errorf("Unable to rekey commitment output "+
+				"%v: %v", oldOutPoint, err)
+		}
+		return nil
 	}
 
-	return u.registerSweepConf(finalTx, kgtnOutputs, classHeight)
-}
-
-// registerSweepConf is responsible for registering a finalized kindergarten
-// sweep transaction for confirmation notifications. If the confirmation was
-// successfully registered, a goroutine will be spawned that waits for the
-// confirmation, and graduates the provided kindergarten class within the
-// nursery store.
-func (u *utxoNursery) registerSweepConf(finalTx *wire.MsgTx,
-	kgtnOutputs []kidOutput, heightHint uint32) error {
-
-	finalTxID := finalTx.TxHash()
-
 	confChan, err := u.cfg.Notifier.RegisterConfirmationsNtfn(
-		&finalTxID, u.cfg.ConfDepth, heightHint)
+		&spenderTxID, u.commitConfDepth(kid), heightHint,
+	)
 	if err != nil {
-		utxnLog.Errorf("unable to register notification for "+
-			"sweep confirmation: %v", finalTxID)
-		return err
+		utxnLog.Errorf("Unable to register confirmation notification "+
+			"for txn %v, will retry upon restart: %v", spenderTxID,
+			err)
+		return nil
 	}
 
-	utxnLog.Infof("Registering sweep tx %v for confs at height=%d",
-		finalTxID, heightHint)
-
-	u.wg.Add(1)
-	go u.waitForSweepConf(heightHint, kgtnOutputs, confChan)
-
-	return nil
+	return confChan
 }
 
-// waitForSweepConf watches for the confirmation of a sweep transaction
-// containing a batch of kindergarten outputs. Once confirmation has been
-// received, the nursery will mark those outputs as fully graduated, and proceed
-// to mark any mature channels as fully closed in channeldb.
-// NOTE(conner): this method MUST be called as a go routine.
-func (u *utxoNursery) waitForSweepConf(classHeight uint32,
-	kgtnOutputs []kidOutput, confChan *chainntnfs.ConfirmationEvent) {
+// promotePreschool moves a confirmed commitment output from the preschool to
+// the kindergarten bucket, unless the canceled channel has been closed. The
+// returned boolean indicates whether the caller should continue to monitor the
+// output.
+func (u *utxoNursery) promotePreschool(kid *kidOutput,
+	canceled <-chan struct{}) bool {
+
+	err := u.retryLocked(
+		fmt.Sprintf("move commitment output %v from preschool to "+
+			"kindergarten", kid.OutPoint()),
+		u.cfg.TransitionRetries, u.cfg.TransitionRetryBackoff,
+		func() error {
+			select {
+			case <-canceled:
+				return errIncubationCanceled
+			default:
+			}
 
-	defer u.wg.Done()
+			err := u.cfg.Store.PreschoolToKinder(kid)
+			if err != nil {
+				return fmt.Errorf("unable to promote output of "+
+					"Channel(%s): %v",
+					kid.OriginChanPoint(), err)
+			}
 
-	select {
-	case _, ok := <-confChan.Confirmed:
-		if !ok {
-			utxnLog.Errorf("Notification chan closed, can't"+
-				" advance %v graduating outputs",
-				len(kgtnOutputs))
-			return
-		}
+			err = u.deferOverdueKinder(kid)
+			if err != nil {
+				return fmt.Errorf("unable to defer overdue "+
+					"output: %v", err)
+			}
 
-	case <-u.quit:
-		return
+			return nil
+		},
+	)
+	switch {
+	case err == ErrNurseryShuttingDown, err == errIncubationCanceled:
+		return false
+	case err != nil:
+		utxnLog.Criticalf("Unable to move commitment output %v from "+
+			"preschool to kindergarten bucket, output will not be "+
+			"swept until restart: %v", kid.OutPoint(), err)
+		return false
 	}
 
 	u.mu.Lock()
 	defer u.mu.Unlock()
 
-	// TODO(conner): add retry logic?
+	utxnLog.Infof("Commitment output %v promoted to "+
+		"kindergarten, csv=%v", kid.OutPoint(), kid.BlocksToMaturity())
+
+	u.notifyEvent(newOutputEvent(NurseryEventPromoted, kndrPrefix, kid))
+
+	// Preschool outputs are not bounded by a timelock, as they are only
+	// waiting for the commitment txn to confirm.
+	if entryHeight := kid.EntryHeight(); entryHeight != 0 {
+		u.observeTimeInState(psclPrefix, entryHeight,
+			kid.ConfHeight(), 0)
+	}
+
+	return true
+}
 
-	// Mark the confirmed kindergarten outputs as graduated.
-	if err := u.cfg.Store.GraduateKinder(classHeight); err != nil {
-		utxnLog.Errorf("Unable to graduate %v kingdergarten outputs: "+
-			"%v", len(kgtnOutputs), err)
-		return
+// deferOverdueKinder reschedules a kindergarten output whose maturity height
+// has already been processed or finalized by the nursery, such that it is
+// swept at the next height that is yet to be. Otherwise, the output would be
+// registered at a height that will never be revisited. This can happen if the
+// commitment txn confirmed long before we learned of it, e.g. when recovering
+// from an extended outage.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) deferOverdueKinder(kid *kidOutput) error {
+	nextHeight, err := u.nextUnprocessedHeight()
+	if err != nil {
+		return err
 	}
 
-	utxnLog.Infof("Graduated %d kindergarten outputs from height=%d",
-		len(kgtnOutputs), classHeight)
+	maturityHeight := kid.MaturityHeight()
+	if nextHeight == 0 || maturityHeight >= nextHeight {
+		return nil
+	}
 
-	// Iterate over the kid outputs and construct a set of all channel
-	// points to which they belong.
-	var possibleCloses = make(map[wire.OutPoint]struct{})
-	for _, kid := range kgtnOutputs {
-		possibleCloses[*kid.OriginChanPoint()] = struct{}{}
+	utxnLog.Warnf("Found overdue commitment output %v with maturity "+
+		"height=%d at best height=%d, scheduling sweep at height=%d",
+		kid.OutPoint(), maturityHeight, u.bestHeight, nextHeight)
 
+	err = u.cfg.Store.DeferKinder(kid, maturityHeight, nextHeight)
+	if err != nil {
+		return fmt.Errorf("unable to defer output from height=%d to "+
+			"height=%d: %v", maturityHeight, nextHeight, err)
 	}
 
-	// Attempt to close each channel, only doing so if all of the channel's
-	// outputs have been graduated.
-	for chanPoint := range possibleCloses {
-		if err := u.closeAndRemoveIfMature(&chanPoint); err != nil {
-			utxnLog.Errorf("Failed to close and remove channel %v",
-				chanPoint)
-			return
-		}
-	}
+	return nil
 }
 
-// sweepCribOutput broadcasts the crib output's htlc timeout txn, and sets up a
-// notification that will advance it to the kindergarten bucket upon
-// confirmation.
-func (u *utxoNursery) sweepCribOutput(classHeight uint32, baby *babyOutput) error {
-	utxnLog.Infof("Publishing CTLV-delayed HTLC output using timeout tx "+
-		"(txid=%v): %v", baby.timeoutTx.TxHash(),
-		newLogClosure(func() string {
-			return spew.Sdump(baby.timeoutTx)
-		}),
-	)
+// nextUnprocessedHeight returns the lowest height that has been neither
+// processed nor finalized by the nursery, or zero if the nursery has yet to
+// process or finalize any height.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) nextUnprocessedHeight() (uint32, error) {
+	lastFinalizedHeight, err := u.cfg.Store.LastFinalizedHeight()
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch last finalized "+
+			"height: %v", err)
+	}
 
-	// Broadcast HTLC transaction
-	// TODO(conner): handle concrete error types returned from publication
-	err := u.cfg.PublishTransaction(baby.timeoutTx)
-	if err != nil &&
-		!strings.Contains(err.Error(), "TX rejected:") {
-		utxnLog.Errorf("Unable to broadcast baby tx: "+
-			"%v, %v", err,
-			spew.Sdump(baby.timeoutTx))
-		return err
+	lastHeight := u.bestHeight
+	if lastFinalizedHeight > lastHeight {
+		lastHeight = lastFinalizedHeight
+	}
+	if lastHeight == 0 {
+		return 0, nil
 	}
 
-	return u.registerTimeoutConf(baby, classHeight)
+	return lastHeight + 1, nil
 }
 
-// registerTimeoutConf is responsible for subscribing to confirmation
-// notification for an htlc timeout transaction. If successful, a goroutine will
-// be spawned that will transition the provided baby output into the
-// kindergarten state within the nursery store.
-func (u *utxoNursery) registerTimeoutConf(baby *babyOutput, heightHint uint32) error {
+// sweepNoDelayKinder immediately finalizes and broadcasts the sweep of the
+// height holding a promoted kindergarten output without a relative timelock,
+// rather than waiting for the nursery to process the height. Heights preceding
+// it are finalized in order, such that the nursery never signs different sweep
+// txns for the same height. Since the kindergarten outputs at a height are
+// spendable in the block following their maturity height, the other outputs
+// at the height can safely be swept along with it, while its crib outputs are
+// only broadcast once the height is processed.
+func (u *utxoNursery) sweepNoDelayKinder(kid *kidOutput) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
 
-	birthTxID := baby.timeoutTx.TxHash()
+	// No new sweeps are broadcast while the incubator is paused, the
+	// output will instead be swept once its height is processed.
+	if u.paused {
+		return nil
+	}
 
-	// Register for the confirmation of presigned htlc txn.
-	confChan, err := u.cfg.Notifier.RegisterConfirmationsNtfn(
-		&birthTxID, u.cfg.ConfDepth, heightHint)
-	if err != nil {
+	// Until the nursery has processed a height, the output may still be
+	// deferred once the nursery catches up, so we leave it to be swept
+	// then.
+	nextHeight, err := u.nextUnprocessedHeight()
+	if err != nil || nextHeight == 0 {
 		return err
 	}
 
-	utxnLog.Infof("Htlc output %v registered for promotion "+
-		"notification.", baby.OutPoint())
-
-	u.wg.Add(1)
-	go u.waitForTimeoutConf(baby, confChan)
-
-	return nil
-}
+	// Overdue outputs were deferred to the next unprocessed height upon
+	// their promotion.
+	height := kid.MaturityHeight()
+	if height < nextHeight {
+		height = nextHeight
+	}
 
-// waitForTimeoutConf watches for the confirmation of an htlc timeout
-// transaction, and attempts to move the htlc output from the crib bucket to the
-// kindergarten bucket upon success.
-func (u *utxoNursery) waitForTimeoutConf(baby *babyOutput,
-	confChan *chainntnfs.ConfirmationEvent) {
+	activeHeights, err := u.cfg.Store.HeightsBelowOrEqual(height)
+	if err != nil {
+		return fmt.Errorf("unable to fetch active heights below "+
+			"height=%d: %v", height, err)
+	}
 
-	defer u.wg.Done()
+	lastFinalizedHeight, err := u.cfg.Store.LastFinalizedHeight()
+	if err != nil {
+		return fmt.Errorf("unable to fetch last finalized height: %v",
+			err)
+	}
 
-	select {
-	case txConfirmation, ok := <-confChan.Confirmed:
-		if !ok {
-			utxnLog.Errorf("Notification chan "+
-				"closed, can't advance baby output %v",
-				baby.OutPoint())
-			return
+	for _, activeHeight := range activeHeights {
+		if activeHeight <= lastFinalizedHeight {
+			continue
 		}
 
-		baby.SetConfHeight(txConfirmation.BlockHeight)
+		if _, err := u.finalizeHeight(activeHeight); err != nil {
+			return fmt.Errorf("unable to finalize height=%d: %v",
+				activeHeight, err)
+		}
+	}
 
-	case <-u.quit:
-		return
+	finalTxns, kgtnOutputs, _, err := u.cfg.Store.FetchClass(height)
+	if err != nil {
+		return fmt.Errorf("unable to fetch class at height=%d: %v",
+			height, err)
 	}
 
-	u.mu.Lock()
-	defer u.mu.Unlock()
+	// The output may not have been swept, e.g. if it was too small to be
+	// swept on its own, in which case it is retried at the next height.
+	if len(finalTxns) == 0 {
+		return nil
+	}
 
-	// TODO(conner): add retry logic?
+	utxnLog.Infof("Immediately sweeping commitment output %v without "+
+		"relative timelock at height=%d", kid.OutPoint(), height)
 
-	err := u.cfg.Store.CribToKinder(baby)
+	err = u.sweepGraduatingKinders(height, finalTxns, kgtnOutputs)
 	if err != nil {
-		utxnLog.Errorf("Unable to move htlc output from "+
-			"crib to kindergarten bucket: %v", err)
-		return
+		return fmt.Errorf("unable to sweep height=%d: %v", height, err)
 	}
 
-	utxnLog.Infof("Htlc output %v promoted to "+
-		"kindergarten", baby.OutPoint())
+	return nil
 }
 
-// registerCommitConf is responsible for subscribing to the confirmation of a
-// commitment transaction. If successful, the provided preschool output will be
-// moved persistently into the kindergarten state within the nursery store.
-func (u *utxoNursery) registerCommitConf(kid *kidOutput, heightHint uint32) error {
-	txID := kid.OutPoint().Hash
+// demoteKinder moves a commitment output whose confirmation was reorged out of
+// the chain from the kindergarten back to the preschool bucket. The returned
+// boolean indicates whether the caller should continue to monitor the output.
+func (u *utxoNursery) demoteKinder(kid *kidOutput, reorgDepth int32) bool {
+	utxnLog.Warnf("Confirmation of commitment output %v at height=%d "+
+		"reorged out of chain, depth=%d", kid.OutPoint(),
+		kid.ConfHeight(), reorgDepth)
+
+	var swept bool
+	err := u.retryLocked(
+		fmt.Sprintf("move commitment output %v from kindergarten to "+
+			"preschool", kid.OutPoint()),
+		u.cfg.TransitionRetries, u.cfg.TransitionRetryBackoff,
+		func() error {
+			err := u.cfg.Store.KinderToPreschool(kid)
+			if err == ErrKinderNotFound {
+				swept = true
+				return nil
+			}
 
-	confChan, err := u.cfg.Notifier.RegisterConfirmationsNtfn(&txID,
-		u.cfg.ConfDepth, heightHint)
-	if err != nil {
-		return err
+			return err
+		},
+	)
+	switch {
+	case err == ErrNurseryShuttingDown:
+		return false
+
+	// If the output is no longer in the kindergarten bucket, it has
+	// already been swept, and the sweep will be handled separately.
+	case swept:
+		utxnLog.Warnf("Commitment output %v no longer in "+
+			"kindergarten, unable to demote", kid.OutPoint())
+		return false
+
+	case err != nil:
+		utxnLog.Criticalf("Unable to move commitment output %v from "+
+			"kindergarten to preschool bucket: %v",
+			kid.OutPoint(), err)
+		return false
 	}
 
-	utxnLog.Infof("Commitment outpoint %v registered for "+
-		"confirmation notification.", kid.OutPoint())
+	kid.SetConfHeight(0)
 
-	u.wg.Add(1)
-	go u.waitForCommitConf(kid, confChan)
+	utxnLog.Infof("Commitment output %v demoted to preschool",
+		kid.OutPoint())
 
-	return nil
+	return true
 }
 
-// waitForCommitConf is intended to be run as a goroutine that will wait until a
-// channel force close commitment transaction has been included in a confirmed
-// block. Once the transaction has been confirmed (as reported by the Chain
-// Notifier), waitForCommitConf will delete the output from the "preschool"
-// database bucket and atomically add it to the "kindergarten" database bucket.
-// This is the second step in the output incubation process.
-func (u *utxoNursery) waitForCommitConf(kid *kidOutput,
-	confChan *chainntnfs.ConfirmationEvent) {
+// updateLimboBalance reports the total value of all outputs being incubated by
+// the nursery to the nursery's metrics. This should be called after each state
+// transition that changes the limbo balance, i.e. incubation and graduation.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) updateLimboBalance() {
+	// Avoid scanning the nursery store if the balance would be discarded.
+	if _, ok := u.cfg.Metrics.(noopNurseryMetrics); ok {
+		return
+	}
 
-	defer u.wg.Done()
+	chanPoints, err := u.cfg.Store.ListChannels()
+	if err != nil {
+		utxnLog.Errorf("Unable to list nursery channels: %v", err)
+		return
+	}
 
-	select {
-	case txConfirmation, ok := <-confChan.Confirmed:
-		if !ok {
-			utxnLog.Errorf("Notification chan "+
-				"closed, can't advance output %v",
-				kid.OutPoint())
+	var limboBalance btcutil.Amount
+	for i := range chanPoints {
+		balance, err := u.cfg.Store.ChanLimboBalance(&chanPoints[i])
+		if err != nil {
+			utxnLog.Errorf("Unable to fetch limbo balance of "+
+				"Channel(%s): %v", &chanPoints[i], err)
 			return
 		}
-
-		kid.SetConfHeight(txConfirmation.BlockHeight)
-
-	case <-u.quit:
-		return
+		limboBalance += balance
 	}
 
-	u.mu.Lock()
-	defer u.mu.Unlock()
+	u.cfg.Metrics.SetLimboBalance(limboBalance)
+}
 
-	// TODO(conner): add retry logic?
+// observeTimeInState reports the number of blocks elapsed between an output's
+// entry height and exit height for the given state to the nursery's metrics.
+func (u *utxoNursery) observeTimeInState(state []byte, entryHeight,
+	exitHeight, expected uint32) {
 
-	err := u.cfg.Store.PreschoolToKinder(kid)
-	if err != nil {
-		utxnLog.Errorf("Unable to move commitment output "+
-			"from preschool to kindergarten bucket: %v",
-			err)
+	if exitHeight < entryHeight {
 		return
 	}
 
-	utxnLog.Infof("Commitment output %v promoted to "+
-		"kindergarten, csv=%v", kid.OutPoint(), kid.BlocksToMaturity())
+	u.cfg.Metrics.ObserveTimeInState(string(state),
+		exitHeight-entryHeight, expected)
 }
 
 // contractMaturityReport is a report that details the maturity progress of a
@@ -1208,14 +7405,39 @@ type contractMaturityReport struct {
 	// awaiting maturity within the utxoNursery.
 	chanPoint wire.OutPoint
 
+	// closeTxid is the txid of the force close txn of the contract, from
+	// which its outputs originate. This is nil if the txid was not
+	// recorded when the contract's outputs were incubated.
+	closeTxid *chainhash.Hash
+
 	// limboBalance is the total number of frozen coins within this
 	// contract.
 	limboBalance btcutil.Amount
 
+	// byWitnessType counts the contract's outputs that have yet to be
+	// swept, i.e. those in the crib, preschool, or kindergarten, by their
+	// witness type.
+	byWitnessType map[lnwallet.WitnessType]int
+
 	// recoveredBalance is the total value that has been successfully swept
 	// back to the user's wallet.
 	recoveredBalance btcutil.Amount
 
+	// recoveryFee is the estimated fee that will be paid to sweep the
+	// contract's kindergarten outputs, at the fee rate at the time the
+	// report was generated.
+	recoveryFee btcutil.Amount
+
+	// sweepRebroadcasts is the greatest number of times any sweep txn of
+	// the contract's kindergarten outputs has been rebroadcast without
+	// confirming.
+	sweepRebroadcasts uint32
+
+	// awaitingFinalConfs is true if all of the contract's outputs have been
+	// swept, but the sweeps have yet to reach the confirmation depth
+	// required before the channel is marked fully closed.
+	awaitingFinalConfs bool
+
 	// localAmount is the local value of the commitment output.
 	localAmount btcutil.Amount
 
@@ -1232,6 +7454,57 @@ type contractMaturityReport struct {
 
 	// htlcs records a maturity report for each htlc output in this channel.
 	htlcs []htlcMaturityReport
+
+	// outputs records a maturity report for every output of this channel
+	// tracked by the nursery, including the commitment output.
+	outputs []outputMaturityReport
+
+	// droppedOutputs records the outputs of this channel that were
+	// abandoned rather than incubated, along with the reason why.
+	droppedOutputs []droppedOutput
+}
+
+// outputMaturityReport provides a summary of a single output tracked by the
+// nursery, and is embedded as part of the overarching contractMaturityReport.
+type outputMaturityReport struct {
+	// outpoint is the outpoint of the output within the nursery.
+	outpoint wire.OutPoint
+
+	// amount is the value of the output.
+	amount btcutil.Amount
+
+	// witnessType is the witness type used to spend the output.
+	witnessType lnwallet.WitnessType
+
+	// witnessName is the human readable name of the output's witness
+	// type, suitable for display to users.
+	witnessName string
+
+	// state is the nursery state the output currently resides in, one of
+	// crib, pscl, kndr, or grad.
+	state string
+
+	// maturityHeight is the absolute block height that this output will
+	// mature at, or zero if it is not yet known.
+	maturityHeight uint32
+
+	// sweepConfHeight is the block height at which the output's sweep txn
+	// confirmed, or zero if the output has not yet graduated.
+	sweepConfHeight uint32
+
+	// incubatedAt is the time at which the output entered the nursery, or
+	// the zero time if it was incubated before this was recorded.
+	incubatedAt time.Time
+
+	// confsRemaining is the number of confirmations the commitment txn of
+	// a preschool output still requires before the output is promoted to
+	// the kindergarten. This is zero for outputs in any other state.
+	confsRemaining uint32
+
+	// blocksRemaining is the number of blocks remaining until a
+	// kindergarten output reaches its maturity height. This is zero for
+	// outputs in any other state.
+	blocksRemaining uint32
 }
 
 // htlcMaturityReport provides a summary of a single htlc output, and is
@@ -1273,7 +7546,7 @@ func (c *contractMaturityReport) AddLimboCommitment(kid *kidOutput) {
 	// If the confirmation height is set, then this means the contract has
 	// been confirmed, and we know the final maturity height.
 	if kid.ConfHeight() != 0 {
-		c.maturityHeight = kid.BlocksToMaturity() + kid.ConfHeight()
+		c.maturityHeight = kid.MaturityHeight()
 	}
 }
 
@@ -1285,7 +7558,7 @@ func (c *contractMaturityReport) AddRecoveredCommitment(kid *kidOutput) {
 	c.localAmount += kid.Amount()
 	c.confHeight = kid.ConfHeight()
 	c.maturityRequirement = kid.BlocksToMaturity()
-	c.maturityHeight = kid.BlocksToMaturity() + kid.ConfHeight()
+	c.maturityHeight = kid.MaturityHeight()
 }
 
 // AddLimboStage1Htlc adds an htlc crib output to the maturity report's
@@ -1319,7 +7592,7 @@ func (c *contractMaturityReport) AddLimboStage2Htlc(kid *kidOutput) {
 	// has been confirmed, and we know the final maturity height of the CSV
 	// delay.
 	if kid.ConfHeight() != 0 {
-		htlcReport.maturityHeight = kid.ConfHeight() + kid.BlocksToMaturity()
+		htlcReport.maturityHeight = kid.MaturityHeight()
 	}
 
 	c.htlcs = append(c.htlcs, htlcReport)
@@ -1335,9 +7608,115 @@ func (c *contractMaturityReport) AddRecoveredHtlc(kid *kidOutput) {
 		amount:              kid.Amount(),
 		confHeight:          kid.ConfHeight(),
 		maturityRequirement: kid.BlocksToMaturity(),
-		maturityHeight:      kid.ConfHeight() + kid.BlocksToMaturity(),
+		maturityHeight:      kid.MaturityHeight(),
+	})
+
+}
+
+// AddImmatureOutput counts an output that has yet to be swept towards the
+// report's breakdown of such outputs by witness type.
+func (c *contractMaturityReport) AddImmatureOutput(kid *kidOutput) {
+	c.byWitnessType[kid.WitnessType()]++
+}
+
+// AddOutput adds a maturity report for an output in the given nursery state to
+// the report's outputs. The balances of the report are left unmodified.
+func (c *contractMaturityReport) AddOutput(state []byte, kid *kidOutput,
+	maturityHeight uint32) {
+
+	c.outputs = append(c.outputs, outputMaturityReport{
+		outpoint:        *kid.OutPoint(),
+		amount:          kid.Amount(),
+		witnessType:     kid.WitnessType(),
+		witnessName:     kid.WitnessType().String(),
+		state:           string(state),
+		maturityHeight:  maturityHeight,
+		sweepConfHeight: kid.SweepConfHeight(),
+		incubatedAt:     kid.IncubatedAt(),
 	})
+}
+
+// closeOrAwaitFinalConfs closes the given channel if all of its outputs have
+// graduated and its final sweep, confirmed at sweepHeight, has reached
+// GraduationConfDepth. If the sweep has yet to reach this depth, the channel is
+// scheduled to be closed by closeFinalizedChannels once it does.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) closeOrAwaitFinalConfs(chanPoint *wire.OutPoint,
+	sweepHeight uint32) error {
+
+	if u.cfg.GraduationConfDepth <= u.cfg.SweepConfDepth {
+		return u.closeAndRemoveIfMature(chanPoint)
+	}
+
+	isMature, err := u.cfg.Store.IsMatureChannel(chanPoint)
+	if err == ErrContractNotFound {
+		return nil
+	} else if err != nil {
+		utxnLog.Errorf("Unable to determine maturity of "+
+			"channel=%s", chanPoint)
+		return err
+	}
+
+	if !isMature {
+		return nil
+	}
+
+	// The sweep has one confirmation at the height it was included in, so
+	// it reaches GraduationConfDepth GraduationConfDepth-1 blocks later.
+	closeHeight := sweepHeight + u.cfg.GraduationConfDepth - 1
+	if u.bestHeight >= closeHeight {
+		delete(u.finalConfHeights, *chanPoint)
+		return u.closeAndRemoveIfMature(chanPoint)
+	}
+
+	utxnLog.Infof("Channel(%s) swept, awaiting final confirmations "+
+		"until height=%d", chanPoint, closeHeight)
+
+	u.finalConfHeights[*chanPoint] = closeHeight
+
+	return nil
+}
+
+// closeFinalizedChannels closes each graduated channel whose final sweep has
+// reached GraduationConfDepth as of the given height.
+func (u *utxoNursery) closeFinalizedChannels(height uint32) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for chanPoint, closeHeight := range u.finalConfHeights {
+		if height < closeHeight {
+			continue
+		}
+
+		chanPoint := chanPoint
+		if err := u.closeAndRemoveIfMature(&chanPoint); err != nil {
+			utxnLog.Errorf("Failed to close and remove channel %v",
+				chanPoint)
+			continue
+		}
 
+		delete(u.finalConfHeights, chanPoint)
+	}
+}
+
+// isChanFullyClosed returns true if the close summary of the given channel is
+// no longer pending, indicating it has already been marked fully closed. Any
+// failure to fetch the close summaries is treated as the channel not being
+// fully closed.
+func (u *utxoNursery) isChanFullyClosed(chanPoint *wire.OutPoint) bool {
+	closeSummaries, err := u.cfg.DB.FetchClosedChannels(false)
+	if err != nil {
+		return false
+	}
+
+	for _, closeSummary := range closeSummaries {
+		if closeSummary.ChanPoint == *chanPoint {
+			return !closeSummary.IsPending
+		}
+	}
+
+	return false
 }
 
 // closeAndRemoveIfMature removes a particular channel from the channel index
@@ -1354,44 +7733,314 @@ func (u *utxoNursery) closeAndRemoveIfMature(chanPoint *wire.OutPoint) error {
 		return err
 	}
 
-	// Nothing to do if we are still incubating.
-	if !isMature {
-		return nil
+	// Nothing to do if we are still incubating.
+	if !isMature {
+		return nil
+	}
+
+	// Before the channel is removed from the nursery store, gather the
+	// txids of its sweeps and the total value they recovered, so that
+	// they can be recorded in the channel's close summary.
+	sweepTxids, err := u.cfg.Store.ChanSweepTxids(chanPoint)
+	if err != nil {
+		utxnLog.Errorf("Unable to fetch sweep txids of "+
+			"channel=%s: %v", chanPoint, err)
+		return err
+	}
+
+	var recoveredBalance btcutil.Amount
+	err = u.cfg.Store.ForChanOutputs(chanPoint, func(k, v []byte) error {
+		var kid kidOutput
+		if err := kid.Decode(bytes.NewReader(v)); err != nil {
+			return err
+		}
+		recoveredBalance += kid.Amount()
+
+		return nil
+	})
+	if err != nil {
+		utxnLog.Errorf("Unable to compute recovered balance of "+
+			"channel=%s: %v", chanPoint, err)
+		return err
+	}
+
+	// Now that the sweeping transaction has been broadcast, for
+	// each of the immature outputs, we'll mark them as being fully
+	// closed within the database.
+	err = u.cfg.DB.MarkChanSwept(chanPoint, sweepTxids, recoveredBalance)
+	switch {
+	case err == nil:
+		utxnLog.Infof("Marked Channel(%s) as fully closed", chanPoint)
+
+	// The channel may have already been marked fully closed, e.g. by an
+	// earlier attempt that failed to remove it from the nursery store. In
+	// that case, we proceed to remove it, such that the store is cleaned
+	// up.
+	case u.isChanFullyClosed(chanPoint):
+		utxnLog.Infof("Channel(%s) already marked as fully closed: %v",
+			chanPoint, err)
+
+	default:
+		utxnLog.Errorf("Unable to mark channel=%v as fully "+
+			"closed: %v", chanPoint, err)
+		return err
+	}
+
+	if u.cfg.OnChannelMatured != nil {
+		u.cfg.OnChannelMatured(*chanPoint, recoveredBalance)
+	}
+
+	// Now that the channel is fully closed, we remove the channel from the
+	// nursery store here. This preserves the invariant that we never remove
+	// a channel unless it is mature, as this is the only place the utxo
+	// nursery removes a channel. If configured to do so, the channel's
+	// graduated outputs are archived rather than deleted.
+	removeChannel := u.cfg.Store.RemoveChannel
+	if u.cfg.RetainGraduated {
+		removeChannel = u.cfg.Store.ArchiveChannel
+	}
+	if err := removeChannel(chanPoint); err != nil {
+		utxnLog.Errorf("Unable to remove channel=%s from "+
+			"nursery store: %v", chanPoint, err)
+		return err
+	}
+
+	utxnLog.Infof("Removed channel %v from nursery store", chanPoint)
+
+	delete(u.chanCancels, *chanPoint)
+
+	u.notifyEvent(&NurseryEvent{
+		Type:      NurseryEventChannelClosed,
+		ChanPoint: *chanPoint,
+	})
+
+	return nil
+}
+
+// NurseryEventType identifies the state transition described by a
+// NurseryEvent.
+type NurseryEventType uint8
+
+const (
+	// NurseryEventIncubated indicates that an output has entered the
+	// nursery, either in the preschool or crib state.
+	NurseryEventIncubated NurseryEventType = iota
+
+	// NurseryEventPromoted indicates that a commitment output has been
+	// promoted from the preschool to the kindergarten state.
+	NurseryEventPromoted
+
+	// NurseryEventEnrolled indicates that an htlc output has been enrolled
+	// in the kindergarten state after its timeout txn confirmed.
+	NurseryEventEnrolled
+
+	// NurseryEventSwept indicates that the sweep txn spending a
+	// kindergarten output has been broadcast.
+	NurseryEventSwept
+
+	// NurseryEventGraduated indicates that the sweep txn spending a
+	// kindergarten output has confirmed, and the output has graduated.
+	NurseryEventGraduated
+
+	// NurseryEventChannelClosed indicates that all outputs of a channel
+	// have graduated, and the channel has been marked fully closed. Events
+	// of this type do not describe a particular output.
+	NurseryEventChannelClosed
+
+	// NurseryEventResolved indicates that the htlc output spent by a crib
+	// output's timeout txn was claimed by another txn, such that the crib
+	// output was resolved without advancing to the kindergarten state.
+	NurseryEventResolved
+
+	// NurseryEventSweepConfirmed indicates that a sweep txn has confirmed,
+	// graduating the kindergarten outputs it spends. Events of this type
+	// describe the sweep txn rather than a particular output, with Amount
+	// holding the total value of the outputs it spends.
+	NurseryEventSweepConfirmed
+)
+
+// String returns a human readable representation of the event type.
+func (t NurseryEventType) String() string {
+	switch t {
+	case NurseryEventIncubated:
+		return "Incubated"
+	case NurseryEventPromoted:
+		return "Promoted"
+	case NurseryEventEnrolled:
+		return "Enrolled"
+	case NurseryEventSwept:
+		return "Swept"
+	case NurseryEventGraduated:
+		return "Graduated"
+	case NurseryEventChannelClosed:
+		return "ChannelClosed"
+	case NurseryEventResolved:
+		return "Resolved"
+	case NurseryEventSweepConfirmed:
+		return "SweepConfirmed"
+	default:
+		return "Unknown"
+	}
+}
+
+// NurseryEvent describes a state transition of an output within the nursery.
+type NurseryEvent struct {
+	// Type is the kind of transition the event describes.
+	Type NurseryEventType
+
+	// ChanPoint is the channel point of the channel the output originated
+	// from.
+	ChanPoint wire.OutPoint
+
+	// OutPoint is the outpoint of the output.
+	OutPoint wire.OutPoint
+
+	// Amount is the value of the output.
+	Amount btcutil.Amount
+
+	// State is the nursery state of the output after the transition, one
+	// of crib, pscl, kndr, or grad.
+	State string
+
+	// SweepTxid is the txid of the confirmed sweep txn, only set for
+	// events of type NurseryEventSweepConfirmed.
+	SweepTxid chainhash.Hash
+
+	// Fee is the fee paid by the confirmed sweep txn, only set for events
+	// of type NurseryEventSweepConfirmed.
+	Fee btcutil.Amount
+
+	// NetSwept is the value returned to the wallet by the confirmed sweep
+	// txn after paying its fee, only set for events of type
+	// NurseryEventSweepConfirmed.
+	NetSwept btcutil.Amount
+}
+
+// newOutputEvent constructs a NurseryEvent of the given type describing the
+// provided output, which now resides in the given nursery state.
+func newOutputEvent(eventType NurseryEventType, state []byte,
+	kid *kidOutput) *NurseryEvent {
+
+	return &NurseryEvent{
+		Type:      eventType,
+		ChanPoint: *kid.OriginChanPoint(),
+		OutPoint:  *kid.OutPoint(),
+		Amount:    kid.Amount(),
+		State:     string(state),
+	}
+}
+
+// NurseryEventSubscription delivers the events emitted by the nursery to a
+// single subscriber, in the order in which they occurred.
+type NurseryEventSubscription struct {
+	// Events receives each event emitted by the nursery. The channel is
+	// closed once the subscription is cancelled, or the nursery stops.
+	Events <-chan *NurseryEvent
+
+	// Cancel unregisters the subscription, freeing any resources.
+	Cancel func()
+
+	events chan *NurseryEvent
+	queue  []*NurseryEvent
+	signal chan struct{}
+	cancel chan struct{}
+	id     uint64
+}
+
+// SubscribeNurseryEvents returns a subscription that receives an event
+// whenever an output within the nursery transitions state.
+func (u *utxoNursery) SubscribeNurseryEvents() *NurseryEventSubscription {
+	events := make(chan *NurseryEvent)
+	client := &NurseryEventSubscription{
+		Events: events,
+		events: events,
+		signal: make(chan struct{}, 1),
+		cancel: make(chan struct{}),
 	}
 
-	// Now that the sweeping transaction has been broadcast, for
-	// each of the immature outputs, we'll mark them as being fully
-	// closed within the database.
-	err = u.cfg.DB.MarkChanFullyClosed(chanPoint)
-	if err != nil {
-		utxnLog.Errorf("Unable to mark channel=%v as fully "+
-			"closed: %v", chanPoint, err)
-		return err
+	var once sync.Once
+	client.Cancel = func() {
+		once.Do(func() {
+			u.mu.Lock()
+			delete(u.eventClients, client.id)
+			u.mu.Unlock()
+
+			close(client.cancel)
+		})
 	}
 
-	utxnLog.Infof("Marked Channel(%s) as fully closed", chanPoint)
+	u.mu.Lock()
+	client.id = u.nextEventClientID
+	u.nextEventClientID++
+	u.eventClients[client.id] = client
+	u.mu.Unlock()
 
-	// Now that the channel is fully closed, we remove the channel from the
-	// nursery store here. This preserves the invariant that we never remove
-	// a channel unless it is mature, as this is the only place the utxo
-	// nursery removes a channel.
-	if err := u.cfg.Store.RemoveChannel(chanPoint); err != nil {
-		utxnLog.Errorf("Unable to remove channel=%s from "+
-			"nursery store: %v", chanPoint, err)
-		return err
+	u.wg.Add(1)
+	go u.dispatchEvents(client)
+
+	return client
+}
+
+// notifyEvent queues the given event for delivery to all subscribers.
+//
+// NOTE: This method MUST be called while holding the nursery's mutex.
+func (u *utxoNursery) notifyEvent(event *NurseryEvent) {
+	for _, client := range u.eventClients {
+		client.queue = append(client.queue, event)
+
+		select {
+		case client.signal <- struct{}{}:
+		default:
+		}
 	}
+}
 
-	utxnLog.Infof("Removed channel %v from nursery store", chanPoint)
+// dispatchEvents delivers the events queued for a subscriber in order, until
+// the subscription is cancelled or the nursery shuts down, after which the
+// subscriber's channel is closed.
+// NOTE: This method MUST be called as a go routine.
+func (u *utxoNursery) dispatchEvents(client *NurseryEventSubscription) {
+	defer u.wg.Done()
+	defer close(client.events)
 
-	return nil
+	for {
+		u.mu.Lock()
+		if len(client.queue) == 0 {
+			u.mu.Unlock()
+
+			select {
+			case <-client.signal:
+				continue
+			case <-client.cancel:
+				return
+			case <-u.quit:
+				return
+			}
+		}
+
+		event := client.queue[0]
+		client.queue[0] = nil
+		client.queue = client.queue[1:]
+		u.mu.Unlock()
+
+		select {
+		case client.events <- event:
+		case <-client.cancel:
+			return
+		case <-u.quit:
+			return
+		}
+	}
 }
 
 // newSweepPkScript creates a new public key script which should be used to
-// sweep any time-locked, or contested channel funds into the wallet.
-// Specifically, the script generated is a version 0, pay-to-witness-pubkey-hash
-// (p2wkh) output.
-func newSweepPkScript(wallet lnwallet.WalletController) ([]byte, error) {
-	sweepAddr, err := wallet.NewAddress(lnwallet.WitnessPubKey, false)
+// sweep any time-locked, or contested channel funds into the wallet. The script
+// pays to a new wallet address of the given type, e.g. a version 0,
+// pay-to-witness-pubkey-hash (p2wkh) output.
+func newSweepPkScript(wallet lnwallet.WalletController,
+	addrType lnwallet.AddressType) ([]byte, error) {
+
+	sweepAddr, err := wallet.NewAddress(addrType, false)
 	if err != nil {
 		return nil, err
 	}
@@ -1399,6 +8048,29 @@ func newSweepPkScript(wallet lnwallet.WalletController) ([]byte, error) {
 	return txscript.PayToAddrScript(sweepAddr)
 }
 
+// Sweeper is responsible for crafting the txns that sweep mature outputs back
+// into the wallet. This allows the nursery to track the maturity of its outputs
+// while delegating the sweeping strategy, e.g. fee bumping or batching, to an
+// external implementation.
+type Sweeper interface {
+	// SweepInputs returns a fully signed txn spending each of the provided
+	// mature outputs.
+	SweepInputs([]CsvSpendableOutput) (*wire.MsgTx, error)
+}
+
+// ExternalSweeper is a dedicated sweep service to which the nursery hands off
+// its mature outputs, rather than crafting and broadcasting their sweeps
+// itself. The nursery continues to decide when outputs are swept, and tracks
+// their graduation.
+type ExternalSweeper interface {
+	// SweepOutputs hands off the provided mature outputs, returning the
+	// txid of the txn that sweeps them. The txn must spend every one of
+	// the outputs. After a restart, the same outputs may be handed off
+	// again, in which case the txid of the existing sweep should be
+	// returned.
+	SweepOutputs([]CsvSpendableOutput) (*chainhash.Hash, error)
+}
+
 // CsvSpendableOutput is a SpendableOutput that contains all of the information
 // necessary to construct, sign, and sweep an output locked with a CSV delay.
 type CsvSpendableOutput interface {
@@ -1414,12 +8086,74 @@ type CsvSpendableOutput interface {
 
 	// BlocksToMaturity returns the relative timelock, as a number of
 	// blocks, that must be built on top of the confirmation height before
-	// the output can be spent.
+	// the output can be spent. For time based locks, this is instead
+	// measured in seconds.
 	BlocksToMaturity() uint32
 
+	// Sequence returns the sequence number of the txin spending this
+	// output, encoding its relative timelock.
+	Sequence() uint32
+
 	// OriginChanPoint returns the outpoint of the channel from which this
 	// output is derived.
 	OriginChanPoint() *wire.OutPoint
+
+	// SweepPriority returns how urgently the output should be swept,
+	// which determines the fee rate paid by its sweep txn.
+	SweepPriority() SweepPriority
+}
+
+// SweepPriority expresses how urgently an output should be swept. Outputs are
+// swept in separate txns per priority tier, with the fee rate of each txn
+// estimated using the tier's confirmation target.
+type SweepPriority uint8
+
+const (
+	// SweepPriorityNormal is the priority of outputs that should be swept
+	// in a timely manner, but aren't racing anyone.
+	SweepPriorityNormal SweepPriority = iota
+
+	// SweepPriorityRelaxed is the priority of outputs that can only be
+	// claimed by us, such as our own delayed commitment output, which can
+	// afford a slow, cheap sweep.
+	SweepPriorityRelaxed
+
+	// SweepPriorityUrgent is the priority of outputs that the
+	// counterparty may attempt to claim before us, such as the outputs of
+	// a revoked commitment, which should be swept in the next block.
+	SweepPriorityUrgent
+)
+
+// ConfTarget returns the confirmation target, in blocks, used to estimate the
+// fee rate of sweeps of this priority, given the configured target for normal
+// sweeps. Urgent sweeps always target the next block, while relaxed sweeps
+// never target a sooner block than normal sweeps.
+func (p SweepPriority) ConfTarget(normalTarget uint32) uint32 {
+	switch p {
+	case SweepPriorityUrgent:
+		return urgentSweepConfTarget
+	case SweepPriorityRelaxed:
+		if normalTarget > relaxedSweepConfTarget {
+			return normalTarget
+		}
+		return relaxedSweepConfTarget
+	default:
+		return normalTarget
+	}
+}
+
+// String returns a human readable name for the sweep priority.
+func (p SweepPriority) String() string {
+	switch p {
+	case SweepPriorityNormal:
+		return "Normal"
+	case SweepPriorityRelaxed:
+		return "Relaxed"
+	case SweepPriorityUrgent:
+		return "Urgent"
+	default:
+		return fmt.Sprintf("SweepPriority(%d)", uint8(p))
+	}
 }
 
 // babyOutput represents a two-stage CSV locked output, and is used to track
@@ -1492,6 +8226,23 @@ func (bo *babyOutput) Decode(r io.Reader) error {
 	return bo.kidOutput.Decode(r)
 }
 
+// validate checks that a decoded baby output satisfies the invariants upheld by
+// the outputs written to the nursery store.
+func (bo *babyOutput) validate() error {
+	switch {
+	case bo.expiry == 0:
+		return fmt.Errorf("baby output %v has no expiry", bo.OutPoint())
+
+	case bo.timeoutTx == nil || len(bo.timeoutTx.TxIn) == 0 ||
+		len(bo.timeoutTx.TxOut) == 0:
+
+		return fmt.Errorf("baby output %v has malformed timeout tx",
+			bo.OutPoint())
+	}
+
+	return bo.kidOutput.validate()
+}
+
 // kidOutput represents an output that's waiting for a required blockheight
 // before its funds will be available to be moved into the user's wallet.  The
 // struct includes a WitnessGenerator closure which will be used to generate
@@ -1503,22 +8254,72 @@ type kidOutput struct {
 
 	originChanPoint wire.OutPoint
 
-	// TODO(roasbeef): using block timeouts everywhere currently, will need
-	// to modify logic later to account for MTP based timeouts.
+	// blocksToMaturity is the relative timelock of the output. If
+	// timeLocked is set, this is measured in seconds of median-time-past,
+	// otherwise it is a number of blocks.
 	blocksToMaturity uint32
 	confHeight       uint32
+
+	// timeLocked indicates that the output's relative timelock is time
+	// based, rather than block based.
+	timeLocked bool
+
+	// feeBudget is the maximum fee this output may contribute towards the
+	// transaction that sweeps it. A zero value indicates no budget.
+	feeBudget btcutil.Amount
+
+	// sweepPkScript is the script to which the output should be swept. If
+	// empty, the output is swept to a script generated by the wallet.
+	sweepPkScript []byte
+
+	// sweepConfHeight is the height at which the txn sweeping the output
+	// confirmed. This is only set once the output has graduated.
+	sweepConfHeight uint32
+
+	// incubatedAt is the time at which the output was handed to the
+	// nursery, persisted with a granularity of one second.
+	incubatedAt time.Time
+
+	// entryHeight is the best height known to the nursery when the output
+	// entered the crib or preschool state, allowing the number of blocks
+	// spent in that state to be reported once it transitions. A zero
+	// value indicates that the height is unknown.
+	entryHeight uint32
+
+	// sweepFeeRate is the fee rate, in satoshis per unit of weight, that
+	// should be preferred over the live fee estimate when sweeping the
+	// output. A zero value indicates no preference.
+	sweepFeeRate btcutil.Amount
+
+	// confDepth is the number of confirmations required for the commitment
+	// txn of the output's channel before the output is promoted to the
+	// kindergarten, overriding CommitConfDepth. A zero value indicates that
+	// CommitConfDepth applies.
+	confDepth uint32
 }
 
+// makeKidOutput constructs a kid output with the given relative timelock. If
+// blocksToMaturity has the wire.SequenceLockTimeIsSeconds flag set, it is
+// interpreted as a time based relative lock, encoded as in a txin sequence.
 func makeKidOutput(outpoint, originChanPoint *wire.OutPoint,
 	blocksToMaturity uint32, witnessType lnwallet.WitnessType,
 	signDescriptor *lnwallet.SignDescriptor) kidOutput {
 
+	var timeLocked bool
+	if blocksToMaturity&wire.SequenceLockTimeIsSeconds != 0 {
+		timeLocked = true
+		blocksToMaturity = (blocksToMaturity &
+			wire.SequenceLockTimeMask) << wire.SequenceLockTimeGranularity
+	}
+
 	return kidOutput{
 		breachedOutput: makeBreachedOutput(
 			outpoint, witnessType, signDescriptor,
 		),
 		originChanPoint:  *originChanPoint,
 		blocksToMaturity: blocksToMaturity,
+		timeLocked:       timeLocked,
+		incubatedAt:      time.Unix(time.Now().Unix(), 0),
 	}
 }
 
@@ -1538,12 +8339,142 @@ func (k *kidOutput) ConfHeight() uint32 {
 	return k.confHeight
 }
 
+// SweepPkScript returns the script to which the output should be swept, or nil
+// if the output should be swept back into the wallet.
+func (k *kidOutput) SweepPkScript() []byte {
+	return k.sweepPkScript
+}
+
+// SweepConfHeight returns the height at which the output's sweep txn confirmed,
+// or zero if the output has not yet graduated.
+func (k *kidOutput) SweepConfHeight() uint32 {
+	return k.sweepConfHeight
+}
+
+// IncubatedAt returns the time at which the output entered the nursery, or the
+// zero time if the output was incubated before this was recorded.
+func (k *kidOutput) IncubatedAt() time.Time {
+	return k.incubatedAt
+}
+
+// EntryHeight returns the height at which the output entered the crib or
+// preschool state, or zero if the height is unknown.
+func (k *kidOutput) EntryHeight() uint32 {
+	return k.entryHeight
+}
+
+// SweepFeeRate returns the fee rate, in satoshis per unit of weight, at which
+// the output should preferably be swept, or zero if there is no preference.
+func (k *kidOutput) SweepFeeRate() btcutil.Amount {
+	return k.sweepFeeRate
+}
+
+// ConfDepth returns the number of confirmations required for the output's
+// commitment txn, or zero if the nursery's CommitConfDepth applies.
+func (k *kidOutput) ConfDepth() uint32 {
+	return k.confDepth
+}
+
+// IsTimeLocked returns true if the output's relative timelock is measured in
+// seconds of median-time-past rather than blocks.
+func (k *kidOutput) IsTimeLocked() bool {
+	return k.timeLocked
+}
+
+// Sequence returns the sequence number that must be used by the txin spending
+// this output in order to satisfy its relative timelock. Time based locks are
+// rounded up to the granularity supported by the sequence encoding.
+func (k *kidOutput) Sequence() uint32 {
+	if !k.timeLocked {
+		return k.blocksToMaturity
+	}
+
+	units := (k.blocksToMaturity + 1<<wire.SequenceLockTimeGranularity - 1) >>
+		wire.SequenceLockTimeGranularity
+
+	return wire.SequenceLockTimeIsSeconds | units
+}
+
+// MaturityHeight returns the height at which the output is expected to mature,
+// or zero if it has not yet confirmed. For time based locks, this is an
+// estimate assuming the target block interval, and the output's maturity must
+// be confirmed using the median-time-past before it is swept.
+func (k *kidOutput) MaturityHeight() uint32 {
+	if k.confHeight == 0 {
+		return 0
+	}
+
+	if !k.timeLocked {
+		return k.confHeight + k.blocksToMaturity
+	}
+
+	return k.confHeight + (k.blocksToMaturity+targetBlockInterval-1)/
+		targetBlockInterval
+}
+
+// FeeBudget returns the maximum fee this output may contribute towards the
+// transaction that sweeps it, a zero value indicates no budget.
+func (k *kidOutput) FeeBudget() btcutil.Amount {
+	return k.feeBudget
+}
+
+// SweepPriority returns how urgently the output should be swept, which is
+// derived from its witness type. Outputs of a revoked commitment may be
+// claimed by the counterparty, while our own delayed commitment and anchor
+// outputs can only be claimed by us.
+func (k *kidOutput) SweepPriority() SweepPriority {
+	switch k.WitnessType() {
+	case lnwallet.CommitmentRevoke, lnwallet.HtlcOfferedRevoke,
+		lnwallet.HtlcAcceptedRevoke:
+
+		return SweepPriorityUrgent
+
+	case lnwallet.CommitmentTimeLock, lnwallet.CommitmentAnchor:
+		return SweepPriorityRelaxed
+
+	default:
+		return SweepPriorityNormal
+	}
+}
+
+const (
+	// kidOutputVersion0 is the version of kid outputs persisted before
+	// outputs were versioned. These records begin directly with the
+	// output's amount, whose most significant byte is always zero, as no
+	// output can exceed 2^56 satoshis. Fields added over time are
+	// appended, such that older records simply end early.
+	kidOutputVersion0 byte = 0
+
+	// kidOutputVersion1 is the first explicitly versioned encoding of kid
+	// outputs, prefixing the version 0 layout with a version byte. These
+	// records end after the output's sweep fee rate.
+	kidOutputVersion1 byte = 1
+
+	// kidOutputVersion2 appends the confirmation depth required for the
+	// commitment txn of the output's channel to the version 1 layout.
+	kidOutputVersion2 byte = 2
+
+	// kidOutputVersion3 appends the height at which the output entered the
+	// nursery to the version 2 layout.
+	kidOutputVersion3 byte = 3
+
+	// currentKidOutputVersion is the version with which kid outputs are
+	// written to the nursery store. Any change to the encoding must bump
+	// this version, and teach Decode to read the previous versions.
+	currentKidOutputVersion = kidOutputVersion3
+)
+
 // Encode converts a KidOutput struct into a form suitable for on-disk database
 // storage. Note that the signDescriptor struct field is included so that the
 // output's witness can be generated by createSweepTx() when the output becomes
-// spendable.
+// spendable. The output is prefixed with currentKidOutputVersion.
 func (k *kidOutput) Encode(w io.Writer) error {
 	var scratch [8]byte
+	scratch[0] = currentKidOutputVersion
+	if _, err := w.Write(scratch[:1]); err != nil {
+		return err
+	}
+
 	byteOrder.PutUint64(scratch[:], uint64(k.Amount()))
 	if _, err := w.Write(scratch[:]); err != nil {
 		return err
@@ -1571,18 +8502,116 @@ func (k *kidOutput) Encode(w io.Writer) error {
 		return err
 	}
 
-	return lnwallet.WriteSignDescriptor(w, k.SignDesc())
+	if err := lnwallet.WriteSignDescriptor(w, k.SignDesc()); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint64(scratch[:], uint64(k.FeeBudget()))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	scratch[0] = 0
+	if k.timeLocked {
+		scratch[0] = 1
+	}
+	if _, err := w.Write(scratch[:1]); err != nil {
+		return err
+	}
+
+	if err := wire.WriteVarBytes(w, 0, k.sweepPkScript); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint32(scratch[:4], k.sweepConfHeight)
+	if _, err := w.Write(scratch[:4]); err != nil {
+		return err
+	}
+
+	// A zero timestamp indicates that the incubation time is unknown.
+	var incubatedAt int64
+	if !k.incubatedAt.IsZero() {
+		incubatedAt = k.incubatedAt.Unix()
+	}
+	byteOrder.PutUint64(scratch[:], uint64(incubatedAt))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint64(scratch[:], uint64(k.sweepFeeRate))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint32(scratch[:4], k.confDepth)
+	if _, err := w.Write(scratch[:4]); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint32(scratch[:4], k.entryHeight)
+	_, err := w.Write(scratch[:4])
+	return err
+}
+
+// validate checks that a decoded kid output satisfies the invariants upheld by
+// the outputs written to the nursery store, such that a corrupted record can be
+// detected before it is acted upon.
+func (k *kidOutput) validate() error {
+	// A relative lock is limited to the sequence lock mask, which is
+	// converted to seconds for time based locks.
+	maxMaturity := uint32(wire.SequenceLockTimeMask)
+	if k.timeLocked {
+		maxMaturity <<= wire.SequenceLockTimeGranularity
+	}
+
+	switch {
+	case k.Amount() <= 0:
+		return fmt.Errorf("kid output %v has invalid amount %v",
+			k.OutPoint(), k.Amount())
+
+	case k.outpoint == (wire.OutPoint{}):
+		return fmt.Errorf("kid output has no outpoint")
+
+	case k.originChanPoint == (wire.OutPoint{}):
+		return fmt.Errorf("kid output %v has no origin channel point",
+			k.OutPoint())
+
+	case k.blocksToMaturity > maxMaturity:
+		return fmt.Errorf("kid output %v has invalid maturity of %d "+
+			"blocks", k.OutPoint(), k.blocksToMaturity)
+	}
+
+	return nil
 }
 
 // Decode takes a byte array representation of a kidOutput and converts it to an
 // struct. Note that the witnessFunc method isn't added during deserialization
-// and must be added later based on the value of the witnessType field.
+// and must be added later based on the value of the witnessType field. Both
+// versioned and unversioned records are accepted.
 func (k *kidOutput) Decode(r io.Reader) error {
 	var scratch [8]byte
 
-	if _, err := r.Read(scratch[:]); err != nil {
+	if _, err := io.ReadFull(r, scratch[:1]); err != nil {
 		return err
 	}
+
+	version := scratch[0]
+	switch version {
+	// Unversioned records have no version byte, so the byte read is the
+	// most significant byte of the amount, and we read the remainder.
+	case kidOutputVersion0:
+		if _, err := io.ReadFull(r, scratch[1:]); err != nil {
+			return err
+		}
+
+	case kidOutputVersion1, kidOutputVersion2, kidOutputVersion3:
+		if _, err := io.ReadFull(r, scratch[:]); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unknown kid output version %d", version)
+	}
 	k.amt = btcutil.Amount(byteOrder.Uint64(scratch[:]))
 
 	if err := readOutpoint(io.LimitReader(r, 40), &k.outpoint); err != nil {
@@ -1609,7 +8638,185 @@ func (k *kidOutput) Decode(r io.Reader) error {
 	}
 	k.witnessType = lnwallet.WitnessType(byteOrder.Uint16(scratch[:2]))
 
-	return lnwallet.ReadSignDescriptor(r, &k.signDesc)
+	if err := lnwallet.ReadSignDescriptor(r, &k.signDesc); err != nil {
+		return err
+	}
+
+	// Unversioned records may end after any of the fields appended before
+	// outputs were versioned, in which case the remaining fields are zero.
+	// Versioned records must contain every field of their version.
+	unversioned := version == kidOutputVersion0
+
+	// Outputs persisted before the introduction of fee budgets end after
+	// the sign descriptor, in which case the output has no budget.
+	if _, err := io.ReadFull(r, scratch[:]); err == io.EOF && unversioned {
+		k.feeBudget = 0
+		return nil
+	} else if err != nil {
+		return err
+	}
+	k.feeBudget = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	// Similarly, outputs persisted before the introduction of time based
+	// relative locks are always block based.
+	if _, err := io.ReadFull(r, scratch[:1]); err == io.EOF && unversioned {
+		k.timeLocked = false
+		return nil
+	} else if err != nil {
+		return err
+	}
+	k.timeLocked = scratch[0] == 1
+
+	// Finally, outputs persisted before the introduction of custom sweep
+	// destinations are always swept back into the wallet.
+	sweepPkScript, err := wire.ReadVarBytes(
+		r, 0, txscript.MaxScriptSize, "sweepPkScript",
+	)
+	if err == io.EOF && unversioned {
+		k.sweepPkScript = nil
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	k.sweepPkScript = nil
+	if len(sweepPkScript) > 0 {
+		k.sweepPkScript = sweepPkScript
+	}
+
+	// Outputs persisted before sweep confirmation heights were recorded
+	// have no known sweep confirmation height.
+	if _, err := io.ReadFull(r, scratch[:4]); err == io.EOF && unversioned {
+		k.sweepConfHeight = 0
+		return nil
+	} else if err != nil {
+		return err
+	}
+	k.sweepConfHeight = byteOrder.Uint32(scratch[:4])
+
+	// Likewise, outputs persisted before incubation times were recorded
+	// have no known incubation time.
+	if _, err := io.ReadFull(r, scratch[:]); err == io.EOF && unversioned {
+		k.incubatedAt = time.Time{}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	k.incubatedAt = time.Time{}
+	incubatedAt := int64(byteOrder.Uint64(scratch[:]))
+	if incubatedAt != 0 {
+		k.incubatedAt = time.Unix(incubatedAt, 0)
+	}
+
+	// Outputs persisted before sweep fee rates could be provided have no
+	// preferred fee rate.
+	if _, err := io.ReadFull(r, scratch[:]); err == io.EOF && unversioned {
+		k.sweepFeeRate = 0
+		return nil
+	} else if err != nil {
+		return err
+	}
+	k.sweepFeeRate = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	// Outputs persisted before version 2, which introduced per-channel
+	// confirmation depths, are subject to the nursery's CommitConfDepth.
+	k.confDepth = 0
+	k.entryHeight = 0
+	if version < kidOutputVersion2 {
+		return nil
+	}
+
+	if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+		return err
+	}
+	k.confDepth = byteOrder.Uint32(scratch[:4])
+
+	// Outputs persisted before version 3, which introduced entry heights,
+	// have no known entry height.
+	if version < kidOutputVersion3 {
+		return nil
+	}
+
+	if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+		return err
+	}
+	k.entryHeight = byteOrder.Uint32(scratch[:4])
+
+	return nil
+}
+
+// dropReason describes why an output of a force closed channel was abandoned by
+// the nursery rather than incubated.
+type dropReason uint8
+
+const (
+	// dropZeroValue indicates that the output carried no value, as we had
+	// no settled balance in the corresponding output of the commitment.
+	dropZeroValue dropReason = 0
+
+	// dropUneconomical indicates that the output would not have retained
+	// the EconomicalSweepThreshold after paying for its own sweep.
+	dropUneconomical dropReason = 1
+)
+
+// String returns a human readable description of the drop reason.
+func (r dropReason) String() string {
+	switch r {
+	case dropZeroValue:
+		return "zero value"
+	case dropUneconomical:
+		return "uneconomical"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(r))
+	}
+}
+
+// droppedOutput records an output of a force closed channel that the nursery
+// intentionally abandoned, such that the abandonment can later be audited.
+type droppedOutput struct {
+	outpoint wire.OutPoint
+	amt      btcutil.Amount
+	reason   dropReason
+}
+
+// Encode serializes the dropped output into the passed io.Writer.
+func (d *droppedOutput) Encode(w io.Writer) error {
+	var scratch [8]byte
+
+	if err := writeOutpoint(w, &d.outpoint); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint64(scratch[:], uint64(d.amt))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	scratch[0] = byte(d.reason)
+	_, err := w.Write(scratch[:1])
+	return err
+}
+
+// Decode deserializes a dropped output from the passed io.Reader.
+func (d *droppedOutput) Decode(r io.Reader) error {
+	var scratch [8]byte
+
+	if err := readOutpoint(r, &d.outpoint); err != nil {
+		return err
+	}
+
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return err
+	}
+	d.amt = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	if _, err := io.ReadFull(r, scratch[:1]); err != nil {
+		return err
+	}
+	d.reason = dropReason(scratch[0])
+
+	return nil
 }
 
 // TODO(bvu): copied from channeldb, remove repetition
@@ -4,11 +4,18 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"math"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/boltdb/bolt"
+	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/lnwallet/btcwallet"
 	"github.com/roasbeef/btcd/btcec"
 	"github.com/roasbeef/btcd/chaincfg/chainhash"
 	"github.com/roasbeef/btcd/txscript"
@@ -203,6 +210,7 @@ var (
 			originChanPoint:  outPoints[0],
 			blocksToMaturity: uint32(42),
 			confHeight:       uint32(1000),
+			feeBudget:        btcutil.Amount(1e5),
 		},
 
 		{
@@ -214,6 +222,12 @@ var (
 			originChanPoint:  outPoints[0],
 			blocksToMaturity: uint32(42),
 			confHeight:       uint32(1000),
+			sweepPkScript: []byte{
+				0x00, 0x14, 0x01, 0x02, 0x03, 0x04, 0x05,
+				0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c,
+				0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12, 0x13,
+				0x14,
+			},
 		},
 
 		{
@@ -379,3 +393,5810 @@ func TestBabyOutputSerialization(t *testing.T) {
 
 	}
 }
+
+// TestKidOutputDecodeWithoutFeeBudget asserts that kid outputs serialized
+// before the addition of fee budgets can still be decoded, and are treated as
+// having no fee budget.
+func TestKidOutputDecodeWithoutFeeBudget(t *testing.T) {
+	kid := kidOutputs[0]
+
+	var b bytes.Buffer
+	if err := kid.Encode(&b); err != nil {
+		t.Fatalf("unable to serialize kid output: %v", err)
+	}
+
+	// Strip the version byte, along with the trailing fee budget, time
+	// lock flag, empty sweep script, sweep confirmation height, incubation
+	// time, sweep fee rate, confirmation depth, and entry height to produce
+	// the legacy serialization.
+	legacyBytes := b.Bytes()[1 : b.Len()-38]
+
+	var deserializedKid kidOutput
+	err := deserializedKid.Decode(bytes.NewReader(legacyBytes))
+	if err != nil {
+		t.Fatalf("unable to deserialize legacy kid output: %v", err)
+	}
+
+	kid.feeBudget = 0
+	if !reflect.DeepEqual(kid, deserializedKid) {
+		t.Fatalf("unexpected kidOutput, want %+v, got %+v",
+			kid, deserializedKid)
+	}
+}
+
+// TestKidOutputConfDepth asserts that a per-channel confirmation depth is
+// persisted with a kid output, and takes precedence over CommitConfDepth when
+// determining the confirmations its commitment txn still requires.
+func TestKidOutputConfDepth(t *testing.T) {
+	kid := kidOutputs[0]
+	kid.confDepth = 6
+
+	var b bytes.Buffer
+	if err := kid.Encode(&b); err != nil {
+		t.Fatalf("unable to serialize kid output: %v", err)
+	}
+
+	var deserializedKid kidOutput
+	err := deserializedKid.Decode(bytes.NewReader(b.Bytes()))
+	if err != nil {
+		t.Fatalf("unable to deserialize kid output: %v", err)
+	}
+	if deserializedKid.ConfDepth() != 6 {
+		t.Fatalf("expected conf depth 6, got %d",
+			deserializedKid.ConfDepth())
+	}
+
+	// Records of versions prior to 2 end after the sweep fee rate, and are
+	// decoded without a confirmation depth.
+	v1Bytes := append([]byte(nil), b.Bytes()[:b.Len()-8]...)
+	v1Bytes[0] = kidOutputVersion1
+	v0Bytes := v1Bytes[1:]
+
+	for version, kidBytes := range [][]byte{v0Bytes, v1Bytes} {
+		deserializedKid = kidOutput{}
+		err := deserializedKid.Decode(bytes.NewReader(kidBytes))
+		if err != nil {
+			t.Fatalf("unable to deserialize version %d kid "+
+				"output: %v", version, err)
+		}
+		if deserializedKid.ConfDepth() != 0 {
+			t.Fatalf("expected no conf depth for version %d kid "+
+				"output, got %d", version,
+				deserializedKid.ConfDepth())
+		}
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		CommitConfDepth: 2,
+	})
+	nursery.bestHeight = kid.ConfHeight() + 1
+
+	// The commitment txn has two confirmations, which satisfies the
+	// CommitConfDepth, but not the output's own confirmation depth.
+	if remaining := nursery.commitConfsRemaining(&kid); remaining != 4 {
+		t.Fatalf("expected 4 confirmations remaining, got %d",
+			remaining)
+	}
+
+	kid.confDepth = 0
+	if remaining := nursery.commitConfsRemaining(&kid); remaining != 0 {
+		t.Fatalf("expected no confirmations remaining, got %d",
+			remaining)
+	}
+}
+
+// TestKidOutputEntryHeight asserts that the height at which a kid output
+// entered the nursery is persisted with it, and that outputs serialized before
+// entry heights were recorded are decoded with an unknown entry height.
+func TestKidOutputEntryHeight(t *testing.T) {
+	kid := kidOutputs[0]
+	kid.entryHeight = 100
+
+	var b bytes.Buffer
+	if err := kid.Encode(&b); err != nil {
+		t.Fatalf("unable to serialize kid output: %v", err)
+	}
+
+	var deserializedKid kidOutput
+	err := deserializedKid.Decode(bytes.NewReader(b.Bytes()))
+	if err != nil {
+		t.Fatalf("unable to deserialize kid output: %v", err)
+	}
+	if deserializedKid.EntryHeight() != 100 {
+		t.Fatalf("expected entry height 100, got %d",
+			deserializedKid.EntryHeight())
+	}
+
+	// Stripping the trailing entry height yields a version 2 record, which
+	// is decoded without an entry height.
+	legacyBytes := append([]byte(nil), b.Bytes()[:b.Len()-4]...)
+	legacyBytes[0] = kidOutputVersion2
+
+	deserializedKid = kidOutput{}
+	err = deserializedKid.Decode(bytes.NewReader(legacyBytes))
+	if err != nil {
+		t.Fatalf("unable to deserialize legacy kid output: %v", err)
+	}
+	if deserializedKid.EntryHeight() != 0 {
+		t.Fatalf("expected unknown entry height, got %d",
+			deserializedKid.EntryHeight())
+	}
+
+	// A version 3 record must carry the entry height.
+	legacyBytes[0] = kidOutputVersion3
+
+	deserializedKid = kidOutput{}
+	err = deserializedKid.Decode(bytes.NewReader(legacyBytes))
+	if err == nil {
+		t.Fatalf("expected version 3 kid output without entry " +
+			"height to be rejected")
+	}
+}
+
+// TestKidOutputVersion asserts that kid outputs are written with the current
+// version, that unversioned records can still be read, and that truncated
+// versioned records and records of an unknown version are rejected.
+func TestKidOutputVersion(t *testing.T) {
+	kid := kidOutputs[0]
+
+	var b bytes.Buffer
+	if err := kid.Encode(&b); err != nil {
+		t.Fatalf("unable to serialize kid output: %v", err)
+	}
+
+	kidBytes := b.Bytes()
+	if kidBytes[0] != currentKidOutputVersion {
+		t.Fatalf("expected version %d, got %d",
+			currentKidOutputVersion, kidBytes[0])
+	}
+
+	// Removing the version byte, along with the confirmation depth and
+	// entry height, yields an unversioned record, which should decode to
+	// the same output.
+	var legacyKid kidOutput
+	err := legacyKid.Decode(bytes.NewReader(kidBytes[1 : len(kidBytes)-8]))
+	if err != nil {
+		t.Fatalf("unable to deserialize unversioned kid output: %v",
+			err)
+	}
+	if !reflect.DeepEqual(kid, legacyKid) {
+		t.Fatalf("unexpected kidOutput, want %+v, got %+v",
+			kid, legacyKid)
+	}
+
+	// Only unversioned records may end early, a versioned record missing
+	// the fields following the sign descriptor must be rejected.
+	var truncatedKid kidOutput
+	err = truncatedKid.Decode(bytes.NewReader(kidBytes[:len(kidBytes)-38]))
+	if err == nil {
+		t.Fatalf("expected truncated kid output to be rejected")
+	}
+
+	unknownBytes := append([]byte(nil), kidBytes...)
+	unknownBytes[0] = currentKidOutputVersion + 1
+
+	var unknownKid kidOutput
+	err = unknownKid.Decode(bytes.NewReader(unknownBytes))
+	if err == nil {
+		t.Fatalf("expected kid output of unknown version to be " +
+			"rejected")
+	}
+}
+
+// TestOutputValidate asserts that decoded outputs violating the invariants of
+// the nursery store are rejected.
+func TestOutputValidate(t *testing.T) {
+	for i := range kidOutputs {
+		if err := kidOutputs[i].validate(); err != nil {
+			t.Fatalf("kid output %d failed validation: %v", i, err)
+		}
+	}
+	for i := range babyOutputs {
+		if err := babyOutputs[i].validate(); err != nil {
+			t.Fatalf("baby output %d failed validation: %v", i, err)
+		}
+	}
+
+	zeroAmount := kidOutputs[0]
+	zeroAmount.amt = 0
+
+	noOutpoint := kidOutputs[0]
+	noOutpoint.outpoint = wire.OutPoint{}
+
+	noChanPoint := kidOutputs[0]
+	noChanPoint.originChanPoint = wire.OutPoint{}
+
+	badMaturity := kidOutputs[0]
+	badMaturity.blocksToMaturity = wire.SequenceLockTimeMask + 1
+
+	for _, kid := range []kidOutput{
+		zeroAmount, noOutpoint, noChanPoint, badMaturity,
+	} {
+		if err := kid.validate(); err == nil {
+			t.Fatalf("expected invalid kid output %+v to fail "+
+				"validation", kid)
+		}
+	}
+
+	noExpiry := babyOutputs[0]
+	noExpiry.expiry = 0
+
+	noTimeoutTx := babyOutputs[0]
+	noTimeoutTx.timeoutTx = nil
+
+	for _, baby := range []babyOutput{noExpiry, noTimeoutTx} {
+		if err := baby.validate(); err == nil {
+			t.Fatalf("expected invalid baby output %+v to fail "+
+				"validation", baby)
+		}
+	}
+}
+
+// TestKidOutputIncubatedAt asserts that newly created kid outputs record the
+// time at which they were incubated, and that it survives serialization.
+func TestKidOutputIncubatedAt(t *testing.T) {
+	before := time.Now().Add(-time.Second)
+	kid := makeKidOutput(
+		&outPoints[1], &outPoints[0], 144,
+		lnwallet.CommitmentTimeLock, &signDescriptors[0],
+	)
+	if kid.IncubatedAt().Before(before) ||
+		kid.IncubatedAt().After(time.Now()) {
+
+		t.Fatalf("unexpected incubation time: %v", kid.IncubatedAt())
+	}
+
+	var b bytes.Buffer
+	if err := kid.Encode(&b); err != nil {
+		t.Fatalf("unable to serialize kid output: %v", err)
+	}
+
+	var deserializedKid kidOutput
+	if err := deserializedKid.Decode(&b); err != nil {
+		t.Fatalf("unable to deserialize kid output: %v", err)
+	}
+
+	if !deserializedKid.IncubatedAt().Equal(kid.IncubatedAt()) {
+		t.Fatalf("expected incubation time %v, got %v",
+			kid.IncubatedAt(), deserializedKid.IncubatedAt())
+	}
+}
+
+// TestComputeSweepFee asserts that sweep fees are computed correctly, and that
+// invalid fee rates, overflows, and fees consuming the entire input value are
+// rejected.
+func TestComputeSweepFee(t *testing.T) {
+	tests := []struct {
+		name         string
+		weight       uint64
+		feePerWeight btcutil.Amount
+		totalIn      btcutil.Amount
+		expFee       btcutil.Amount
+		expOut       btcutil.Amount
+		expErr       bool
+	}{
+		{
+			name:         "standard fee",
+			weight:       500,
+			feePerWeight: 10,
+			totalIn:      100000,
+			expFee:       5000,
+			expOut:       95000,
+		},
+		{
+			name:         "zero fee rate",
+			weight:       500,
+			feePerWeight: 0,
+			totalIn:      100000,
+			expFee:       0,
+			expOut:       100000,
+		},
+		{
+			name:         "zero weight",
+			weight:       0,
+			feePerWeight: 10,
+			totalIn:      100000,
+			expErr:       true,
+		},
+		{
+			name:         "negative fee rate",
+			weight:       500,
+			feePerWeight: -1,
+			totalIn:      100000,
+			expErr:       true,
+		},
+		{
+			name:         "fee overflow",
+			weight:       math.MaxUint64,
+			feePerWeight: 2,
+			totalIn:      100000,
+			expErr:       true,
+		},
+		{
+			name:         "fee equals input",
+			weight:       500,
+			feePerWeight: 200,
+			totalIn:      100000,
+			expErr:       true,
+		},
+		{
+			name:         "fee exceeds input",
+			weight:       500,
+			feePerWeight: 1000,
+			totalIn:      100000,
+			expErr:       true,
+		},
+	}
+
+	for _, test := range tests {
+		fee, out, err := computeSweepFee(
+			test.weight, test.feePerWeight, test.totalIn,
+		)
+		switch {
+		case test.expErr && err == nil:
+			t.Fatalf("%s: expected error, got fee=%v out=%v",
+				test.name, fee, out)
+		case !test.expErr && err != nil:
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		case test.expErr:
+			continue
+		}
+
+		if fee != test.expFee {
+			t.Fatalf("%s: expected fee %v, got %v", test.name,
+				test.expFee, fee)
+		}
+		if out != test.expOut {
+			t.Fatalf("%s: expected output %v, got %v", test.name,
+				test.expOut, out)
+		}
+	}
+}
+
+// TestKidOutputTimeLock asserts that kid outputs constructed with a time based
+// relative lock produce the appropriate sequence number, and that the lock
+// type survives serialization.
+func TestKidOutputTimeLock(t *testing.T) {
+	kidRef := kidOutputs[0]
+
+	// Construct a kid output whose relative lock is 10 units of 512
+	// seconds, encoded as in a txin sequence.
+	csvDelay := uint32(wire.SequenceLockTimeIsSeconds | 10)
+	kid := makeKidOutput(kidRef.OutPoint(), kidRef.OriginChanPoint(),
+		csvDelay, kidRef.WitnessType(), kidRef.SignDesc())
+
+	if !kid.IsTimeLocked() {
+		t.Fatalf("expected kid output to be time locked")
+	}
+	if kid.BlocksToMaturity() != 10*512 {
+		t.Fatalf("expected relative lock of %d seconds, got %d",
+			10*512, kid.BlocksToMaturity())
+	}
+	if kid.Sequence() != csvDelay {
+		t.Fatalf("expected sequence %x, got %x", csvDelay,
+			kid.Sequence())
+	}
+
+	// Block based outputs should use their relative lock as the sequence.
+	if kidRef.Sequence() != kidRef.BlocksToMaturity() {
+		t.Fatalf("expected sequence %d, got %d",
+			kidRef.BlocksToMaturity(), kidRef.Sequence())
+	}
+
+	var b bytes.Buffer
+	if err := kid.Encode(&b); err != nil {
+		t.Fatalf("unable to serialize kid output: %v", err)
+	}
+
+	var deserializedKid kidOutput
+	if err := deserializedKid.Decode(&b); err != nil {
+		t.Fatalf("unable to deserialize kid output: %v", err)
+	}
+
+	if !reflect.DeepEqual(kid, deserializedKid) {
+		t.Fatalf("unexpected kidOutput, want %+v, got %+v",
+			kid, deserializedKid)
+	}
+}
+
+// TestIsBatchableTimeoutTx asserts that only htlc timeout txns whose
+// signatures commit solely to their own input and output are considered
+// batchable.
+func TestIsBatchableTimeoutTx(t *testing.T) {
+	makeTimeoutTx := func(sigHash txscript.SigHashType) *wire.MsgTx {
+		tx := timeoutTx.Copy()
+		tx.TxIn[0].Witness = wire.TxWitness{
+			nil,
+			append([]byte{0x30, 0x01}, byte(sigHash)),
+			append([]byte{0x30, 0x02}, byte(sigHash)),
+			nil,
+			[]byte{0x00},
+		}
+		return tx
+	}
+
+	batchSigHash := txscript.SigHashSingle | txscript.SigHashAnyOneCanPay
+	if !isBatchableTimeoutTx(makeTimeoutTx(batchSigHash)) {
+		t.Fatalf("expected SIGHASH_SINGLE|ANYONECANPAY timeout txn " +
+			"to be batchable")
+	}
+
+	if isBatchableTimeoutTx(makeTimeoutTx(txscript.SigHashAll)) {
+		t.Fatalf("expected SIGHASH_ALL timeout txn to not be batchable")
+	}
+
+	multiOutputTx := makeTimeoutTx(batchSigHash)
+	multiOutputTx.AddTxOut(multiOutputTx.TxOut[0])
+	if isBatchableTimeoutTx(multiOutputTx) {
+		t.Fatalf("expected timeout txn with multiple outputs to not " +
+			"be batchable")
+	}
+}
+
+// TestNurseryBatchCribSweeps asserts that crib outputs whose timeout txns are
+// signed with SIGHASH_SINGLE|SIGHASH_ANYONECANPAY are broadcast in a single
+// batched txn, to which they're re-keyed, while others are broadcast
+// individually.
+func TestNurseryBatchCribSweeps(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	batchSigHash := txscript.SigHashSingle | txscript.SigHashAnyOneCanPay
+	makeBaby := func(i int, sigHash txscript.SigHashType) babyOutput {
+		tx := timeoutTx.Copy()
+		tx.TxIn[0].PreviousOutPoint.Index = uint32(i)
+		tx.TxIn[0].Witness = wire.TxWitness{
+			nil,
+			append([]byte{0x30, 0x01}, byte(sigHash)),
+			append([]byte{0x30, 0x02}, byte(sigHash)),
+			nil,
+			[]byte{0x00},
+		}
+		tx.TxOut[0].Value += int64(i)
+
+		baby := babyOutputs[2]
+		baby.outpoint = wire.OutPoint{Hash: tx.TxHash()}
+		baby.amt = btcutil.Amount(tx.TxOut[0].Value)
+		baby.timeoutTx = tx
+		return baby
+	}
+	babies := []babyOutput{
+		makeBaby(0, batchSigHash),
+		makeBaby(1, txscript.SigHashAll),
+		makeBaby(2, batchSigHash),
+	}
+	if err := ns.Incubate(nil, babies); err != nil {
+		t.Fatalf("unable to incubate crib outputs: %v", err)
+	}
+
+	var published []*wire.MsgTx
+	nursery := newUtxoNursery(&NurseryConfig{
+		BatchCribSweeps: true,
+		Notifier: &mockConfNotifier{
+			registrations: make(
+				chan *chainntnfs.ConfirmationEvent, 3,
+			),
+		},
+		PublishTransaction: func(tx *wire.MsgTx) error {
+			published = append(published, tx)
+			return nil
+		},
+		Store: ns,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	classHeight := babies[0].expiry
+	nursery.mu.Lock()
+	err = nursery.broadcastHeight(classHeight)
+	nursery.mu.Unlock()
+	if err != nil {
+		t.Fatalf("unable to broadcast height: %v", err)
+	}
+
+	// The batchable timeout txns should have been combined, pairing each
+	// input with its output.
+	var batchTx *wire.MsgTx
+	for _, tx := range published {
+		switch {
+		case len(tx.TxIn) == 2:
+			batchTx = tx
+
+		case tx.TxHash() != babies[1].timeoutTx.TxHash():
+			t.Fatalf("unexpected txn %v published", tx.TxHash())
+		}
+	}
+	if batchTx == nil {
+		t.Fatalf("batched timeout txn not published")
+	}
+	for _, i := range []int{0, 2} {
+		timeoutTx := babies[i].timeoutTx
+		txIn := timeoutTx.TxIn[0]
+
+		var found bool
+		for j := range batchTx.TxIn {
+			if batchTx.TxIn[j].PreviousOutPoint !=
+				txIn.PreviousOutPoint {
+
+				continue
+			}
+			if batchTx.TxOut[j].Value != timeoutTx.TxOut[0].Value {
+				t.Fatalf("input of timeout txn %d not paired "+
+					"with its output", i)
+			}
+			found = true
+		}
+		if !found {
+			t.Fatalf("timeout txn %d not batched", i)
+		}
+	}
+
+	// The batched crib outputs should now be stored under the outputs of
+	// the batched txn.
+	_, _, cribOutputs, err := ns.FetchClass(classHeight)
+	if err != nil {
+		t.Fatalf("unable to fetch class: %v", err)
+	}
+	if len(cribOutputs) != 3 {
+		t.Fatalf("expected 3 crib outputs, found %d", len(cribOutputs))
+	}
+
+	batchTxID := batchTx.TxHash()
+	var numBatched int
+	for _, baby := range cribOutputs {
+		if baby.OutPoint().Hash != batchTxID {
+			if *baby.OutPoint() != *babies[1].OutPoint() {
+				t.Fatalf("unexpected crib output %v",
+					baby.OutPoint())
+			}
+			continue
+		}
+		if err := validateTimeoutTx(&baby); err != nil {
+			t.Fatalf("invalid re-keyed crib output: %v", err)
+		}
+		numBatched++
+	}
+	if numBatched != 2 {
+		t.Fatalf("expected 2 re-keyed crib outputs, found %d",
+			numBatched)
+	}
+}
+
+// TestNurseryEventSubscription asserts that nursery events are delivered to
+// subscribers in order, and that subscriptions are closed upon cancellation or
+// when the nursery stops.
+func TestNurseryEventSubscription(t *testing.T) {
+	nursery := newUtxoNursery(&NurseryConfig{})
+
+	sub := nursery.SubscribeNurseryEvents()
+	cancelledSub := nursery.SubscribeNurseryEvents()
+	cancelledSub.Cancel()
+
+	nursery.mu.Lock()
+	for i := range kidOutputs {
+		nursery.notifyEvent(newOutputEvent(
+			NurseryEventPromoted, kndrPrefix, &kidOutputs[i],
+		))
+	}
+	nursery.mu.Unlock()
+
+	for i := range kidOutputs {
+		select {
+		case event := <-sub.Events:
+			if event.Type != NurseryEventPromoted {
+				t.Fatalf("expected event type %v, got %v",
+					NurseryEventPromoted, event.Type)
+			}
+			if event.OutPoint != *kidOutputs[i].OutPoint() {
+				t.Fatalf("expected event for output %v, got %v",
+					kidOutputs[i].OutPoint(), event.OutPoint)
+			}
+			if event.State != string(kndrPrefix) {
+				t.Fatalf("expected state %s, got %s",
+					kndrPrefix, event.State)
+			}
+
+		case <-time.After(5 * time.Second):
+			t.Fatalf("event #%d not received", i)
+		}
+	}
+
+	if _, ok := <-cancelledSub.Events; ok {
+		t.Fatalf("expected cancelled subscription to be closed")
+	}
+
+	if err := nursery.Stop(); err != nil {
+		t.Fatalf("unable to stop nursery: %v", err)
+	}
+
+	if _, ok := <-sub.Events; ok {
+		t.Fatalf("expected subscription to be closed after stop")
+	}
+}
+
+// TestNurseryBufferKinders asserts that small mature kindergarten outputs are
+// deferred to the next height holding kindergarten outputs, but only if doing
+// so does not exceed the configured buffering window.
+func TestNurseryBufferKinders(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	// Incubate two outputs, the first maturing at height 528, and the
+	// second maturing three blocks later at height 531.
+	kids := []kidOutput{kidOutputs[2], kidOutputs[3]}
+	kids[1].confHeight = kids[0].confHeight + 3
+	for i := range kids {
+		if err := ns.Incubate(&kids[i], nil); err != nil {
+			t.Fatalf("unable to incubate output: %v", err)
+		}
+		if err := ns.PreschoolToKinder(&kids[i]); err != nil {
+			t.Fatalf("unable to move pscl output to kndr: %v", err)
+		}
+	}
+
+	classHeight := kids[0].MaturityHeight()
+	nextHeight := kids[1].MaturityHeight()
+
+	tests := []struct {
+		name      string
+		window    uint32
+		maxValue  btcutil.Amount
+		expBuffer bool
+	}{
+		{
+			name: "buffering disabled",
+		},
+		{
+			name:   "next height outside window",
+			window: 2,
+		},
+		{
+			name:     "output too large",
+			window:   5,
+			maxValue: kids[0].Amount(),
+		},
+		{
+			name:      "buffered",
+			window:    5,
+			expBuffer: true,
+		},
+	}
+
+	for _, test := range tests {
+		nursery := newUtxoNursery(&NurseryConfig{
+			Store:               ns,
+			SweepBufferMaxValue: test.maxValue,
+			SweepBufferWindow:   test.window,
+		})
+
+		sweepNow, err := nursery.bufferKinders(
+			ns, classHeight, 0, []kidOutput{kids[0]},
+		)
+		if err != nil {
+			t.Fatalf("%s: unable to buffer outputs: %v", test.name,
+				err)
+		}
+
+		expSweepNow := 1
+		expNextOutputs := 1
+		if test.expBuffer {
+			expSweepNow = 0
+			expNextOutputs = 2
+		}
+
+		if len(sweepNow) != expSweepNow {
+			t.Fatalf("%s: expected %d outputs to be swept, got %d",
+				test.name, expSweepNow, len(sweepNow))
+		}
+
+		_, nextOutputs, _, err := ns.FetchClass(nextHeight)
+		if err != nil {
+			t.Fatalf("%s: unable to fetch class: %v", test.name, err)
+		}
+		if len(nextOutputs) != expNextOutputs {
+			t.Fatalf("%s: expected %d outputs at height %d, got %d",
+				test.name, expNextOutputs, nextHeight,
+				len(nextOutputs))
+		}
+	}
+}
+
+// TestEstimateRecoveryFee asserts that the estimated recovery fee reflects the
+// weight of the kindergarten outputs and the current fee rate.
+func TestEstimateRecoveryFee(t *testing.T) {
+	kids := []kidOutput{kidOutputs[0], kidOutputs[2]}
+
+	var weightEstimate lnwallet.TxWeightEstimator
+	weightEstimate.AddP2WKHOutput()
+	weightEstimate.AddWitnessInput(lnwallet.ToLocalTimeoutWitnessSize)
+	weightEstimate.AddWitnessInput(lnwallet.ToLocalTimeoutWitnessSize)
+	weight := btcutil.Amount(weightEstimate.Weight())
+
+	estimator := &lnwallet.StaticFeeEstimator{FeeRate: 40}
+	nursery := newUtxoNursery(&NurseryConfig{
+		Estimator: estimator,
+	})
+
+	fee, err := nursery.estimateRecoveryFee(kids)
+	if err != nil {
+		t.Fatalf("unable to estimate recovery fee: %v", err)
+	}
+	if fee != 10*weight {
+		t.Fatalf("expected recovery fee %v, got %v", 10*weight, fee)
+	}
+
+	// The estimate should track changes to the fee rate between calls.
+	estimator.FeeRate = 80
+
+	fee, err = nursery.estimateRecoveryFee(kids)
+	if err != nil {
+		t.Fatalf("unable to estimate recovery fee: %v", err)
+	}
+	if fee != 20*weight {
+		t.Fatalf("expected recovery fee %v, got %v", 20*weight, fee)
+	}
+}
+
+// TestEstimateSweepFee asserts that the estimated sweep fee is computed using
+// the current fee rate, clamped to the configured bounds, capped by the fee
+// budgets of the inputs, and raised to the minimum relay fee rate unless this
+// would exceed the fee budgets.
+func TestEstimateSweepFee(t *testing.T) {
+	const weight = 1000
+
+	estimator := &lnwallet.StaticFeeEstimator{FeeRate: 40}
+	nursery := newUtxoNursery(&NurseryConfig{
+		Estimator:  estimator,
+		MinFeeRate: 5,
+		MaxFeeRate: 20,
+	})
+
+	kid := kidOutputs[0]
+	tests := []struct {
+		feeRate         btcutil.Amount
+		maxFeePerWeight btcutil.Amount
+		minRelayFeeRate btcutil.Amount
+		expectedFee     btcutil.Amount
+		expectedErr     error
+	}{
+		// An estimate within the bounds is applied as is.
+		{feeRate: 40, expectedFee: 10 * weight},
+
+		// An estimate below the minimum is raised to MinFeeRate.
+		{feeRate: 4, expectedFee: 5 * weight},
+
+		// An estimate above the maximum is lowered to MaxFeeRate.
+		{feeRate: 400, expectedFee: 20 * weight},
+
+		// An estimate exceeding the fee budgets of the inputs is
+		// lowered to the rate they permit.
+		{feeRate: 40, maxFeePerWeight: 7, expectedFee: 7 * weight},
+
+		// The minimum relay fee rate overrides the configured bounds.
+		{
+			feeRate:         4,
+			minRelayFeeRate: 15,
+			expectedFee:     15 * weight,
+		},
+
+		// It's also applied if permitted by the fee budgets.
+		{
+			feeRate:         40,
+			maxFeePerWeight: 7,
+			minRelayFeeRate: 6,
+			expectedFee:     7 * weight,
+		},
+		{
+			feeRate:         20,
+			maxFeePerWeight: 7,
+			minRelayFeeRate: 6,
+			expectedFee:     6 * weight,
+		},
+
+		// However, the fee budgets are never exceeded to pay the
+		// minimum relay fee rate.
+		{
+			feeRate:         40,
+			maxFeePerWeight: 7,
+			minRelayFeeRate: 15,
+			expectedErr:     ErrFeeBudgetTooLow,
+		},
+	}
+
+	for i, test := range tests {
+		estimator.FeeRate = test.feeRate
+		nursery.cfg.MinRelayFeeRate = test.minRelayFeeRate
+
+		fee, err := nursery.estimateSweepFee(&sweepEstimate{
+			inputs:          []CsvSpendableOutput{&kid},
+			weight:          weight,
+			confTarget:      6,
+			maxFeePerWeight: test.maxFeePerWeight,
+		})
+		if err != test.expectedErr {
+			t.Fatalf("test #%d: expected error %v, got %v", i,
+				test.expectedErr, err)
+		}
+		if fee != test.expectedFee {
+			t.Fatalf("test #%d: expected fee %v, got %v", i,
+				test.expectedFee, fee)
+		}
+	}
+
+	// A fee consuming the entire value of the inputs can't be paid.
+	_, err := nursery.estimateSweepFee(&sweepEstimate{
+		inputs:     []CsvSpendableOutput{&kid},
+		weight:     uint64(kid.Amount()),
+		confTarget: 6,
+	})
+	if err != ErrDustSweep {
+		t.Fatalf("expected ErrDustSweep, got %v", err)
+	}
+}
+
+// TestSweepFeeBudgetBelowInputWeight asserts that a fee budget smaller than the
+// weight of its input, which would round down to a zero fee rate, isn't
+// mistaken for the absence of a budget, and that the output is deferred rather
+// than swept at a fee exceeding its budget.
+func TestSweepFeeBudgetBelowInputWeight(t *testing.T) {
+	nursery := newUtxoNursery(&NurseryConfig{
+		Estimator: &lnwallet.StaticFeeEstimator{FeeRate: 40},
+		GenSweepScript: func(lnwallet.AddressType) ([]byte, error) {
+			return bytes.Repeat([]byte{0x00}, 22), nil
+		},
+	})
+
+	kid := kidOutputs[0]
+	kid.feeBudget = 100
+
+	height := kid.ConfHeight() + kid.BlocksToMaturity()
+	_, err := nursery.estimateSweep(height, []kidOutput{kid})
+	if err != ErrFeeBudgetTooLow {
+		t.Fatalf("expected ErrFeeBudgetTooLow, got %v", err)
+	}
+
+	finalTxns, _, deferred, err := nursery.createSweepTxns(
+		height, []kidOutput{kid},
+	)
+	if err != nil {
+		t.Fatalf("unable to create sweep txns: %v", err)
+	}
+	if len(finalTxns) != 0 {
+		t.Fatalf("expected no sweep txns, got %d", len(finalTxns))
+	}
+	if len(deferred) != 1 || *deferred[0].OutPoint() != *kid.OutPoint() {
+		t.Fatalf("expected output %v to be deferred, got %v",
+			kid.OutPoint(), deferred)
+	}
+}
+
+// unsignedCsvOutput wraps a kid output, producing an empty witness such that
+// sweep txns can be crafted without a signer.
+type unsignedCsvOutput struct {
+	*kidOutput
+}
+
+func (o unsignedCsvOutput) BuildWitness(signer lnwallet.Signer,
+	txn *wire.MsgTx, hashCache *txscript.TxSigHashes,
+	txinIdx int) ([][]byte, error) {
+
+	return nil, nil
+}
+
+// TestSweepFeeRateHint asserts that a preferred sweep fee rate is used in place
+// of the live fee estimate, subject to the configured bounds.
+func TestSweepFeeRateHint(t *testing.T) {
+	// No estimator is configured, as it should not be queried when a fee
+	// rate hint is provided.
+	nursery := newUtxoNursery(&NurseryConfig{
+		MaxFeeRate: 50,
+	})
+
+	kid := kidOutputs[0]
+	kid.amt = btcutil.SatoshiPerBitcoin
+	inputs := []CsvSpendableOutput{unsignedCsvOutput{&kid}}
+	pkScript := bytes.Repeat([]byte{0x00}, 22)
+
+	tests := []struct {
+		feeRateHint     btcutil.Amount
+		expectedFeeRate btcutil.Amount
+	}{
+		// A hint within the bounds is used as is.
+		{feeRateHint: 30, expectedFeeRate: 30},
+
+		// A hint above the maximum is lowered to MaxFeeRate.
+		{feeRateHint: 80, expectedFeeRate: 50},
+	}
+
+	for i, test := range tests {
+		_, feeRate, err := nursery.sweepCsvSpendableOutputsTxn(
+			1000, defaultSweepConfTarget, test.feeRateHint, 0,
+			pkScript, inputs,
+		)
+		if err != nil {
+			t.Fatalf("test #%d: unable to create sweep tx: %v",
+				i, err)
+		}
+		if feeRate != test.expectedFeeRate {
+			t.Fatalf("test #%d: expected fee rate %v, got %v", i,
+				test.expectedFeeRate, feeRate)
+		}
+	}
+}
+
+// TestSweepMinRelayFeeRate asserts that the fee rate of a sweep txn is raised
+// to the configured minimum relay fee rate, even if it would exceed the
+// configured bounds on the fee rate, but that the sweep is refused if it would
+// exceed the fee budgets of its outputs.
+func TestSweepMinRelayFeeRate(t *testing.T) {
+	kid := kidOutputs[0]
+	kid.amt = btcutil.SatoshiPerBitcoin
+	inputs := []CsvSpendableOutput{unsignedCsvOutput{&kid}}
+	pkScript := bytes.Repeat([]byte{0x00}, 22)
+
+	// The estimates are expressed in satoshis per unit of weight, while
+	// the static fee estimator is configured in satoshis per vbyte.
+	tests := []struct {
+		estimate        btcutil.Amount
+		maxFeeRate      btcutil.Amount
+		maxFeePerWeight btcutil.Amount
+		expectedFeeRate btcutil.Amount
+		expectedErr     error
+	}{
+		// An estimate above the floor is used as is.
+		{estimate: 30, expectedFeeRate: 30},
+
+		// An estimate below the floor is raised to it.
+		{estimate: 2, expectedFeeRate: 5},
+
+		// The floor takes precedence over MaxFeeRate.
+		{estimate: 2, maxFeeRate: 3, expectedFeeRate: 5},
+
+		// The output fee budgets take precedence over the floor.
+		{
+			estimate:        30,
+			maxFeePerWeight: 4,
+			expectedErr:     ErrFeeBudgetTooLow,
+		},
+	}
+
+	for i, test := range tests {
+		nursery := newUtxoNursery(&NurseryConfig{
+			Estimator: &lnwallet.StaticFeeEstimator{
+				FeeRate: test.estimate * 4,
+			},
+			MaxFeeRate:      test.maxFeeRate,
+			MinRelayFeeRate: 5,
+		})
+
+		_, feeRate, err := nursery.sweepCsvSpendableOutputsTxn(
+			1000, defaultSweepConfTarget, 0, test.maxFeePerWeight,
+			pkScript, inputs,
+		)
+		if err != test.expectedErr {
+			t.Fatalf("test #%d: expected error %v, got %v", i,
+				test.expectedErr, err)
+		}
+		if feeRate != test.expectedFeeRate {
+			t.Fatalf("test #%d: expected fee rate %v, got %v", i,
+				test.expectedFeeRate, feeRate)
+		}
+	}
+}
+
+// TestCreatePartialSweepTx asserts that outputs whose witness can't be
+// generated are excluded from the sweep txn, while the remaining outputs are
+// still swept.
+func TestCreatePartialSweepTx(t *testing.T) {
+	nursery := newUtxoNursery(&NurseryConfig{
+		Estimator: &lnwallet.StaticFeeEstimator{FeeRate: 10},
+		GenSweepScript: func(lnwallet.AddressType) ([]byte, error) {
+			return bytes.Repeat([]byte{0x00}, 22), nil
+		},
+	})
+
+	goodWitness := func(*wire.MsgTx, *txscript.TxSigHashes,
+		int) ([][]byte, error) {
+
+		return nil, nil
+	}
+	badWitness := func(*wire.MsgTx, *txscript.TxSigHashes,
+		int) ([][]byte, error) {
+
+		return nil, fmt.Errorf("invalid sign descriptor")
+	}
+
+	bad := kidOutputs[0]
+	bad.witnessFunc = badWitness
+	good := kidOutputs[3]
+	good.witnessFunc = goodWitness
+
+	height := good.MaturityHeight()
+	sweepTx, _, excluded, err := nursery.createPartialSweepTx(
+		height, []kidOutput{bad, good},
+	)
+	if err != nil {
+		t.Fatalf("unable to create sweep tx: %v", err)
+	}
+	if sweepTx == nil || len(sweepTx.TxIn) != 1 ||
+		sweepTx.TxIn[0].PreviousOutPoint != *good.OutPoint() {
+
+		t.Fatalf("expected sweep of only output %v, got %v",
+			good.OutPoint(), sweepTx)
+	}
+	if len(excluded) != 1 || *excluded[0].OutPoint() != *bad.OutPoint() {
+		t.Fatalf("expected output %v to be excluded, got %v",
+			bad.OutPoint(), excluded)
+	}
+
+	// If none of the witnesses can be generated, no txn is crafted and all
+	// outputs are excluded.
+	good.witnessFunc = badWitness
+	sweepTx, _, excluded, err = nursery.createPartialSweepTx(
+		height, []kidOutput{bad, good},
+	)
+	if err != nil {
+		t.Fatalf("unable to create sweep tx: %v", err)
+	}
+	if sweepTx != nil {
+		t.Fatalf("expected no sweep tx, got %v", sweepTx)
+	}
+	if len(excluded) != 2 {
+		t.Fatalf("expected 2 excluded outputs, got %d", len(excluded))
+	}
+}
+
+// confTargetFeeEstimator is a FeeEstimator that returns a distinct fee rate
+// for each confirmation target.
+type confTargetFeeEstimator struct {
+	feeRates map[uint32]btcutil.Amount
+}
+
+func (e *confTargetFeeEstimator) EstimateFeePerByte(
+	numBlocks uint32) (btcutil.Amount, error) {
+
+	return e.feeRates[numBlocks] * 4, nil
+}
+
+func (e *confTargetFeeEstimator) EstimateFeePerWeight(
+	numBlocks uint32) (btcutil.Amount, error) {
+
+	feeRate, ok := e.feeRates[numBlocks]
+	if !ok {
+		return 0, fmt.Errorf("no fee rate for target %d", numBlocks)
+	}
+
+	return feeRate, nil
+}
+
+func (e *confTargetFeeEstimator) Start() error {
+	return nil
+}
+
+func (e *confTargetFeeEstimator) Stop() error {
+	return nil
+}
+
+// TestSweepPriorityTiers asserts that kindergarten outputs of different
+// priorities are swept in separate txns, whose fee rates are estimated using
+// the confirmation target of their tier.
+func TestSweepPriorityTiers(t *testing.T) {
+	nursery := newUtxoNursery(&NurseryConfig{
+		Estimator: &confTargetFeeEstimator{
+			feeRates: map[uint32]btcutil.Amount{
+				urgentSweepConfTarget:  100,
+				defaultSweepConfTarget: 50,
+				relaxedSweepConfTarget: 10,
+			},
+		},
+		GenSweepScript: func(lnwallet.AddressType) ([]byte, error) {
+			return bytes.Repeat([]byte{0x00}, 22), nil
+		},
+	})
+
+	noWitness := func(*wire.MsgTx, *txscript.TxSigHashes,
+		int) ([][]byte, error) {
+
+		return nil, nil
+	}
+
+	relaxed := kidOutputs[0]
+	relaxed.witnessFunc = noWitness
+	urgent := kidOutputs[1]
+	urgent.witnessType = lnwallet.CommitmentRevoke
+	urgent.witnessFunc = noWitness
+	normal := kidOutputs[2]
+	normal.witnessType = lnwallet.HtlcOfferedTimeout
+	normal.witnessFunc = noWitness
+
+	tests := []struct {
+		kid             kidOutput
+		expPriority     SweepPriority
+		expectedFeeRate btcutil.Amount
+	}{
+		{relaxed, SweepPriorityRelaxed, 10},
+		{urgent, SweepPriorityUrgent, 100},
+		{normal, SweepPriorityNormal, 50},
+	}
+
+	kgtnOutputs := make([]kidOutput, 0, len(tests))
+	for _, test := range tests {
+		if test.kid.SweepPriority() != test.expPriority {
+			t.Fatalf("expected output %v to have priority %v, "+
+				"got %v", test.kid.OutPoint(), test.expPriority,
+				test.kid.SweepPriority())
+		}
+		kgtnOutputs = append(kgtnOutputs, test.kid)
+	}
+
+	finalTxns, feeRates, deferred, err := nursery.createSweepTxns(
+		2000, kgtnOutputs,
+	)
+	if err != nil {
+		t.Fatalf("unable to create sweep txns: %v", err)
+	}
+	if len(deferred) != 0 {
+		t.Fatalf("expected no deferred outputs, got %d", len(deferred))
+	}
+	if len(finalTxns) != len(tests) {
+		t.Fatalf("expected %d sweep txns, got %d", len(tests),
+			len(finalTxns))
+	}
+
+	for i, test := range tests {
+		txIns := finalTxns[i].TxIn
+		if len(txIns) != 1 ||
+			txIns[0].PreviousOutPoint != *test.kid.OutPoint() {
+
+			t.Fatalf("test #%d: expected sweep of only output %v, "+
+				"got %v", i, test.kid.OutPoint(), txIns)
+		}
+		if feeRates[i] != test.expectedFeeRate {
+			t.Fatalf("test #%d: expected fee rate %v, got %v", i,
+				test.expectedFeeRate, feeRates[i])
+		}
+	}
+}
+
+// TestSweepPriorityConfTarget asserts that the configured SweepConfTarget is
+// used for normal sweeps, while urgent sweeps always target the next block and
+// relaxed sweeps never target a sooner block than normal sweeps.
+func TestSweepPriorityConfTarget(t *testing.T) {
+	nursery := newUtxoNursery(&NurseryConfig{})
+	if nursery.cfg.SweepConfTarget != defaultSweepConfTarget {
+		t.Fatalf("expected default sweep conf target %d, got %d",
+			defaultSweepConfTarget, nursery.cfg.SweepConfTarget)
+	}
+
+	tests := []struct {
+		priority     SweepPriority
+		normalTarget uint32
+		expTarget    uint32
+	}{
+		{SweepPriorityNormal, 12, 12},
+		{SweepPriorityUrgent, 12, urgentSweepConfTarget},
+		{SweepPriorityRelaxed, 12, relaxedSweepConfTarget},
+		{SweepPriorityUrgent, 144, urgentSweepConfTarget},
+		{SweepPriorityRelaxed, 144, 144},
+	}
+
+	for i, test := range tests {
+		target := test.priority.ConfTarget(test.normalTarget)
+		if target != test.expTarget {
+			t.Fatalf("test #%d: expected %v conf target %d, got %d",
+				i, test.priority, test.expTarget, target)
+		}
+	}
+}
+
+// TestSweepConfTargetDeadline asserts that the confirmation target of htlc
+// outputs is escalated towards the next block as their deadline approaches,
+// while other outputs retain the target of their priority tier.
+func TestSweepConfTargetDeadline(t *testing.T) {
+	nursery := newUtxoNursery(&NurseryConfig{
+		HtlcSweepDeadline: 20,
+	})
+
+	htlc := kidOutputs[2]
+	htlc.witnessType = lnwallet.HtlcOfferedTimeout
+	other := kidOutputs[3]
+	other.witnessType = lnwallet.CommitmentNoDelay
+
+	maturity := htlc.MaturityHeight()
+
+	tests := []struct {
+		kid       *kidOutput
+		height    uint32
+		expTarget uint32
+	}{
+		{&htlc, maturity, defaultSweepConfTarget},
+		{&htlc, maturity + 12, 4},
+		{&htlc, maturity + 18, 1},
+		{&htlc, maturity + 19, urgentSweepConfTarget},
+		{&htlc, maturity + 25, urgentSweepConfTarget},
+		{&other, maturity + 19, defaultSweepConfTarget},
+	}
+
+	for i, test := range tests {
+		target := nursery.sweepConfTarget(test.kid, test.height)
+		if target != test.expTarget {
+			t.Fatalf("test #%d: expected conf target %d, got %d",
+				i, test.expTarget, target)
+		}
+	}
+
+	// Without a deadline, htlc outputs are never escalated.
+	nursery.cfg.HtlcSweepDeadline = 0
+	target := nursery.sweepConfTarget(&htlc, maturity+25)
+	if target != defaultSweepConfTarget {
+		t.Fatalf("expected conf target %d, got %d",
+			defaultSweepConfTarget, target)
+	}
+}
+
+// panickingFeeEstimator is a fee estimator that panics upon estimation.
+type panickingFeeEstimator struct {
+	confTargetFeeEstimator
+}
+
+func (e *panickingFeeEstimator) EstimateFeePerWeight(
+	numBlocks uint32) (btcutil.Amount, error) {
+
+	panic("fee backend unavailable")
+}
+
+// TestSweepFeeRateFallback asserts that failed, panicking, and absurd fee
+// estimates are reported to OnFeeEstimationError, and replaced by the
+// FallbackFeeRate if one is configured.
+func TestSweepFeeRateFallback(t *testing.T) {
+	var numErrors int
+	nursery := newUtxoNursery(&NurseryConfig{
+		Estimator: &confTargetFeeEstimator{
+			feeRates: map[uint32]btcutil.Amount{
+				2: 0,
+				3: absurdFeePerWeight + 1,
+				6: 25,
+			},
+		},
+		OnFeeEstimationError: func(uint32, error) {
+			numErrors++
+		},
+	})
+
+	// A usable estimate is returned as is.
+	_, feeRate, err := nursery.sweepFeeRate(6)
+	if err != nil {
+		t.Fatalf("unable to estimate fee rate: %v", err)
+	}
+	if feeRate != 25 || numErrors != 0 {
+		t.Fatalf("expected fee rate 25 with no errors, got %v with "+
+			"%d errors", feeRate, numErrors)
+	}
+
+	// Without a fallback, a missing, zero, or absurd estimate results in
+	// an error.
+	for _, confTarget := range []uint32{1, 2, 3} {
+		if _, _, err := nursery.sweepFeeRate(confTarget); err == nil {
+			t.Fatalf("expected error for conf target %d",
+				confTarget)
+		}
+	}
+	if numErrors != 3 {
+		t.Fatalf("expected 3 estimation errors, got %d", numErrors)
+	}
+
+	// With a fallback, the same estimates, as well as a panicking
+	// estimator, yield the fallback fee rate.
+	nursery.cfg.FallbackFeeRate = 10
+	for _, confTarget := range []uint32{1, 2, 3} {
+		_, feeRate, err := nursery.sweepFeeRate(confTarget)
+		if err != nil {
+			t.Fatalf("unable to estimate fee rate: %v", err)
+		}
+		if feeRate != 10 {
+			t.Fatalf("expected fallback fee rate 10, got %v",
+				feeRate)
+		}
+	}
+
+	nursery.cfg.Estimator = &panickingFeeEstimator{}
+	_, feeRate, err = nursery.sweepFeeRate(6)
+	if err != nil {
+		t.Fatalf("unable to estimate fee rate: %v", err)
+	}
+	if feeRate != 10 || numErrors != 7 {
+		t.Fatalf("expected fallback fee rate 10 with 7 errors, got %v "+
+			"with %d errors", feeRate, numErrors)
+	}
+}
+
+// TestDeriveSweepScript asserts that the configured DeriveSweepScript takes
+// precedence over GenSweepScript, and that it's seeded by the first output
+// being swept, while explicit sweep scripts are still respected.
+func TestDeriveSweepScript(t *testing.T) {
+	nursery := newUtxoNursery(&NurseryConfig{
+		GenSweepScript: func(lnwallet.AddressType) ([]byte, error) {
+			return nil, fmt.Errorf("sweep script should be derived")
+		},
+		DeriveSweepScript: func(_ lnwallet.AddressType,
+			seed *wire.OutPoint) ([]byte, error) {
+
+			return append([]byte{0x00, 0x20}, seed.Hash[:]...), nil
+		},
+	})
+
+	kgtnOutputs := []kidOutput{kidOutputs[0], kidOutputs[3]}
+	pkScript, err := nursery.sweepScript(kgtnOutputs)
+	if err != nil {
+		t.Fatalf("unable to derive sweep script: %v", err)
+	}
+
+	expScript := append([]byte{0x00, 0x20}, outPoints[1].Hash[:]...)
+	if !bytes.Equal(pkScript, expScript) {
+		t.Fatalf("expected sweep script %x, got %x", expScript,
+			pkScript)
+	}
+
+	// Deriving the script again should produce the same result.
+	pkScript, err = nursery.sweepScript(kgtnOutputs)
+	if err != nil {
+		t.Fatalf("unable to derive sweep script: %v", err)
+	}
+	if !bytes.Equal(pkScript, expScript) {
+		t.Fatalf("expected sweep script %x, got %x", expScript,
+			pkScript)
+	}
+
+	// An output with its own sweep script should be swept to it instead.
+	pkScript, err = nursery.sweepScript(kidOutputs[1:2])
+	if err != nil {
+		t.Fatalf("unable to determine sweep script: %v", err)
+	}
+	if !bytes.Equal(pkScript, kidOutputs[1].sweepPkScript) {
+		t.Fatalf("expected sweep script %x, got %x",
+			kidOutputs[1].sweepPkScript, pkScript)
+	}
+}
+
+// TestSweepAddressTypeValidation asserts that the nursery refuses to start with
+// a sweep address type the wallet can't generate, unless the sweep scripts are
+// derived by DeriveSweepScript.
+func TestSweepAddressTypeValidation(t *testing.T) {
+	deriveSweepScript := func(lnwallet.AddressType,
+		*wire.OutPoint) ([]byte, error) {
+
+		return nil, nil
+	}
+
+	tests := []struct {
+		addrType          lnwallet.AddressType
+		deriveSweepScript func(lnwallet.AddressType,
+			*wire.OutPoint) ([]byte, error)
+		valid bool
+	}{
+		{
+			addrType: lnwallet.UnknownAddressType,
+			valid:    true,
+		},
+		{
+			addrType: lnwallet.NestedWitnessPubKey,
+			valid:    true,
+		},
+		{
+			addrType: lnwallet.TaprootPubKey,
+			valid:    false,
+		},
+		{
+			addrType:          lnwallet.TaprootPubKey,
+			deriveSweepScript: deriveSweepScript,
+			valid:             true,
+		},
+		{
+			addrType: lnwallet.TaprootPubKey + 1,
+			valid:    false,
+		},
+	}
+
+	for i, test := range tests {
+		nursery := newUtxoNursery(&NurseryConfig{
+			DeriveSweepScript: test.deriveSweepScript,
+			SweepAddressType:  test.addrType,
+		})
+
+		err := nursery.cfg.validate()
+		if test.valid && err != nil {
+			t.Fatalf("test #%d: expected valid config, got: %v",
+				i, err)
+		}
+		if !test.valid && err == nil {
+			t.Fatalf("test #%d: expected invalid config", i)
+		}
+	}
+}
+
+// TestSweepOutputWeight asserts that the weight of an output sweeping into the
+// wallet is estimated from the configured address type, while that of a sweep
+// script provided upon incubation is estimated from the script itself.
+func TestSweepOutputWeight(t *testing.T) {
+	tests := []struct {
+		addrType  lnwallet.AddressType
+		pkScript  []byte
+		addOutput func(*lnwallet.TxWeightEstimator)
+	}{
+		{
+			addrType: lnwallet.WitnessPubKey,
+			addOutput: (*lnwallet.TxWeightEstimator).
+				AddP2WKHOutput,
+		},
+		{
+			addrType: lnwallet.NestedWitnessPubKey,
+			addOutput: (*lnwallet.TxWeightEstimator).
+				AddP2SHOutput,
+		},
+		{
+			addrType: lnwallet.PubKeyHash,
+			addOutput: (*lnwallet.TxWeightEstimator).
+				AddP2PKHOutput,
+		},
+		{
+			addrType: lnwallet.TaprootPubKey,
+			addOutput: (*lnwallet.TxWeightEstimator).
+				AddP2TROutput,
+		},
+		{
+			addrType: lnwallet.TaprootPubKey,
+			pkScript: make([]byte, lnwallet.P2WSHSize),
+			addOutput: (*lnwallet.TxWeightEstimator).
+				AddP2WSHOutput,
+		},
+	}
+
+	for i, test := range tests {
+		nursery := newUtxoNursery(&NurseryConfig{
+			SweepAddressType: test.addrType,
+		})
+
+		kid := kidOutputs[0]
+		kid.sweepPkScript = test.pkScript
+
+		var weightEstimate, expWeightEstimate lnwallet.TxWeightEstimator
+		nursery.addSweepOutputWeight(
+			&weightEstimate, []kidOutput{kid},
+		)
+		test.addOutput(&expWeightEstimate)
+
+		if weightEstimate.Weight() != expWeightEstimate.Weight() {
+			t.Fatalf("test #%d: expected weight %d, got %d", i,
+				expWeightEstimate.Weight(),
+				weightEstimate.Weight())
+		}
+	}
+}
+
+// TestSpentKinders asserts that only the kindergarten outputs spent by a sweep
+// txn are reported to the OnSweepBroadcast hook.
+func TestSpentKinders(t *testing.T) {
+	sweepTx := wire.NewMsgTx(2)
+	sweepTx.AddTxIn(&wire.TxIn{PreviousOutPoint: outPoints[3]})
+	sweepTx.AddTxIn(&wire.TxIn{PreviousOutPoint: outPoints[1]})
+
+	kgtnOutputs := []kidOutput{kidOutputs[0], kidOutputs[1], kidOutputs[2]}
+	outputs := spentKinders(sweepTx, kgtnOutputs)
+
+	expected := []wire.OutPoint{outPoints[1], outPoints[3]}
+	if len(outputs) != len(expected) {
+		t.Fatalf("expected %d spent outputs, got %d", len(expected),
+			len(outputs))
+	}
+	for i, output := range outputs {
+		if *output.OutPoint() != expected[i] {
+			t.Fatalf("expected spent output %v, got %v",
+				expected[i], output.OutPoint())
+		}
+		if *output.OriginChanPoint() != outPoints[0] {
+			t.Fatalf("expected origin chan point %v, got %v",
+				outPoints[0], output.OriginChanPoint())
+		}
+	}
+}
+
+// TestNurserySweepConfirmedEvent asserts that the confirmation of a sweep txn
+// emits an event detailing the total value it swept from limbo, the fee it
+// paid, and the net value it returned to the wallet.
+func TestNurserySweepConfirmedEvent(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid1, kid2 := kidOutputs[0], kidOutputs[1]
+	classHeight := kid1.MaturityHeight()
+	for _, kid := range []*kidOutput{&kid1, &kid2} {
+		if err := ns.Incubate(kid, nil); err != nil {
+			t.Fatalf("unable to incubate output: %v", err)
+		}
+		if err := ns.PreschoolToKinder(kid); err != nil {
+			t.Fatalf("unable to move pscl output to kndr: %v", err)
+		}
+	}
+
+	const fee = btcutil.Amount(1000)
+	totalIn := kid1.Amount() + kid2.Amount()
+
+	sweepTx := wire.NewMsgTx(2)
+	sweepTx.AddTxIn(&wire.TxIn{PreviousOutPoint: *kid1.OutPoint()})
+	sweepTx.AddTxIn(&wire.TxIn{PreviousOutPoint: *kid2.OutPoint()})
+	sweepTx.AddTxOut(&wire.TxOut{Value: int64(totalIn - fee)})
+
+	err = ns.FinalizeKinder(classHeight, []*wire.MsgTx{sweepTx}, nil)
+	if err != nil {
+		t.Fatalf("unable to finalize kndr: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		DB:    cdb,
+		Store: ns,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	sub := nursery.SubscribeNurseryEvents()
+	defer sub.Cancel()
+
+	kgtnOutputs := []kidOutput{kid1, kid2}
+	if !nursery.graduateKinders(classHeight, sweepTx, kgtnOutputs,
+		classHeight+1) {
+
+		t.Fatalf("unable to graduate kindergarten outputs")
+	}
+
+	for {
+		select {
+		case event := <-sub.Events:
+			if event.Type != NurseryEventSweepConfirmed {
+				continue
+			}
+
+			if event.SweepTxid != sweepTx.TxHash() {
+				t.Fatalf("expected sweep txid %v, got %v",
+					sweepTx.TxHash(), event.SweepTxid)
+			}
+			if event.Amount != totalIn || event.Fee != fee ||
+				event.NetSwept != totalIn-fee {
+
+				t.Fatalf("expected total=%v, fee=%v, net "+
+					"swept=%v, got total=%v, fee=%v, net "+
+					"swept=%v", totalIn, fee, totalIn-fee,
+					event.Amount, event.Fee, event.NetSwept)
+			}
+			return
+
+		case <-time.After(5 * time.Second):
+			t.Fatalf("sweep confirmed event not received")
+		}
+	}
+}
+
+// TestNurseryGraduateKindersAlreadyGraduated asserts that graduating the
+// outputs of a sweep txn whose outputs have already graduated neither reports
+// a graduation nor emits any events.
+func TestNurseryGraduateKindersAlreadyGraduated(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[0]
+	classHeight := kid.MaturityHeight()
+	if err := ns.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate output: %v", err)
+	}
+	if err := ns.PreschoolToKinder(&kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	sweepTx := wire.NewMsgTx(2)
+	sweepTx.AddTxIn(&wire.TxIn{PreviousOutPoint: *kid.OutPoint()})
+	sweepTx.AddTxOut(&wire.TxOut{Value: int64(kid.Amount() - 1000)})
+
+	err = ns.FinalizeKinder(classHeight, []*wire.MsgTx{sweepTx}, nil)
+	if err != nil {
+		t.Fatalf("unable to finalize kndr: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		DB:    cdb,
+		Store: ns,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	kgtnOutputs := []kidOutput{kid}
+	if !nursery.graduateKinders(classHeight, sweepTx, kgtnOutputs,
+		classHeight+1) {
+
+		t.Fatalf("unable to graduate kindergarten outputs")
+	}
+
+	sub := nursery.SubscribeNurseryEvents()
+	defer sub.Cancel()
+
+	if nursery.graduateKinders(classHeight, sweepTx, kgtnOutputs,
+		classHeight+1) {
+
+		t.Fatalf("expected already graduated outputs to be skipped")
+	}
+
+	select {
+	case event := <-sub.Events:
+		t.Fatalf("unexpected event for graduated outputs: %v",
+			event.Type)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestNurserySweepMatureOutputsOnce asserts that sweeping a channel's mature
+// outputs more than once before their sweep confirms only watches for the
+// sweep's confirmation once.
+func TestNurserySweepMatureOutputsOnce(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[0]
+	classHeight := kid.MaturityHeight()
+	if err := ns.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate output: %v", err)
+	}
+	if err := ns.PreschoolToKinder(&kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	sweepTx := wire.NewMsgTx(2)
+	sweepTx.AddTxIn(&wire.TxIn{PreviousOutPoint: *kid.OutPoint()})
+	sweepTx.AddTxOut(&wire.TxOut{Value: int64(kid.Amount() - 1000)})
+
+	err = ns.FinalizeKinder(classHeight, []*wire.MsgTx{sweepTx}, nil)
+	if err != nil {
+		t.Fatalf("unable to finalize kndr: %v", err)
+	}
+
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 2),
+	}
+	var numPublished int
+	nursery := newUtxoNursery(&NurseryConfig{
+		ChainIO:  &mockChainIO{bestHeight: int32(classHeight)},
+		DB:       cdb,
+		Notifier: notifier,
+		PublishTransaction: func(*wire.MsgTx) error {
+			numPublished++
+			return nil
+		},
+		Store: ns,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	chanPoint := kid.OriginChanPoint()
+	for i := 0; i < 2; i++ {
+		if err := nursery.SweepMatureOutputs(chanPoint); err != nil {
+			t.Fatalf("unable to sweep mature outputs: %v", err)
+		}
+	}
+
+	if numPublished != 2 {
+		t.Fatalf("expected sweep to be broadcast twice, got %d",
+			numPublished)
+	}
+
+	select {
+	case <-notifier.registrations:
+	case <-time.After(time.Second):
+		t.Fatalf("sweep confirmation not registered")
+	}
+	select {
+	case <-notifier.registrations:
+		t.Fatalf("sweep confirmation registered twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestWaitBroadcastJitter asserts that the delay between broadcasts is bounded
+// by BroadcastJitter, and is interrupted if the nursery shuts down.
+func TestWaitBroadcastJitter(t *testing.T) {
+	nursery := newUtxoNursery(&NurseryConfig{
+		BroadcastJitter: 10 * time.Millisecond,
+	})
+
+	start := time.Now()
+	if err := nursery.waitBroadcastJitter(); err != nil {
+		t.Fatalf("unable to wait for jitter: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("jitter of %v exceeded maximum", elapsed)
+	}
+
+	// Once the nursery shuts down, the wait should be abandoned.
+	nursery.cfg.BroadcastJitter = time.Hour
+	close(nursery.quit)
+
+	if err := nursery.waitBroadcastJitter(); err != ErrNurseryShuttingDown {
+		t.Fatalf("expected ErrNurseryShuttingDown, got %v", err)
+	}
+}
+
+// TestNurseryBroadcastJitterDelayed asserts that consecutive broadcasts at a
+// height are spaced out by a goroutine that doesn't require the nursery's
+// mutex, while the delayed txns remain queued for republication.
+func TestNurseryBroadcastJitterDelayed(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	var babies []babyOutput
+	for i := 0; i < 2; i++ {
+		tx := timeoutTx.Copy()
+		tx.TxIn[0].PreviousOutPoint.Index = uint32(i)
+
+		baby := babyOutputs[2]
+		baby.outpoint = wire.OutPoint{Hash: tx.TxHash()}
+		baby.amt = btcutil.Amount(tx.TxOut[0].Value)
+		baby.timeoutTx = tx
+		babies = append(babies, baby)
+	}
+	if err := ns.Incubate(nil, babies); err != nil {
+		t.Fatalf("unable to incubate crib outputs: %v", err)
+	}
+
+	published := make(chan *wire.MsgTx, 2)
+	nursery := newUtxoNursery(&NurseryConfig{
+		BroadcastJitter: time.Nanosecond,
+		Notifier: &mockConfNotifier{
+			registrations: make(
+				chan *chainntnfs.ConfirmationEvent, 2,
+			),
+		},
+		PublishTransaction: func(tx *wire.MsgTx) error {
+			published <- tx
+			return nil
+		},
+		Store: ns,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	// The mutex is held throughout, as the delayed broadcast must not
+	// depend on it.
+	nursery.mu.Lock()
+	defer nursery.mu.Unlock()
+
+	classHeight := babies[0].expiry
+	if err := nursery.broadcastHeight(classHeight); err != nil {
+		t.Fatalf("unable to broadcast height: %v", err)
+	}
+
+	// Only the first timeout txn should have been broadcast immediately,
+	// while the other is queued for republication.
+	var first *wire.MsgTx
+	select {
+	case first = <-published:
+	default:
+		t.Fatalf("first timeout txn not published")
+	}
+
+	second := babies[0].timeoutTx
+	if second.TxHash() == first.TxHash() {
+		second = babies[1].timeoutTx
+	}
+	if _, ok := nursery.unpublishedTxns[second.TxHash()]; !ok {
+		t.Fatalf("delayed txn %v not queued for republication",
+			second.TxHash())
+	}
+
+	select {
+	case tx := <-published:
+		if tx.TxHash() != second.TxHash() {
+			t.Fatalf("expected delayed txn %v, got %v",
+				second.TxHash(), tx.TxHash())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("delayed txn not published")
+	}
+}
+
+// TestNurseryDrain asserts that draining the nursery rejects new incubations,
+// waits for in-flight graduations, and times out if they fail to complete.
+func TestNurseryDrain(t *testing.T) {
+	// With no in-flight graduations, the nursery should drain immediately.
+	nursery := newUtxoNursery(&NurseryConfig{})
+	if err := nursery.Drain(time.Second); err != nil {
+		t.Fatalf("unable to drain nursery: %v", err)
+	}
+
+	err := nursery.IncubateOutputs(&lnwallet.ForceCloseSummary{}, nil, 0)
+	if err != ErrNurseryDraining {
+		t.Fatalf("expected ErrNurseryDraining, got: %v", err)
+	}
+
+	// Simulate an in-flight graduation that completes while draining.
+	nursery = newUtxoNursery(&NurseryConfig{})
+	nursery.graduations.Add(1)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		nursery.graduations.Done()
+	}()
+
+	if err := nursery.Drain(5 * time.Second); err != nil {
+		t.Fatalf("unable to drain nursery: %v", err)
+	}
+
+	// Finally, an in-flight graduation that never completes should cause
+	// the drain to time out.
+	nursery = newUtxoNursery(&NurseryConfig{})
+	nursery.graduations.Add(1)
+	defer nursery.graduations.Done()
+
+	if err := nursery.Drain(50 * time.Millisecond); err == nil {
+		t.Fatalf("expected drain to time out")
+	}
+}
+
+// TestNurseryPauseResume asserts that the heights received by the incubator
+// while paused are not processed until it is resumed, upon which they are
+// processed in the order in which they arrived.
+func TestNurseryPauseResume(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		Store: ns,
+	})
+
+	epochs := make(chan *chainntnfs.BlockEpoch)
+	nursery.wg.Add(1)
+	go nursery.incubator(&chainntnfs.BlockEpochEvent{
+		Epochs: epochs,
+		Cancel: func() {},
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	nursery.Pause()
+
+	hashes := make(map[uint32]chainhash.Hash)
+	for height := uint32(101); height <= 102; height++ {
+		hashes[height] = chainhash.Hash{byte(height)}
+		hash := hashes[height]
+		epochs <- &chainntnfs.BlockEpoch{
+			Hash:   &hash,
+			Height: int32(height),
+		}
+	}
+
+	// Both heights should be queued, without having been graduated.
+	for i := 0; ; i++ {
+		nursery.mu.Lock()
+		numQueued := len(nursery.pausedEpochs)
+		nursery.mu.Unlock()
+		if numQueued == 2 {
+			break
+		}
+		if i == 50 {
+			t.Fatalf("expected 2 queued heights, got %d", numQueued)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	lastGradHeight, err := ns.LastGraduatedHeight()
+	if err != nil {
+		t.Fatalf("unable to fetch last graduated height: %v", err)
+	}
+	if lastGradHeight != 0 {
+		t.Fatalf("expected no graduated height while paused, got %d",
+			lastGradHeight)
+	}
+
+	// Once resumed, the queued heights should be processed in order, such
+	// that the last of them is the last graduated.
+	nursery.Resume()
+
+	for i := 0; i < 50; i++ {
+		height, hash, err := ns.LastGraduatedBlock()
+		if err != nil {
+			t.Fatalf("unable to fetch last graduated block: %v",
+				err)
+		}
+		if height == 102 && hash != nil && *hash == hashes[102] {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("queued heights not processed after resuming")
+}
+
+// TestNurseryGraduateClassCanceled asserts that graduateClass leaves the
+// nursery's state untouched once its context has been canceled, and that
+// stopping the nursery cancels contexts derived via withQuit.
+func TestNurseryGraduateClassCanceled(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		Store: ns,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := nursery.graduateClass(ctx, 100, nil); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got: %v", err)
+	}
+	if nursery.bestHeight != 0 {
+		t.Fatalf("expected best height to be unset, got %d",
+			nursery.bestHeight)
+	}
+
+	lastGradHeight, err := ns.LastGraduatedHeight()
+	if err != nil {
+		t.Fatalf("unable to fetch last graduated height: %v", err)
+	}
+	if lastGradHeight != 0 {
+		t.Fatalf("expected height 100 not to be graduated, last "+
+			"graduated height is %d", lastGradHeight)
+	}
+
+	// Stopping the nursery should cancel any context derived from its quit
+	// channel.
+	ctx, cancel = nursery.withQuit(context.Background())
+	defer cancel()
+
+	if err := nursery.Stop(); err != nil {
+		t.Fatalf("unable to stop nursery: %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("context not canceled after stopping nursery")
+	}
+}
+
+// mockChainIO is a BlockChainIO whose main chain consists of the block hashes
+// it was assigned, by height. Outputs in the spent set are reported as spent.
+type mockChainIO struct {
+	bestHeight int32
+	hashes     map[int64]chainhash.Hash
+	spent      map[wire.OutPoint]struct{}
+}
+
+func (m *mockChainIO) GetBestBlock() (*chainhash.Hash, int32, error) {
+	hash := m.hashes[int64(m.bestHeight)]
+	return &hash, m.bestHeight, nil
+}
+
+func (m *mockChainIO) GetUtxo(op *wire.OutPoint,
+	heightHint uint32) (*wire.TxOut, error) {
+
+	if _, ok := m.spent[*op]; ok {
+		return nil, btcwallet.ErrOutputSpent
+	}
+
+	return nil, fmt.Errorf("utxo not found")
+}
+
+func (m *mockChainIO) GetBlockHash(blockHeight int64) (*chainhash.Hash,
+	error) {
+
+	hash, ok := m.hashes[blockHeight]
+	if !ok {
+		return nil, fmt.Errorf("no block at height %d", blockHeight)
+	}
+
+	return &hash, nil
+}
+
+func (m *mockChainIO) GetBlock(
+	blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+
+	return nil, fmt.Errorf("block not found")
+}
+
+// TestNurseryReloadClassesChainDiverged asserts that the hash of each replayed
+// block is recorded, and that the replay is aborted once the block at the last
+// graduated height is no longer part of the main chain.
+func TestNurseryReloadClassesChainDiverged(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	chainIO := &mockChainIO{
+		bestHeight: 102,
+		hashes:     make(map[int64]chainhash.Hash),
+	}
+	for height := int64(100); height <= 102; height++ {
+		chainIO.hashes[height] = chainhash.Hash{byte(height)}
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		ChainIO: chainIO,
+		Store:   ns,
+	})
+
+	// Heights graduated without a block hash can't be verified.
+	if err := ns.GraduateHeight(100); err != nil {
+		t.Fatalf("unable to graduate height: %v", err)
+	}
+	_, hash, err := ns.LastGraduatedBlock()
+	if err != nil {
+		t.Fatalf("unable to fetch last graduated block: %v", err)
+	}
+	if hash != nil {
+		t.Fatalf("expected no hash for last graduated height, got %v",
+			hash)
+	}
+
+	// Replaying the missed blocks should record the hash of each of them.
+	ctx := context.Background()
+	if err := nursery.reloadClasses(ctx, 100); err != nil {
+		t.Fatalf("unable to reload classes: %v", err)
+	}
+
+	height, hash, err := ns.LastGraduatedBlock()
+	if err != nil {
+		t.Fatalf("unable to fetch last graduated block: %v", err)
+	}
+	expHash := chainIO.hashes[102]
+	if height != 102 || hash == nil || *hash != expHash {
+		t.Fatalf("expected last graduated block %v at height 102, "+
+			"got %v at height %d", expHash, hash, height)
+	}
+
+	// If the block at the last graduated height is reorged out while the
+	// nursery is offline, the replay must be aborted.
+	chainIO.hashes[102] = chainhash.Hash{0xff}
+	chainIO.hashes[103] = chainhash.Hash{0xfe}
+	chainIO.bestHeight = 103
+
+	if err := nursery.reloadClasses(ctx, 102); err != ErrChainDiverged {
+		t.Fatalf("expected ErrChainDiverged, got: %v", err)
+	}
+
+	lastGradHeight, err := ns.LastGraduatedHeight()
+	if err != nil {
+		t.Fatalf("unable to fetch last graduated height: %v", err)
+	}
+	if lastGradHeight != 102 {
+		t.Fatalf("expected last graduated height 102, got %d",
+			lastGradHeight)
+	}
+}
+
+// TestNurseryReloadClassesErrorContext asserts that a failure while replaying
+// missed blocks identifies the height at which it occurred.
+func TestNurseryReloadClassesErrorContext(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	// The hash of the block at height 102 is unknown to the backend.
+	chainIO := &mockChainIO{
+		bestHeight: 102,
+		hashes: map[int64]chainhash.Hash{
+			100: {100},
+			101: {101},
+		},
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		ChainIO: chainIO,
+		Store:   ns,
+	})
+
+	err = nursery.reloadClasses(context.Background(), 100)
+	if err == nil {
+		t.Fatalf("expected replay to fail at height 102")
+	}
+	if !strings.Contains(err.Error(), "height=102") {
+		t.Fatalf("expected error to identify height 102, got: %v", err)
+	}
+
+	// The heights preceding the failure should have been graduated.
+	lastGradHeight, err := ns.LastGraduatedHeight()
+	if err != nil {
+		t.Fatalf("unable to fetch last graduated height: %v", err)
+	}
+	if lastGradHeight != 101 {
+		t.Fatalf("expected last graduated height 101, got %d",
+			lastGradHeight)
+	}
+}
+
+// TestNurseryPrematureCribSweep asserts that a crib output indexed at a height
+// preceding its CLTV expiry is not broadcast at that height, and is instead
+// requeued at its expiry height.
+func TestNurseryPrematureCribSweep(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		Store: ns,
+		PublishTransaction: func(tx *wire.MsgTx) error {
+			t.Fatalf("premature timeout txn %v broadcast",
+				tx.TxHash())
+			return nil
+		},
+	})
+
+	baby := babyOutputs[0]
+	if err := ns.Incubate(nil, []babyOutput{baby}); err != nil {
+		t.Fatalf("unable to incubate crib output: %v", err)
+	}
+
+	// Index the crib output at a height well before its expiry, as if it
+	// had been bucketed incorrectly.
+	const wrongHeight = 100
+	misplaced := baby
+	misplaced.expiry = wrongHeight
+	if err := ns.RequeueCrib(&misplaced, baby.expiry); err != nil {
+		t.Fatalf("unable to move crib output: %v", err)
+	}
+
+	if err := nursery.broadcastHeight(wrongHeight); err != nil {
+		t.Fatalf("unable to broadcast height: %v", err)
+	}
+
+	// The crib output should now be found at its expiry height, rather
+	// than the height at which it was misplaced.
+	_, _, babies, err := ns.FetchClass(wrongHeight)
+	if err != nil {
+		t.Fatalf("unable to fetch class: %v", err)
+	}
+	if len(babies) != 0 {
+		t.Fatalf("expected no crib outputs at height=%d, found %d",
+			wrongHeight, len(babies))
+	}
+
+	_, _, babies, err = ns.FetchClass(baby.expiry)
+	if err != nil {
+		t.Fatalf("unable to fetch class: %v", err)
+	}
+	if len(babies) != 1 || *babies[0].OutPoint() != *baby.OutPoint() {
+		t.Fatalf("expected crib output %v at height=%d, found %v",
+			baby.OutPoint(), baby.expiry, babies)
+	}
+}
+
+// TestValidateTimeoutTx asserts that a crib output's presigned timeout txn is
+// only considered valid if it creates the crib output's outpoint with the
+// expected value.
+func TestValidateTimeoutTx(t *testing.T) {
+	validBaby := func() babyOutput {
+		baby := babyOutputs[0]
+		baby.timeoutTx = timeoutTx
+		baby.outpoint = wire.OutPoint{
+			Hash:  timeoutTx.TxHash(),
+			Index: 0,
+		}
+		baby.amt = btcutil.Amount(timeoutTx.TxOut[0].Value)
+
+		return baby
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*babyOutput)
+		valid  bool
+	}{
+		{
+			name:   "valid",
+			mutate: func(*babyOutput) {},
+			valid:  true,
+		},
+		{
+			name: "mismatched txid",
+			mutate: func(baby *babyOutput) {
+				baby.outpoint.Hash[0] ^= 0xff
+			},
+		},
+		{
+			name: "missing output",
+			mutate: func(baby *babyOutput) {
+				baby.outpoint.Index = uint32(
+					len(timeoutTx.TxOut),
+				)
+			},
+		},
+		{
+			name: "mismatched amount",
+			mutate: func(baby *babyOutput) {
+				baby.amt++
+			},
+		},
+		{
+			name: "missing timeout tx",
+			mutate: func(baby *babyOutput) {
+				baby.timeoutTx = nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		baby := validBaby()
+		test.mutate(&baby)
+
+		err := validateTimeoutTx(&baby)
+		if test.valid && err != nil {
+			t.Fatalf("%s: expected valid timeout tx, got: %v",
+				test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Fatalf("%s: expected invalid timeout tx", test.name)
+		}
+	}
+}
+
+// TestSweepWitnessSize asserts that the witness size of every witness type
+// the wallet can sign can be estimated, and that other witness types are
+// rejected rather than silently omitted from a sweep.
+func TestSweepWitnessSize(t *testing.T) {
+	witnessTypes := []lnwallet.WitnessType{
+		lnwallet.CommitmentTimeLock,
+		lnwallet.CommitmentNoDelay,
+		lnwallet.CommitmentRevoke,
+		lnwallet.HtlcOfferedRevoke,
+		lnwallet.HtlcAcceptedRevoke,
+		lnwallet.HtlcOfferedTimeout,
+		lnwallet.CommitmentAnchor,
+	}
+	for _, witnessType := range witnessTypes {
+		size, err := sweepWitnessSize(witnessType)
+		if err != nil {
+			t.Fatalf("unable to estimate witness size of type %v: %v",
+				witnessType, err)
+		}
+		if size == 0 {
+			t.Fatalf("expected non-zero witness size for type %v",
+				witnessType)
+		}
+	}
+
+	unsupportedTypes := []lnwallet.WitnessType{
+		lnwallet.HtlcAcceptedSuccess,
+		lnwallet.WitnessType(1000),
+	}
+	for _, witnessType := range unsupportedTypes {
+		if _, err := sweepWitnessSize(witnessType); err == nil {
+			t.Fatalf("expected error for unsupported witness "+
+				"type %v", witnessType)
+		}
+	}
+}
+
+// TestClassifyPublishErr asserts that errors returned by the chain backends
+// when publishing a txn are mapped to the expected typed errors.
+func TestClassifyPublishErr(t *testing.T) {
+	unknownErr := fmt.Errorf("some other failure")
+
+	tests := []struct {
+		err      error
+		expected error
+	}{
+		{nil, nil},
+		{
+			fmt.Errorf("TX rejected: already have transaction abcd"),
+			ErrTxAlreadyPublished,
+		},
+		{
+			fmt.Errorf("-26: txn-already-in-mempool"),
+			ErrTxAlreadyPublished,
+		},
+		{
+			fmt.Errorf("TX rejected: transaction abcd has " +
+				"insufficient priority"),
+			ErrTxFeeTooLow,
+		},
+		{
+			fmt.Errorf("-26: 66: min relay fee not met"),
+			ErrTxFeeTooLow,
+		},
+		{
+			fmt.Errorf("dial tcp 127.0.0.1:8334: connection refused"),
+			ErrPublishConnectivity,
+		},
+		{
+			fmt.Errorf("TX rejected: orphan transaction abcd"),
+			ErrTxAlreadyPublished,
+		},
+		{ErrTxFeeTooLow, ErrTxFeeTooLow},
+		{unknownErr, unknownErr},
+	}
+
+	for i, test := range tests {
+		if err := classifyPublishErr(test.err); err != test.expected {
+			t.Fatalf("test #%d: expected %v, got %v", i,
+				test.expected, err)
+		}
+	}
+}
+
+// TestNurseryRepublishTxns asserts that a sweep txn that couldn't be published
+// due to a connectivity issue is republished upon the next block, and that its
+// outputs are only reported as swept once it's published.
+func TestNurseryRepublishTxns(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[0]
+	classHeight := kid.MaturityHeight()
+	if err := ns.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate output: %v", err)
+	}
+	if err := ns.PreschoolToKinder(&kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	sweepTx := wire.NewMsgTx(2)
+	sweepTx.AddTxIn(&wire.TxIn{PreviousOutPoint: *kid.OutPoint()})
+	sweepTx.AddTxOut(&wire.TxOut{Value: int64(kid.Amount() - 1000)})
+	finalTxns := []*wire.MsgTx{sweepTx}
+
+	err = ns.FinalizeKinder(classHeight, finalTxns, nil)
+	if err != nil {
+		t.Fatalf("unable to finalize kndr: %v", err)
+	}
+
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 1),
+	}
+	publishErr := ErrPublishConnectivity
+	var numPublished int
+	nursery := newUtxoNursery(&NurseryConfig{
+		DB:       cdb,
+		Notifier: notifier,
+		PublishTransaction: func(*wire.MsgTx) error {
+			numPublished++
+			return publishErr
+		},
+		Store: ns,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	sub := nursery.SubscribeNurseryEvents()
+	defer sub.Cancel()
+
+	assertSweptEvent := func(expSwept bool) {
+		t.Helper()
+
+		timeout := time.After(50 * time.Millisecond)
+		for {
+			select {
+			case event := <-sub.Events:
+				if event.Type != NurseryEventSwept {
+					continue
+				}
+				if !expSwept {
+					t.Fatalf("unexpected swept event")
+				}
+				return
+
+			case <-timeout:
+				if expSwept {
+					t.Fatalf("swept event not received")
+				}
+				return
+			}
+		}
+	}
+
+	nursery.mu.Lock()
+	err = nursery.sweepGraduatingKinders(
+		classHeight, finalTxns, []kidOutput{kid},
+	)
+	nursery.mu.Unlock()
+	if err != nil {
+		t.Fatalf("unable to sweep kindergarten outputs: %v", err)
+	}
+
+	// The sweep's confirmation should be watched, though its output
+	// shouldn't be reported as swept until it's published.
+	select {
+	case <-notifier.registrations:
+	case <-time.After(time.Second):
+		t.Fatalf("sweep confirmation not registered")
+	}
+	assertSweptEvent(false)
+
+	// While the backend remains unreachable, the txn should be retried
+	// upon each block.
+	nursery.republishTxns()
+	if numPublished != 2 {
+		t.Fatalf("expected 2 publish attempts, got %d", numPublished)
+	}
+	assertSweptEvent(false)
+
+	// Once the backend accepts the txn, its output is reported as swept,
+	// and it's no longer republished.
+	publishErr = nil
+	nursery.republishTxns()
+	assertSweptEvent(true)
+
+	nursery.republishTxns()
+	if numPublished != 3 {
+		t.Fatalf("expected 3 publish attempts, got %d", numPublished)
+	}
+}
+
+// TestNurseryStatus asserts that the nursery status reports the number of
+// outputs in each state, along with the nursery store's heights.
+func TestNurseryStatus(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		Store: ns,
+	})
+
+	assertStatus := func(expStatus nurseryStatus) {
+		status, err := nursery.NurseryStatus()
+		if err != nil {
+			t.Fatalf("unable to fetch nursery status: %v", err)
+		}
+		if *status != expStatus {
+			t.Fatalf("expected status %+v, got %+v", expStatus,
+				*status)
+		}
+	}
+
+	assertStatus(nurseryStatus{})
+
+	kid := kidOutputs[0]
+	if err := ns.Incubate(&kid, babyOutputs); err != nil {
+		t.Fatalf("unable to incubate outputs: %v", err)
+	}
+
+	assertStatus(nurseryStatus{
+		numCrib: uint32(len(babyOutputs)),
+		numPscl: 1,
+	})
+
+	if err := ns.PreschoolToKinder(&kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+	if err := ns.FinalizeKinder(kid.MaturityHeight(), nil, nil); err != nil {
+		t.Fatalf("unable to finalize kndr: %v", err)
+	}
+
+	assertStatus(nurseryStatus{
+		lastFinalizedHeight: kid.MaturityHeight(),
+		numCrib:             uint32(len(babyOutputs)),
+		numKndr:             1,
+	})
+}
+
+// TestNurseryStuckOutputs asserts that crib and kindergarten outputs are only
+// reported as stuck once they are overdue by more than the given number of
+// blocks.
+func TestNurseryStuckOutputs(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		Store: ns,
+	})
+
+	kid := kidOutputs[0]
+	if err := ns.Incubate(&kid, babyOutputs); err != nil {
+		t.Fatalf("unable to incubate outputs: %v", err)
+	}
+	if err := ns.PreschoolToKinder(&kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	assertNumStuck := func(olderThan uint32, expNum int) []StuckOutputInfo {
+		stuckOutputs, err := nursery.StuckOutputs(olderThan)
+		if err != nil {
+			t.Fatalf("unable to fetch stuck outputs: %v", err)
+		}
+		if len(stuckOutputs) != expNum {
+			t.Fatalf("expected %d stuck outputs, got %d: %+v",
+				expNum, len(stuckOutputs), stuckOutputs)
+		}
+		return stuckOutputs
+	}
+
+	// Without a best height, no outputs can be considered stuck.
+	assertNumStuck(0, 0)
+
+	// The kindergarten output matures at height 1042, while two of the
+	// crib outputs expire at height 4, and the other at 3829.
+	nursery.bestHeight = 1100
+	assertNumStuck(100, 2)
+
+	stuckOutputs := assertNumStuck(50, 3)
+	for _, info := range stuckOutputs {
+		if info.State != string(kndrPrefix) {
+			continue
+		}
+
+		if info.OutPoint != *kid.OutPoint() ||
+			info.DueHeight != kid.MaturityHeight() ||
+			info.BlocksOverdue != 1100-kid.MaturityHeight() {
+
+			t.Fatalf("unexpected stuck kindergarten output: %+v",
+				info)
+		}
+		return
+	}
+
+	t.Fatalf("kindergarten output not reported as stuck")
+}
+
+// TestNurseryStuckOutputsSkipCorrupt asserts that a corrupt output record is
+// skipped when listing stuck outputs, rather than preventing the remaining
+// outputs from being reported.
+func TestNurseryStuckOutputsSkipCorrupt(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		Store: ns,
+	})
+
+	for _, i := range []int{0, 2} {
+		kid := kidOutputs[i]
+		if err := ns.Incubate(&kid, nil); err != nil {
+			t.Fatalf("unable to incubate output: %v", err)
+		}
+		if err := ns.PreschoolToKinder(&kid); err != nil {
+			t.Fatalf("unable to move pscl output to kndr: %v", err)
+		}
+	}
+
+	// Overwrite the second output with a record that decodes, but has a
+	// zero amount.
+	corrupt := kidOutputs[2]
+	corrupt.amt = 0
+
+	var b bytes.Buffer
+	if err := corrupt.Encode(&b); err != nil {
+		t.Fatalf("unable to serialize kid output: %v", err)
+	}
+
+	err = ns.db.Update(func(tx *bolt.Tx) error {
+		chanBucket := ns.getChannelBucket(tx, corrupt.OriginChanPoint())
+		pfxOutputKey, err := prefixOutputKey(
+			kndrPrefix, corrupt.OutPoint(),
+		)
+		if err != nil {
+			return err
+		}
+
+		return chanBucket.Put(pfxOutputKey, b.Bytes())
+	})
+	if err != nil {
+		t.Fatalf("unable to corrupt output: %v", err)
+	}
+
+	// Both outputs are overdue, but only the intact one should be
+	// reported.
+	nursery.bestHeight = 1100
+	stuckOutputs, err := nursery.StuckOutputs(50)
+	if err != nil {
+		t.Fatalf("unable to fetch stuck outputs: %v", err)
+	}
+	if len(stuckOutputs) != 1 ||
+		stuckOutputs[0].OutPoint != *kidOutputs[0].OutPoint() {
+
+		t.Fatalf("expected only output %v, got %+v",
+			kidOutputs[0].OutPoint(), stuckOutputs)
+	}
+}
+
+// TestNurseryFinalizedSweepTx asserts that the finalized sweep txn for a height
+// is returned exactly as it was persisted, and that heights which are not
+// finalized, or which were finalized with several txns, are reported as such.
+func TestNurseryFinalizedSweepTx(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		Store: ns,
+	})
+
+	kid := kidOutputs[0]
+	if err := ns.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate outputs: %v", err)
+	}
+	if err := ns.PreschoolToKinder(&kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	height := kid.MaturityHeight()
+	_, err = nursery.FinalizedSweepTx(height)
+	if err != ErrNoFinalizedSweep {
+		t.Fatalf("expected ErrNoFinalizedSweep, got: %v", err)
+	}
+
+	err = ns.FinalizeKinder(height, []*wire.MsgTx{timeoutTx}, nil)
+	if err != nil {
+		t.Fatalf("unable to finalize kndr: %v", err)
+	}
+
+	sweepTx, err := nursery.FinalizedSweepTx(height)
+	if err != nil {
+		t.Fatalf("unable to fetch finalized sweep txn: %v", err)
+	}
+
+	var expBytes, sweepBytes bytes.Buffer
+	if err := timeoutTx.Serialize(&expBytes); err != nil {
+		t.Fatalf("unable to serialize txn: %v", err)
+	}
+	if err := sweepTx.Serialize(&sweepBytes); err != nil {
+		t.Fatalf("unable to serialize txn: %v", err)
+	}
+	if !bytes.Equal(expBytes.Bytes(), sweepBytes.Bytes()) {
+		t.Fatalf("finalized sweep txn does not match persisted txn")
+	}
+
+	// When a height is finalized with several sweep txns, only the full
+	// list of txns can be returned for it.
+	kid2 := kidOutputs[3]
+	if err := ns.Incubate(&kid2, nil); err != nil {
+		t.Fatalf("unable to incubate outputs: %v", err)
+	}
+	if err := ns.PreschoolToKinder(&kid2); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	secondTx := timeoutTx.Copy()
+	secondTx.LockTime++
+
+	height2 := kid2.MaturityHeight()
+	err = ns.FinalizeKinder(
+		height2, []*wire.MsgTx{timeoutTx, secondTx}, nil,
+	)
+	if err != nil {
+		t.Fatalf("unable to finalize kndr: %v", err)
+	}
+
+	_, err = nursery.FinalizedSweepTx(height2)
+	if err != ErrMultipleFinalizedSweeps {
+		t.Fatalf("expected ErrMultipleFinalizedSweeps, got: %v", err)
+	}
+
+	sweepTxns, err := nursery.FinalizedSweepTxns(height2)
+	if err != nil {
+		t.Fatalf("unable to fetch finalized sweep txns: %v", err)
+	}
+	if len(sweepTxns) != 2 || sweepTxns[1].TxHash() != secondTx.TxHash() {
+		t.Fatalf("unexpected finalized sweep txns: %v", sweepTxns)
+	}
+}
+
+// TestNurseryFinalizeHeightInOrder asserts that FinalizeHeight finalizes any
+// lower active heights before the requested one, such that their outputs
+// aren't stranded, and that heights above the best height are rejected.
+func TestNurseryFinalizeHeightInOrder(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	// The first output matures well below the second.
+	kids := []kidOutput{kidOutputs[3], kidOutputs[0]}
+	for i := range kids {
+		if err := ns.Incubate(&kids[i], nil); err != nil {
+			t.Fatalf("unable to incubate output: %v", err)
+		}
+		if err := ns.PreschoolToKinder(&kids[i]); err != nil {
+			t.Fatalf("unable to move pscl output to kndr: %v", err)
+		}
+	}
+	lowHeight := kids[0].MaturityHeight()
+	highHeight := kids[1].MaturityHeight()
+
+	chainIO := &mockChainIO{
+		bestHeight: int32(highHeight - 1),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		ChainIO: chainIO,
+		Store:   ns,
+		Sweeper: &mockSweeper{numInputs: 1},
+	})
+
+	// The second output's height has yet to be mined, so it can't be
+	// finalized.
+	if _, err := nursery.FinalizeHeight(highHeight); err == nil {
+		t.Fatalf("expected failure finalizing height above best height")
+	}
+	assertLastFinalizedHeight(t, ns, 0)
+
+	chainIO.bestHeight = int32(highHeight)
+	finalTxns, err := nursery.FinalizeHeight(highHeight)
+	if err != nil {
+		t.Fatalf("unable to finalize height: %v", err)
+	}
+	if len(finalTxns) != 1 ||
+		finalTxns[0].TxIn[0].PreviousOutPoint != *kids[1].OutPoint() {
+
+		t.Fatalf("unexpected finalized txns: %v", finalTxns)
+	}
+	assertLastFinalizedHeight(t, ns, highHeight)
+
+	// The lower height should have been finalized first, rather than
+	// skipped.
+	lowTxns, _, _, err := ns.FetchClass(lowHeight)
+	if err != nil {
+		t.Fatalf("unable to fetch class: %v", err)
+	}
+	if len(lowTxns) != 1 ||
+		lowTxns[0].TxIn[0].PreviousOutPoint != *kids[0].OutPoint() {
+
+		t.Fatalf("expected height=%d to be finalized, got %v",
+			lowHeight, lowTxns)
+	}
+
+	// Finalizing either height again returns the persisted txns.
+	againTxns, err := nursery.FinalizeHeight(lowHeight)
+	if err != nil {
+		t.Fatalf("unable to finalize height: %v", err)
+	}
+	if len(againTxns) != 1 ||
+		againTxns[0].TxHash() != lowTxns[0].TxHash() {
+
+		t.Fatalf("expected persisted txns for height=%d", lowHeight)
+	}
+}
+
+// TestNurseryProjectedSweep asserts that the projected sweep of a height
+// reflects the weight, fee and number of its kindergarten outputs, without
+// finalizing a sweep txn for the height.
+func TestNurseryProjectedSweep(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		Estimator: &lnwallet.StaticFeeEstimator{FeeRate: 40},
+		Store:     ns,
+	})
+
+	kids := []kidOutput{kidOutputs[2], kidOutputs[3]}
+	height := kids[0].MaturityHeight()
+
+	// A height without any kindergarten outputs has nothing to sweep.
+	weight, fee, inputs, err := nursery.ProjectedSweep(height)
+	if err != nil {
+		t.Fatalf("unable to project sweep: %v", err)
+	}
+	if weight != 0 || fee != 0 || inputs != 0 {
+		t.Fatalf("expected empty projection, got weight=%v fee=%v "+
+			"inputs=%v", weight, fee, inputs)
+	}
+
+	for i := range kids {
+		if err := ns.Incubate(&kids[i], nil); err != nil {
+			t.Fatalf("unable to incubate outputs: %v", err)
+		}
+		if err := ns.PreschoolToKinder(&kids[i]); err != nil {
+			t.Fatalf("unable to move pscl output to kndr: %v", err)
+		}
+	}
+
+	var weightEstimate lnwallet.TxWeightEstimator
+	weightEstimate.AddP2WKHOutput()
+	weightEstimate.AddWitnessInput(lnwallet.ToLocalTimeoutWitnessSize)
+	weightEstimate.AddWitnessInput(lnwallet.ToLocalTimeoutWitnessSize)
+	expWeight := uint64(weightEstimate.Weight())
+
+	weight, fee, inputs, err = nursery.ProjectedSweep(height)
+	if err != nil {
+		t.Fatalf("unable to project sweep: %v", err)
+	}
+	if weight != expWeight {
+		t.Fatalf("expected weight %v, got %v", expWeight, weight)
+	}
+	if fee != 10*btcutil.Amount(expWeight) {
+		t.Fatalf("expected fee %v, got %v",
+			10*btcutil.Amount(expWeight), fee)
+	}
+	if inputs != len(kids) {
+		t.Fatalf("expected %v inputs, got %v", len(kids), inputs)
+	}
+
+	// Projecting the sweep must not finalize the height.
+	_, err = nursery.FinalizedSweepTx(height)
+	if err != ErrNoFinalizedSweep {
+		t.Fatalf("expected ErrNoFinalizedSweep, got: %v", err)
+	}
+}
+
+// TestNurseryProjectedSweepGrouping asserts that the projected sweep groups
+// the outputs into txns just as createSweepTxns does, and prices each of them
+// subject to the configured fee rate bounds.
+func TestNurseryProjectedSweepGrouping(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	// The estimated fee rate of 10 sat/weight is raised to the minimum
+	// relay fee rate.
+	const minRelayFeeRate = 20
+	nursery := newUtxoNursery(&NurseryConfig{
+		Estimator:       &lnwallet.StaticFeeEstimator{FeeRate: 40},
+		MinRelayFeeRate: minRelayFeeRate,
+		Store:           ns,
+	})
+
+	// The outputs are swept to different scripts, and thus by separate
+	// txns.
+	kids := []kidOutput{kidOutputs[2], kidOutputs[3]}
+	kids[1].sweepPkScript = make([]byte, lnwallet.P2WSHSize)
+	height := kids[0].MaturityHeight()
+
+	for i := range kids {
+		if err := ns.Incubate(&kids[i], nil); err != nil {
+			t.Fatalf("unable to incubate outputs: %v", err)
+		}
+		if err := ns.PreschoolToKinder(&kids[i]); err != nil {
+			t.Fatalf("unable to move pscl output to kndr: %v", err)
+		}
+	}
+
+	var expWeight uint64
+	for i := range kids {
+		var weightEstimate lnwallet.TxWeightEstimator
+		nursery.addSweepOutputWeight(
+			&weightEstimate, []kidOutput{kids[i]},
+		)
+		weightEstimate.AddWitnessInput(
+			lnwallet.ToLocalTimeoutWitnessSize,
+		)
+		expWeight += uint64(weightEstimate.Weight())
+	}
+	expFee := minRelayFeeRate * btcutil.Amount(expWeight)
+
+	weight, fee, inputs, err := nursery.ProjectedSweep(height)
+	if err != nil {
+		t.Fatalf("unable to project sweep: %v", err)
+	}
+	if weight != expWeight {
+		t.Fatalf("expected weight %v, got %v", expWeight, weight)
+	}
+	if fee != expFee {
+		t.Fatalf("expected fee %v, got %v", expFee, fee)
+	}
+	if inputs != len(kids) {
+		t.Fatalf("expected %v inputs, got %v", len(kids), inputs)
+	}
+}
+
+// TestNurseryGraduationConfDepth asserts that a channel whose outputs have all
+// graduated is not closed until its sweep reaches GraduationConfDepth, and is
+// reported as awaiting final confirmations in the meantime.
+func TestNurseryGraduationConfDepth(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		DB:                  cdb,
+		GraduationConfDepth: 3,
+		Store:               ns,
+	})
+
+	kid := kidOutputs[3]
+	chanPoint := kid.OriginChanPoint()
+	if err := ns.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate commitment output: %v", err)
+	}
+	if err := ns.PreschoolToKinder(&kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+	if err := ns.GraduateKinder(kid.MaturityHeight(), 100); err != nil {
+		t.Fatalf("unable to graduate kndr outputs: %v", err)
+	}
+
+	// Graduate the channel as if its sweep confirmed at the current best
+	// height. Since two more confirmations are required, the channel
+	// should be scheduled for closure rather than closed immediately.
+	const sweepHeight = 100
+
+	nursery.mu.Lock()
+	nursery.bestHeight = sweepHeight
+	err = nursery.closeOrAwaitFinalConfs(chanPoint, sweepHeight)
+	nursery.mu.Unlock()
+	if err != nil {
+		t.Fatalf("unable to close channel: %v", err)
+	}
+
+	closeHeight, ok := nursery.finalConfHeights[*chanPoint]
+	if !ok {
+		t.Fatalf("expected channel to be awaiting final confirmations")
+	}
+	if closeHeight != sweepHeight+2 {
+		t.Fatalf("expected close height %d, got %d", sweepHeight+2,
+			closeHeight)
+	}
+
+	report, err := nursery.NurseryReport(chanPoint)
+	if err != nil {
+		t.Fatalf("unable to fetch nursery report: %v", err)
+	}
+	if !report.awaitingFinalConfs {
+		t.Fatalf("expected report to indicate channel is awaiting " +
+			"final confirmations")
+	}
+
+	// A block below the close height should leave the channel pending.
+	nursery.closeFinalizedChannels(sweepHeight + 1)
+	if _, ok := nursery.finalConfHeights[*chanPoint]; !ok {
+		t.Fatalf("channel closed before reaching final confirmations")
+	}
+}
+
+// TestNurseryAllReports asserts that AllNurseryReports returns a report for
+// every channel tracked by the nursery store.
+func TestNurseryAllReports(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		DB:    cdb,
+		Store: ns,
+	})
+
+	reports, err := nursery.AllNurseryReports()
+	if err != nil {
+		t.Fatalf("unable to fetch nursery reports: %v", err)
+	}
+	if len(reports) != 0 {
+		t.Fatalf("expected no reports, got %d", len(reports))
+	}
+
+	kid := kidOutputs[0]
+	if err := ns.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate commitment output: %v", err)
+	}
+
+	reports, err = nursery.AllNurseryReports()
+	if err != nil {
+		t.Fatalf("unable to fetch nursery reports: %v", err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("expected 1 report, got %d", len(reports))
+	}
+
+	report, ok := reports[*kid.OriginChanPoint()]
+	if !ok {
+		t.Fatalf("expected report for channel %v",
+			kid.OriginChanPoint())
+	}
+	if report.chanPoint != *kid.OriginChanPoint() {
+		t.Fatalf("expected report for channel %v, got %v",
+			kid.OriginChanPoint(), report.chanPoint)
+	}
+	if len(report.outputs) != 1 {
+		t.Fatalf("expected 1 output in report, got %d",
+			len(report.outputs))
+	}
+
+	// The output's witness type should be reported by its human readable
+	// name.
+	witnessName := report.outputs[0].witnessName
+	if witnessName != "commitment timelock" {
+		t.Fatalf("expected witness name \"commitment timelock\", "+
+			"got %q", witnessName)
+	}
+}
+
+// TestNurseryReportByWitnessType asserts that the report breaks down the
+// channel's outputs that have yet to be swept by their witness type.
+func TestNurseryReportByWitnessType(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		DB:    cdb,
+		Store: ns,
+	})
+
+	kid := kidOutputs[0]
+	baby := babyOutputs[0]
+	baby.witnessType = lnwallet.HtlcOfferedTimeout
+	if err := ns.Incubate(&kid, []babyOutput{baby}); err != nil {
+		t.Fatalf("unable to incubate outputs: %v", err)
+	}
+
+	report, err := nursery.NurseryReport(kid.OriginChanPoint())
+	if err != nil {
+		t.Fatalf("unable to fetch nursery report: %v", err)
+	}
+
+	expected := map[lnwallet.WitnessType]int{
+		lnwallet.CommitmentTimeLock: 1,
+		lnwallet.HtlcOfferedTimeout: 1,
+	}
+	if !reflect.DeepEqual(report.byWitnessType, expected) {
+		t.Fatalf("expected witness type counts %v, got %v", expected,
+			report.byWitnessType)
+	}
+}
+
+// TestNurseryReportStaleCrib asserts that an htlc whose crib record remains
+// alongside its kindergarten record only contributes to the limbo balance of
+// the report once.
+func TestNurseryReportStaleCrib(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		Estimator: &lnwallet.StaticFeeEstimator{FeeRate: 10},
+		Store:     ns,
+	})
+
+	baby := babyOutputs[1]
+	baby.witnessType = lnwallet.HtlcOfferedTimeout
+	if err := ns.Incubate(nil, []babyOutput{baby}); err != nil {
+		t.Fatalf("unable to incubate crib output: %v", err)
+	}
+	if err := ns.CribToKinder(&baby); err != nil {
+		t.Fatalf("unable to move crib output to kndr: %v", err)
+	}
+
+	// Re-enter the output into the crib, leaving behind a stale crib
+	// record for the htlc that has already advanced.
+	if err := ns.Incubate(nil, []babyOutput{baby}); err != nil {
+		t.Fatalf("unable to incubate crib output: %v", err)
+	}
+
+	report, err := nursery.NurseryReport(baby.OriginChanPoint())
+	if err != nil {
+		t.Fatalf("unable to fetch nursery report: %v", err)
+	}
+
+	if report.limboBalance != baby.Amount() {
+		t.Fatalf("expected limbo balance %v, got %v", baby.Amount(),
+			report.limboBalance)
+	}
+	if len(report.htlcs) != 1 || report.htlcs[0].stage != 2 {
+		t.Fatalf("expected single stage 2 htlc, got %+v",
+			report.htlcs)
+	}
+	if len(report.outputs) != 1 ||
+		report.outputs[0].state != string(kndrPrefix) {
+
+		t.Fatalf("expected single kndr output, got %+v",
+			report.outputs)
+	}
+}
+
+// TestNurseryReportRemaining asserts that the report estimates the number of
+// confirmations remaining for preschool outputs, and the number of blocks
+// remaining for kindergarten outputs.
+func TestNurseryReportRemaining(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		CommitConfDepth: 6,
+		DB:              cdb,
+		Estimator:       &lnwallet.StaticFeeEstimator{FeeRate: 40},
+		Store:           ns,
+	})
+
+	// The preschool output's commitment has two confirmations, while the
+	// kindergarten output matures ten blocks from now.
+	psclKid := kidOutputs[0]
+	kndrKid := kidOutputs[3]
+	nursery.bestHeight = psclKid.ConfHeight() + 1
+
+	if err := ns.Incubate(&psclKid, nil); err != nil {
+		t.Fatalf("unable to incubate commitment output: %v", err)
+	}
+	if err := ns.Incubate(&kndrKid, nil); err != nil {
+		t.Fatalf("unable to incubate commitment output: %v", err)
+	}
+	kndrKid.confHeight = nursery.bestHeight + 10 -
+		kndrKid.BlocksToMaturity()
+	if err := ns.PreschoolToKinder(&kndrKid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	report, err := nursery.NurseryReport(psclKid.OriginChanPoint())
+	if err != nil {
+		t.Fatalf("unable to fetch nursery report: %v", err)
+	}
+	if len(report.outputs) != 2 {
+		t.Fatalf("expected 2 outputs in report, got %d",
+			len(report.outputs))
+	}
+
+	for _, output := range report.outputs {
+		switch output.outpoint {
+		case *psclKid.OutPoint():
+			if output.confsRemaining != 4 {
+				t.Fatalf("expected 4 confs remaining, got %d",
+					output.confsRemaining)
+			}
+
+		case *kndrKid.OutPoint():
+			if output.blocksRemaining != 10 {
+				t.Fatalf("expected 10 blocks remaining, got %d",
+					output.blocksRemaining)
+			}
+		}
+	}
+}
+
+// TestNurseryConfWatcherLimit asserts that confirmation watchers beyond
+// MaxConfWatchers are queued, and only registered once a running watcher
+// exits.
+func TestNurseryConfWatcherLimit(t *testing.T) {
+	nursery := newUtxoNursery(&NurseryConfig{
+		MaxConfWatchers: 1,
+	})
+
+	registered := make(chan string, 2)
+	release := make(chan struct{})
+
+	nursery.mu.Lock()
+	err := nursery.startConfWatcher("first", func() (func(), error) {
+		registered <- "first"
+		return func() {
+			defer nursery.wg.Done()
+			<-release
+		}, nil
+	})
+	if err != nil {
+		nursery.mu.Unlock()
+		t.Fatalf("unable to start first watcher: %v", err)
+	}
+	err = nursery.startConfWatcher("second", func() (func(), error) {
+		registered <- "second"
+		return func() {
+			nursery.wg.Done()
+		}, nil
+	})
+	nursery.mu.Unlock()
+	if err != nil {
+		t.Fatalf("unable to start second watcher: %v", err)
+	}
+
+	if name := <-registered; name != "first" {
+		t.Fatalf("expected first watcher to register, got %v", name)
+	}
+
+	// The second watcher should remain queued while the first is running.
+	select {
+	case name := <-registered:
+		t.Fatalf("watcher %v registered while limit reached", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Once the first watcher exits, the second should be registered.
+	close(release)
+	select {
+	case name := <-registered:
+		if name != "second" {
+			t.Fatalf("expected second watcher to register, got %v",
+				name)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("queued watcher was never registered")
+	}
+
+	nursery.wg.Wait()
+}
+
+// TestNurseryCommitConfRegistrationInterval asserts that commitment
+// confirmation registrations are throttled to one per
+// CommitConfRegistrationInterval, and that queued registrations are dropped
+// once their channel's incubation is stopped.
+func TestNurseryCommitConfRegistrationInterval(t *testing.T) {
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 3),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		CommitConfRegistrationInterval: time.Minute,
+		Notifier:                       notifier,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	// The registrar's interval is paced by ticks delivered by the test.
+	// Each time the registrar starts waiting for a tick, it signals the
+	// waiting channel.
+	ticks := make(chan time.Time)
+	waiting := make(chan struct{}, 1)
+	nursery.after = func(time.Duration) <-chan time.Time {
+		waiting <- struct{}{}
+		return ticks
+	}
+	waitForRegistrar := func() {
+		select {
+		case <-waiting:
+		case <-time.After(time.Second):
+			t.Fatalf("registrar never waited for the interval")
+		}
+	}
+
+	nursery.mu.Lock()
+	for i := 0; i < 3; i++ {
+		err := nursery.registerCommitConf(&kidOutputs[i], 0)
+		if err != nil {
+			nursery.mu.Unlock()
+			t.Fatalf("unable to register commit conf: %v", err)
+		}
+	}
+	nursery.mu.Unlock()
+
+	// Nothing should be registered until the registrar is started.
+	if len(notifier.registrations) != 0 {
+		t.Fatalf("commitment confirmation registered before start")
+	}
+
+	nursery.wg.Add(1)
+	go nursery.commitConfRegistrar()
+
+	// The first registration is made immediately, after which the second
+	// must wait for the interval to pass.
+	waitForRegistrar()
+	if len(notifier.registrations) != 1 {
+		t.Fatalf("expected 1 registration, got %d",
+			len(notifier.registrations))
+	}
+	<-notifier.registrations
+
+	ticks <- time.Time{}
+	waitForRegistrar()
+	if len(notifier.registrations) != 1 {
+		t.Fatalf("expected 1 registration, got %d",
+			len(notifier.registrations))
+	}
+	<-notifier.registrations
+
+	// Stopping the channel's incubation should drop the last queued
+	// registration.
+	nursery.mu.Lock()
+	nursery.stopChanIncubation(kidOutputs[2].OriginChanPoint(), nil)
+	if len(nursery.commitConfQueue) != 0 {
+		nursery.mu.Unlock()
+		t.Fatalf("expected empty queue, found %d registrations",
+			len(nursery.commitConfQueue))
+	}
+	nursery.mu.Unlock()
+}
+
+// controlledConfNotifier is a mockConfNotifier whose confirmation
+// registrations signal the registering channel, and then wait for a result
+// from the results channel, failing if the result is a non-nil error.
+type controlledConfNotifier struct {
+	*mockConfNotifier
+
+	registering chan struct{}
+	results     chan error
+}
+
+func (c *controlledConfNotifier) RegisterConfirmationsNtfn(
+	txid *chainhash.Hash, numConfs,
+	heightHint uint32) (*chainntnfs.ConfirmationEvent, error) {
+
+	c.registering <- struct{}{}
+	if err := <-c.results; err != nil {
+		return nil, err
+	}
+
+	return c.mockConfNotifier.RegisterConfirmationsNtfn(
+		txid, numConfs, heightHint,
+	)
+}
+
+// TestNurseryCommitConfRegistrationRetry asserts that a failed commitment
+// confirmation registration is queued again behind the other queued
+// registrations, and that no watcher is launched for a registration whose
+// channel's incubation is stopped while it is being made.
+func TestNurseryCommitConfRegistrationRetry(t *testing.T) {
+	notifier := &controlledConfNotifier{
+		mockConfNotifier: &mockConfNotifier{
+			registrations: make(
+				chan *chainntnfs.ConfirmationEvent, 3,
+			),
+		},
+		registering: make(chan struct{}, 3),
+		results:     make(chan error),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		CommitConfRegistrationInterval: time.Minute,
+		Notifier:                       notifier,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+	defer close(notifier.results)
+
+	ticks := make(chan time.Time)
+	waiting := make(chan struct{}, 1)
+	nursery.after = func(time.Duration) <-chan time.Time {
+		waiting <- struct{}{}
+		return ticks
+	}
+	waitForRegistrar := func() {
+		select {
+		case <-waiting:
+		case <-time.After(time.Second):
+			t.Fatalf("registrar never waited for the interval")
+		}
+	}
+	queuedOutpoints := func() []wire.OutPoint {
+		nursery.mu.Lock()
+		defer nursery.mu.Unlock()
+
+		var outpoints []wire.OutPoint
+		for _, reg := range nursery.commitConfQueue {
+			outpoints = append(outpoints, *reg.kid.OutPoint())
+		}
+		return outpoints
+	}
+
+	nursery.mu.Lock()
+	for i := 0; i < 2; i++ {
+		err := nursery.registerCommitConf(&kidOutputs[i], 0)
+		if err != nil {
+			nursery.mu.Unlock()
+			t.Fatalf("unable to register commit conf: %v", err)
+		}
+	}
+	nursery.mu.Unlock()
+
+	nursery.wg.Add(1)
+	go nursery.commitConfRegistrar()
+
+	// The first registration fails, and should be queued again behind the
+	// second.
+	<-notifier.registering
+	notifier.results <- fmt.Errorf("notifier unavailable")
+	waitForRegistrar()
+
+	expOutpoints := []wire.OutPoint{
+		*kidOutputs[1].OutPoint(), *kidOutputs[0].OutPoint(),
+	}
+	if outpoints := queuedOutpoints(); !reflect.DeepEqual(
+		outpoints, expOutpoints) {
+
+		t.Fatalf("expected queued outpoints %v, got %v",
+			expOutpoints, outpoints)
+	}
+
+	ticks <- time.Time{}
+	<-notifier.registering
+	notifier.results <- nil
+	waitForRegistrar()
+	if len(notifier.registrations) != 1 {
+		t.Fatalf("expected 1 registration, got %d",
+			len(notifier.registrations))
+	}
+	<-notifier.registrations
+
+	// Stop the channel's incubation while the retried registration is
+	// being made, which must not launch a watcher for it.
+	ticks <- time.Time{}
+	<-notifier.registering
+
+	nursery.mu.Lock()
+	chanPoint := *kidOutputs[0].OriginChanPoint()
+	nursery.stopChanIncubation(&chanPoint, nil)
+	nursery.mu.Unlock()
+
+	notifier.results <- nil
+	waitForRegistrar()
+
+	nursery.mu.Lock()
+	_, watching := nursery.chanCancels[chanPoint]
+	nursery.mu.Unlock()
+	if watching {
+		t.Fatalf("watcher launched for stopped channel")
+	}
+}
+
+// mockConfNotifier is a ChainNotifier that hands out a new confirmation event
+// for each registration, and delivers it on the registrations channel. Spend
+// events are delivered on the spendRegistrations channel, if non-nil.
+type mockConfNotifier struct {
+	chainntnfs.ChainNotifier
+
+	registrations      chan *chainntnfs.ConfirmationEvent
+	spendRegistrations chan chan *chainntnfs.SpendDetail
+}
+
+func (m *mockConfNotifier) RegisterConfirmationsNtfn(txid *chainhash.Hash,
+	numConfs, heightHint uint32) (*chainntnfs.ConfirmationEvent, error) {
+
+	confEvent := &chainntnfs.ConfirmationEvent{
+		Confirmed:    make(chan *chainntnfs.TxConfirmation, 1),
+		NegativeConf: make(chan int32, 1),
+	}
+	m.registrations <- confEvent
+
+	return confEvent, nil
+}
+
+func (m *mockConfNotifier) RegisterSpendNtfn(outpoint *wire.OutPoint,
+	heightHint uint32) (*chainntnfs.SpendEvent, error) {
+
+	spendChan := make(chan *chainntnfs.SpendDetail, 1)
+	if m.spendRegistrations != nil {
+		m.spendRegistrations <- spendChan
+	}
+
+	return &chainntnfs.SpendEvent{
+		Spend:  spendChan,
+		Cancel: func() {},
+	}, nil
+}
+
+// TestNurseryReregisterConf asserts that a confirmation watcher re-registers
+// its notification if the notifier closes the subscription while the nursery
+// is still running, and that the output advances upon the new registration.
+func TestNurseryReregisterConf(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[3]
+	if err := ns.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate output: %v", err)
+	}
+
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 2),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		Notifier: notifier,
+		Store:    ns,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	confHeight := kid.ConfHeight()
+	maturityHeight := kid.MaturityHeight()
+
+	nursery.mu.Lock()
+	err = nursery.registerCommitConf(&kid, 0)
+	nursery.mu.Unlock()
+	if err != nil {
+		t.Fatalf("unable to register commit conf: %v", err)
+	}
+
+	nextRegistration := func() *chainntnfs.ConfirmationEvent {
+		select {
+		case confEvent := <-notifier.registrations:
+			return confEvent
+		case <-time.After(time.Second):
+			t.Fatalf("confirmation notification not registered")
+			return nil
+		}
+	}
+
+	// Closing the subscription should prompt the watcher to register for
+	// the confirmation again.
+	close(nextRegistration().Confirmed)
+	confEvent := nextRegistration()
+
+	confEvent.Confirmed <- &chainntnfs.TxConfirmation{
+		BlockHeight: confHeight,
+	}
+
+	for i := 0; i < 50; i++ {
+		_, kndrOutputs, _, err := ns.FetchClass(maturityHeight)
+		if err != nil {
+			t.Fatalf("unable to fetch class: %v", err)
+		}
+		if len(kndrOutputs) == 1 {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("output not promoted after re-registration")
+}
+
+// TestNurseryPartialSweepGraduation asserts that when the kindergarten outputs
+// at a height are split across several sweep txns, the confirmation of one of
+// them graduates its own outputs, without waiting for the others to confirm.
+func TestNurseryPartialSweepGraduation(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid1, kid2 := kidOutputs[0], kidOutputs[1]
+	classHeight := kid1.MaturityHeight()
+	for _, kid := range []*kidOutput{&kid1, &kid2} {
+		if err := ns.Incubate(kid, nil); err != nil {
+			t.Fatalf("unable to incubate output: %v", err)
+		}
+		if err := ns.PreschoolToKinder(kid); err != nil {
+			t.Fatalf("unable to move pscl output to kndr: %v", err)
+		}
+	}
+
+	sweepTx1 := timeoutTx.Copy()
+	sweepTx1.TxIn[0].PreviousOutPoint = *kid1.OutPoint()
+	sweepTx2 := timeoutTx.Copy()
+	sweepTx2.TxIn[0].PreviousOutPoint = *kid2.OutPoint()
+	finalTxns := []*wire.MsgTx{sweepTx1, sweepTx2}
+
+	if err := ns.FinalizeKinder(classHeight, finalTxns, nil); err != nil {
+		t.Fatalf("unable to finalize kndr: %v", err)
+	}
+
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 2),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		DB:       cdb,
+		Notifier: notifier,
+		Store:    ns,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	_, kgtnOutputs, _, err := ns.FetchClass(classHeight)
+	if err != nil {
+		t.Fatalf("unable to fetch class: %v", err)
+	}
+
+	nursery.mu.Lock()
+	err = nursery.registerSweepConf(finalTxns, kgtnOutputs, classHeight)
+	nursery.mu.Unlock()
+	if err != nil {
+		t.Fatalf("unable to register sweep conf: %v", err)
+	}
+
+	// A confirmation is registered for each of the sweep txns, in the
+	// order in which they were finalized.
+	confEvents := make([]*chainntnfs.ConfirmationEvent, 0, 2)
+	for i := 0; i < 2; i++ {
+		select {
+		case confEvent := <-notifier.registrations:
+			confEvents = append(confEvents, confEvent)
+		case <-time.After(time.Second):
+			t.Fatalf("sweep confirmation not registered")
+		}
+	}
+
+	waitForKndrOutputs := func(numOutputs int) []kidOutput {
+		for i := 0; i < 50; i++ {
+			_, kndrOutputs, _, err := ns.FetchClass(classHeight)
+			if err != nil {
+				t.Fatalf("unable to fetch class: %v", err)
+			}
+			if len(kndrOutputs) == numOutputs {
+				return kndrOutputs
+			}
+
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		t.Fatalf("expected %d kindergarten outputs at height=%d",
+			numOutputs, classHeight)
+		return nil
+	}
+
+	// Confirming only the second sweep should graduate the output it
+	// spends, while the first remains in the kindergarten.
+	confEvents[1].Confirmed <- &chainntnfs.TxConfirmation{
+		BlockHeight: classHeight + 1,
+	}
+
+	kndrOutputs := waitForKndrOutputs(1)
+	if *kndrOutputs[0].OutPoint() != *kid1.OutPoint() {
+		t.Fatalf("expected output %v to remain in kindergarten, got %v",
+			kid1.OutPoint(), kndrOutputs[0].OutPoint())
+	}
+
+	// The height's sweeps must remain finalized until every output has
+	// graduated.
+	assertFinalizedTxns(t, ns, classHeight, finalTxns)
+
+	confEvents[0].Confirmed <- &chainntnfs.TxConfirmation{
+		BlockHeight: classHeight + 2,
+	}
+
+	waitForKndrOutputs(0)
+	assertHeightIsPurged(t, ns, classHeight)
+}
+
+// TestNurseryConflictingSweep asserts that a sweep txn whose inputs are found
+// to be spent on-chain is not broadcast, and that the outputs are instead
+// graduated once the conflicting txn spending them confirms.
+func TestNurseryConflictingSweep(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[0]
+	classHeight := kid.MaturityHeight()
+	if err := ns.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate output: %v", err)
+	}
+	if err := ns.PreschoolToKinder(&kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	sweepTx := timeoutTx.Copy()
+	sweepTx.TxIn[0].PreviousOutPoint = *kid.OutPoint()
+	finalTxns := []*wire.MsgTx{sweepTx}
+	if err := ns.FinalizeKinder(classHeight, finalTxns, nil); err != nil {
+		t.Fatalf("unable to finalize kndr: %v", err)
+	}
+
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 1),
+		spendRegistrations: make(
+			chan chan *chainntnfs.SpendDetail, 1,
+		),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		ChainIO: &mockChainIO{
+			spent: map[wire.OutPoint]struct{}{
+				*kid.OutPoint(): {},
+			},
+		},
+		DB:       cdb,
+		Notifier: notifier,
+		PublishTransaction: func(tx *wire.MsgTx) error {
+			t.Fatalf("conflicted sweep tx %v broadcast",
+				tx.TxHash())
+			return nil
+		},
+		Store: ns,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	_, kgtnOutputs, _, err := ns.FetchClass(classHeight)
+	if err != nil {
+		t.Fatalf("unable to fetch class: %v", err)
+	}
+
+	nursery.mu.Lock()
+	err = nursery.sweepGraduatingKinders(classHeight, finalTxns, kgtnOutputs)
+	nursery.mu.Unlock()
+	if err != nil {
+		t.Fatalf("unable to sweep kindergarten outputs: %v", err)
+	}
+
+	// No confirmation should be registered for our own sweep, only a
+	// spend notification for the conflicted output.
+	var spendChan chan *chainntnfs.SpendDetail
+	select {
+	case spendChan = <-notifier.spendRegistrations:
+	case <-time.After(time.Second):
+		t.Fatalf("spend of conflicted output not registered")
+	}
+	select {
+	case <-notifier.registrations:
+		t.Fatalf("confirmation of conflicted sweep registered")
+	default:
+	}
+
+	conflictingTx := sweepTx.Copy()
+	conflictingTx.TxOut[0].Value--
+	conflictingTxID := conflictingTx.TxHash()
+	spendChan <- &chainntnfs.SpendDetail{
+		SpentOutPoint:  kid.OutPoint(),
+		SpenderTxHash:  &conflictingTxID,
+		SpendingTx:     conflictingTx,
+		SpendingHeight: int32(classHeight + 1),
+	}
+
+	// The conflicting txn is then treated as the sweep of the output,
+	// which graduates once it confirms.
+	var confEvent *chainntnfs.ConfirmationEvent
+	select {
+	case confEvent = <-notifier.registrations:
+	case <-time.After(time.Second):
+		t.Fatalf("confirmation of conflicting sweep not registered")
+	}
+	confEvent.Confirmed <- &chainntnfs.TxConfirmation{
+		BlockHeight: classHeight + 1,
+	}
+
+	for i := 0; i < 50; i++ {
+		_, kndrOutputs, _, err := ns.FetchClass(classHeight)
+		if err != nil {
+			t.Fatalf("unable to fetch class: %v", err)
+		}
+		if len(kndrOutputs) == 0 {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("output not graduated upon confirmation of conflicting " +
+		"sweep")
+}
+
+// TestNurseryHandoffExternalSweep asserts that the spend watches of handed off
+// outputs are re-registered upon restart, and that a kindergarten output swept
+// by the external service graduates once the external sweep confirms, rather
+// than being swept again by the nursery.
+func TestNurseryHandoffExternalSweep(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[0]
+	classHeight := kid.MaturityHeight()
+	if err := ns.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate output: %v", err)
+	}
+	if err := ns.PreschoolToKinder(&kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	sweepTx := timeoutTx.Copy()
+	sweepTx.TxIn[0].PreviousOutPoint = *kid.OutPoint()
+	finalTxns := []*wire.MsgTx{sweepTx}
+	if err := ns.FinalizeKinder(classHeight, finalTxns, nil); err != nil {
+		t.Fatalf("unable to finalize kndr: %v", err)
+	}
+
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 1),
+		spendRegistrations: make(
+			chan chan *chainntnfs.SpendDetail, 1,
+		),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		DB: cdb,
+		HandoffOutputs: func([]HandoffOutput) error {
+			t.Fatalf("outputs handed off again upon restart")
+			return nil
+		},
+		Notifier: notifier,
+		PublishTransaction: func(tx *wire.MsgTx) error {
+			t.Fatalf("externally swept sweep tx %v broadcast",
+				tx.TxHash())
+			return nil
+		},
+		Store: ns,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	nextSpendChan := func() chan *chainntnfs.SpendDetail {
+		select {
+		case spendChan := <-notifier.spendRegistrations:
+			return spendChan
+		case <-time.After(time.Second):
+			t.Fatalf("spend of handed off output not registered")
+			return nil
+		}
+	}
+
+	// Upon restart, the spend of the handed off output should be watched
+	// once again.
+	if err := nursery.rewatchHandoffs(classHeight); err != nil {
+		t.Fatalf("unable to rewatch handed off outputs: %v", err)
+	}
+
+	externalTx := sweepTx.Copy()
+	externalTx.TxOut[0].Value--
+	externalTxID := externalTx.TxHash()
+	spendDetail := &chainntnfs.SpendDetail{
+		SpentOutPoint:  kid.OutPoint(),
+		SpenderTxHash:  &externalTxID,
+		SpendingTx:     externalTx,
+		SpendingHeight: int32(classHeight + 1),
+	}
+	nextSpendChan() <- spendDetail
+
+	for i := 0; ; i++ {
+		nursery.mu.Lock()
+		_, ok := nursery.handoffSpends[*kid.OutPoint()]
+		nursery.mu.Unlock()
+		if ok {
+			break
+		}
+		if i == 50 {
+			t.Fatalf("spend of handed off output not recorded")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	_, kgtnOutputs, _, err := ns.FetchClass(classHeight)
+	if err != nil {
+		t.Fatalf("unable to fetch class: %v", err)
+	}
+
+	nursery.mu.Lock()
+	err = nursery.sweepGraduatingKinders(classHeight, finalTxns, kgtnOutputs)
+	nursery.mu.Unlock()
+	if err != nil {
+		t.Fatalf("unable to sweep kindergarten outputs: %v", err)
+	}
+
+	// The external sweep is treated as a conflicting sweep of the output,
+	// which graduates once it confirms.
+	nextSpendChan() <- spendDetail
+
+	var confEvent *chainntnfs.ConfirmationEvent
+	select {
+	case confEvent = <-notifier.registrations:
+	case <-time.After(time.Second):
+		t.Fatalf("confirmation of external sweep not registered")
+	}
+	confEvent.Confirmed <- &chainntnfs.TxConfirmation{
+		BlockHeight: classHeight + 1,
+	}
+
+	for i := 0; i < 50; i++ {
+		_, kndrOutputs, _, err := ns.FetchClass(classHeight)
+		if err != nil {
+			t.Fatalf("unable to fetch class: %v", err)
+		}
+		if len(kndrOutputs) == 0 {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("output not graduated upon confirmation of external sweep")
+}
+
+// TestNurseryCommitmentSpendFallback asserts that if a channel's funding
+// outpoint is spent by a txn other than the expected commitment txn, a
+// preschool output paid by that txn is re-keyed to it, and promoted once it
+// confirms.
+func TestNurseryCommitmentSpendFallback(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[0]
+	if err := ns.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate output: %v", err)
+	}
+
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 2),
+		spendRegistrations: make(
+			chan chan *chainntnfs.SpendDetail, 1,
+		),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		Notifier: notifier,
+		Store:    ns,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	// The kid output is modified by the watcher once registered.
+	chanPoint := *kid.OriginChanPoint()
+	commitOutput := *kid.SignDesc().Output
+	confHeight := kid.ConfHeight()
+	maturityHeight := kid.MaturityHeight()
+
+	nursery.mu.Lock()
+	err = nursery.registerCommitConf(&kid, 0)
+	nursery.mu.Unlock()
+	if err != nil {
+		t.Fatalf("unable to register commit conf: %v", err)
+	}
+
+	nextRegistration := func() *chainntnfs.ConfirmationEvent {
+		select {
+		case confEvent := <-notifier.registrations:
+			return confEvent
+		case <-time.After(time.Second):
+			t.Fatalf("confirmation notification not registered")
+			return nil
+		}
+	}
+
+	// The original commitment txn is registered first, along with the
+	// funding outpoint.
+	nextRegistration()
+
+	var spendChan chan *chainntnfs.SpendDetail
+	select {
+	case spendChan = <-notifier.spendRegistrations:
+	case <-time.After(time.Second):
+		t.Fatalf("spend notification not registered")
+	}
+
+	// Spend the funding outpoint with a different txn, which pays our
+	// commitment output at its second output.
+	spendingTx := &wire.MsgTx{
+		Version: 2,
+		TxIn: []*wire.TxIn{{
+			PreviousOutPoint: chanPoint,
+		}},
+		TxOut: []*wire.TxOut{
+			{Value: 1000, PkScript: []byte{0x00}},
+			&commitOutput,
+		},
+	}
+	spenderTxID := spendingTx.TxHash()
+	spendChan <- &chainntnfs.SpendDetail{
+		SpentOutPoint: &chanPoint,
+		SpenderTxHash: &spenderTxID,
+		SpendingTx:    spendingTx,
+	}
+
+	// The nursery should now wait upon the confirmation of the spending
+	// txn, which promotes the re-keyed output.
+	confEvent := nextRegistration()
+	confEvent.Confirmed <- &chainntnfs.TxConfirmation{
+		BlockHeight: confHeight,
+	}
+
+	expOutPoint := wire.OutPoint{Hash: spenderTxID, Index: 1}
+	for i := 0; i < 50; i++ {
+		_, kndrOutputs, _, err := ns.FetchClass(maturityHeight)
+		if err != nil {
+			t.Fatalf("unable to fetch class: %v", err)
+		}
+		if len(kndrOutputs) == 1 {
+			if *kndrOutputs[0].OutPoint() != expOutPoint {
+				t.Fatalf("expected promoted output %v, got %v",
+					expOutPoint, kndrOutputs[0].OutPoint())
+			}
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("output not promoted after commitment spend")
+}
+
+// TestNurseryCancelIncubation asserts that canceling the incubation of a
+// channel stops the confirmation watchers of its outputs, and removes the
+// outputs from the nursery store.
+func TestNurseryCancelIncubation(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[0]
+	baby := babyOutputs[1]
+	if err := ns.Incubate(&kid, []babyOutput{baby}); err != nil {
+		t.Fatalf("unable to incubate outputs: %v", err)
+	}
+	chanPoint := *kid.OriginChanPoint()
+
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 2),
+		spendRegistrations: make(
+			chan chan *chainntnfs.SpendDetail, 2,
+		),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		Notifier: notifier,
+		Store:    ns,
+	})
+	defer close(nursery.quit)
+
+	nursery.mu.Lock()
+	err = nursery.registerCommitConf(&kid, 0)
+	if err == nil {
+		err = nursery.registerTimeoutConf(&baby, 0)
+	}
+	nursery.mu.Unlock()
+	if err != nil {
+		t.Fatalf("unable to register confirmations: %v", err)
+	}
+
+	if err := nursery.CancelIncubation(&chanPoint); err != nil {
+		t.Fatalf("unable to cancel incubation: %v", err)
+	}
+
+	// Both watchers should exit without the nursery shutting down.
+	watchersDone := make(chan struct{})
+	go func() {
+		nursery.wg.Wait()
+		close(watchersDone)
+	}()
+	select {
+	case <-watchersDone:
+	case <-time.After(time.Second):
+		t.Fatalf("confirmation watchers not stopped")
+	}
+
+	exists, err := ns.ChannelExists(&chanPoint)
+	if err != nil {
+		t.Fatalf("unable to check channel existence: %v", err)
+	}
+	if exists {
+		t.Fatalf("channel should have been removed")
+	}
+
+	// The channel is no longer known to the nursery.
+	err = nursery.CancelIncubation(&chanPoint)
+	if err != ErrContractNotFound {
+		t.Fatalf("expected ErrContractNotFound, got: %v", err)
+	}
+}
+
+// TestNurseryIncubateBreachOutputs asserts that the outputs of a justice txn
+// are incubated as preschool outputs awaiting the justice txn's confirmation,
+// and that zero-value outputs are recorded as dropped rather than incubated.
+func TestNurseryIncubateBreachOutputs(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 2),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		Notifier: notifier,
+		Store:    ns,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	zeroSignDesc := signDescriptors[1]
+	zeroSignDesc.Output = &wire.TxOut{
+		PkScript: signDescriptors[1].Output.PkScript,
+	}
+
+	chanPoint := &outPoints[0]
+	outputs := []breachedOutput{
+		makeBreachedOutput(
+			&outPoints[1], lnwallet.CommitmentTimeLock,
+			&signDescriptors[0],
+		),
+		makeBreachedOutput(
+			&outPoints[2], lnwallet.CommitmentTimeLock,
+			&zeroSignDesc,
+		),
+	}
+
+	err = nursery.IncubateBreachOutputs(chanPoint, outputs, 144, nil)
+	if err != nil {
+		t.Fatalf("unable to incubate breach outputs: %v", err)
+	}
+
+	// Only the non-zero output should await the justice txn's
+	// confirmation in the preschool bucket.
+	select {
+	case <-notifier.registrations:
+	case <-time.After(time.Second):
+		t.Fatalf("confirmation notification not registered")
+	}
+
+	preschools, err := ns.FetchPreschools()
+	if err != nil {
+		t.Fatalf("unable to fetch preschools: %v", err)
+	}
+	if len(preschools) != 1 {
+		t.Fatalf("expected 1 preschool output, got %d",
+			len(preschools))
+	}
+
+	kid := preschools[0]
+	if *kid.OutPoint() != outPoints[1] {
+		t.Fatalf("expected preschool output %v, got %v",
+			outPoints[1], kid.OutPoint())
+	}
+	if *kid.OriginChanPoint() != *chanPoint {
+		t.Fatalf("expected origin chan point %v, got %v",
+			chanPoint, kid.OriginChanPoint())
+	}
+	if kid.BlocksToMaturity() != 144 {
+		t.Fatalf("expected blocks to maturity 144, got %d",
+			kid.BlocksToMaturity())
+	}
+
+	dropped, err := ns.FetchDroppedOutputs(chanPoint)
+	if err != nil {
+		t.Fatalf("unable to fetch dropped outputs: %v", err)
+	}
+	if len(dropped) != 1 || dropped[0].outpoint != outPoints[2] ||
+		dropped[0].reason != dropZeroValue {
+
+		t.Fatalf("unexpected dropped outputs: %v", dropped)
+	}
+}
+
+// TestNurseryIncubateBreachOutputsBatch asserts that the outputs of a justice
+// txn are persisted by a single write to the nursery store, along with any
+// dropped outputs.
+func TestNurseryIncubateBreachOutputsBatch(t *testing.T) {
+	store := newMockNurseryStore()
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 2),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		Notifier: notifier,
+		Store:    store,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	zeroSignDesc := signDescriptors[1]
+	zeroSignDesc.Output = &wire.TxOut{
+		PkScript: signDescriptors[1].Output.PkScript,
+	}
+
+	chanPoint := &outPoints[0]
+	outputs := []breachedOutput{
+		makeBreachedOutput(
+			&outPoints[1], lnwallet.CommitmentTimeLock,
+			&signDescriptors[0],
+		),
+		makeBreachedOutput(
+			&outPoints[2], lnwallet.CommitmentTimeLock,
+			&signDescriptors[1],
+		),
+		makeBreachedOutput(
+			&outPoints[3], lnwallet.CommitmentTimeLock,
+			&zeroSignDesc,
+		),
+	}
+
+	err := nursery.IncubateBreachOutputs(chanPoint, outputs, 144, nil)
+	if err != nil {
+		t.Fatalf("unable to incubate breach outputs: %v", err)
+	}
+
+	if calls := store.numCalls("IncubateBatch"); calls != 1 {
+		t.Fatalf("expected 1 call to IncubateBatch, got %d", calls)
+	}
+
+	preschools, err := store.FetchPreschools()
+	if err != nil {
+		t.Fatalf("unable to fetch preschools: %v", err)
+	}
+	if len(preschools) != 2 {
+		t.Fatalf("expected 2 preschool outputs, got %d",
+			len(preschools))
+	}
+
+	dropped, err := store.FetchDroppedOutputs(chanPoint)
+	if err != nil {
+		t.Fatalf("unable to fetch dropped outputs: %v", err)
+	}
+	if len(dropped) != 1 || dropped[0].outpoint != outPoints[3] {
+		t.Fatalf("unexpected dropped outputs: %v", dropped)
+	}
+}
+
+// TestNurseryIncubateIdempotent asserts that repeating an incubation request
+// neither double counts the limbo balance of its outputs, nor registers for
+// their confirmation again, while any outputs not yet tracked are incubated.
+func TestNurseryIncubateIdempotent(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 2),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		Notifier: notifier,
+		Store:    ns,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	kid := kidOutputs[3]
+	chanPoint := *kid.OriginChanPoint()
+	incubate := func(babies []babyOutput) {
+		nursery.mu.Lock()
+		err := nursery.incubate(&incubationRequest{
+			chanPoint:   chanPoint,
+			kidOutputs:  []kidOutput{kid},
+			htlcOutputs: babies,
+		})
+		nursery.mu.Unlock()
+		if err != nil {
+			t.Fatalf("unable to incubate outputs: %v", err)
+		}
+	}
+
+	incubate(babyOutputs[:1])
+	incubate(babyOutputs[:1])
+
+	expBalance := kid.Amount() + babyOutputs[0].Amount()
+	assertChanLimboBalance(t, ns, &chanPoint, expBalance)
+	assertNumChanOutputs(t, ns, &chanPoint, 2)
+
+	// Only the first request should have registered for the commitment's
+	// confirmation.
+	select {
+	case <-notifier.registrations:
+	case <-time.After(time.Second):
+		t.Fatalf("confirmation notification not registered")
+	}
+	select {
+	case <-notifier.registrations:
+		t.Fatalf("confirmation notification registered twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// A request containing an output that isn't yet tracked should only
+	// incubate that output.
+	incubate(babyOutputs[:2])
+
+	expBalance += babyOutputs[1].Amount()
+	assertChanLimboBalance(t, ns, &chanPoint, expBalance)
+	assertNumChanOutputs(t, ns, &chanPoint, 3)
+}
+
+// TestNurseryIncubateOutputsBatch asserts that the outputs of multiple force
+// closed channels are incubated together, and that the confirmation of each
+// channel's commitment output is registered.
+func TestNurseryIncubateOutputsBatch(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 2),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		Notifier: notifier,
+		Store:    ns,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	closeSummaries := []*lnwallet.ForceCloseSummary{
+		{
+			ChanPoint:          outPoints[4],
+			SelfOutpoint:       outPoints[0],
+			SelfOutputSignDesc: &signDescriptors[0],
+			SelfOutputMaturity: 144,
+		},
+		{
+			ChanPoint:          outPoints[5],
+			SelfOutpoint:       outPoints[1],
+			SelfOutputSignDesc: &signDescriptors[1],
+			SelfOutputMaturity: 144,
+		},
+	}
+
+	if err := nursery.IncubateOutputsBatch(closeSummaries); err != nil {
+		t.Fatalf("unable to incubate outputs: %v", err)
+	}
+
+	for _, closeSummary := range closeSummaries {
+		selfAmt := btcutil.Amount(
+			closeSummary.SelfOutputSignDesc.Output.Value,
+		)
+		assertNumChanOutputs(t, ns, &closeSummary.ChanPoint, 1)
+		assertChanLimboBalance(t, ns, &closeSummary.ChanPoint, selfAmt)
+	}
+
+	for range closeSummaries {
+		select {
+		case <-notifier.registrations:
+		case <-time.After(time.Second):
+			t.Fatalf("confirmation notification not registered")
+		}
+	}
+}
+
+// TestNurseryIncubateAnchorOutput asserts that the anchor resolution of a
+// force close summary is incubated as a preschool output with no relative
+// delay, alongside our commitment output.
+func TestNurseryIncubateAnchorOutput(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 2),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		Notifier: notifier,
+		Store:    ns,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	closeSummary := &lnwallet.ForceCloseSummary{
+		ChanPoint:          outPoints[4],
+		SelfOutpoint:       outPoints[0],
+		SelfOutputSignDesc: &signDescriptors[0],
+		SelfOutputMaturity: 144,
+		AnchorResolution: &lnwallet.AnchorResolution{
+			AnchorOutpoint: outPoints[1],
+			AnchorSignDesc: signDescriptors[1],
+		},
+	}
+	if err := nursery.IncubateOutputs(closeSummary, nil, 0); err != nil {
+		t.Fatalf("unable to incubate outputs: %v", err)
+	}
+
+	assertNumChanOutputs(t, ns, &closeSummary.ChanPoint, 2)
+
+	kids, err := ns.FetchPreschools()
+	if err != nil {
+		t.Fatalf("unable to fetch preschool outputs: %v", err)
+	}
+	var anchor *kidOutput
+	for i := range kids {
+		if *kids[i].OutPoint() == outPoints[1] {
+			anchor = &kids[i]
+		}
+	}
+	if anchor == nil {
+		t.Fatalf("anchor output not incubated")
+	}
+	if anchor.WitnessType() != lnwallet.CommitmentAnchor {
+		t.Fatalf("expected anchor witness type, got %v",
+			anchor.WitnessType())
+	}
+	if anchor.BlocksToMaturity() != 0 {
+		t.Fatalf("expected anchor to have no relative delay, got %v",
+			anchor.BlocksToMaturity())
+	}
+
+	// Both the commitment and anchor outputs should await the confirmation
+	// of the commitment txn.
+	for i := 0; i < 2; i++ {
+		select {
+		case <-notifier.registrations:
+		case <-time.After(time.Second):
+			t.Fatalf("confirmation notification not registered")
+		}
+	}
+}
+
+// TestNurseryReportCloseTxid asserts that the txid of a channel's force close
+// txn is persisted upon incubation and exposed in the channel's report.
+func TestNurseryReportCloseTxid(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 1),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		Notifier: notifier,
+		Store:    ns,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	closeTx := wire.NewMsgTx(2)
+	closeTx.AddTxIn(&wire.TxIn{PreviousOutPoint: outPoints[4]})
+	closeTx.AddTxOut(signDescriptors[0].Output)
+
+	closeSummary := &lnwallet.ForceCloseSummary{
+		ChanPoint: outPoints[4],
+		SelfOutpoint: wire.OutPoint{
+			Hash:  closeTx.TxHash(),
+			Index: 0,
+		},
+		CloseTx:            closeTx,
+		SelfOutputSignDesc: &signDescriptors[0],
+		SelfOutputMaturity: 144,
+	}
+	if err := nursery.IncubateOutputs(closeSummary, nil, 0); err != nil {
+		t.Fatalf("unable to incubate outputs: %v", err)
+	}
+
+	report, err := nursery.NurseryReport(&closeSummary.ChanPoint)
+	if err != nil {
+		t.Fatalf("unable to fetch nursery report: %v", err)
+	}
+
+	expected := closeTx.TxHash()
+	if report.closeTxid == nil || *report.closeTxid != expected {
+		t.Fatalf("expected close txid %v, got %v", expected,
+			report.closeTxid)
+	}
+}
+
+// mockSweeper is a Sweeper that returns a fixed txn spending the first
+// numInputs of the provided outputs.
+type mockSweeper struct {
+	numInputs int
+	inputs    []CsvSpendableOutput
+	err       error
+}
+
+func (m *mockSweeper) SweepInputs(
+	inputs []CsvSpendableOutput) (*wire.MsgTx, error) {
+
+	m.inputs = inputs
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	sweepTx := wire.NewMsgTx(2)
+	for _, input := range inputs[:m.numInputs] {
+		sweepTx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: *input.OutPoint(),
+		})
+	}
+	sweepTx.AddTxOut(&wire.TxOut{
+		Value:    1000,
+		PkScript: []byte{0x00, 0x14},
+	})
+
+	return sweepTx, nil
+}
+
+// TestNurserySweeper asserts that sweep txns are crafted by the configured
+// Sweeper, and that txns failing to spend every mature output are rejected.
+func TestNurserySweeper(t *testing.T) {
+	sweeper := &mockSweeper{numInputs: 2}
+	nursery := newUtxoNursery(&NurseryConfig{
+		Sweeper: sweeper,
+	})
+
+	kgtnOutputs := []kidOutput{kidOutputs[0], kidOutputs[1]}
+	height := kgtnOutputs[0].MaturityHeight()
+	sweepTx, _, err := nursery.createSweepTx(height, kgtnOutputs)
+	if err != nil {
+		t.Fatalf("unable to create sweep tx: %v", err)
+	}
+	if len(sweeper.inputs) != len(kgtnOutputs) {
+		t.Fatalf("expected sweeper to receive %d inputs, got %d",
+			len(kgtnOutputs), len(sweeper.inputs))
+	}
+	if len(sweepTx.TxIn) != len(kgtnOutputs) {
+		t.Fatalf("expected sweep tx with %d inputs, got %d",
+			len(kgtnOutputs), len(sweepTx.TxIn))
+	}
+
+	// If the sweeper omits one of the outputs, the sweep must be rejected,
+	// as the omitted output would never graduate.
+	sweeper.numInputs = 1
+	if _, _, err := nursery.createSweepTx(height, kgtnOutputs); err == nil {
+		t.Fatalf("expected sweep tx missing an input to be rejected")
+	}
+}
+
+// TestNurseryBumpStuckCommitments asserts that the anchor output of a
+// commitment txn that remains unconfirmed is spent by a CPFP txn every
+// CpfpThreshold blocks, and that commitment txns without an anchor output are
+// left alone.
+func TestNurseryBumpStuckCommitments(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	// The first channel's commitment txn has both a delayed output and an
+	// anchor output for us, while the second only has a delayed output.
+	newKid := func(hash byte, index uint32, chanPoint wire.OutPoint,
+		witnessType lnwallet.WitnessType) kidOutput {
+
+		kid := kidOutputs[0]
+		kid.outpoint = wire.OutPoint{
+			Hash:  chainhash.Hash{hash},
+			Index: index,
+		}
+		kid.witnessType = witnessType
+		kid.originChanPoint = chanPoint
+		kid.entryHeight = 100
+
+		return kid
+	}
+	kids := []kidOutput{
+		newKid(1, 0, outPoints[0], lnwallet.CommitmentTimeLock),
+		newKid(1, 1, outPoints[0], lnwallet.CommitmentAnchor),
+		newKid(2, 0, outPoints[1], lnwallet.CommitmentTimeLock),
+	}
+	for i := range kids {
+		if err := ns.Incubate(&kids[i], nil); err != nil {
+			t.Fatalf("unable to incubate output: %v", err)
+		}
+	}
+
+	var published []*wire.MsgTx
+	sweeper := &mockSweeper{numInputs: 1}
+	nursery := newUtxoNursery(&NurseryConfig{
+		CpfpSweeper:   sweeper,
+		CpfpThreshold: 6,
+		Store:         ns,
+		PublishTransaction: func(tx *wire.MsgTx) error {
+			published = append(published, tx)
+			return nil
+		},
+	})
+
+	// No CPFP txn should be published before the threshold is reached,
+	// nor at heights that aren't a multiple of it.
+	for _, height := range []uint32{100, 105, 107, 111} {
+		nursery.bumpStuckCommitments(height)
+	}
+	if len(published) != 0 {
+		t.Fatalf("expected no CPFP txns, got %d", len(published))
+	}
+
+	// Every CpfpThreshold blocks, a CPFP txn spending only the anchor
+	// output should be published.
+	for i, height := range []uint32{106, 112} {
+		nursery.bumpStuckCommitments(height)
+		if len(published) != i+1 {
+			t.Fatalf("expected %d CPFP txns at height=%d, got %d",
+				i+1, height, len(published))
+		}
+
+		cpfpTx := published[i]
+		if len(cpfpTx.TxIn) != 1 ||
+			cpfpTx.TxIn[0].PreviousOutPoint != kids[1].outpoint {
+
+			t.Fatalf("expected CPFP txn to spend only anchor "+
+				"output %v", kids[1].outpoint)
+		}
+	}
+
+	// A failure to create the CPFP txn shouldn't publish anything.
+	sweeper.err = fmt.Errorf("no wallet inputs")
+	nursery.bumpStuckCommitments(118)
+	if len(published) != 2 {
+		t.Fatalf("expected no additional CPFP txns, got %d",
+			len(published)-2)
+	}
+}
+
+// mockExternalSweeper is an ExternalSweeper that records the outputs handed
+// off to it, and reports a fixed sweep txid.
+type mockExternalSweeper struct {
+	inputs    []CsvSpendableOutput
+	sweepTxID chainhash.Hash
+}
+
+func (m *mockExternalSweeper) SweepOutputs(
+	inputs []CsvSpendableOutput) (*chainhash.Hash, error) {
+
+	m.inputs = inputs
+	return &m.sweepTxID, nil
+}
+
+// TestNurseryExternalSweeper asserts that mature kindergarten outputs are
+// handed off to the configured ExternalSweeper rather than swept by the
+// nursery, and that they graduate once the reported sweep txn confirms.
+func TestNurseryExternalSweeper(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[3]
+	if err := ns.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate output: %v", err)
+	}
+	if err := ns.PreschoolToKinder(&kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	sweeper := &mockExternalSweeper{
+		sweepTxID: chainhash.Hash{0x01},
+	}
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 1),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		DB:              cdb,
+		ExternalSweeper: sweeper,
+		Notifier:        notifier,
+		Store:           ns,
+	})
+	defer nursery.Stop()
+
+	height := kid.MaturityHeight()
+
+	nursery.mu.Lock()
+	finalTxns, err := nursery.finalizeHeight(height)
+	if err == nil {
+		err = nursery.broadcastHeight(height)
+	}
+	nursery.mu.Unlock()
+	if err != nil {
+		t.Fatalf("unable to sweep height=%d: %v", height, err)
+	}
+
+	if len(finalTxns) != 0 {
+		t.Fatalf("expected no finalized sweep txns, got %d",
+			len(finalTxns))
+	}
+	if len(sweeper.inputs) != 1 ||
+		*sweeper.inputs[0].OutPoint() != *kid.OutPoint() {
+
+		t.Fatalf("expected output %v to be handed off, got %v",
+			kid.OutPoint(), sweeper.inputs)
+	}
+
+	var confEvent *chainntnfs.ConfirmationEvent
+	select {
+	case confEvent = <-notifier.registrations:
+	case <-time.After(time.Second):
+		t.Fatalf("sweep confirmation not registered")
+	}
+
+	confEvent.Confirmed <- &chainntnfs.TxConfirmation{
+		BlockHeight: height + 1,
+	}
+
+	for i := 0; i < 50; i++ {
+		_, kndrOutputs, _, err := ns.FetchClass(height)
+		if err != nil {
+			t.Fatalf("unable to fetch class: %v", err)
+		}
+		if len(kndrOutputs) == 0 {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("output not graduated after external sweep confirmed")
+}
+
+// TestCreateSweepTxnsExcludesImmature asserts that kindergarten outputs which
+// aren't yet spendable are excluded from the sweep txns and returned, such that
+// they can be deferred.
+func TestCreateSweepTxnsExcludesImmature(t *testing.T) {
+	sweeper := &mockSweeper{numInputs: 1}
+	nursery := newUtxoNursery(&NurseryConfig{
+		Sweeper: sweeper,
+	})
+
+	// At height 600, the output confirmed at height 500 with a delay of 28
+	// blocks is mature, while the output confirmed at 1000 is not.
+	mature := kidOutputs[3]
+	immature := kidOutputs[0]
+
+	finalTxns, _, deferred, err := nursery.createSweepTxns(
+		600, []kidOutput{immature, mature},
+	)
+	if err != nil {
+		t.Fatalf("unable to create sweep txns: %v", err)
+	}
+
+	if len(finalTxns) != 1 {
+		t.Fatalf("expected 1 sweep txn, got %d", len(finalTxns))
+	}
+	if len(sweeper.inputs) != 1 ||
+		*sweeper.inputs[0].OutPoint() != *mature.OutPoint() {
+
+		t.Fatalf("expected sweep of only output %v",
+			mature.OutPoint())
+	}
+
+	if len(deferred) != 1 || *deferred[0].OutPoint() != *immature.OutPoint() {
+		t.Fatalf("expected output %v to be deferred, got %v",
+			immature.OutPoint(), deferred)
+	}
+}
+
+// mockOutputSelector is an OutputSelector that returns predetermined sets of
+// outputs.
+type mockOutputSelector struct {
+	outputSets [][]kidOutput
+}
+
+func (m *mockOutputSelector) SelectOutputs(_ uint32,
+	_ []kidOutput) [][]kidOutput {
+
+	return m.outputSets
+}
+
+// TestCreateSweepTxnsOutputSelector asserts that a sweep txn is crafted for
+// each set of outputs returned by the OutputSelector, that unselected outputs
+// are deferred, and that outputs which aren't candidates or were already
+// selected are ignored.
+func TestCreateSweepTxnsOutputSelector(t *testing.T) {
+	kid0, kid1, kid2 := kidOutputs[0], kidOutputs[1], kidOutputs[2]
+
+	sweeper := &mockSweeper{numInputs: 1}
+	nursery := newUtxoNursery(&NurseryConfig{
+		OutputSelector: &mockOutputSelector{
+			outputSets: [][]kidOutput{
+				{kid1},
+				{kid0, kid1},
+				{kidOutputs[3]},
+			},
+		},
+		Sweeper: sweeper,
+	})
+
+	finalTxns, _, deferred, err := nursery.createSweepTxns(
+		2000, []kidOutput{kid0, kid1, kid2},
+	)
+	if err != nil {
+		t.Fatalf("unable to create sweep txns: %v", err)
+	}
+
+	expected := []wire.OutPoint{*kid1.OutPoint(), *kid0.OutPoint()}
+	if len(finalTxns) != len(expected) {
+		t.Fatalf("expected %d sweep txns, got %d", len(expected),
+			len(finalTxns))
+	}
+	for i, finalTx := range finalTxns {
+		if len(finalTx.TxIn) != 1 ||
+			finalTx.TxIn[0].PreviousOutPoint != expected[i] {
+
+			t.Fatalf("expected sweep txn #%d to spend %v", i,
+				expected[i])
+		}
+	}
+
+	if len(deferred) != 1 || *deferred[0].OutPoint() != *kid2.OutPoint() {
+		t.Fatalf("expected output %v to be deferred, got %v",
+			kid2.OutPoint(), deferred)
+	}
+}
+
+// TestNurseryFinalizeSweepFailure asserts that a failure to craft the sweep
+// txns at a height finalizes the height without any txns, and defers the
+// kindergarten outputs to the next height so that their sweep is retried.
+func TestNurseryFinalizeSweepFailure(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[3]
+	if err := ns.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate output: %v", err)
+	}
+	if err := ns.PreschoolToKinder(&kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		Store: ns,
+		Sweeper: &mockSweeper{
+			err: fmt.Errorf("wallet locked"),
+		},
+	})
+
+	classHeight := kid.MaturityHeight()
+
+	nursery.mu.Lock()
+	finalTxns, err := nursery.finalizeHeight(classHeight)
+	nursery.mu.Unlock()
+	if err != nil {
+		t.Fatalf("unable to finalize height: %v", err)
+	}
+	if len(finalTxns) != 0 {
+		t.Fatalf("expected no finalized txns, got %d", len(finalTxns))
+	}
+
+	assertLastFinalizedHeight(t, ns, classHeight)
+	assertKndrNotAtMaturityHeight(t, ns, &kid)
+
+	_, nextOutputs, _, err := ns.FetchClass(classHeight + 1)
+	if err != nil {
+		t.Fatalf("unable to fetch class: %v", err)
+	}
+	if len(nextOutputs) != 1 {
+		t.Fatalf("expected output to be deferred to height %d",
+			classHeight+1)
+	}
+}
+
+// TestNurseryBuildSweepTxPreview asserts that the sweep txn previewed for a
+// channel matches the txn signed once its kindergarten outputs mature, and
+// that corrupt outputs are left out of the preview.
+func TestNurseryBuildSweepTxPreview(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	for _, i := range []int{1, 2, 3} {
+		kid := kidOutputs[i]
+		if err := ns.Incubate(&kid, nil); err != nil {
+			t.Fatalf("unable to incubate output: %v", err)
+		}
+		if err := ns.PreschoolToKinder(&kid); err != nil {
+			t.Fatalf("unable to move pscl output to kndr: %v", err)
+		}
+	}
+
+	// Overwrite the output maturing at a later height with a record that
+	// decodes, but has a zero amount, which should prevent it from being
+	// included in the preview.
+	corrupt := kidOutputs[1]
+	corrupt.amt = 0
+
+	var b bytes.Buffer
+	if err := corrupt.Encode(&b); err != nil {
+		t.Fatalf("unable to serialize kid output: %v", err)
+	}
+
+	err = ns.db.Update(func(tx *bolt.Tx) error {
+		chanBucket := ns.getChannelBucket(tx, corrupt.OriginChanPoint())
+		pfxOutputKey, err := prefixOutputKey(
+			kndrPrefix, corrupt.OutPoint(),
+		)
+		if err != nil {
+			return err
+		}
+
+		return chanBucket.Put(pfxOutputKey, b.Bytes())
+	})
+	if err != nil {
+		t.Fatalf("unable to corrupt output: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		Store:     ns,
+		Signer:    &mockSigner{key: alicePrivKey},
+		Estimator: &lnwallet.StaticFeeEstimator{FeeRate: 40},
+		GenSweepScript: func(lnwallet.AddressType) ([]byte, error) {
+			return bytes.Repeat([]byte{0x00}, 22), nil
+		},
+		SweepConfTarget: defaultSweepConfTarget,
+	})
+
+	classHeight := kidOutputs[2].MaturityHeight()
+	nursery.bestHeight = classHeight
+
+	chanPoint := kidOutputs[2].OriginChanPoint()
+	previewTx, err := nursery.BuildSweepTxPreview(chanPoint)
+	if err != nil {
+		t.Fatalf("unable to build sweep tx preview: %v", err)
+	}
+
+	nursery.mu.Lock()
+	finalTxns, err := nursery.finalizeHeight(classHeight)
+	nursery.mu.Unlock()
+	if err != nil {
+		t.Fatalf("unable to finalize height: %v", err)
+	}
+	if len(finalTxns) != 1 {
+		t.Fatalf("expected 1 finalized txn, got %d", len(finalTxns))
+	}
+
+	var previewBuf, finalBuf bytes.Buffer
+	if err := previewTx.Serialize(&previewBuf); err != nil {
+		t.Fatalf("unable to serialize preview txn: %v", err)
+	}
+	if err := finalTxns[0].Serialize(&finalBuf); err != nil {
+		t.Fatalf("unable to serialize finalized txn: %v", err)
+	}
+	if !bytes.Equal(previewBuf.Bytes(), finalBuf.Bytes()) {
+		t.Fatalf("preview txn %v doesn't match finalized txn %v",
+			previewTx.TxHash(), finalTxns[0].TxHash())
+	}
+}
+
+// TestNurseryZeroFeeEstimate asserts that a zero fee estimate prevents a sweep
+// txn from being crafted, deferring the kindergarten outputs to the next
+// height rather than broadcasting a txn that would never be relayed.
+func TestNurseryZeroFeeEstimate(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[3]
+	if err := ns.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate output: %v", err)
+	}
+	if err := ns.PreschoolToKinder(&kid); err != nil {
+		t.Fatalf("unable to move pscl output to kndr: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		Store: ns,
+		Estimator: &confTargetFeeEstimator{
+			feeRates: map[uint32]btcutil.Amount{
+				defaultSweepConfTarget: 0,
+			},
+		},
+		GenSweepScript: func(lnwallet.AddressType) ([]byte, error) {
+			return bytes.Repeat([]byte{0x00}, 22), nil
+		},
+		SweepConfTarget: defaultSweepConfTarget,
+	})
+
+	_, err = nursery.estimateFeeRate(defaultSweepConfTarget)
+	if err != ErrZeroFeeEstimate {
+		t.Fatalf("expected ErrZeroFeeEstimate, got %v", err)
+	}
+
+	classHeight := kid.MaturityHeight()
+
+	nursery.mu.Lock()
+	finalTxns, err := nursery.finalizeHeight(classHeight)
+	nursery.mu.Unlock()
+	if err != nil {
+		t.Fatalf("unable to finalize height: %v", err)
+	}
+	if len(finalTxns) != 0 {
+		t.Fatalf("expected no finalized txns, got %d", len(finalTxns))
+	}
+
+	assertKndrNotAtMaturityHeight(t, ns, &kid)
+
+	_, nextOutputs, _, err := ns.FetchClass(classHeight + 1)
+	if err != nil {
+		t.Fatalf("unable to fetch class: %v", err)
+	}
+	if len(nextOutputs) != 1 {
+		t.Fatalf("expected output to be deferred to height %d",
+			classHeight+1)
+	}
+}
+
+// TestNurseryDeferOverdueKinder asserts that a commitment output whose
+// maturity height has already been processed by the nursery is rescheduled
+// for the next block upon being promoted to the kindergarten bucket.
+func TestNurseryDeferOverdueKinder(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[3]
+	if err := ns.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate output: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		Store: ns,
+	})
+	nursery.bestHeight = kid.MaturityHeight() + 5
+
+	if !nursery.promotePreschool(&kid, nil) {
+		t.Fatalf("unable to promote preschool output")
+	}
+
+	assertKndrNotAtMaturityHeight(t, ns, &kid)
+
+	_, nextOutputs, _, err := ns.FetchClass(nursery.bestHeight + 1)
+	if err != nil {
+		t.Fatalf("unable to fetch class: %v", err)
+	}
+	if len(nextOutputs) != 1 {
+		t.Fatalf("expected overdue output to be scheduled at "+
+			"height %d", nursery.bestHeight+1)
+	}
+}
+
+// TestNurseryPromotePreschoolRetry asserts that a failed promotion of a
+// commitment output is retried, and that the output remains in the preschool
+// bucket once the retries are exhausted.
+func TestNurseryPromotePreschoolRetry(t *testing.T) {
+	store := newMockNurseryStore()
+
+	kid := kidOutputs[3]
+	if err := store.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate output: %v", err)
+	}
+
+	nursery := newUtxoNursery(&NurseryConfig{
+		Store:                  store,
+		TransitionRetries:      2,
+		TransitionRetryBackoff: time.Millisecond,
+	})
+
+	// The promotion should succeed once the injected failures have been
+	// exhausted.
+	errInjected := fmt.Errorf("injected failure")
+	store.failNext("PreschoolToKinder", 2, errInjected)
+
+	if !nursery.promotePreschool(&kid, nil) {
+		t.Fatalf("unable to promote preschool output")
+	}
+	if calls := store.numCalls("PreschoolToKinder"); calls != 3 {
+		t.Fatalf("expected 3 attempts to promote output, got %d",
+			calls)
+	}
+
+	_, kndrOutputs, _, err := store.FetchClass(kid.MaturityHeight())
+	if err != nil {
+		t.Fatalf("unable to fetch class: %v", err)
+	}
+	if len(kndrOutputs) != 1 {
+		t.Fatalf("expected output to be promoted to kindergarten")
+	}
+
+	// A promotion that fails on every attempt should leave the output in
+	// the preschool bucket.
+	pscl := kidOutputs[0]
+	if err := store.Incubate(&pscl, nil); err != nil {
+		t.Fatalf("unable to incubate output: %v", err)
+	}
+	store.failNext("PreschoolToKinder", -1, errInjected)
+
+	if nursery.promotePreschool(&pscl, nil) {
+		t.Fatalf("expected promotion of preschool output to fail")
+	}
+
+	psclOutputs, err := store.FetchPreschools()
+	if err != nil {
+		t.Fatalf("unable to fetch preschool outputs: %v", err)
+	}
+	if len(psclOutputs) != 1 ||
+		*psclOutputs[0].OutPoint() != *pscl.OutPoint() {
+
+		t.Fatalf("expected output %v to remain in preschool, got %v",
+			pscl.OutPoint(), psclOutputs)
+	}
+}
+
+// TestNurseryGraduateClassStoreFailure asserts that a height is not recorded
+// as graduated if the nursery store fails to persist it, such that it will be
+// processed again.
+func TestNurseryGraduateClassStoreFailure(t *testing.T) {
+	store := newMockNurseryStore()
+	nursery := newUtxoNursery(&NurseryConfig{
+		Store: store,
+	})
+
+	store.failNext("GraduateHeight", 1, fmt.Errorf("injected failure"))
+
+	ctx := context.Background()
+	if err := nursery.graduateClass(ctx, 100, nil); err == nil {
+		t.Fatalf("expected graduation of height 100 to fail")
+	}
+
+	lastGradHeight, err := store.LastGraduatedHeight()
+	if err != nil {
+		t.Fatalf("unable to fetch last graduated height: %v", err)
+	}
+	if lastGradHeight != 0 {
+		t.Fatalf("expected height 100 not to be graduated, last "+
+			"graduated height is %d", lastGradHeight)
+	}
+
+	// Once the store recovers, the height should graduate.
+	if err := nursery.graduateClass(ctx, 100, nil); err != nil {
+		t.Fatalf("unable to graduate height 100: %v", err)
+	}
+
+	lastGradHeight, err = store.LastGraduatedHeight()
+	if err != nil {
+		t.Fatalf("unable to fetch last graduated height: %v", err)
+	}
+	if lastGradHeight != 100 {
+		t.Fatalf("expected last graduated height 100, got %d",
+			lastGradHeight)
+	}
+}
+
+// TestNurseryIncubateRecordsDropped asserts that the dropped outputs and close
+// txid of a channel are persisted atomically with its incubated outputs, and
+// aren't recorded again for a duplicate request.
+func TestNurseryIncubateRecordsDropped(t *testing.T) {
+	store := newMockNurseryStore()
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 1),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		Notifier: notifier,
+		Store:    store,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	kid := kidOutputs[0]
+	chanPoint := *kid.OriginChanPoint()
+	closeTxid := timeoutTx.TxHash()
+	dropped := []droppedOutput{{
+		outpoint: outPoints[1],
+		amt:      1,
+		reason:   dropUneconomical,
+	}}
+	incubate := func() error {
+		nursery.mu.Lock()
+		defer nursery.mu.Unlock()
+
+		return nursery.incubate(&incubationRequest{
+			chanPoint:  chanPoint,
+			kidOutputs: []kidOutput{kid},
+			dropped:    dropped,
+			closeTxid:  &closeTxid,
+		})
+	}
+
+	assertRecorded := func(expRecorded bool) {
+		t.Helper()
+
+		droppedOutputs, err := store.FetchDroppedOutputs(&chanPoint)
+		if err != nil {
+			t.Fatalf("unable to fetch dropped outputs: %v", err)
+		}
+		txid, err := store.ChannelCloseTx(&chanPoint)
+		if err != nil {
+			t.Fatalf("unable to fetch close txid: %v", err)
+		}
+
+		switch {
+		case expRecorded && (len(droppedOutputs) != 1 || txid == nil):
+			t.Fatalf("expected dropped outputs and close txid to " +
+				"be recorded")
+
+		case !expRecorded && (len(droppedOutputs) != 0 || txid != nil):
+			t.Fatalf("expected dropped outputs and close txid not " +
+				"to be recorded")
+		}
+	}
+
+	// If the outputs can't be persisted, neither should the dropped
+	// outputs nor the close txid.
+	store.failNext("IncubateBatch", 1, fmt.Errorf("injected failure"))
+	if err := incubate(); err == nil {
+		t.Fatalf("expected incubation to fail")
+	}
+	assertRecorded(false)
+
+	if err := incubate(); err != nil {
+		t.Fatalf("unable to incubate outputs: %v", err)
+	}
+	assertRecorded(true)
+
+	// A duplicate request shouldn't record them again.
+	numCalls := store.numCalls("RecordDroppedOutputs")
+	if err := incubate(); err != nil {
+		t.Fatalf("unable to incubate outputs: %v", err)
+	}
+	if store.numCalls("RecordDroppedOutputs") != numCalls {
+		t.Fatalf("dropped outputs recorded for duplicate request")
+	}
+}
+
+// TestNurseryBeginIncubationFailure asserts that an incubation request that
+// can't be persisted is reported as failed to the caller, as it would be lost
+// upon restart, while still being retried upon the arrival of the next block.
+func TestNurseryBeginIncubationFailure(t *testing.T) {
+	store := newMockNurseryStore()
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 1),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		IncubateRetries:      1,
+		IncubateRetryBackoff: time.Millisecond,
+		Notifier:             notifier,
+		Store:                store,
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	kid := kidOutputs[0]
+	req := &incubationRequest{
+		chanPoint:  *kid.OriginChanPoint(),
+		kidOutputs: []kidOutput{kid},
+	}
+
+	store.failNext("IncubateBatch", 2, fmt.Errorf("injected failure"))
+	if err := nursery.beginIncubation(req); err == nil {
+		t.Fatalf("expected unpersisted incubation to fail")
+	}
+	if calls := store.numCalls("IncubateBatch"); calls != 2 {
+		t.Fatalf("expected 2 attempts to persist incubation, got %d",
+			calls)
+	}
+	if len(nursery.pendingIncubations) != 1 {
+		t.Fatalf("expected incubation to be queued for retry")
+	}
+
+	nursery.retryPendingIncubations()
+
+	if len(nursery.pendingIncubations) != 0 {
+		t.Fatalf("expected queued incubation to be persisted")
+	}
+	preschools, err := store.FetchPreschools()
+	if err != nil {
+		t.Fatalf("unable to fetch preschools: %v", err)
+	}
+	if len(preschools) != 1 {
+		t.Fatalf("expected 1 preschool output, got %d",
+			len(preschools))
+	}
+}
+
+// TestNurserySweepNoDelayKinder asserts that a promoted commitment output
+// without a relative timelock is swept immediately, by finalizing and
+// broadcasting its height ahead of time.
+func TestNurserySweepNoDelayKinder(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	kid := kidOutputs[3]
+	kid.blocksToMaturity = 0
+	if err := ns.Incubate(&kid, nil); err != nil {
+		t.Fatalf("unable to incubate output: %v", err)
+	}
+
+	var published []*wire.MsgTx
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 1),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		Notifier: notifier,
+		PublishTransaction: func(tx *wire.MsgTx) error {
+			published = append(published, tx)
+			return nil
+		},
+		Store:                   ns,
+		SweepNoDelayImmediately: true,
+		Sweeper:                 &mockSweeper{numInputs: 1},
+	})
+	defer func() {
+		close(nursery.quit)
+		nursery.wg.Wait()
+	}()
+
+	// The commitment confirmed before the nursery's best height, so the
+	// output is scheduled at the next height upon its promotion.
+	nursery.bestHeight = kid.MaturityHeight() + 5
+	nextHeight := nursery.bestHeight + 1
+
+	if !nursery.promotePreschool(&kid, nil) {
+		t.Fatalf("unable to promote preschool output")
+	}
+
+	if err := nursery.sweepNoDelayKinder(&kid); err != nil {
+		t.Fatalf("unable to sweep output: %v", err)
+	}
+
+	// The next height should have been finalized ahead of time, and its
+	// sweep broadcast.
+	assertLastFinalizedHeight(t, ns, nextHeight)
+
+	if len(published) != 1 || len(published[0].TxIn) != 1 ||
+		published[0].TxIn[0].PreviousOutPoint != *kid.OutPoint() {
+
+		t.Fatalf("expected sweep of output %v to be published, got %v",
+			kid.OutPoint(), published)
+	}
+
+	select {
+	case <-notifier.registrations:
+	case <-time.After(time.Second):
+		t.Fatalf("sweep confirmation not registered")
+	}
+}
+
+// TestIsEconomical asserts that outputs are only incubated if their value
+// exceeds the estimated cost of sweeping them by the configured threshold.
+func TestIsEconomical(t *testing.T) {
+	nursery := newUtxoNursery(&NurseryConfig{
+		EconomicalSweepThreshold: 1,
+	})
+
+	const feePerWeight = 10
+	inputWeight := lnwallet.InputSize*4 + lnwallet.ToLocalTimeoutWitnessSize
+	sweepCost := btcutil.Amount(feePerWeight * inputWeight)
+
+	kid := kidOutputs[0]
+	kid.witnessType = lnwallet.CommitmentTimeLock
+
+	// An output worth exactly its sweep cost should be abandoned.
+	kid.amt = sweepCost
+	if nursery.isEconomical(&kid, feePerWeight) {
+		t.Fatalf("expected output worth %v to be uneconomical", kid.amt)
+	}
+
+	// An output worth more than its sweep cost should be incubated.
+	kid.amt = sweepCost + 1
+	if !nursery.isEconomical(&kid, feePerWeight) {
+		t.Fatalf("expected output worth %v to be economical", kid.amt)
+	}
+
+	// A zero fee rate indicates the check is disabled.
+	kid.amt = 1
+	if !nursery.isEconomical(&kid, 0) {
+		t.Fatalf("expected check to be disabled without a fee rate")
+	}
+}
+
+// mockNurseryMetrics is a NurseryMetrics implementation that records the
+// counters and gauges reported by the nursery.
+type mockNurseryMetrics struct {
+	noopNurseryMetrics
+
+	incubated    map[string]int
+	swept        map[string]int
+	graduated    int
+	limboBalance btcutil.Amount
+	timeInState  map[string][]uint32
+}
+
+func newMockNurseryMetrics() *mockNurseryMetrics {
+	return &mockNurseryMetrics{
+		incubated:   make(map[string]int),
+		swept:       make(map[string]int),
+		timeInState: make(map[string][]uint32),
+	}
+}
+
+func (m *mockNurseryMetrics) ObserveTimeInState(state string, actual,
+	expected uint32) {
+
+	m.timeInState[state] = append(m.timeInState[state], actual)
+}
+
+func (m *mockNurseryMetrics) AddOutputsIncubated(state string, n int) {
+	m.incubated[state] += n
+}
+
+func (m *mockNurseryMetrics) AddOutputsSwept(state string, n int) {
+	m.swept[state] += n
+}
+
+func (m *mockNurseryMetrics) AddOutputsGraduated(n int) {
+	m.graduated += n
+}
+
+func (m *mockNurseryMetrics) SetLimboBalance(balance btcutil.Amount) {
+	m.limboBalance = balance
+}
+
+// TestNurseryMetricsIncubate asserts that incubating outputs increments the
+// incubation counters and updates the limbo balance gauge.
+func TestNurseryMetricsIncubate(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	metrics := newMockNurseryMetrics()
+	nursery := newUtxoNursery(&NurseryConfig{
+		Metrics: metrics,
+		Store:   ns,
+	})
+
+	req := &incubationRequest{
+		chanPoint:   *babyOutputs[0].OriginChanPoint(),
+		htlcOutputs: babyOutputs,
+	}
+
+	nursery.mu.Lock()
+	err = nursery.incubate(req)
+	nursery.mu.Unlock()
+	if err != nil {
+		t.Fatalf("unable to incubate outputs: %v", err)
+	}
+
+	var expBalance btcutil.Amount
+	for i := range babyOutputs {
+		expBalance += babyOutputs[i].Amount()
+	}
+
+	if metrics.incubated[string(cribPrefix)] != len(babyOutputs) {
+		t.Fatalf("expected %d incubated crib outputs, got %d",
+			len(babyOutputs), metrics.incubated[string(cribPrefix)])
+	}
+	if metrics.incubated[string(psclPrefix)] != 0 {
+		t.Fatalf("expected no incubated pscl outputs, got %d",
+			metrics.incubated[string(psclPrefix)])
+	}
+	if metrics.limboBalance != expBalance {
+		t.Fatalf("expected limbo balance %v, got %v", expBalance,
+			metrics.limboBalance)
+	}
+}
+
+// TestNurseryTimeInStateAfterRestart asserts that the number of blocks an
+// output spends in the preschool state is reported upon its promotion, even if
+// the nursery restarted after the output was incubated.
+func TestNurseryTimeInStateAfterRestart(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to open channel db: %v", err)
+	}
+	defer cleanUp()
+
+	ns, err := newNurseryStore(&bitcoinGenesis, cdb)
+	if err != nil {
+		t.Fatalf("unable to open nursery store: %v", err)
+	}
+
+	notifier := &mockConfNotifier{
+		registrations: make(chan *chainntnfs.ConfirmationEvent, 1),
+	}
+	nursery := newUtxoNursery(&NurseryConfig{
+		Notifier: notifier,
+		Store:    ns,
+	})
+	nursery.bestHeight = 100
+
+	kid := kidOutputs[3]
+	nursery.mu.Lock()
+	err = nursery.incubate(&incubationRequest{
+		chanPoint:  *kid.OriginChanPoint(),
+		kidOutputs: []kidOutput{kid},
+	})
+	nursery.mu.Unlock()
+	if err != nil {
+		t.Fatalf("unable to incubate output: %v", err)
+	}
+	close(nursery.quit)
+	nursery.wg.Wait()
+
+	// A restarted nursery only learns of the output's entry height from
+	// the nursery store.
+	metrics := newMockNurseryMetrics()
+	nursery = newUtxoNursery(&NurseryConfig{
+		Metrics: metrics,
+		Store:   ns,
+	})
+
+	preschools, err := ns.FetchPreschools()
+	if err != nil {
+		t.Fatalf("unable to fetch preschools: %v", err)
+	}
+	if len(preschools) != 1 {
+		t.Fatalf("expected 1 preschool output, got %d",
+			len(preschools))
+	}
+
+	pscl := preschools[0]
+	pscl.SetConfHeight(110)
+	if !nursery.promotePreschool(&pscl, nil) {
+		t.Fatalf("unable to promote preschool output")
+	}
+
+	observed := metrics.timeInState[string(psclPrefix)]
+	if len(observed) != 1 || observed[0] != 10 {
+		t.Fatalf("expected 10 blocks in preschool, got %v", observed)
+	}
+}